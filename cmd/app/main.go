@@ -2,16 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq" // Импорт драйвера PostgreSQL
+	"github.com/ponomare0v/todo-go-app/pkg/grpcapi"
 	"github.com/ponomare0v/todo-go-app/pkg/handler"
+	"github.com/ponomare0v/todo-go-app/pkg/health"
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
 	"github.com/ponomare0v/todo-go-app/pkg/repository"
 	"github.com/ponomare0v/todo-go-app/pkg/server"
 	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/ponomare0v/todo-go-app/pkg/tracing"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
@@ -38,29 +46,106 @@ func main() {
 		logrus.Fatalf("ошибка загрузки переменных окружения: %s", err.Error())
 	}
 
-	db, err := repository.NewPostgresDB(repository.Config{
-		Host:     viper.GetString("db.host"),
-		Port:     viper.GetString("db.port"),
-		Username: viper.GetString("db.username"),
-		Password: os.Getenv("DB_PASSWORD"),
-		DBName:   viper.GetString("db.dbname"),
-		SSLMode:  viper.GetString("db.sslmode"),
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			logrus.Fatalf("ошибка миграции: %s", err.Error())
+		}
+		return
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    viper.GetString("tracing.exporter"),
+		Endpoint:    viper.GetString("tracing.endpoint"),
+		Insecure:    viper.GetBool("tracing.insecure"),
+		ServiceName: viper.GetString("tracing.service_name"),
+		SampleRatio: viper.GetFloat64("tracing.sample_ratio"),
 	})
+	if err != nil {
+		logrus.Fatalf("не удалось настроить трассировку: %s", err.Error())
+	}
+
+	checker := health.New(viper.GetDuration("health.check_timeout"))
+
+	repos, closeStorage, err := initRepository(checker)
 	if err != nil {
 		logrus.Fatalf("не удалось инициализоровать БД: %s", err.Error())
 	}
 
-	repos := repository.NewRepository(db)
-	services := service.NewService(repos)
-	handlers := handler.NewHandler(services)
+	limits, err := initRateLimits()
+	if err != nil {
+		logrus.Fatalf("не удалось настроить ограничение запросов: %s", err.Error())
+	}
+
+	oidcProviders, err := initOIDC()
+	if err != nil {
+		logrus.Fatalf("не удалось настроить вход через OIDC: %s", err.Error())
+	}
+
+	services := service.NewService(repository.Instrument(repos), service.Config{Lockout: limits.lockout, OIDC: oidcProviders})
+	// бизнес-метрики считаются на каждый опрос Prometheus, трейсы для них не нужны
+	if err := metrics.RegisterBusiness(services.Admin.GetTotals); err != nil {
+		logrus.Fatalf("не удалось зарегистрировать метрики: %s", err.Error())
+	}
+	services = service.Trace(services)
+
+	handlers := handler.NewHandler(services, handler.Config{
+		RequestTimeout: viper.GetDuration("request_timeout"),
+		Health:         checker,
+		AuthLimiter:    limits.auth,
+		APILimiter:     limits.api,
+		TrustedProxies: limits.trustedProxies,
+		PublicURL:      viper.GetString("public_url"),
+		OIDCSuccessURL: viper.GetString("oidc.success_url"),
+
+		GraphQLPlayground: viper.GetBool("graphql.playground"),
+	})
 
 	srv := new(server.Server)
 	go func() {
-		if err := srv.Run(viper.GetString("port"), handlers.InitRoutes()); err != nil {
+		// после Shutdown ListenAndServe возвращает ErrServerClosed - это штатная остановка, а не ошибка
+		if err := srv.Run(viper.GetString("port"), handlers.InitRoutes()); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			logrus.Fatalf("error occured while running http server: %s", err.Error())
 		}
 	}()
 
+	grpcSrv := new(server.GRPCServer)
+	grpcStopped := checker.Worker("grpc")
+	go func() {
+		err := grpcSrv.Run(viper.GetString("grpc.port"), grpcapi.NewHandler(services, grpcapi.Config{
+			RequestTimeout: viper.GetDuration("request_timeout"),
+		}).NewServer())
+		grpcStopped(err)
+		if err != nil {
+			logrus.Fatalf("error occured while running grpc server: %s", err.Error())
+		}
+	}()
+
+	var metricsSrv *server.Server
+	if port := viper.GetString("metrics.port"); port != "" {
+		metricsSrv = new(server.Server)
+		metricsStopped := checker.Worker("metrics")
+		mux := http.NewServeMux()
+		mux.Handle(viper.GetString("metrics.path"), metrics.Handler())
+		go func() {
+			err := metricsSrv.Run(port, mux)
+			metricsStopped(err)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.Fatalf("error occured while running metrics server: %s", err.Error())
+			}
+		}()
+	}
+
+	// фоновый импорт из Trello и Todoist: задания хранятся в БД, поэтому после перезапуска продолжаются с того же места
+	importsCtx, stopImports := context.WithCancel(context.Background())
+	importsStopped := checker.Worker("imports")
+	importsDone := make(chan struct{})
+	go func() {
+		defer close(importsDone)
+		runImportJobs(importsCtx, services.ImportJob, viper.GetDuration("imports.poll_interval"))
+		importsStopped(nil)
+	}()
+
+	checker.Started()
 	logrus.Print("TodoApp Started")
 
 	quit := make(chan os.Signal, 1)                      //канал типа os.Signal
@@ -70,17 +155,167 @@ func main() {
 
 	logrus.Print("TodoApp Shutting Down")
 
-	// вызовем два метода остановки сервера и закрытия всех соединений с БД. Это гарантирует нам, что мы закончим выполнение всех текущих операций перед выходом из приложения.
-	if err := srv.Shutdown(context.Background()); err != nil {
+	// сначала /readyz начинает отвечать 503, и только через drain_delay серверы перестают принимать соединения:
+	// за это время балансировщик успевает убрать реплику, и новые запросы не получают connection refused.
+	// Повторный сигнал останавливает сразу.
+	checker.Drain()
+	select {
+	case <-time.After(viper.GetDuration("drain_delay")):
+	case <-quit:
+	}
+
+	// вызовем методы остановки серверов и закрытия всех соединений с БД. Это гарантирует нам, что мы закончим выполнение всех текущих операций перед выходом из приложения.
+	// Общий таймаут нужен, чтобы долгие стримы gRPC не задерживали остановку бесконечно.
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("shutdown_timeout"))
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
 		logrus.Errorf("error occured on server shutting down: %s", err.Error())
 	}
 
-	if err := db.Close(); err != nil {
+	if err := grpcSrv.Shutdown(ctx); err != nil {
+		logrus.Errorf("error occured on grpc server shutting down: %s", err.Error())
+	}
+
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			logrus.Errorf("error occured on metrics server shutting down: %s", err.Error())
+		}
+	}
+
+	// текущий список задания импорта откатывается, задание продолжит следующий запуск
+	stopImports()
+	select {
+	case <-importsDone:
+	case <-ctx.Done():
+		logrus.Error("import jobs did not stop before shutdown timeout")
+	}
+
+	if err := closeStorage(); err != nil {
 		logrus.Errorf("error occured on db connection close: %s", err.Error())
 	}
+
+	if err := limits.close(); err != nil {
+		logrus.Errorf("error occured on rate limit store close: %s", err.Error())
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logrus.Errorf("error occured on flushing traces: %s", err.Error())
+	}
+}
+
+// runImportJobs обрабатывает очередь фонового импорта, пока не отменен ctx. Пустую очередь проверяет раз в interval,
+// после ошибки тоже ждет interval, чтобы не нагружать недоступную БД.
+func runImportJobs(ctx context.Context, jobs service.ImportJob, interval time.Duration) {
+	for {
+		processed, err := jobs.ProcessImportJob(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logrus.Errorf("import job: %s", err.Error())
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// initRepository выбирает хранилище по ключу storage.driver и возвращает функцию для его закрытия.
+// Для БД в checker добавляются проверки готовности: база отвечает и схема той версии, которую знает бинарник.
+func initRepository(checker *health.Checker) (*repository.Repository, func() error, error) {
+	cfg := storageConfig()
+	if cfg.Driver == "memory" {
+		logrus.Warn("using in-memory storage: all data will be lost on restart")
+		return repository.NewMemoryRepository(), func() error { return nil }, nil
+	}
+
+	db, err := repository.OpenDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status, err := prepareSchema(cfg.Driver, db)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	checker.Add("database", db.PingContext)
+	checker.Add("schema", func(ctx context.Context) error {
+		return repository.CheckSchema(ctx, db, status.Latest)
+	})
+
+	if err := metrics.RegisterDB(db.DB, cfg.Driver); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return repository.NewDBRepository(cfg.Driver, db), db.Close, nil
+}
+
+// prepareSchema при migrations.auto накатывает миграции и в любом случае проверяет, что бинарник знает версию схемы
+func prepareSchema(driver string, db *sqlx.DB) (repository.MigrationStatus, error) {
+	migrator, err := repository.NewMigrator(driver, db)
+	if err != nil {
+		return repository.MigrationStatus{}, err
+	}
+	defer migrator.Close()
+
+	if viper.GetBool("migrations.auto") {
+		if err := migrator.Up(); err != nil {
+			return repository.MigrationStatus{}, err
+		}
+	}
+
+	if err := migrator.CheckVersion(); err != nil {
+		return repository.MigrationStatus{}, err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return repository.MigrationStatus{}, err
+	}
+	if status.Version < status.Latest {
+		logrus.Warnf("database schema is at version %d, latest is %d: run \"migrate up\" or enable migrations.auto (until then /readyz fails)", status.Version, status.Latest)
+	}
+	return status, nil
 }
 
 func initConfig() error {
+	viper.SetDefault("storage.driver", "postgres")
+	viper.SetDefault("sqlite.path", "todo.db")
+	viper.SetDefault("migrations.auto", false)
+	viper.SetDefault("metrics.port", "9100")
+	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.service_name", "todo-go-app")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("rate_limit.store", "memory")
+	viper.SetDefault("rate_limit.auth.requests", 20)
+	viper.SetDefault("rate_limit.auth.period", time.Minute)
+	viper.SetDefault("rate_limit.auth.burst", 10)
+	viper.SetDefault("rate_limit.api.requests", 600)
+	viper.SetDefault("rate_limit.api.period", time.Minute)
+	viper.SetDefault("rate_limit.api.burst", 100)
+	viper.SetDefault("lockout.threshold", 5)
+	viper.SetDefault("lockout.base", time.Minute)
+	viper.SetDefault("lockout.max", time.Hour)
+	viper.SetDefault("lockout.window", 24*time.Hour)
+	viper.SetDefault("redis.addr", "localhost:6379")
+	viper.SetDefault("grpc.port", "9000")
+	viper.SetDefault("shutdown_timeout", 10*time.Second)
+	viper.SetDefault("drain_delay", 0)
+	viper.SetDefault("health.check_timeout", 2*time.Second)
+	viper.SetDefault("request_timeout", 30*time.Second)
+	viper.SetDefault("imports.poll_interval", 2*time.Second)
+
 	viper.AddConfigPath("configs")
 	viper.SetConfigName("config")
 	return viper.ReadInConfig() //функция считывает значение конфига и записывает во внутренний объект вайпера, а возвращает ошибку
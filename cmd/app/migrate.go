@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/spf13/viper"
+)
+
+const migrateUsage = "usage: todo-go-app migrate up|down|status|goto N"
+
+// runMigrate - подкоманда "migrate": управляет схемой базы из storage.driver по встроенным миграциям
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	cfg := storageConfig()
+	db, err := repository.OpenDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := repository.NewMigrator(cfg.Driver, db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "goto":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, parseErr := strconv.ParseUint(args[1], 10, 0)
+		if parseErr != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		err = migrator.Goto(uint(version))
+	case "status":
+		// статус выводим ниже для всех команд
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "version: %d, dirty: %t, latest: %d\n", status.Version, status.Dirty, status.Latest)
+	return nil
+}
+
+// storageConfig собирает настройки хранилища из конфига и переменных окружения
+func storageConfig() repository.StorageConfig {
+	return repository.StorageConfig{
+		Driver: viper.GetString("storage.driver"),
+		Postgres: repository.Config{
+			Host:     viper.GetString("db.host"),
+			Port:     viper.GetString("db.port"),
+			Username: viper.GetString("db.username"),
+			Password: os.Getenv("DB_PASSWORD"),
+			DBName:   viper.GetString("db.dbname"),
+			SSLMode:  viper.GetString("db.sslmode"),
+		},
+		SQLite: repository.SQLiteConfig{
+			Path: viper.GetString("sqlite.path"),
+		},
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/spf13/viper"
+)
+
+// useSQLite настраивает хранилище на пустую базу SQLite во временном каталоге
+// и возвращает соединение с ней и номер последней встроенной миграции
+func useSQLite(t *testing.T) (*sqlx.DB, uint) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todo.db")
+	viper.Set("storage.driver", "sqlite")
+	viper.Set("sqlite.path", path)
+	t.Cleanup(viper.Reset)
+
+	db, err := repository.NewSQLiteDB(repository.SQLiteConfig{Path: path})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := repository.NewSQLiteMigrator(db)
+	if err != nil {
+		t.Fatalf("sqlite migrator: %v", err)
+	}
+	defer migrator.Close()
+	status, err := migrator.Status()
+	if err != nil || status.Latest < 3 {
+		t.Fatalf("Status: %+v, %v", status, err)
+	}
+	return db, status.Latest
+}
+
+// migrate запускает подкоманду и возвращает то, что она вывела в stdout
+func migrate(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := runMigrate(args)
+	os.Stdout = stdout
+	w.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out)), runErr
+}
+
+func status(version uint, dirty bool, latest uint) string {
+	return fmt.Sprintf("version: %d, dirty: %t, latest: %d", version, dirty, latest)
+}
+
+func TestRunMigrate(t *testing.T) {
+	_, latest := useSQLite(t)
+
+	steps := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"status"}, status(0, false, latest)},
+		{[]string{"up"}, status(latest, false, latest)},
+		{[]string{"up"}, status(latest, false, latest)}, // повторный up ничего не меняет и не падает
+		{[]string{"down"}, status(latest-1, false, latest)},
+		{[]string{"goto", "3"}, status(3, false, latest)},
+		{[]string{"goto", fmt.Sprint(latest)}, status(latest, false, latest)},
+		{[]string{"goto", "0"}, status(0, false, latest)},
+	}
+	for _, step := range steps {
+		out, err := migrate(t, step.args...)
+		if err != nil || out != step.want {
+			t.Errorf("migrate %v: got %q, %v; want %q", step.args, out, err, step.want)
+		}
+	}
+}
+
+func TestRunMigrateArgs(t *testing.T) {
+	_, latest := useSQLite(t)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, migrateUsage},
+		{[]string{"sideways"}, migrateUsage},
+		{[]string{"goto"}, migrateUsage},
+		{[]string{"goto", "1", "2"}, migrateUsage},
+		{[]string{"goto", "-1"}, `invalid version "-1"`},
+		{[]string{"goto", "v2"}, `invalid version "v2"`},
+		{[]string{"goto", fmt.Sprint(latest + 1)}, fmt.Sprintf("unknown migration version %d, latest is %d", latest+1, latest)},
+	}
+	for _, tt := range tests {
+		if _, err := migrate(t, tt.args...); err == nil || err.Error() != tt.want {
+			t.Errorf("migrate %v: got %v, want %q", tt.args, err, tt.want)
+		}
+	}
+	if out, _ := migrate(t, "status"); out != status(0, false, latest) {
+		t.Errorf("schema changed after invalid commands: %q", out)
+	}
+}
+
+// TestMigrateDirty - упавшая миграция оставляет схему dirty: приложение не стартует, up отказывается работать,
+// а goto после ручной правки схемы только фиксирует версию (Force), не запуская миграции
+func TestMigrateDirty(t *testing.T) {
+	db, latest := useSQLite(t)
+	if _, err := migrate(t, "goto", fmt.Sprint(latest-1)); err != nil {
+		t.Fatalf("goto: %v", err)
+	}
+	// миграция latest упала на середине: версия уже записана с флагом dirty
+	if _, err := db.Exec("UPDATE schema_migrations SET version = $1, dirty = 1", latest); err != nil {
+		t.Fatalf("mark dirty: %v", err)
+	}
+
+	if out, err := migrate(t, "status"); err != nil || out != status(latest, true, latest) {
+		t.Errorf("status: got %q, %v", out, err)
+	}
+	if _, err := migrate(t, "up"); err == nil || !strings.Contains(err.Error(), "Dirty") {
+		t.Errorf("up on a dirty schema: got %v, want a dirty database error", err)
+	}
+	if _, err := prepareSchema("sqlite", db); !errors.Is(err, repository.ErrSchemaDirty) {
+		t.Errorf("prepareSchema: got %v, want ErrSchemaDirty", err)
+	}
+
+	// миграция так и не применилась, поэтому фиксируем предыдущую версию: Force не трогает таблицы
+	if out, err := migrate(t, "goto", fmt.Sprint(latest-1)); err != nil || out != status(latest-1, false, latest) {
+		t.Fatalf("goto on a dirty schema: got %q, %v", out, err)
+	}
+	// теперь миграция накатывается заново, и схема становится чистой
+	if out, err := migrate(t, "up"); err != nil || out != status(latest, false, latest) {
+		t.Errorf("up after goto: got %q, %v", out, err)
+	}
+	if _, err := prepareSchema("sqlite", db); err != nil {
+		t.Errorf("prepareSchema after the fix: %v", err)
+	}
+}
+
+func TestPrepareSchema(t *testing.T) {
+	db, latest := useSQLite(t)
+
+	// без migrations.auto схема не накатывается, но и запуск не ломается: readiness не пройдет, пока не сделать migrate up
+	got, err := prepareSchema("sqlite", db)
+	if err != nil || got.Version != 0 || got.Latest != latest {
+		t.Errorf("prepareSchema without auto: got %+v, %v", got, err)
+	}
+
+	viper.Set("migrations.auto", true)
+	if got, err := prepareSchema("sqlite", db); err != nil || got.Version != latest {
+		t.Errorf("prepareSchema with auto: got %+v, %v", got, err)
+	}
+	if err := repository.CheckSchema(context.Background(), db, latest); err != nil {
+		t.Errorf("CheckSchema: %v", err)
+	}
+
+	// базу уже мигрировала более новая версия приложения
+	if _, err := db.Exec("UPDATE schema_migrations SET version = $1", latest+1); err != nil {
+		t.Fatalf("bump version: %v", err)
+	}
+	if err := repository.CheckSchema(context.Background(), db, latest); err == nil {
+		t.Error("CheckSchema on a newer schema: got no error")
+	}
+	viper.Set("migrations.auto", false)
+	if _, err := prepareSchema("sqlite", db); !errors.Is(err, repository.ErrSchemaTooNew) {
+		t.Errorf("prepareSchema on a newer schema: got %v, want ErrSchemaTooNew", err)
+	}
+}
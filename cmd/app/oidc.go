@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/oidc"
+	"github.com/spf13/viper"
+)
+
+var envUnsafe = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// oidcProviderConfig - провайдер из oidc.providers в конфиге. Секрет клиента в конфиг не пишется,
+// он берется из переменной окружения OIDC_<NAME>_CLIENT_SECRET
+type oidcProviderConfig struct {
+	Name          string   `mapstructure:"name"`
+	Issuer        string   `mapstructure:"issuer"`
+	ClientID      string   `mapstructure:"client_id"`
+	Scopes        []string `mapstructure:"scopes"`
+	LinkBy        string   `mapstructure:"link_by"`
+	LinkDomains   []string `mapstructure:"link_domains"`
+	AutoProvision bool     `mapstructure:"auto_provision"`
+}
+
+// initOIDC читает провайдеров OpenID Connect, без них вход через OIDC выключен
+func initOIDC() (*oidc.Providers, error) {
+	var providers []oidcProviderConfig
+	if err := viper.UnmarshalKey("oidc.providers", &providers); err != nil {
+		return nil, err
+	}
+
+	cfg := oidc.Config{Providers: make([]oidc.ProviderConfig, 0, len(providers))}
+	for _, provider := range providers {
+		cfg.Providers = append(cfg.Providers, oidc.ProviderConfig{
+			Name:          provider.Name,
+			Issuer:        provider.Issuer,
+			ClientID:      provider.ClientID,
+			ClientSecret:  os.Getenv(oidcSecretEnv(provider.Name)),
+			Scopes:        provider.Scopes,
+			LinkBy:        provider.LinkBy,
+			LinkDomains:   provider.LinkDomains,
+			AutoProvision: provider.AutoProvision,
+		})
+	}
+	return oidc.New(cfg)
+}
+
+// oidcSecretEnv - имя переменной с секретом клиента: для провайдера "corp-sso" это OIDC_CORP_SSO_CLIENT_SECRET
+func oidcSecretEnv(name string) string {
+	return "OIDC_" + envUnsafe.ReplaceAllString(strings.ToUpper(name), "_") + "_CLIENT_SECRET"
+}
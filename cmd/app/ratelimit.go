@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+type rateLimits struct {
+	auth, api      *ratelimit.Limiter
+	lockout        *ratelimit.Lockout
+	trustedProxies []string
+	close          func() error
+}
+
+// initRateLimits выбирает хранилище лимитов (rate_limit.store) и собирает лимитеры и блокировку входа.
+// Лимиты в памяти у каждой реплики свои; чтобы они были общими, нужен Redis.
+func initRateLimits() (rateLimits, error) {
+	limits := rateLimits{close: func() error { return nil }}
+
+	for _, proxy := range viper.GetStringSlice("trusted_proxies") {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return limits, fmt.Errorf("trusted_proxies: %q is neither an IP nor a CIDR", proxy)
+			}
+		}
+		limits.trustedProxies = append(limits.trustedProxies, proxy)
+	}
+
+	var store ratelimit.Store
+	switch driver := viper.GetString("rate_limit.store"); driver {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     viper.GetString("redis.addr"),
+			DB:       viper.GetInt("redis.db"),
+			Password: os.Getenv("REDIS_PASSWORD"),
+		})
+		redisStore := ratelimit.NewRedisStore(client, "todo:")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := redisStore.Ping(ctx); err != nil {
+			client.Close()
+			return limits, fmt.Errorf("redis: %w", err)
+		}
+		store, limits.close = redisStore, client.Close
+	default:
+		return limits, fmt.Errorf("unknown rate limit store %q", driver)
+	}
+
+	limits.auth = ratelimit.NewLimiter(store, "auth", limitConfig("rate_limit.auth"))
+	limits.api = ratelimit.NewLimiter(store, "api", limitConfig("rate_limit.api"))
+	limits.lockout = ratelimit.NewLockout(store, ratelimit.LockoutPolicy{
+		Threshold: viper.GetInt("lockout.threshold"),
+		Base:      viper.GetDuration("lockout.base"),
+		Max:       viper.GetDuration("lockout.max"),
+		Window:    viper.GetDuration("lockout.window"),
+	})
+	return limits, nil
+}
+
+func limitConfig(key string) ratelimit.Limit {
+	return ratelimit.Limit{
+		Requests: viper.GetInt(key + ".requests"),
+		Period:   viper.GetDuration(key + ".period"),
+		Burst:    viper.GetInt(key + ".burst"),
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ponomare0v/todo-go-app/pkg/client"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"golang.org/x/term"
+)
+
+type cli struct {
+	cfg config
+	api *client.Client
+}
+
+func (c *cli) login(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("login", flag.ContinueOnError)
+	username := flags.String("username", c.cfg.Username, "username")
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	if *username == "" {
+		fmt.Fprint(os.Stderr, "Username: ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		*username = strings.TrimSpace(line)
+	}
+
+	password, err := readPassword(reader)
+	if err != nil {
+		return err
+	}
+
+	token, err := c.api.SignIn(ctx, *username, password)
+	var twoFactorRequired *client.TwoFactorRequiredError
+	if errors.As(err, &twoFactorRequired) {
+		fmt.Fprint(os.Stderr, "Two-factor code (or recovery code): ")
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && line == "" {
+			return readErr
+		}
+		token, err = c.api.SignIn2FA(ctx, twoFactorRequired.ChallengeToken, strings.TrimSpace(line))
+	}
+	if err != nil {
+		return err
+	}
+
+	c.cfg.Username = *username
+	c.cfg.Token = token
+	if err := saveConfig(c.cfg); err != nil {
+		return err
+	}
+	fmt.Printf("signed in to %s as %s\n", c.cfg.Server, *username)
+	return nil
+}
+
+// readPassword читает пароль без эха, если stdin - терминал, иначе просто строку (для скриптов)
+func readPassword(reader *bufio.Reader) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Password: ")
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(password), err
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (c *cli) logout() error {
+	c.cfg.Token = ""
+	return saveConfig(c.cfg)
+}
+
+func (c *cli) lists(ctx context.Context) error {
+	lists, err := c.api.GetLists(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tDESCRIPTION")
+	for _, list := range lists {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", list.Id, list.Title, list.Description)
+	}
+	return tw.Flush()
+}
+
+func (c *cli) items(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: todo items LIST")
+	}
+	list, err := c.resolveList(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	items, err := c.api.GetItems(ctx, list.Id)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDONE\tTITLE\tDESCRIPTION")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", item.Id, checkbox(item.Done), item.Title, item.Description)
+	}
+	return tw.Flush()
+}
+
+func (c *cli) add(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("add", flag.ContinueOnError)
+	listArg := flags.String("list", "", "list id or title")
+	description := flags.String("description", "", "item description")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 || *listArg == "" {
+		return errors.New(`usage: todo add "TITLE" -list LIST`)
+	}
+
+	list, err := c.resolveList(ctx, *listArg)
+	if err != nil {
+		return err
+	}
+	id, err := c.api.CreateItem(ctx, list.Id, models.TodoItem{Title: rest[0], Description: *description})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("added item %d to %q\n", id, list.Title)
+	return nil
+}
+
+func (c *cli) done(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("done", flag.ContinueOnError)
+	undo := flags.Bool("undo", false, "mark the item as not done")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := singleId(rest, "usage: todo done ID")
+	if err != nil {
+		return err
+	}
+
+	done := !*undo
+	return c.api.UpdateItem(ctx, id, models.UpdateItemInput{Done: &done})
+}
+
+func (c *cli) edit(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("edit", flag.ContinueOnError)
+	var input models.UpdateItemInput
+	flags.Func("title", "new title", func(value string) error {
+		input.Title = &value
+		return nil
+	})
+	flags.Func("description", "new description", func(value string) error {
+		input.Description = &value
+		return nil
+	})
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := singleId(rest, "usage: todo edit ID [-title TEXT] [-description TEXT]")
+	if err != nil {
+		return err
+	}
+	if input.Title == nil && input.Description == nil {
+		return errors.New("nothing to change: pass -title and/or -description")
+	}
+
+	return c.api.UpdateItem(ctx, id, input)
+}
+
+func (c *cli) rm(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("rm", flag.ContinueOnError)
+	listArg := flags.String("list", "", "delete the whole list with its items")
+	rest, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if *listArg != "" {
+		if len(rest) != 0 {
+			return errors.New("usage: todo rm -list LIST")
+		}
+		list, err := c.resolveList(ctx, *listArg)
+		if err != nil {
+			return err
+		}
+		return c.api.DeleteList(ctx, list.Id)
+	}
+
+	id, err := singleId(rest, "usage: todo rm ID")
+	if err != nil {
+		return err
+	}
+	return c.api.DeleteItem(ctx, id)
+}
+
+// resolveList находит список по id или по названию без учета регистра
+func (c *cli) resolveList(ctx context.Context, arg string) (models.TodoList, error) {
+	if id, err := strconv.Atoi(arg); err == nil {
+		return c.api.GetList(ctx, id)
+	}
+
+	lists, err := c.api.GetLists(ctx)
+	if err != nil {
+		return models.TodoList{}, err
+	}
+
+	var found []models.TodoList
+	for _, list := range lists {
+		if strings.EqualFold(list.Title, arg) {
+			found = append(found, list)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return models.TodoList{}, fmt.Errorf("list %q not found", arg)
+	case 1:
+		return found[0], nil
+	default:
+		return models.TodoList{}, fmt.Errorf("several lists are called %q, use the list id", arg)
+	}
+}
+
+// parseArgs разбирает флаги вперемешку с позиционными аргументами: todo add "title" -list X
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return rest, nil
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
+
+func singleId(args []string, usage string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New(usage)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", args[0])
+	}
+	return id, nil
+}
+
+func checkbox(done bool) string {
+	if done {
+		return "[x]"
+	}
+	return "[ ]"
+}
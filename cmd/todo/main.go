@@ -0,0 +1,100 @@
+// todo - терминальный клиент REST API: вход, списки и задачи из командной строки и интерактивный просмотр списка
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ponomare0v/todo-go-app/pkg/client"
+)
+
+const usage = `usage: todo [-server URL] COMMAND
+
+commands:
+  login [-username NAME]                       sign in and remember the token
+  logout                                       forget the token
+  lists                                        show your lists
+  items LIST                                   show items of a list
+  add TITLE -list LIST [-description TEXT]     add an item to a list
+  done ID [-undo]                              mark an item as done (or not done)
+  edit ID [-title TEXT] [-description TEXT]    change an item
+  rm ID | rm -list LIST                        delete an item or a whole list
+  view LIST                                    interactive view of a list
+
+LIST is a list id or its title. The server defaults to $TODO_SERVER or ` + defaultServer + `.
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "todo:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("todo", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	server := flags.String("server", "", "API server URL")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		return errors.New("command is required")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	switch {
+	case *server != "":
+		cfg.Server = *server
+	case os.Getenv("TODO_SERVER") != "":
+		cfg.Server = os.Getenv("TODO_SERVER")
+	}
+
+	cli := &cli{cfg: cfg, api: client.New(cfg.Server, client.WithToken(cfg.Token))}
+
+	command, args := args[0], args[1:]
+	if command != "login" && cfg.Token == "" {
+		return errors.New("not signed in, run: todo login")
+	}
+
+	switch command {
+	case "login":
+		err = cli.login(ctx, args)
+	case "logout":
+		err = cli.logout()
+	case "lists":
+		err = cli.lists(ctx)
+	case "items":
+		err = cli.items(ctx, args)
+	case "add":
+		err = cli.add(ctx, args)
+	case "done":
+		err = cli.done(ctx, args)
+	case "edit":
+		err = cli.edit(ctx, args)
+	case "rm":
+		err = cli.rm(ctx, args)
+	case "view":
+		err = cli.view(ctx, args)
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	if errors.Is(err, client.ErrUnauthorized) && command != "login" {
+		return errors.New("session expired, run: todo login")
+	}
+	return err
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/client"
+	"github.com/ponomare0v/todo-go-app/pkg/handler"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+// newServer поднимает API поверх in-memory хранилища с пользователем alice и
+// перенаправляет каталог конфигов todo во временный каталог
+func newServer(t *testing.T) (string, *client.Client) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TODO_SERVER", "")
+
+	services := service.NewService(repository.NewMemoryRepository(), service.Config{})
+	server := httptest.NewServer(handler.NewHandler(services, handler.Config{RequestTimeout: 5 * time.Second}).InitRoutes())
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	api := client.New(server.URL, client.WithRetry(0, 0))
+	if _, err := api.SignUp(ctx, models.User{Name: "Alice", Username: "alice", Password: "secret123"}); err != nil {
+		t.Fatalf("SignUp: %v", err)
+	}
+	if _, err := api.SignIn(ctx, "alice", "secret123"); err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+	return server.URL, api
+}
+
+// todo запускает клиент с stdin и возвращает его stdout
+func todo(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.WriteString(inW, stdin)
+		inW.Close()
+	}()
+
+	prevIn, prevOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = inR, outW
+	runErr := run(context.Background(), args)
+	os.Stdin, os.Stdout = prevIn, prevOut
+	outW.Close()
+	inR.Close()
+
+	out, err := io.ReadAll(outR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), runErr
+}
+
+func savedConfig(t *testing.T) config {
+	t.Helper()
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("config is not saved: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("config mode: got %v, want 0600", info.Mode().Perm())
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestLogin(t *testing.T) {
+	url, _ := newServer(t)
+
+	if _, err := todo(t, "", "lists"); err == nil || err.Error() != "not signed in, run: todo login" {
+		t.Errorf("lists before login: got %v", err)
+	}
+
+	// сервер по умолчанию недоступен: адрес берется из -server, а не из $TODO_SERVER
+	t.Setenv("TODO_SERVER", "http://127.0.0.1:1")
+	out, err := todo(t, "alice\nsecret123\n", "-server", url, "login")
+	if err != nil || out != "signed in to "+url+" as alice\n" {
+		t.Fatalf("login: got %q, %v", out, err)
+	}
+	cfg := savedConfig(t)
+	if cfg.Server != url || cfg.Username != "alice" || cfg.Token == "" {
+		t.Errorf("saved config: got %+v", cfg)
+	}
+
+	// имя пользователя запомнено, повторный вход спрашивает только пароль
+	if _, err := todo(t, "wrong-password\n", "-server", url, "login"); err == nil {
+		t.Error("login with a wrong password: got no error")
+	}
+	if savedConfig(t).Token != cfg.Token {
+		t.Error("failed login replaced the saved token")
+	}
+
+	t.Setenv("TODO_SERVER", url)
+	if _, err := todo(t, "", "logout"); err != nil {
+		t.Fatalf("logout: %v", err)
+	}
+	if cfg := savedConfig(t); cfg.Token != "" || cfg.Username != "alice" {
+		t.Errorf("config after logout: got %+v", cfg)
+	}
+	if _, err := todo(t, "", "lists"); err == nil || !strings.Contains(err.Error(), "todo login") {
+		t.Errorf("lists after logout: got %v", err)
+	}
+
+	// просроченный или отозванный токен
+	if err := saveConfig(config{Server: url, Username: "alice", Token: "expired"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := todo(t, "", "lists"); err == nil || err.Error() != "session expired, run: todo login" {
+		t.Errorf("lists with an expired token: got %v", err)
+	}
+}
+
+func TestRunArgs(t *testing.T) {
+	url, api := newServer(t)
+	if err := saveConfig(config{Server: url, Username: "alice", Token: api.Token()}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, title := range []string{"Groceries", "Work", "Work"} {
+		if _, err := api.CreateList(ctx, models.TodoList{Title: title}); err != nil {
+			t.Fatalf("CreateList: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no command", []string{}, "command is required"},
+		{"unknown global flag", []string{"-verbose", "lists"}, "flag provided but not defined: -verbose"},
+		{"unknown command", []string{"ls"}, `unknown command "ls"`},
+		{"items without list", []string{"items"}, "usage: todo items LIST"},
+		{"items of a missing list", []string{"items", "Home"}, `list "Home" not found`},
+		{"items of an ambiguous list", []string{"items", "work"}, `several lists are called "work", use the list id`},
+		{"add without -list", []string{"add", "Milk"}, `usage: todo add "TITLE" -list LIST`},
+		{"add with two titles", []string{"add", "Milk", "Bread", "-list", "Groceries"}, `usage: todo add "TITLE" -list LIST`},
+		{"add with an unknown flag", []string{"add", "Milk", "-list", "Groceries", "-due", "tomorrow"}, "flag provided but not defined: -due"},
+		{"done without id", []string{"done", "-undo"}, "usage: todo done ID"},
+		{"done with a bad id", []string{"done", "first"}, `invalid id "first"`},
+		{"edit without changes", []string{"edit", "1"}, "nothing to change: pass -title and/or -description"},
+		{"edit without id", []string{"edit", "-title", "Bread"}, "usage: todo edit ID [-title TEXT] [-description TEXT]"},
+		{"rm without id", []string{"rm"}, "usage: todo rm ID"},
+		{"rm list with an item id", []string{"rm", "-list", "Groceries", "1"}, "usage: todo rm -list LIST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := todo(t, "", tt.args...); err == nil || err.Error() != tt.want {
+				t.Errorf("todo %v: got %v, want %q", tt.args, err, tt.want)
+			}
+		})
+	}
+
+	if lists, err := api.GetLists(ctx); err != nil || len(lists) != 3 {
+		t.Errorf("lists after failed commands: got %v, %v", lists, err)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	url, api := newServer(t)
+	if err := saveConfig(config{Server: url, Username: "alice", Token: api.Token()}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	listId, err := api.CreateList(ctx, models.TodoList{Title: "Groceries", Description: "weekly"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		out, err := todo(t, "", args...)
+		if err != nil {
+			t.Fatalf("todo %v: %v", args, err)
+		}
+		return out
+	}
+	rows := func(out string) [][]string {
+		var rows [][]string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			rows = append(rows, strings.Fields(line))
+		}
+		return rows
+	}
+
+	if got := rows(run("lists")); len(got) != 1 || got[0][0] != strconv.Itoa(listId) || got[0][1] != "Groceries" {
+		t.Errorf("lists: got %v", got)
+	}
+
+	// флаги можно писать и до, и после названия задачи; список ищется по названию без учета регистра
+	if out := run("add", "Milk", "-list", "groceries", "-description", "2 bottles"); !strings.HasPrefix(out, "added item ") ||
+		!strings.HasSuffix(out, ` to "Groceries"`+"\n") {
+		t.Errorf("add: got %q", out)
+	}
+	run("add", "-list", strconv.Itoa(listId), "Bread")
+
+	items, err := api.GetItems(ctx, listId)
+	if err != nil || len(items) != 2 || items[0].Title != "Milk" || items[0].Description != "2 bottles" {
+		t.Fatalf("items after add: got %+v, %v", items, err)
+	}
+	milk, bread := strconv.Itoa(items[0].Id), strconv.Itoa(items[1].Id)
+
+	run("done", milk)
+	run("edit", bread, "-title", "Rye bread")
+	got := rows(run("items", "Groceries"))
+	if len(got) != 2 || got[0][0] != milk || got[0][1] != "[x]" || got[1][1] != "[" || strings.Join(got[1][3:], " ") != "Rye bread" {
+		t.Errorf("items: got %v", got)
+	}
+
+	run("done", "-undo", milk)
+	if item, err := api.GetItem(ctx, items[0].Id); err != nil || item.Done {
+		t.Errorf("done -undo: got %+v, %v", item, err)
+	}
+
+	run("rm", bread)
+	if _, err := api.GetItem(ctx, items[1].Id); err == nil {
+		t.Error("rm: item still exists")
+	}
+	run("rm", "-list", "Groceries")
+	if lists, err := api.GetLists(ctx); err != nil || len(lists) != 0 {
+		t.Errorf("rm -list: got %v, %v", lists, err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	if cfg, err := loadConfig(); err != nil || cfg != (config{Server: defaultServer}) {
+		t.Errorf("no config: got %+v, %v", cfg, err)
+	}
+
+	path := filepath.Join(dir, "todo", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"username": "alice", "token": "t"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := loadConfig(); err != nil || cfg != (config{Server: defaultServer, Username: "alice", Token: "t"}) {
+		t.Errorf("config without server: got %+v, %v", cfg, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"server": `), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(); err == nil {
+		t.Error("broken config: got no error")
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"golang.org/x/term"
+)
+
+const viewHelp = "↑/k ↓/j move · space done · a add · e edit · d delete · r refresh · q quit"
+
+// listView - интерактивный просмотр списка в raw-режиме терминала. Все изменения сразу уходят в API,
+// после каждого список перечитывается, поэтому на экране всегда состояние сервера.
+type listView struct {
+	cli    *cli
+	list   models.TodoList
+	items  []models.TodoItem
+	cursor int
+	status string
+}
+
+func (c *cli) view(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: todo view LIST")
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("view needs an interactive terminal")
+	}
+
+	list, err := c.resolveList(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	v := &listView{cli: c, list: list}
+	if err := v.reload(ctx); err != nil {
+		return err
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	fmt.Print("\x1b[?1049h\x1b[?25l") // альтернативный экран, курсор скрыт
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		term.Restore(fd, state)
+	}()
+
+	return v.loop(ctx)
+}
+
+func (v *listView) reload(ctx context.Context) error {
+	items, err := v.cli.api.GetItems(ctx, v.list.Id)
+	if err != nil {
+		return err
+	}
+	v.items = items
+	if v.cursor >= len(v.items) {
+		v.cursor = len(v.items) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	return nil
+}
+
+func (v *listView) loop(ctx context.Context) error {
+	buf := make([]byte, 16)
+	for {
+		v.render()
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		v.status = ""
+
+		switch key := string(buf[:n]); key {
+		case "q", "\x03", "\x1b":
+			return nil
+		case "k", "\x1b[A":
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case "j", "\x1b[B":
+			if v.cursor < len(v.items)-1 {
+				v.cursor++
+			}
+		case " ", "x":
+			v.apply(ctx, v.toggle)
+		case "a":
+			v.apply(ctx, v.add)
+		case "e":
+			v.apply(ctx, v.edit)
+		case "d":
+			v.apply(ctx, v.remove)
+		case "r":
+			v.apply(ctx, func(context.Context) error { return nil })
+		}
+	}
+}
+
+// apply выполняет действие и перечитывает список, ошибку показывает в строке статуса
+func (v *listView) apply(ctx context.Context, action func(ctx context.Context) error) {
+	if err := action(ctx); err != nil {
+		v.status = err.Error()
+		return
+	}
+	if err := v.reload(ctx); err != nil {
+		v.status = err.Error()
+	}
+}
+
+func (v *listView) current() (models.TodoItem, bool) {
+	if len(v.items) == 0 {
+		return models.TodoItem{}, false
+	}
+	return v.items[v.cursor], true
+}
+
+func (v *listView) toggle(ctx context.Context) error {
+	item, ok := v.current()
+	if !ok {
+		return nil
+	}
+	done := !item.Done
+	return v.cli.api.UpdateItem(ctx, item.Id, models.UpdateItemInput{Done: &done})
+}
+
+func (v *listView) add(ctx context.Context) error {
+	title, ok := v.prompt("New item: ", "")
+	if !ok || strings.TrimSpace(title) == "" {
+		return nil
+	}
+	_, err := v.cli.api.CreateItem(ctx, v.list.Id, models.TodoItem{Title: title})
+	if err == nil {
+		v.cursor = len(v.items) // новая задача - в конце списка, reload поправит курсор
+	}
+	return err
+}
+
+func (v *listView) edit(ctx context.Context) error {
+	item, ok := v.current()
+	if !ok {
+		return nil
+	}
+	title, ok := v.prompt("Title: ", item.Title)
+	if !ok || title == item.Title {
+		return nil
+	}
+	return v.cli.api.UpdateItem(ctx, item.Id, models.UpdateItemInput{Title: &title})
+}
+
+func (v *listView) remove(ctx context.Context) error {
+	item, ok := v.current()
+	if !ok {
+		return nil
+	}
+	answer, ok := v.prompt(fmt.Sprintf("Delete %q? (y/N) ", item.Title), "")
+	if !ok || !strings.EqualFold(answer, "y") {
+		return nil
+	}
+	return v.cli.api.DeleteItem(ctx, item.Id)
+}
+
+func (v *listView) render() {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	fmt.Fprintf(&b, "\x1b[1m%s\x1b[0m", v.list.Title)
+	if v.list.Description != "" {
+		fmt.Fprintf(&b, " - %s", v.list.Description)
+	}
+	b.WriteString("\r\n\r\n")
+
+	if len(v.items) == 0 {
+		b.WriteString("  (no items, press a to add one)\r\n")
+	}
+	for i, item := range v.items {
+		pointer := "  "
+		if i == v.cursor {
+			pointer = "\x1b[7m>"
+		}
+		fmt.Fprintf(&b, "%s %s %s\x1b[0m\r\n", pointer, checkbox(item.Done), item.Title)
+	}
+
+	b.WriteString("\r\n\x1b[2m" + viewHelp + "\x1b[0m\r\n")
+	if v.status != "" {
+		fmt.Fprintf(&b, "\x1b[31m%s\x1b[0m\r\n", v.status)
+	}
+	fmt.Print(b.String())
+}
+
+// prompt читает строку в raw-режиме: Enter подтверждает, Esc отменяет, Backspace стирает символ
+func (v *listView) prompt(label, initial string) (string, bool) {
+	line := []rune(initial)
+	buf := make([]byte, 16)
+	fmt.Print("\x1b[?25h")
+	defer fmt.Print("\x1b[?25l")
+
+	for {
+		fmt.Printf("\r\x1b[2K%s%s", label, string(line))
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return "", false
+		}
+		input := buf[:n]
+		if input[0] == 0x1b {
+			if n == 1 {
+				return "", false
+			}
+			continue // стрелки и прочие escape-последовательности в строке ввода не поддерживаем
+		}
+
+		// за одно чтение может прийти несколько символов, например при вставке из буфера обмена
+		for len(input) > 0 {
+			r, size := utf8.DecodeRune(input)
+			input = input[size:]
+			switch {
+			case r == '\r' || r == '\n':
+				return string(line), true
+			case r == 0x03:
+				return "", false
+			case r == 0x7f || r == 0x08:
+				if len(line) > 0 {
+					line = line[:len(line)-1]
+				}
+			case r >= ' ' && r != utf8.RuneError:
+				line = append(line, r)
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func (a *app) listUsers(ctx context.Context) error {
+	users, err := a.services.Admin.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, []string{strconv.Itoa(user.Id), user.Username, user.Name, strconv.FormatBool(user.Disabled)})
+	}
+	return a.out.print(users, []string{"ID", "USERNAME", "NAME", "DISABLED"}, rows)
+}
+
+func (a *app) createUser(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("users create", flag.ContinueOnError)
+	name := flags.String("name", "", "display name")
+	username := flags.String("username", "", "login")
+	password := passwordFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	pass, err := password()
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.services.Authorization.CreateUser(ctx, models.User{Name: *name, Username: *username, Password: pass}); err != nil {
+		return err
+	}
+	return a.printUser(ctx, *username)
+}
+
+func (a *app) setDisabled(ctx context.Context, args []string, disabled bool) error {
+	username, err := singleArg(args, "USERNAME")
+	if err != nil {
+		return err
+	}
+
+	if err := a.services.Admin.SetDisabled(ctx, username, disabled); err != nil {
+		return err
+	}
+	return a.printUser(ctx, username)
+}
+
+func (a *app) resetPassword(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("users reset-password", flag.ContinueOnError)
+	password := passwordFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	username, err := singleArg(flags.Args(), "USERNAME")
+	if err != nil {
+		return err
+	}
+
+	pass, err := password()
+	if err != nil {
+		return err
+	}
+
+	if err := a.services.Admin.ResetPassword(ctx, username, pass); err != nil {
+		return err
+	}
+	return a.printUser(ctx, username)
+}
+
+func (a *app) resetTwoFactor(ctx context.Context, args []string) error {
+	username, err := singleArg(args, "USERNAME")
+	if err != nil {
+		return err
+	}
+
+	if err := a.services.Admin.ResetTwoFactor(ctx, username); err != nil {
+		return err
+	}
+	return a.printUser(ctx, username)
+}
+
+func (a *app) stats(ctx context.Context, args []string) error {
+	var usernames []string
+	switch len(args) {
+	case 0:
+		users, err := a.services.Admin.GetUsers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			usernames = append(usernames, user.Username)
+		}
+	case 1:
+		usernames = args
+	default:
+		return errors.New("expected at most one USERNAME")
+	}
+
+	type userStats struct {
+		Username string `json:"username"`
+		models.UserStats
+	}
+	stats := make([]userStats, 0, len(usernames))
+	rows := make([][]string, 0, len(usernames))
+	for _, username := range usernames {
+		s, err := a.services.Admin.GetStats(ctx, username)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, userStats{Username: username, UserStats: s})
+		rows = append(rows, []string{strconv.Itoa(s.UserId), username, strconv.Itoa(s.Lists), strconv.Itoa(s.SharedLists),
+			strconv.Itoa(s.Items), strconv.Itoa(s.DoneItems)})
+	}
+	return a.out.print(stats, []string{"ID", "USERNAME", "LISTS", "SHARED", "ITEMS", "DONE"}, rows)
+}
+
+func (a *app) purgeUser(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("users purge", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "confirm that the user and their data must be deleted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	username, err := singleArg(flags.Args(), "USERNAME")
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		return errors.New("purge deletes the user with all lists nobody else shares; pass -yes to confirm")
+	}
+
+	// статистику снимаем до удаления, чтобы показать, что именно удалено
+	stats, err := a.services.Admin.GetStats(ctx, username)
+	if err != nil {
+		return err
+	}
+	if err := a.services.Admin.PurgeUser(ctx, username); err != nil {
+		return err
+	}
+
+	return a.out.print(stats, []string{"PURGED ID", "LISTS", "SHARED (KEPT)", "ITEMS"}, [][]string{{
+		strconv.Itoa(stats.UserId), strconv.Itoa(stats.Lists), strconv.Itoa(stats.SharedLists), strconv.Itoa(stats.Items),
+	}})
+}
+
+func (a *app) transferList(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("lists transfer", flag.ContinueOnError)
+	from := flags.String("from", "", "current owner username")
+	to := flags.String("to", "", "new owner username")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	arg, err := singleArg(flags.Args(), "LIST_ID")
+	if err != nil {
+		return err
+	}
+	listId, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("invalid list id %q", arg)
+	}
+	if *from == "" || *to == "" {
+		return errors.New("-from and -to are required")
+	}
+
+	if err := a.services.Admin.TransferList(ctx, listId, *from, *to); err != nil {
+		return err
+	}
+
+	result := map[string]interface{}{"list_id": listId, "from": *from, "to": *to}
+	return a.out.print(result, []string{"LIST", "FROM", "TO"}, [][]string{{strconv.Itoa(listId), *from, *to}})
+}
+
+func (a *app) printUser(ctx context.Context, username string) error {
+	user, err := a.services.Admin.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return a.out.print(user, []string{"ID", "USERNAME", "NAME", "DISABLED"}, [][]string{{
+		strconv.Itoa(user.Id), user.Username, user.Name, strconv.FormatBool(user.Disabled),
+	}})
+}
+
+// passwordFlags регистрирует -password и -password-stdin. Второй вариант не оставляет пароль в истории shell.
+func passwordFlags(flags *flag.FlagSet) func() (string, error) {
+	password := flags.String("password", "", "new password")
+	fromStdin := flags.Bool("password-stdin", false, "read the password from stdin")
+
+	return func() (string, error) {
+		if *fromStdin {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return "", fmt.Errorf("read password: %w", err)
+			}
+			return strings.TrimRight(line, "\r\n"), nil
+		}
+		if *password == "" {
+			return "", errors.New("-password or -password-stdin is required")
+		}
+		return *password, nil
+	}
+}
+
+func singleArg(args []string, name string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected exactly one %s", name)
+	}
+	return args[0], nil
+}
@@ -0,0 +1,159 @@
+// todoctl - админская утилита для операторов: управление пользователями и их данными напрямую через
+// сервисы и репозитории приложения, без HTTP API и без psql. Читает тот же configs/config.yaml и .env, что и сервер.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/spf13/viper"
+)
+
+const usage = `usage: todoctl [-config DIR] [-o table|json] COMMAND
+
+commands:
+  users list
+  users create -name NAME -username USERNAME (-password PASSWORD | -password-stdin)
+  users disable USERNAME
+  users enable USERNAME
+  users reset-password USERNAME (-password PASSWORD | -password-stdin)
+  users reset-2fa USERNAME
+  users stats [USERNAME]
+  users purge -yes USERNAME
+  lists transfer -from USERNAME -to USERNAME LIST_ID
+`
+
+// app - то, что нужно командам: сервисы и формат вывода
+type app struct {
+	services *service.Service
+	out      printer
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "todoctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("todoctl", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), usage) }
+	configDir := flags.String("config", "configs", "directory with config.yaml")
+	format := flags.String("o", "table", "output format: table or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	out, err := newPrinter(*format, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	args = flags.Args()
+	if len(args) < 2 {
+		flags.Usage()
+		return errors.New("command is required")
+	}
+
+	services, closeDB, err := initServices(*configDir)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	a := &app{services: services, out: out}
+	ctx := context.Background()
+
+	switch args[0] + " " + args[1] {
+	case "users list":
+		return a.listUsers(ctx)
+	case "users create":
+		return a.createUser(ctx, args[2:])
+	case "users disable":
+		return a.setDisabled(ctx, args[2:], true)
+	case "users enable":
+		return a.setDisabled(ctx, args[2:], false)
+	case "users reset-password":
+		return a.resetPassword(ctx, args[2:])
+	case "users reset-2fa":
+		return a.resetTwoFactor(ctx, args[2:])
+	case "users stats":
+		return a.stats(ctx, args[2:])
+	case "users purge":
+		return a.purgeUser(ctx, args[2:])
+	case "lists transfer":
+		return a.transferList(ctx, args[2:])
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown command %q", args[0]+" "+args[1])
+	}
+}
+
+// initServices подключается к хранилищу из конфига сервера. Схема должна быть на последней версии:
+// todoctl миграции не накатывает, для этого есть "todo-go-app migrate up".
+func initServices(configDir string) (*service.Service, func() error, error) {
+	viper.SetDefault("storage.driver", "postgres")
+	viper.SetDefault("sqlite.path", "todo.db")
+	viper.AddConfigPath(configDir)
+	viper.SetConfigName("config")
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, nil, err
+	}
+	// .env нужен только для пароля Postgres, его может и не быть, если DB_PASSWORD задан в окружении
+	_ = godotenv.Load()
+
+	cfg := repository.StorageConfig{
+		Driver: viper.GetString("storage.driver"),
+		Postgres: repository.Config{
+			Host:     viper.GetString("db.host"),
+			Port:     viper.GetString("db.port"),
+			Username: viper.GetString("db.username"),
+			Password: os.Getenv("DB_PASSWORD"),
+			DBName:   viper.GetString("db.dbname"),
+			SSLMode:  viper.GetString("db.sslmode"),
+		},
+		SQLite: repository.SQLiteConfig{
+			Path: viper.GetString("sqlite.path"),
+		},
+	}
+
+	db, err := repository.OpenDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkSchema(cfg.Driver, db); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return service.NewService(repository.NewDBRepository(cfg.Driver, db), service.Config{}), db.Close, nil
+}
+
+func checkSchema(driver string, db *sqlx.DB) error {
+	migrator, err := repository.NewMigrator(driver, db)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if err := migrator.CheckVersion(); err != nil {
+		return err
+	}
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	if status.Version < status.Latest {
+		return fmt.Errorf("database schema is at version %d, latest is %d: run \"todo-go-app migrate up\" first", status.Version, status.Latest)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/spf13/viper"
+)
+
+// sqliteConfig пишет config.yaml с базой SQLite во временном каталоге и возвращает каталог конфига.
+// migrated - накатить ли схему, как это сделал бы "todo-go-app migrate up".
+func sqliteConfig(t *testing.T, migrated bool) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.db")
+	config := "storage:\n  driver: sqlite\nsqlite:\n  path: " + path + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(viper.Reset)
+
+	db, err := repository.NewSQLiteDB(repository.SQLiteConfig{Path: path})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	if migrated {
+		migrator, err := repository.NewSQLiteMigrator(db)
+		if err != nil {
+			t.Fatalf("sqlite migrator: %v", err)
+		}
+		defer migrator.Close()
+		if err := migrator.Up(); err != nil {
+			t.Fatalf("migrate sqlite: %v", err)
+		}
+	}
+	return dir
+}
+
+// ctl запускает todoctl с stdin и возвращает его stdout
+func ctl(t *testing.T, stdin string, args ...string) (string, error) {
+	t.Helper()
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		io.WriteString(inW, stdin)
+		inW.Close()
+	}()
+
+	prevIn, prevOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = inR, outW
+	runErr := run(args)
+	os.Stdin, os.Stdout = prevIn, prevOut
+	outW.Close()
+	inR.Close()
+
+	out, err := io.ReadAll(outR)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), runErr
+}
+
+func TestRunArgs(t *testing.T) {
+	config := sqliteConfig(t, true)
+	if _, err := ctl(t, "", "-config", config, "users", "create", "-name", "Alice", "-username", "alice", "-password", "secret123"); err != nil {
+		t.Fatalf("users create: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no command", []string{}, "command is required"},
+		{"half a command", []string{"users"}, "command is required"},
+		{"unknown output format", []string{"-o", "yaml", "users", "list"}, `unknown output format "yaml"`},
+		{"unknown global flag", []string{"-verbose", "users", "list"}, "flag provided but not defined: -verbose"},
+		{"unknown command", []string{"users", "delete", "alice"}, `unknown command "users delete"`},
+		{"disable without username", []string{"users", "disable"}, "expected exactly one USERNAME"},
+		{"enable with two usernames", []string{"users", "enable", "alice", "bob"}, "expected exactly one USERNAME"},
+		{"create without password", []string{"users", "create", "-name", "Bob", "-username", "bob"}, "-password or -password-stdin is required"},
+		{"create with an unknown flag", []string{"users", "create", "-email", "bob@example.com"}, "flag provided but not defined: -email"},
+		{"reset-password without password", []string{"users", "reset-password", "alice"}, "-password or -password-stdin is required"},
+		{"stats with two usernames", []string{"users", "stats", "alice", "bob"}, "expected at most one USERNAME"},
+		{"purge without -yes", []string{"users", "purge", "alice"}, "pass -yes to confirm"},
+		{"purge with -yes after the username", []string{"users", "purge", "alice", "-yes"}, "expected exactly one USERNAME"},
+		{"transfer without -to", []string{"lists", "transfer", "-from", "alice", "1"}, "-from and -to are required"},
+		{"transfer with a bad list id", []string{"lists", "transfer", "-from", "alice", "-to", "bob", "first"}, `invalid list id "first"`},
+		{"transfer without list id", []string{"lists", "transfer", "-from", "alice", "-to", "bob"}, "expected exactly one LIST_ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ctl(t, "", append([]string{"-config", config}, tt.args...)...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("todoctl %v: got %v, want an error containing %q", tt.args, err, tt.want)
+			}
+		})
+	}
+
+	// ни одна неудачная команда не должна была ничего поменять
+	out, err := ctl(t, "", "-config", config, "-o", "json", "users", "list")
+	var users []struct {
+		Username string `json:"username"`
+		Disabled bool   `json:"disabled"`
+	}
+	if err != nil || json.Unmarshal([]byte(out), &users) != nil || len(users) != 1 || users[0].Username != "alice" || users[0].Disabled {
+		t.Errorf("users list: got %q, %v", out, err)
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	config := sqliteConfig(t, true)
+	run := func(stdin string, args ...string) string {
+		t.Helper()
+		out, err := ctl(t, stdin, append([]string{"-config", config}, args...)...)
+		if err != nil {
+			t.Fatalf("todoctl %v: %v", args, err)
+		}
+		return out
+	}
+
+	// пароль из stdin: перевод строки в конце не становится частью пароля
+	out := run("secret123\r\n", "users", "create", "-name", "Alice", "-username", "alice", "-password-stdin")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || strings.Fields(lines[0])[1] != "USERNAME" ||
+		strings.Fields(lines[1])[1] != "alice" {
+		t.Errorf("users create table: got %q", out)
+	}
+	run("", "users", "create", "-name", "Bob", "-username", "bob", "-password", "secret123")
+
+	if out := run("", "users", "disable", "bob"); !strings.Contains(out, "true") {
+		t.Errorf("users disable: got %q", out)
+	}
+	if out := run("", "-o", "json", "users", "enable", "bob"); !strings.Contains(out, `"disabled": false`) {
+		t.Errorf("users enable: got %q", out)
+	}
+
+	var stats []struct {
+		Username string `json:"username"`
+	}
+	if out := run("", "-o", "json", "users", "stats"); json.Unmarshal([]byte(out), &stats) != nil || len(stats) != 2 {
+		t.Errorf("users stats: got %q", out)
+	}
+	if out := run("", "users", "purge", "-yes", "bob"); !strings.Contains(out, "PURGED ID") {
+		t.Errorf("users purge: got %q", out)
+	}
+	if _, err := ctl(t, "", "-config", config, "users", "stats", "bob"); err == nil {
+		t.Error("users stats of a purged user: got no error")
+	}
+}
+
+func TestRunRequiresMigratedSchema(t *testing.T) {
+	config := sqliteConfig(t, false)
+	if _, err := ctl(t, "", "-config", config, "users", "list"); err == nil || !strings.Contains(err.Error(), "migrate up") {
+		t.Errorf("users list on an empty database: got %v, want a hint to run migrate up", err)
+	}
+	if _, err := ctl(t, "", "-config", t.TempDir(), "users", "list"); err == nil {
+		t.Error("users list without config.yaml: got no error")
+	}
+}
@@ -0,0 +1,885 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: todo/v1/items.proto
+
+package todov1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WatchItemsResponse_Type int32
+
+const (
+	WatchItemsResponse_TYPE_UNSPECIFIED WatchItemsResponse_Type = 0
+	WatchItemsResponse_TYPE_CREATED     WatchItemsResponse_Type = 1
+	WatchItemsResponse_TYPE_UPDATED     WatchItemsResponse_Type = 2
+	WatchItemsResponse_TYPE_DELETED     WatchItemsResponse_Type = 3
+)
+
+// Enum value maps for WatchItemsResponse_Type.
+var (
+	WatchItemsResponse_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_CREATED",
+		2: "TYPE_UPDATED",
+		3: "TYPE_DELETED",
+	}
+	WatchItemsResponse_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_CREATED":     1,
+		"TYPE_UPDATED":     2,
+		"TYPE_DELETED":     3,
+	}
+)
+
+func (x WatchItemsResponse_Type) Enum() *WatchItemsResponse_Type {
+	p := new(WatchItemsResponse_Type)
+	*p = x
+	return p
+}
+
+func (x WatchItemsResponse_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WatchItemsResponse_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_v1_items_proto_enumTypes[0].Descriptor()
+}
+
+func (WatchItemsResponse_Type) Type() protoreflect.EnumType {
+	return &file_todo_v1_items_proto_enumTypes[0]
+}
+
+func (x WatchItemsResponse_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WatchItemsResponse_Type.Descriptor instead.
+func (WatchItemsResponse_Type) EnumDescriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{12, 0}
+}
+
+type TodoItem struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Done        bool                   `protobuf:"varint,4,opt,name=done,proto3" json:"done,omitempty"`
+	// срок выполнения, не задан - без срока
+	DueDate       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TodoItem) Reset() {
+	*x = TodoItem{}
+	mi := &file_todo_v1_items_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TodoItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TodoItem) ProtoMessage() {}
+
+func (x *TodoItem) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TodoItem.ProtoReflect.Descriptor instead.
+func (*TodoItem) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TodoItem) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TodoItem) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *TodoItem) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *TodoItem) GetDone() bool {
+	if x != nil {
+		return x.Done
+	}
+	return false
+}
+
+func (x *TodoItem) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+type CreateItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ListId        int64                  `protobuf:"varint,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	DueDate       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateItemRequest) Reset() {
+	*x = CreateItemRequest{}
+	mi := &file_todo_v1_items_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateItemRequest) ProtoMessage() {}
+
+func (x *CreateItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateItemRequest.ProtoReflect.Descriptor instead.
+func (*CreateItemRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateItemRequest) GetListId() int64 {
+	if x != nil {
+		return x.ListId
+	}
+	return 0
+}
+
+func (x *CreateItemRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateItemRequest) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+type CreateItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateItemResponse) Reset() {
+	*x = CreateItemResponse{}
+	mi := &file_todo_v1_items_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateItemResponse) ProtoMessage() {}
+
+func (x *CreateItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateItemResponse.ProtoReflect.Descriptor instead.
+func (*CreateItemResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateItemResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetAllItemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ListId        int64                  `protobuf:"varint,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllItemsRequest) Reset() {
+	*x = GetAllItemsRequest{}
+	mi := &file_todo_v1_items_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllItemsRequest) ProtoMessage() {}
+
+func (x *GetAllItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllItemsRequest.ProtoReflect.Descriptor instead.
+func (*GetAllItemsRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetAllItemsRequest) GetListId() int64 {
+	if x != nil {
+		return x.ListId
+	}
+	return 0
+}
+
+type GetAllItemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*TodoItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAllItemsResponse) Reset() {
+	*x = GetAllItemsResponse{}
+	mi := &file_todo_v1_items_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAllItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAllItemsResponse) ProtoMessage() {}
+
+func (x *GetAllItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAllItemsResponse.ProtoReflect.Descriptor instead.
+func (*GetAllItemsResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetAllItemsResponse) GetItems() []*TodoItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type GetItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetItemRequest) Reset() {
+	*x = GetItemRequest{}
+	mi := &file_todo_v1_items_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemRequest) ProtoMessage() {}
+
+func (x *GetItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetItemRequest.ProtoReflect.Descriptor instead.
+func (*GetItemRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Item          *TodoItem              `protobuf:"bytes,1,opt,name=item,proto3" json:"item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetItemResponse) Reset() {
+	*x = GetItemResponse{}
+	mi := &file_todo_v1_items_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetItemResponse) ProtoMessage() {}
+
+func (x *GetItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetItemResponse.ProtoReflect.Descriptor instead.
+func (*GetItemResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetItemResponse) GetItem() *TodoItem {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+type UpdateItemRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       *string                `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Description *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Done        *bool                  `protobuf:"varint,4,opt,name=done,proto3,oneof" json:"done,omitempty"`
+	// не задан - срок не меняется; снять срок можно PATCH-запросом REST с "due_date": null
+	DueDate       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateItemRequest) Reset() {
+	*x = UpdateItemRequest{}
+	mi := &file_todo_v1_items_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateItemRequest) ProtoMessage() {}
+
+func (x *UpdateItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateItemRequest.ProtoReflect.Descriptor instead.
+func (*UpdateItemRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateItemRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateItemRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateItemRequest) GetDone() bool {
+	if x != nil && x.Done != nil {
+		return *x.Done
+	}
+	return false
+}
+
+func (x *UpdateItemRequest) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+type UpdateItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateItemResponse) Reset() {
+	*x = UpdateItemResponse{}
+	mi := &file_todo_v1_items_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateItemResponse) ProtoMessage() {}
+
+func (x *UpdateItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateItemResponse.ProtoReflect.Descriptor instead.
+func (*UpdateItemResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{8}
+}
+
+type DeleteItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteItemRequest) Reset() {
+	*x = DeleteItemRequest{}
+	mi := &file_todo_v1_items_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteItemRequest) ProtoMessage() {}
+
+func (x *DeleteItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteItemRequest.ProtoReflect.Descriptor instead.
+func (*DeleteItemRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteItemRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteItemResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteItemResponse) Reset() {
+	*x = DeleteItemResponse{}
+	mi := &file_todo_v1_items_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteItemResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteItemResponse) ProtoMessage() {}
+
+func (x *DeleteItemResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteItemResponse.ProtoReflect.Descriptor instead.
+func (*DeleteItemResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{10}
+}
+
+type WatchItemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ListId        int64                  `protobuf:"varint,1,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchItemsRequest) Reset() {
+	*x = WatchItemsRequest{}
+	mi := &file_todo_v1_items_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchItemsRequest) ProtoMessage() {}
+
+func (x *WatchItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchItemsRequest.ProtoReflect.Descriptor instead.
+func (*WatchItemsRequest) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchItemsRequest) GetListId() int64 {
+	if x != nil {
+		return x.ListId
+	}
+	return 0
+}
+
+// WatchItemsResponse - одно событие изменения задачи
+type WatchItemsResponse struct {
+	state  protoimpl.MessageState  `protogen:"open.v1"`
+	Type   WatchItemsResponse_Type `protobuf:"varint,1,opt,name=type,proto3,enum=todo.v1.WatchItemsResponse_Type" json:"type,omitempty"`
+	ListId int64                   `protobuf:"varint,2,opt,name=list_id,json=listId,proto3" json:"list_id,omitempty"`
+	// для TYPE_DELETED заполнен только id
+	Item          *TodoItem `protobuf:"bytes,3,opt,name=item,proto3" json:"item,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchItemsResponse) Reset() {
+	*x = WatchItemsResponse{}
+	mi := &file_todo_v1_items_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchItemsResponse) ProtoMessage() {}
+
+func (x *WatchItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_v1_items_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchItemsResponse.ProtoReflect.Descriptor instead.
+func (*WatchItemsResponse) Descriptor() ([]byte, []int) {
+	return file_todo_v1_items_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchItemsResponse) GetType() WatchItemsResponse_Type {
+	if x != nil {
+		return x.Type
+	}
+	return WatchItemsResponse_TYPE_UNSPECIFIED
+}
+
+func (x *WatchItemsResponse) GetListId() int64 {
+	if x != nil {
+		return x.ListId
+	}
+	return 0
+}
+
+func (x *WatchItemsResponse) GetItem() *TodoItem {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+var File_todo_v1_items_proto protoreflect.FileDescriptor
+
+const file_todo_v1_items_proto_rawDesc = "" +
+	"\n" +
+	"\x13todo/v1/items.proto\x12\atodo.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9d\x01\n" +
+	"\bTodoItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04done\x18\x04 \x01(\bR\x04done\x125\n" +
+	"\bdue_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\adueDate\"\x9b\x01\n" +
+	"\x11CreateItemRequest\x12\x17\n" +
+	"\alist_id\x18\x01 \x01(\x03R\x06listId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x125\n" +
+	"\bdue_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\adueDate\"$\n" +
+	"\x12CreateItemResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"-\n" +
+	"\x12GetAllItemsRequest\x12\x17\n" +
+	"\alist_id\x18\x01 \x01(\x03R\x06listId\">\n" +
+	"\x13GetAllItemsResponse\x12'\n" +
+	"\x05items\x18\x01 \x03(\v2\x11.todo.v1.TodoItemR\x05items\" \n" +
+	"\x0eGetItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"8\n" +
+	"\x0fGetItemResponse\x12%\n" +
+	"\x04item\x18\x01 \x01(\v2\x11.todo.v1.TodoItemR\x04item\"\xd8\x01\n" +
+	"\x11UpdateItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\x05title\x18\x02 \x01(\tH\x00R\x05title\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tH\x01R\vdescription\x88\x01\x01\x12\x17\n" +
+	"\x04done\x18\x04 \x01(\bH\x02R\x04done\x88\x01\x01\x125\n" +
+	"\bdue_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\adueDateB\b\n" +
+	"\x06_titleB\x0e\n" +
+	"\f_descriptionB\a\n" +
+	"\x05_done\"\x14\n" +
+	"\x12UpdateItemResponse\"#\n" +
+	"\x11DeleteItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x14\n" +
+	"\x12DeleteItemResponse\",\n" +
+	"\x11WatchItemsRequest\x12\x17\n" +
+	"\alist_id\x18\x01 \x01(\x03R\x06listId\"\xde\x01\n" +
+	"\x12WatchItemsResponse\x124\n" +
+	"\x04type\x18\x01 \x01(\x0e2 .todo.v1.WatchItemsResponse.TypeR\x04type\x12\x17\n" +
+	"\alist_id\x18\x02 \x01(\x03R\x06listId\x12%\n" +
+	"\x04item\x18\x03 \x01(\v2\x11.todo.v1.TodoItemR\x04item\"R\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fTYPE_CREATED\x10\x01\x12\x10\n" +
+	"\fTYPE_UPDATED\x10\x02\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x032\xb3\x03\n" +
+	"\vItemService\x12E\n" +
+	"\n" +
+	"CreateItem\x12\x1a.todo.v1.CreateItemRequest\x1a\x1b.todo.v1.CreateItemResponse\x12H\n" +
+	"\vGetAllItems\x12\x1b.todo.v1.GetAllItemsRequest\x1a\x1c.todo.v1.GetAllItemsResponse\x12<\n" +
+	"\aGetItem\x12\x17.todo.v1.GetItemRequest\x1a\x18.todo.v1.GetItemResponse\x12E\n" +
+	"\n" +
+	"UpdateItem\x12\x1a.todo.v1.UpdateItemRequest\x1a\x1b.todo.v1.UpdateItemResponse\x12E\n" +
+	"\n" +
+	"DeleteItem\x12\x1a.todo.v1.DeleteItemRequest\x1a\x1b.todo.v1.DeleteItemResponse\x12G\n" +
+	"\n" +
+	"WatchItems\x12\x1a.todo.v1.WatchItemsRequest\x1a\x1b.todo.v1.WatchItemsResponse0\x01B:Z8github.com/ponomare0v/todo-go-app/pkg/api/todo/v1;todov1b\x06proto3"
+
+var (
+	file_todo_v1_items_proto_rawDescOnce sync.Once
+	file_todo_v1_items_proto_rawDescData []byte
+)
+
+func file_todo_v1_items_proto_rawDescGZIP() []byte {
+	file_todo_v1_items_proto_rawDescOnce.Do(func() {
+		file_todo_v1_items_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_todo_v1_items_proto_rawDesc), len(file_todo_v1_items_proto_rawDesc)))
+	})
+	return file_todo_v1_items_proto_rawDescData
+}
+
+var file_todo_v1_items_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_todo_v1_items_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_todo_v1_items_proto_goTypes = []any{
+	(WatchItemsResponse_Type)(0),  // 0: todo.v1.WatchItemsResponse.Type
+	(*TodoItem)(nil),              // 1: todo.v1.TodoItem
+	(*CreateItemRequest)(nil),     // 2: todo.v1.CreateItemRequest
+	(*CreateItemResponse)(nil),    // 3: todo.v1.CreateItemResponse
+	(*GetAllItemsRequest)(nil),    // 4: todo.v1.GetAllItemsRequest
+	(*GetAllItemsResponse)(nil),   // 5: todo.v1.GetAllItemsResponse
+	(*GetItemRequest)(nil),        // 6: todo.v1.GetItemRequest
+	(*GetItemResponse)(nil),       // 7: todo.v1.GetItemResponse
+	(*UpdateItemRequest)(nil),     // 8: todo.v1.UpdateItemRequest
+	(*UpdateItemResponse)(nil),    // 9: todo.v1.UpdateItemResponse
+	(*DeleteItemRequest)(nil),     // 10: todo.v1.DeleteItemRequest
+	(*DeleteItemResponse)(nil),    // 11: todo.v1.DeleteItemResponse
+	(*WatchItemsRequest)(nil),     // 12: todo.v1.WatchItemsRequest
+	(*WatchItemsResponse)(nil),    // 13: todo.v1.WatchItemsResponse
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+}
+var file_todo_v1_items_proto_depIdxs = []int32{
+	14, // 0: todo.v1.TodoItem.due_date:type_name -> google.protobuf.Timestamp
+	14, // 1: todo.v1.CreateItemRequest.due_date:type_name -> google.protobuf.Timestamp
+	1,  // 2: todo.v1.GetAllItemsResponse.items:type_name -> todo.v1.TodoItem
+	1,  // 3: todo.v1.GetItemResponse.item:type_name -> todo.v1.TodoItem
+	14, // 4: todo.v1.UpdateItemRequest.due_date:type_name -> google.protobuf.Timestamp
+	0,  // 5: todo.v1.WatchItemsResponse.type:type_name -> todo.v1.WatchItemsResponse.Type
+	1,  // 6: todo.v1.WatchItemsResponse.item:type_name -> todo.v1.TodoItem
+	2,  // 7: todo.v1.ItemService.CreateItem:input_type -> todo.v1.CreateItemRequest
+	4,  // 8: todo.v1.ItemService.GetAllItems:input_type -> todo.v1.GetAllItemsRequest
+	6,  // 9: todo.v1.ItemService.GetItem:input_type -> todo.v1.GetItemRequest
+	8,  // 10: todo.v1.ItemService.UpdateItem:input_type -> todo.v1.UpdateItemRequest
+	10, // 11: todo.v1.ItemService.DeleteItem:input_type -> todo.v1.DeleteItemRequest
+	12, // 12: todo.v1.ItemService.WatchItems:input_type -> todo.v1.WatchItemsRequest
+	3,  // 13: todo.v1.ItemService.CreateItem:output_type -> todo.v1.CreateItemResponse
+	5,  // 14: todo.v1.ItemService.GetAllItems:output_type -> todo.v1.GetAllItemsResponse
+	7,  // 15: todo.v1.ItemService.GetItem:output_type -> todo.v1.GetItemResponse
+	9,  // 16: todo.v1.ItemService.UpdateItem:output_type -> todo.v1.UpdateItemResponse
+	11, // 17: todo.v1.ItemService.DeleteItem:output_type -> todo.v1.DeleteItemResponse
+	13, // 18: todo.v1.ItemService.WatchItems:output_type -> todo.v1.WatchItemsResponse
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_todo_v1_items_proto_init() }
+func file_todo_v1_items_proto_init() {
+	if File_todo_v1_items_proto != nil {
+		return
+	}
+	file_todo_v1_items_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todo_v1_items_proto_rawDesc), len(file_todo_v1_items_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_todo_v1_items_proto_goTypes,
+		DependencyIndexes: file_todo_v1_items_proto_depIdxs,
+		EnumInfos:         file_todo_v1_items_proto_enumTypes,
+		MessageInfos:      file_todo_v1_items_proto_msgTypes,
+	}.Build()
+	File_todo_v1_items_proto = out.File
+	file_todo_v1_items_proto_goTypes = nil
+	file_todo_v1_items_proto_depIdxs = nil
+}
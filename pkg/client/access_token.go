@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// CreateAccessToken выпускает персональный токен доступа; Token в ответе больше нигде не показывается.
+// Клиент с таким токеном создается через WithToken.
+func (c *Client) CreateAccessToken(ctx context.Context, token models.AccessToken) (models.AccessToken, error) {
+	input := map[string]interface{}{"name": token.Name, "scopes": token.Scopes, "expires_at": token.ExpiresAt}
+	var created models.AccessToken
+	err := c.do(ctx, http.MethodPost, accessTokensPath, jsonType, input, &created)
+	return created, err
+}
+
+func (c *Client) GetAccessTokens(ctx context.Context) ([]models.AccessToken, error) {
+	var resp struct {
+		Data []models.AccessToken `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, accessTokensPath, "", nil, &resp)
+	return resp.Data, err
+}
+
+func (c *Client) DeleteAccessToken(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf(accessTokenPath, id), "", nil, &statusResponse{})
+}
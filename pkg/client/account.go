@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// GetProfile возвращает профиль пользователя, от имени которого работает клиент
+func (c *Client) GetProfile(ctx context.Context) (models.Profile, error) {
+	var profile models.Profile
+	err := c.do(ctx, http.MethodGet, profilePath, "", nil, &profile)
+	return profile, err
+}
+
+// UpdateProfile меняет отображаемое имя; логин не меняется
+func (c *Client) UpdateProfile(ctx context.Context, input models.UpdateProfileInput) (models.Profile, error) {
+	var profile models.Profile
+	err := c.do(ctx, http.MethodPut, profilePath, jsonType, input, &profile)
+	return profile, err
+}
+
+// ChangePassword меняет пароль и запоминает новый токен: все прежние токены сервер отзывает.
+// Если клиент создан WithCredentials, для автоматического входа дальше используется новый пароль.
+func (c *Client) ChangePassword(ctx context.Context, currentPassword, newPassword string) (string, error) {
+	var resp signInResponse
+	input := models.ChangePasswordInput{CurrentPassword: currentPassword, NewPassword: newPassword}
+	if err := c.do(ctx, http.MethodPut, passwordPath, jsonType, input, &resp); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = resp.Token
+	if c.password != "" {
+		c.password = newPassword
+	}
+	return resp.Token, nil
+}
+
+// DeleteAccount удаляет пользователя вместе с его данными; списки, которыми пользуется кто-то еще, остаются у них.
+// Удаление подтверждается паролем или кодом 2FA, сразу после SignInOIDC input может быть пустым.
+func (c *Client) DeleteAccount(ctx context.Context, input models.DeleteAccountInput) error {
+	if err := c.do(ctx, http.MethodDelete, profilePath, jsonType, input, &statusResponse{}); err != nil {
+		return err
+	}
+
+	c.setToken("")
+	return nil
+}
+
+// ExportAccount скачивает ZIP-архив со всеми данными пользователя: account.json и lists.json
+func (c *Client) ExportAccount(ctx context.Context) ([]byte, error) {
+	var archive []byte
+	err := c.do(ctx, http.MethodGet, accountExportPath, "", nil, &archive)
+	return archive, err
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// CreateAppPassword выпускает пароль приложения для CalDAV; Password в ответе больше нигде не показывается
+func (c *Client) CreateAppPassword(ctx context.Context, name string) (models.AppPassword, error) {
+	var password models.AppPassword
+	err := c.do(ctx, http.MethodPost, appPasswordsPath, jsonType, map[string]string{"name": name}, &password)
+	return password, err
+}
+
+func (c *Client) GetAppPasswords(ctx context.Context) ([]models.AppPassword, error) {
+	var resp struct {
+		Data []models.AppPassword `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, appPasswordsPath, "", nil, &resp)
+	return resp.Data, err
+}
+
+func (c *Client) DeleteAppPassword(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf(appPasswordPath, id), "", nil, &statusResponse{})
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// SignUp регистрирует пользователя и возвращает его id
+func (c *Client) SignUp(ctx context.Context, user models.User) (int, error) {
+	var resp idResponse
+	err := c.do(ctx, http.MethodPost, signUpPath, jsonType, user, &resp)
+	return resp.Id, err
+}
+
+type signInResponse struct {
+	Token          string `json:"token"`
+	ChallengeToken string `json:"challenge_token"`
+}
+
+// SignIn получает токен и запоминает его для следующих запросов. Если у пользователя включена 2FA,
+// возвращает *TwoFactorRequiredError: вход нужно завершить через SignIn2FA.
+func (c *Client) SignIn(ctx context.Context, username, password string) (string, error) {
+	var resp signInResponse
+	input := map[string]string{"username": username, "password": password}
+	if err := c.do(ctx, http.MethodPost, signInPath, jsonType, input, &resp); err != nil {
+		return "", err
+	}
+	if resp.ChallengeToken != "" {
+		return "", &TwoFactorRequiredError{ChallengeToken: resp.ChallengeToken}
+	}
+
+	c.setToken(resp.Token)
+	return resp.Token, nil
+}
+
+// SignIn2FA завершает вход с 2FA кодом из приложения или кодом восстановления и запоминает токен
+func (c *Client) SignIn2FA(ctx context.Context, challengeToken, code string) (string, error) {
+	var resp signInResponse
+	input := map[string]string{"challenge_token": challengeToken, "code": code}
+	if err := c.do(ctx, http.MethodPost, signIn2FAPath, jsonType, input, &resp); err != nil {
+		return "", err
+	}
+
+	c.setToken(resp.Token)
+	return resp.Token, nil
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// Export выгружает все списки пользователя с задачами в формате JSON
+func (c *Client) Export(ctx context.Context) ([]models.ListBackup, error) {
+	var backup models.Backup
+	err := c.do(ctx, http.MethodGet, exportPath+"?format=json", "", nil, &backup)
+	return backup.Lists, err
+}
+
+// Import создает списки с задачами одной транзакцией. С dryRun сервер только проверяет данные.
+// Если какая-то запись не прошла проверку, возвращается *ValidationError с путями до полей.
+func (c *Client) Import(ctx context.Context, lists []models.ListBackup, dryRun bool) (models.ImportReport, error) {
+	var report models.ImportReport
+	path := importPath
+	if dryRun {
+		path += "?dry_run=true"
+	}
+	err := c.do(ctx, http.MethodPost, path, jsonType, models.Backup{Lists: lists}, &report)
+	return report, err
+}
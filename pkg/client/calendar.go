@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CalendarFeed - секретная ссылка на ICS-ленту пользователя
+type CalendarFeed struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// ExportListICS возвращает задачи списка в формате iCalendar (VTODO)
+func (c *Client) ExportListICS(ctx context.Context, listId int) ([]byte, error) {
+	var body []byte
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf(listICSPath, listId), "", nil, &body)
+	return body, err
+}
+
+// CreateCalendarFeed выпускает новую ссылку на ленту, прежняя перестает работать
+func (c *Client) CreateCalendarFeed(ctx context.Context) (CalendarFeed, error) {
+	var feed CalendarFeed
+	err := c.do(ctx, http.MethodPost, feedTokenPath, "", nil, &feed)
+	return feed, err
+}
+
+func (c *Client) DeleteCalendarFeed(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, feedTokenPath, "", nil, &statusResponse{})
+}
+
+// GetCalendarFeed скачивает ленту по токену, как это делает календарный клиент - без входа
+func (c *Client) GetCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	var body []byte
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf(feedPath, url.PathEscape(token)), "", nil, &body)
+	return body, err
+}
@@ -0,0 +1,202 @@
+// Package client - Go-клиент REST API приложения. Пути и тела запросов повторяют handler.InitRoutes
+// и сверяются со спецификацией docs/swagger.yaml командой go generate.
+//
+// Клиент сам получает и обновляет токен, если ему переданы учетные данные (WithCredentials),
+// повторяет идемпотентные запросы (GET, PUT, DELETE) при сетевых ошибках и ответах 429/502/503/504,
+// а ошибки API возвращает как *Error или *ValidationError:
+//
+//	c := client.New("http://localhost:8000", client.WithCredentials("alice", "secret"))
+//	lists, err := c.GetLists(ctx)
+//	if errors.Is(err, client.ErrUnauthorized) { ... }
+package client
+
+//go:generate go run ./internal/speccheck ../../docs/swagger.yaml
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retry      retryPolicy
+
+	mu       sync.Mutex
+	token    string
+	username string
+	password string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient подменяет http.Client, по умолчанию используется клиент с таймаутом 30 секунд
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken задает уже полученный токен, например сохраненный после прошлого SignIn,
+// или персональный токен доступа todo_pat_... для скриптов
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithCredentials включает автоматический вход: токен запрашивается перед первым запросом,
+// заранее обновляется перед истечением и запрашивается заново после ответа 401
+func WithCredentials(username, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithRetry задает число повторов идемпотентных запросов и начальную задержку между ними,
+// задержка растет экспоненциально. maxRetries = 0 отключает повторы.
+func WithRetry(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retry.maxRetries = maxRetries
+		c.retry.baseDelay = baseDelay
+	}
+}
+
+// New создает клиент для сервера baseURL, например "http://localhost:8000"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retry:      defaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token возвращает текущий токен, чтобы его можно было сохранить между запусками
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// do отправляет запрос с телом in (JSON, если это не []byte) и декодирует ответ в out, если out не nil;
+// в out типа *[]byte тело ответа записывается как есть. Запросы к /auth/ и к ленте /calendar/
+// идут без токена и без автоматического входа.
+func (c *Client) do(ctx context.Context, method, path, contentType string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var ok bool
+		if body, ok = in.([]byte); !ok {
+			var err error
+			if body, err = json.Marshal(in); err != nil {
+				return err
+			}
+		}
+	}
+
+	authenticated := !strings.HasPrefix(path, "/auth/") && !strings.HasPrefix(path, "/calendar/")
+	signedInAgain := false
+
+	for attempt := 0; ; attempt++ {
+		token := ""
+		if authenticated {
+			var err error
+			if token, err = c.validToken(ctx); err != nil {
+				return err
+			}
+		}
+
+		resp, err := c.send(ctx, method, path, contentType, body, in != nil, token)
+		if delay, retry := c.retry.next(method, attempt, resp, err); retry {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if err := sleep(ctx, delay); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && authenticated && c.hasCredentials() && !signedInAgain {
+			// токен отозван или истек раньше, чем мы ожидали: входим заново и повторяем запрос один раз
+			resp.Body.Close()
+			signedInAgain = true
+			if err := c.signInAgain(ctx, token); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return decodeResponse(resp, out)
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path, contentType string, body []byte, hasBody bool, token string) (*http.Response, error) {
+	var reader io.Reader
+	if hasBody {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if hasBody {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	// если вызывающий код трассируется, сервер продолжит его трейс
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return c.httpClient.Do(req)
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		var err error
+		*raw, err = io.ReadAll(resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type idResponse struct {
+	Id int `json:"id"`
+}
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,25 @@
+package client_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ponomare0v/todo-go-app/pkg/client"
+	"github.com/ponomare0v/todo-go-app/pkg/client/internal/spec"
+)
+
+// TestRoutesMatchSpec - то же, что go generate ./pkg/client, но без отдельного запуска
+func TestRoutesMatchSpec(t *testing.T) {
+	raw, err := os.ReadFile("../../docs/swagger.yaml")
+	if err != nil {
+		t.Fatalf("read spec: %v", err)
+	}
+
+	problems, err := spec.Check(raw, client.Routes)
+	if err != nil {
+		t.Fatalf("parse spec: %v", err)
+	}
+	for _, problem := range problems {
+		t.Error(problem)
+	}
+}
@@ -0,0 +1,968 @@
+package client_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/client"
+	"github.com/ponomare0v/todo-go-app/pkg/handler"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/oidc"
+	"github.com/ponomare0v/todo-go-app/pkg/oidc/oidctest"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/ponomare0v/todo-go-app/pkg/totp"
+)
+
+const (
+	username = "alice"
+	password = "secret123"
+)
+
+// TestClient - сквозные проверки: клиент ходит в настоящий роутер gin (handler.InitRoutes) поверх сервисов
+// и in-memory хранилища, поднятый в процессе через httptest. Каждая проверка получает свой сервер с пустым хранилищем.
+func TestClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Auth", testAuth)
+	t.Run("Lists", testLists)
+	t.Run("Items", testItems)
+	t.Run("Errors", testErrors)
+	t.Run("ExportImport", testExportImport)
+	t.Run("Calendar", testCalendar)
+	t.Run("AppPasswords", testAppPasswords)
+	t.Run("ImportJobs", testImportJobs)
+	t.Run("AccessTokens", testAccessTokens)
+	t.Run("OIDC", testOIDC)
+	t.Run("TwoFactor", testTwoFactor)
+	t.Run("Account", testAccount)
+	t.Run("TokenRefresh", testTokenRefresh)
+	t.Run("Retries", testRetries)
+	t.Run("GraphQL", testGraphQL)
+}
+
+// newServer поднимает роутер приложения; wrap позволяет вставить перед ним свой обработчик
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) string {
+	t.Helper()
+	return newServerWith(t, service.Config{}, wrap)
+}
+
+func newServerWith(t *testing.T, cfg service.Config, wrap func(http.Handler) http.Handler) string {
+	t.Helper()
+	services := service.NewService(repository.NewMemoryRepository(), cfg)
+	var h http.Handler = handler.NewHandler(services, handler.Config{RequestTimeout: 5 * time.Second}).InitRoutes()
+	if wrap != nil {
+		h = wrap(h)
+	}
+
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	// обработчик фонового импорта, как в cmd/app, только с частым опросом очереди
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		for ctx.Err() == nil {
+			if processed, _ := services.ImportJob.ProcessImportJob(ctx); !processed {
+				select {
+				case <-ctx.Done():
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+		}
+	}()
+	return server.URL
+}
+
+// signedIn регистрирует пользователя и возвращает клиент с его токеном
+func signedIn(t *testing.T, baseURL string, opts ...client.Option) *client.Client {
+	t.Helper()
+	ctx := context.Background()
+	c := client.New(baseURL, append([]client.Option{client.WithRetry(0, 0)}, opts...)...)
+
+	if _, err := c.SignUp(ctx, models.User{Name: "Alice", Username: username, Password: password}); err != nil {
+		t.Fatalf("SignUp: %v", err)
+	}
+	if _, err := c.SignIn(ctx, username, password); err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+	return c
+}
+
+func testAuth(t *testing.T) {
+	ctx := context.Background()
+	c := client.New(newServer(t, nil))
+
+	id, err := c.SignUp(ctx, models.User{Name: "Alice", Username: username, Password: password})
+	if err != nil || id == 0 {
+		t.Fatalf("SignUp: got %d, %v", id, err)
+	}
+
+	if _, err := c.GetLists(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("GetLists without a token: got %v, want ErrUnauthorized", err)
+	}
+
+	token, err := c.SignIn(ctx, username, password)
+	if err != nil || token == "" {
+		t.Fatalf("SignIn: got %q, %v", token, err)
+	}
+	if c.Token() != token {
+		t.Error("SignIn did not store the token")
+	}
+	if _, err := c.GetLists(ctx); err != nil {
+		t.Errorf("GetLists with a token: %v", err)
+	}
+}
+
+func testLists(t *testing.T) {
+	ctx := context.Background()
+	c := signedIn(t, newServer(t, nil))
+
+	id, err := c.CreateList(ctx, models.TodoList{Title: "groceries", Description: "weekly"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+
+	lists, err := c.GetLists(ctx)
+	if err != nil || len(lists) != 1 || lists[0].Id != id {
+		t.Fatalf("GetLists: got %+v, %v", lists, err)
+	}
+
+	title := "food"
+	if err := c.UpdateList(ctx, id, models.UpdateListInput{Title: &title}); err != nil {
+		t.Fatalf("UpdateList: %v", err)
+	}
+	patch := models.Patch{Type: models.MergePatchType, Body: []byte(`{"description":"monthly"}`)}
+	if err := c.PatchList(ctx, id, patch); err != nil {
+		t.Fatalf("PatchList: %v", err)
+	}
+
+	list, err := c.GetList(ctx, id)
+	if err != nil {
+		t.Fatalf("GetList: %v", err)
+	}
+	if want := (models.TodoList{Id: id, Title: "food", Description: "monthly"}); list != want {
+		t.Errorf("GetList: got %+v, want %+v", list, want)
+	}
+
+	if err := c.DeleteList(ctx, id); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if lists, err := c.GetLists(ctx); err != nil || len(lists) != 0 {
+		t.Errorf("GetLists after DeleteList: got %+v, %v", lists, err)
+	}
+}
+
+func testItems(t *testing.T) {
+	ctx := context.Background()
+	c := signedIn(t, newServer(t, nil))
+
+	listId, err := c.CreateList(ctx, models.TodoList{Title: "groceries"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	id, err := c.CreateItem(ctx, listId, models.TodoItem{Title: "milk"})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+
+	done := true
+	if err := c.UpdateItem(ctx, id, models.UpdateItemInput{Done: &done}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	patch := models.Patch{Type: models.JSONPatchType, Body: []byte(`[{"op":"replace","path":"/title","value":"oat milk"}]`)}
+	if err := c.PatchItem(ctx, id, patch); err != nil {
+		t.Fatalf("PatchItem: %v", err)
+	}
+
+	item, err := c.GetItem(ctx, id)
+	if err != nil {
+		t.Fatalf("GetItem: %v", err)
+	}
+	if want := (models.TodoItem{Id: id, Title: "oat milk", Done: true}); item != want {
+		t.Errorf("GetItem: got %+v, want %+v", item, want)
+	}
+
+	if err := c.DeleteItem(ctx, id); err != nil {
+		t.Fatalf("DeleteItem: %v", err)
+	}
+	if items, err := c.GetItems(ctx, listId); err != nil || len(items) != 0 {
+		t.Errorf("GetItems after DeleteItem: got %+v, %v", items, err)
+	}
+}
+
+func testErrors(t *testing.T) {
+	ctx := context.Background()
+	c := signedIn(t, newServer(t, nil))
+
+	_, err := c.CreateList(ctx, models.TodoList{Title: "  "})
+	var validationErr *client.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("CreateList with an empty title: got %v, want *ValidationError", err)
+	}
+	if len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "title" {
+		t.Errorf("validation fields: got %+v, want one error for title", validationErr.Fields)
+	}
+	if !errors.Is(err, client.ErrValidation) {
+		t.Error("ValidationError does not match ErrValidation")
+	}
+
+	err = c.PatchList(ctx, 1, models.Patch{Type: "text/plain", Body: []byte("x")})
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("PatchList with an unsupported type: got %v, want 415", err)
+	}
+	if apiErr != nil && apiErr.RequestId == "" {
+		t.Error("Error.RequestId is empty")
+	}
+
+	huge := `{"description":"` + strings.Repeat("x", 64<<10) + `"}`
+	err = c.PatchList(ctx, 1, models.Patch{Type: models.MergePatchType, Body: []byte(huge)})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("PatchList with a huge patch: got %v, want 413", err)
+	}
+}
+
+func testExportImport(t *testing.T) {
+	ctx := context.Background()
+	c := signedIn(t, newServer(t, nil))
+
+	lists := []models.ListBackup{
+		{Title: "groceries", Items: []models.ItemBackup{{Title: "milk", Done: true}, {Title: "bread"}}},
+		{Title: "empty", Description: "nothing yet"},
+	}
+	report, err := c.Import(ctx, lists, true)
+	if err != nil || !report.DryRun || report.Lists != 2 || report.Items != 2 || len(report.ListIds) != 0 {
+		t.Fatalf("Import dry run: got %+v, %v", report, err)
+	}
+	if got, _ := c.GetLists(ctx); len(got) != 0 {
+		t.Fatalf("dry run created lists: %+v", got)
+	}
+
+	_, err = c.Import(ctx, []models.ListBackup{{Title: "ok"}, {Title: "bad", Items: []models.ItemBackup{{Title: " "}}}}, false)
+	var validationErr *client.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "lists[1].items[0].title" {
+		t.Fatalf("Import with an invalid item: got %v, want one error for lists[1].items[0].title", err)
+	}
+	if got, _ := c.GetLists(ctx); len(got) != 0 {
+		t.Fatalf("failed import created lists: %+v", got)
+	}
+
+	report, err = c.Import(ctx, lists, false)
+	if err != nil || report.DryRun || len(report.ListIds) != 2 {
+		t.Fatalf("Import: got %+v, %v", report, err)
+	}
+
+	exported, err := c.Export(ctx)
+	if err != nil || len(exported) != 2 {
+		t.Fatalf("Export: got %+v, %v", exported, err)
+	}
+	byTitle := make(map[string]models.ListBackup)
+	for _, list := range exported {
+		byTitle[list.Title] = list
+	}
+	if items := byTitle["groceries"].Items; len(items) != 2 {
+		t.Errorf("exported groceries items: got %+v", items)
+	}
+	if list := byTitle["empty"]; list.Description != "nothing yet" || len(list.Items) != 0 {
+		t.Errorf("exported empty list: got %+v", list)
+	}
+}
+
+func testImportJobs(t *testing.T) {
+	ctx := context.Background()
+	c := signedIn(t, newServer(t, nil))
+
+	board := `{"name": "Roadmap", "desc": "Q1",
+		"lists": [{"id": "l1", "name": "To Do", "pos": 1}, {"id": "l2", "name": "Old", "closed": true, "pos": 2}],
+		"cards": [
+			{"id": "c1", "idList": "l1", "name": "Launch", "desc": "public beta", "due": "2030-01-02T10:00:00.000Z",
+			 "dueComplete": true, "pos": 1, "labels": [{"name": "big deal", "color": "red"}]},
+			{"id": "c2", "idList": "l2", "name": "Archived column", "pos": 1}
+		],
+		"checklists": [{"idCard": "c1", "pos": 1, "checkItems": [
+			{"name": "Docs", "state": "complete", "pos": 2}, {"name": "Tests", "state": "incomplete", "pos": 1}
+		]}]}`
+	job, err := c.StartImport(ctx, "trello", "application/json", []byte(board), "")
+	if err != nil || job.Id == 0 || job.Source != "trello" || job.TotalLists != 1 || job.TotalItems != 3 {
+		t.Fatalf("StartImport(trello): got %+v, %v", job, err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !job.Finished() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		if job, err = c.GetImportJob(ctx, job.Id); err != nil {
+			t.Fatalf("GetImportJob: %v", err)
+		}
+	}
+	if job.Status != models.ImportCompleted || job.DoneLists != 1 || job.DoneItems != 3 {
+		t.Fatalf("import job: got %+v, want completed with 1 list and 3 items", job)
+	}
+
+	lists, err := c.GetLists(ctx)
+	if err != nil || len(lists) != 1 || lists[0].Title != "Roadmap" || lists[0].Description != "Q1" {
+		t.Fatalf("GetLists after import: got %+v, %v", lists, err)
+	}
+	items, err := c.GetItems(ctx, lists[0].Id)
+	if err != nil || len(items) != 3 {
+		t.Fatalf("GetItems after import: got %+v, %v", items, err)
+	}
+	if items[0].Title != "Launch @To_Do @big_deal" || !items[0].Done || items[0].DueDate == nil {
+		t.Errorf("imported card: got %+v", items[0])
+	}
+	if items[1].Title != "Tests" || items[1].Done || items[1].Description != "Subtask of: Launch" || items[2].Title != "Docs" || !items[2].Done {
+		t.Errorf("imported checklist: got %+v", items[1:])
+	}
+
+	template := "TYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"task,Bad date,,4,1,,,,en,\n" +
+		"task,,,4,1,,,,en,\n"
+	_, err = c.StartImport(ctx, "todoist", "text/csv", []byte(template), "Home")
+	var validationErr *client.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "lists[0].items[1].title" {
+		t.Fatalf("StartImport with an invalid task: got %v, want one error for lists[0].items[1].title", err)
+	}
+	if jobs, err := c.GetImportJobs(ctx); err != nil || len(jobs) != 1 {
+		t.Errorf("GetImportJobs: got %+v, %v, want only the trello job", jobs, err)
+	}
+
+	var apiErr *client.Error
+	if _, err := c.GetImportJob(ctx, job.Id+100); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetImportJob of a missing job: got %v, want 404", err)
+	}
+	if _, err := c.StartImport(ctx, "trello", "text/csv", []byte("a,b\n"), ""); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("StartImport of a trello csv: got %v, want 415", err)
+	}
+}
+
+func testCalendar(t *testing.T) {
+	ctx := context.Background()
+	baseURL := newServer(t, nil)
+	c := signedIn(t, baseURL)
+
+	listId, err := c.CreateList(ctx, models.TodoList{Title: "work"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	due := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	if _, err := c.CreateItem(ctx, listId, models.TodoItem{Title: "report, draft", DueDate: &due}); err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+
+	ics, err := c.ExportListICS(ctx, listId)
+	if err != nil {
+		t.Fatalf("ExportListICS: %v", err)
+	}
+	for _, want := range []string{"BEGIN:VCALENDAR\r\n", "SUMMARY:report\\, draft\r\n", "DUE:20300102T030405Z\r\n", "END:VCALENDAR\r\n"} {
+		if !strings.Contains(string(ics), want) {
+			t.Errorf("ExportListICS: %q not found in\n%s", want, ics)
+		}
+	}
+
+	feed, err := c.CreateCalendarFeed(ctx)
+	if err != nil || feed.Token == "" || !strings.HasSuffix(feed.URL, "/calendar/"+feed.Token+"/todo.ics") {
+		t.Fatalf("CreateCalendarFeed: got %+v, %v", feed, err)
+	}
+	// лента открывается без входа, как в календарном клиенте
+	anonymous := client.New(baseURL, client.WithRetry(0, 0))
+	if body, err := anonymous.GetCalendarFeed(ctx, feed.Token); err != nil || !strings.Contains(string(body), "CATEGORIES:work") {
+		t.Fatalf("GetCalendarFeed: got %s, %v", body, err)
+	}
+
+	// новая ссылка отзывает прежнюю
+	renewed, err := c.CreateCalendarFeed(ctx)
+	if err != nil || renewed.Token == feed.Token {
+		t.Fatalf("CreateCalendarFeed again: got %+v, %v", renewed, err)
+	}
+	if _, err := anonymous.GetCalendarFeed(ctx, feed.Token); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("GetCalendarFeed with a revoked token: got %v, want ErrNotFound", err)
+	}
+
+	if err := c.DeleteCalendarFeed(ctx); err != nil {
+		t.Fatalf("DeleteCalendarFeed: %v", err)
+	}
+	if _, err := anonymous.GetCalendarFeed(ctx, renewed.Token); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("GetCalendarFeed after delete: got %v, want ErrNotFound", err)
+	}
+}
+
+// testAppPasswords проверяет пароли приложений и то, что с ними работает CalDAV: у клиента нет
+// методов CalDAV, поэтому запросы к /caldav/ отправляются напрямую
+func testAppPasswords(t *testing.T) {
+	ctx := context.Background()
+	baseURL := newServer(t, nil)
+	c := signedIn(t, baseURL)
+
+	created, err := c.CreateAppPassword(ctx, "phone")
+	if err != nil || created.Id == 0 || created.Name != "phone" || created.Password == "" {
+		t.Fatalf("CreateAppPassword: got %+v, %v", created, err)
+	}
+	passwords, err := c.GetAppPasswords(ctx)
+	if err != nil || len(passwords) != 1 || passwords[0].Id != created.Id || passwords[0].Password != "" {
+		t.Fatalf("GetAppPasswords: got %+v, %v", passwords, err)
+	}
+
+	listId, err := c.CreateList(ctx, models.TodoList{Title: "work"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	calendar := baseURL + "/caldav/lists/" + strconv.Itoa(listId) + "/"
+
+	dav := func(method, url, body string, header map[string]string, pass string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest: %v", err)
+		}
+		req.SetBasicAuth(username, pass)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, url, err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	if resp := dav("PROPFIND", baseURL+"/caldav/", "", nil, password); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("PROPFIND with the account password: got %d, want 401", resp.StatusCode)
+	}
+	if resp := dav("PROPFIND", calendar, "", map[string]string{"Depth": "1"}, created.Password); resp.StatusCode != http.StatusMultiStatus {
+		t.Fatalf("PROPFIND calendar: got %d, want 207", resp.StatusCode)
+	}
+
+	todo := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:abc-1\r\nSUMMARY:call bob\r\nDUE:20300102T030405Z\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	if resp := dav(http.MethodPut, calendar+"abc-1.ics", todo, map[string]string{"If-None-Match": "*"}, created.Password); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT new object: got %d, want 201", resp.StatusCode)
+	}
+	items, err := c.GetItems(ctx, listId)
+	if err != nil || len(items) != 1 || items[0].Title != "call bob" || items[0].DueDate == nil {
+		t.Fatalf("GetItems after PUT: got %+v, %v", items, err)
+	}
+
+	resp := dav(http.MethodGet, calendar+"abc-1.ics", "", nil, created.Password)
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("GET object: got %d, ETag %q", resp.StatusCode, etag)
+	}
+
+	// правка через REST меняет ETag, и клиент со старым ETag не перезапишет ее
+	done := true
+	if err := c.UpdateItem(ctx, items[0].Id, models.UpdateItemInput{Done: &done}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if resp := dav(http.MethodPut, calendar+"abc-1.ics", todo, map[string]string{"If-Match": etag}, created.Password); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("PUT with a stale ETag: got %d, want 412", resp.StatusCode)
+	}
+	if resp := dav(http.MethodDelete, calendar+"abc-1.ics", "", nil, created.Password); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE object: got %d, want 204", resp.StatusCode)
+	}
+
+	if err := c.DeleteAppPassword(ctx, created.Id); err != nil {
+		t.Fatalf("DeleteAppPassword: %v", err)
+	}
+	if resp := dav("PROPFIND", calendar, "", nil, created.Password); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("PROPFIND with a revoked app password: got %d, want 401", resp.StatusCode)
+	}
+	if err := c.DeleteAppPassword(ctx, created.Id); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteAppPassword again: got %v, want ErrNotFound", err)
+	}
+}
+
+// testAccessTokens проверяет, что персональный токен работает вместо JWT только в пределах своих прав
+func testAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	baseURL := newServer(t, nil)
+	c := signedIn(t, baseURL)
+
+	listId, err := c.CreateList(ctx, models.TodoList{Title: "work"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+
+	created, err := c.CreateAccessToken(ctx, models.AccessToken{Name: "cron", Scopes: []string{models.ScopeRead, models.ScopeItemsWrite}})
+	if err != nil || created.Id == 0 || !strings.HasPrefix(created.Token, "todo_pat_") {
+		t.Fatalf("CreateAccessToken: got %+v, %v", created, err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, err := c.CreateAccessToken(ctx, models.AccessToken{Name: "bad", Scopes: []string{"admin"}, ExpiresAt: &past}); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("CreateAccessToken with an unknown scope and past expiry: got %v, want ErrValidation", err)
+	}
+
+	script := client.New(baseURL, client.WithToken(created.Token), client.WithRetry(0, 0))
+	if _, err := script.GetLists(ctx); err != nil {
+		t.Errorf("GetLists with the token: %v", err)
+	}
+	if _, err := script.CreateItem(ctx, listId, models.TodoItem{Title: "deploy"}); err != nil {
+		t.Errorf("CreateItem with items:write: %v", err)
+	}
+	if _, err := script.CreateList(ctx, models.TodoList{Title: "other"}); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("CreateList without lists:write: got %v, want ErrForbidden", err)
+	}
+	if err := script.GraphQL(ctx, `mutation { deleteList(id: `+strconv.Itoa(listId)+`) }`, nil, nil); err == nil || !strings.Contains(err.Error(), models.ScopeListsWrite) {
+		t.Errorf("GraphQL deleteList without lists:write: got %v, want a scope error", err)
+	}
+	if _, err := script.GetAccessTokens(ctx); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("GetAccessTokens with a token: got %v, want ErrForbidden", err)
+	}
+	if _, err := script.CreateAppPassword(ctx, "phone"); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("CreateAppPassword with a token: got %v, want ErrForbidden", err)
+	}
+
+	tokens, err := c.GetAccessTokens(ctx)
+	if err != nil || len(tokens) != 1 || tokens[0].Token != "" || tokens[0].LastUsedAt == nil || len(tokens[0].Scopes) != 2 {
+		t.Fatalf("GetAccessTokens: got %+v, %v", tokens, err)
+	}
+
+	if err := c.DeleteAccessToken(ctx, created.Id); err != nil {
+		t.Fatalf("DeleteAccessToken: %v", err)
+	}
+	if _, err := script.GetLists(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("GetLists with a revoked token: got %v, want ErrUnauthorized", err)
+	}
+	if err := c.DeleteAccessToken(ctx, created.Id); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteAccessToken again: got %v, want ErrNotFound", err)
+	}
+}
+
+func testOIDC(t *testing.T) {
+	ctx := context.Background()
+	idp := oidctest.NewProvider()
+	t.Cleanup(idp.Close)
+
+	corp := idp.Config("corp")
+	corp.LinkBy = oidc.LinkByEmail
+	corp.LinkDomains = []string{"Example.com"}
+	partner := idp.Config("partner")
+	partner.AutoProvision = false
+	providers, err := oidc.New(oidc.Config{Providers: []oidc.ProviderConfig{corp, partner}})
+	if err != nil {
+		t.Fatalf("oidc.New: %v", err)
+	}
+	baseURL := newServerWith(t, service.Config{OIDC: providers}, nil)
+
+	alice := signedIn(t, baseURL)
+	listId, err := alice.CreateList(ctx, models.TodoList{Title: "alice's"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+
+	c := client.New(baseURL, client.WithRetry(0, 0))
+	names, err := c.OIDCProviders(ctx)
+	if err != nil || strings.Join(names, ",") != "corp,partner" {
+		t.Fatalf("OIDCProviders: got %v, %v", names, err)
+	}
+
+	// подтвержденный email alice@example.com совпадает с существующим пользователем alice,
+	// но связывается только после ввода ее пароля
+	_, err = c.SignInOIDC(ctx, "corp", idp.Authorize)
+	var linkRequired *client.OIDCLinkRequiredError
+	if !errors.As(err, &linkRequired) || linkRequired.Username != username {
+		t.Fatalf("SignInOIDC into an existing account: got %v, want OIDCLinkRequiredError for %s", err, username)
+	}
+	if _, err := c.LinkOIDC(ctx, linkRequired.LinkToken, "wrong password"); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("LinkOIDC with a wrong password: got %v, want ErrUnauthorized", err)
+	}
+	if _, err := c.LinkOIDC(ctx, "forged", password); !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("LinkOIDC with a forged token: got %v, want ErrBadRequest", err)
+	}
+	if _, err := c.LinkOIDC(ctx, linkRequired.LinkToken, password); err != nil {
+		t.Fatalf("LinkOIDC: %v", err)
+	}
+	if lists, err := c.GetLists(ctx); err != nil || len(lists) != 1 || lists[0].Id != listId {
+		t.Errorf("GetLists after linking: got %+v, %v", lists, err)
+	}
+	if _, err := c.SignInOIDC(ctx, "corp", idp.Authorize); err != nil {
+		t.Fatalf("SignInOIDC after linking: %v", err)
+	}
+
+	// с включенной 2FA вход через провайдера тоже заканчивается кодом
+	enrollment, err := c.EnrollTwoFactor(ctx)
+	if err != nil {
+		t.Fatalf("EnrollTwoFactor: %v", err)
+	}
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatalf("totp.Code: %v", err)
+	}
+	if _, err := c.ConfirmTwoFactor(ctx, code); err != nil {
+		t.Fatalf("ConfirmTwoFactor: %v", err)
+	}
+	_, err = c.SignInOIDC(ctx, "corp", idp.Authorize)
+	var twoFactorRequired *client.TwoFactorRequiredError
+	if !errors.As(err, &twoFactorRequired) {
+		t.Fatalf("SignInOIDC with 2FA: got %v, want TwoFactorRequiredError", err)
+	}
+	if code, err = totp.Code(enrollment.Secret, totp.Step(time.Now())+1); err != nil {
+		t.Fatalf("totp.Code: %v", err)
+	}
+	if _, err := c.SignIn2FA(ctx, twoFactorRequired.ChallengeToken, code); err != nil {
+		t.Fatalf("SignIn2FA after SignInOIDC: %v", err)
+	}
+	if lists, err := c.GetLists(ctx); err != nil || len(lists) != 1 {
+		t.Errorf("GetLists after SignInOIDC with 2FA: got %+v, %v", lists, err)
+	}
+
+	// тот же логин в чужом домене с существующим пользователем не связывается
+	idp.SetUser(oidc.Claims{Subject: "mallory-sub", Email: username + "@evil.example", EmailVerified: true, Name: "Mallory"})
+	mallory := client.New(baseURL, client.WithRetry(0, 0))
+	if _, err := mallory.SignInOIDC(ctx, "corp", idp.Authorize); err != nil {
+		t.Fatalf("SignInOIDC from another domain: %v", err)
+	}
+	if lists, err := mallory.GetLists(ctx); err != nil || len(lists) != 0 {
+		t.Errorf("GetLists of a user from another domain: got %+v, %v", lists, err)
+	}
+
+	// незнакомый пользователь создается при первом входе и находится по связке при следующем
+	idp.SetUser(oidc.Claims{Subject: "bob-sub", Email: "bob@example.com", Name: "Bob", PreferredUsername: "bob"})
+	bob := client.New(baseURL, client.WithRetry(0, 0))
+	if _, err := bob.SignInOIDC(ctx, "corp", idp.Authorize); err != nil {
+		t.Fatalf("SignInOIDC provisioning bob: %v", err)
+	}
+	if lists, err := bob.GetLists(ctx); err != nil || len(lists) != 0 {
+		t.Errorf("GetLists of a provisioned user: got %+v, %v", lists, err)
+	}
+	if _, err := bob.CreateList(ctx, models.TodoList{Title: "bob's"}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	if _, err := bob.SignInOIDC(ctx, "corp", idp.Authorize); err != nil {
+		t.Fatalf("SignInOIDC again: %v", err)
+	}
+	if lists, err := bob.GetLists(ctx); err != nil || len(lists) != 1 {
+		t.Errorf("GetLists after signing in again: got %+v, %v", lists, err)
+	}
+
+	// пароль созданного через OIDC пользователя неизвестен: сразу после входа через провайдера можно задать
+	// первый пароль без текущего, и аккаунт удаляется без пароля
+	if _, err := bob.ChangePassword(ctx, "", "bob-secret123"); err != nil {
+		t.Fatalf("ChangePassword after SignInOIDC: %v", err)
+	}
+	if _, err := bob.SignIn(ctx, "bob", "bob-secret123"); err != nil {
+		t.Fatalf("SignIn with the first password: %v", err)
+	}
+	if _, err := bob.ChangePassword(ctx, "", "bob-secret456"); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("ChangePassword without the current password after a password sign-in: got %v, want ErrValidation", err)
+	}
+	if err := bob.DeleteAccount(ctx, models.DeleteAccountInput{}); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("DeleteAccount without a password after a password sign-in: got %v, want ErrValidation", err)
+	}
+	if _, err := bob.SignInOIDC(ctx, "corp", idp.Authorize); err != nil {
+		t.Fatalf("SignInOIDC before deleting: %v", err)
+	}
+	if err := bob.DeleteAccount(ctx, models.DeleteAccountInput{}); err != nil {
+		t.Fatalf("DeleteAccount after SignInOIDC: %v", err)
+	}
+
+	if _, err := c.SignInOIDC(ctx, "partner", idp.Authorize); !errors.Is(err, client.ErrForbidden) {
+		t.Errorf("SignInOIDC without auto provisioning: got %v, want ErrForbidden", err)
+	}
+	if _, err := c.SignInOIDC(ctx, "unknown", idp.Authorize); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("SignInOIDC with an unknown provider: got %v, want ErrNotFound", err)
+	}
+
+	forgedState := func(ctx context.Context, authURL string) (string, error) {
+		callbackURL, err := idp.Authorize(ctx, authURL)
+		return strings.Replace(callbackURL, "state=", "state=forged", 1), err
+	}
+	if _, err := c.SignInOIDC(ctx, "corp", forgedState); !errors.Is(err, client.ErrBadRequest) {
+		t.Errorf("SignInOIDC with a forged state: got %v, want ErrBadRequest", err)
+	}
+}
+
+func testTwoFactor(t *testing.T) {
+	ctx := context.Background()
+	baseURL := newServer(t, nil)
+	c := signedIn(t, baseURL)
+
+	if status, err := c.GetTwoFactor(ctx); err != nil || status.Enabled {
+		t.Fatalf("GetTwoFactor before enrollment: got %+v, %v", status, err)
+	}
+	if _, err := c.ConfirmTwoFactor(ctx, "123456"); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("ConfirmTwoFactor before enrollment: got %v, want ErrConflict", err)
+	}
+
+	enrollment, err := c.EnrollTwoFactor(ctx)
+	if err != nil || enrollment.Secret == "" || !strings.HasPrefix(enrollment.URI, "otpauth://totp/") {
+		t.Fatalf("EnrollTwoFactor: got %+v, %v", enrollment, err)
+	}
+	code := func(offset int64) string {
+		code, err := totp.Code(enrollment.Secret, totp.Step(time.Now())+offset)
+		if err != nil {
+			t.Fatalf("totp.Code: %v", err)
+		}
+		return code
+	}
+
+	if _, err := c.ConfirmTwoFactor(ctx, code(-100)); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("ConfirmTwoFactor with a stale code: got %v, want ErrValidation", err)
+	}
+	confirmCode := code(0)
+	recoveryCodes, err := c.ConfirmTwoFactor(ctx, confirmCode)
+	if err != nil || len(recoveryCodes) != 10 {
+		t.Fatalf("ConfirmTwoFactor: got %v, %v", recoveryCodes, err)
+	}
+	if _, err := c.EnrollTwoFactor(ctx); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("EnrollTwoFactor when enabled: got %v, want ErrConflict", err)
+	}
+	if status, err := c.GetTwoFactor(ctx); err != nil || !status.Enabled || status.RecoveryCodesLeft != 10 {
+		t.Errorf("GetTwoFactor: got %+v, %v", status, err)
+	}
+
+	// пароля теперь мало: SignIn возвращает challenge, токен выдается только вместе с кодом
+	other := client.New(baseURL, client.WithRetry(0, 0))
+	challenge := func() string {
+		t.Helper()
+		_, err := other.SignIn(ctx, username, password)
+		var required *client.TwoFactorRequiredError
+		if !errors.As(err, &required) || !errors.Is(err, client.ErrTwoFactorRequired) || required.ChallengeToken == "" {
+			t.Fatalf("SignIn with 2FA: got %v, want TwoFactorRequiredError", err)
+		}
+		return required.ChallengeToken
+	}
+
+	first := challenge()
+	if _, err := client.New(baseURL, client.WithToken(first), client.WithRetry(0, 0)).GetLists(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("GetLists with a challenge token: got %v, want ErrUnauthorized", err)
+	}
+	if _, err := other.SignIn2FA(ctx, first, confirmCode); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("SignIn2FA with an already used code: got %v, want ErrUnauthorized", err)
+	}
+	if _, err := other.SignIn2FA(ctx, first+"x", code(1)); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("SignIn2FA with a forged challenge: got %v, want ErrUnauthorized", err)
+	}
+	if token, err := other.SignIn2FA(ctx, first, code(1)); err != nil || token == "" {
+		t.Fatalf("SignIn2FA with the next code: got %q, %v", token, err)
+	}
+	if _, err := other.GetLists(ctx); err != nil {
+		t.Errorf("GetLists after SignIn2FA: %v", err)
+	}
+
+	if _, err := other.SignIn2FA(ctx, challenge(), strings.ToUpper(recoveryCodes[0])); err != nil {
+		t.Fatalf("SignIn2FA with a recovery code: %v", err)
+	}
+	if _, err := other.SignIn2FA(ctx, challenge(), recoveryCodes[0]); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("SignIn2FA with a used recovery code: got %v, want ErrUnauthorized", err)
+	}
+
+	if err := c.DisableTwoFactor(ctx, recoveryCodes[0]); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("DisableTwoFactor with a used recovery code: got %v, want ErrValidation", err)
+	}
+	if err := c.DisableTwoFactor(ctx, recoveryCodes[1]); err != nil {
+		t.Fatalf("DisableTwoFactor: %v", err)
+	}
+	if token, err := other.SignIn(ctx, username, password); err != nil || token == "" {
+		t.Errorf("SignIn after disabling 2FA: got %q, %v", token, err)
+	}
+
+	// с включенной 2FA аккаунт можно удалить кодом вместо пароля
+	d := signedIn(t, newServer(t, nil))
+	if enrollment, err = d.EnrollTwoFactor(ctx); err != nil {
+		t.Fatalf("EnrollTwoFactor: %v", err)
+	}
+	if recoveryCodes, err = d.ConfirmTwoFactor(ctx, code(0)); err != nil {
+		t.Fatalf("ConfirmTwoFactor: %v", err)
+	}
+	if err := d.DeleteAccount(ctx, models.DeleteAccountInput{Code: "000000"}); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("DeleteAccount with a wrong code: got %v, want ErrValidation", err)
+	}
+	if err := d.DeleteAccount(ctx, models.DeleteAccountInput{Code: recoveryCodes[0]}); err != nil {
+		t.Errorf("DeleteAccount with a recovery code: %v", err)
+	}
+}
+
+func testAccount(t *testing.T) {
+	ctx := context.Background()
+	baseURL := newServer(t, nil)
+	c := signedIn(t, baseURL)
+
+	profile, err := c.GetProfile(ctx)
+	if err != nil || profile.Username != username || profile.Name != "Alice" || profile.Id == 0 {
+		t.Fatalf("GetProfile: got %+v, %v", profile, err)
+	}
+	if profile, err = c.UpdateProfile(ctx, models.UpdateProfileInput{Name: "  Alice Liddell "}); err != nil || profile.Name != "Alice Liddell" {
+		t.Errorf("UpdateProfile: got %+v, %v", profile, err)
+	}
+	if _, err := c.UpdateProfile(ctx, models.UpdateProfileInput{Name: " "}); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("UpdateProfile with an empty name: got %v, want ErrValidation", err)
+	}
+
+	// клиент с автоматическим входом продолжает работать и после смены пароля
+	auto := client.New(baseURL, client.WithRetry(0, 0), client.WithCredentials(username, password))
+	if _, err := auto.GetLists(ctx); err != nil {
+		t.Fatalf("GetLists with credentials: %v", err)
+	}
+	oldToken := c.Token()
+
+	if _, err := c.ChangePassword(ctx, "wrong-password", "new-secret123"); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("ChangePassword with a wrong current password: got %v, want ErrValidation", err)
+	}
+	if _, err := c.ChangePassword(ctx, password, "short"); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("ChangePassword with a short password: got %v, want ErrValidation", err)
+	}
+	newToken, err := auto.ChangePassword(ctx, password, "new-secret123")
+	if err != nil || newToken == "" || auto.Token() != newToken {
+		t.Fatalf("ChangePassword: got %q, %v", newToken, err)
+	}
+
+	old := client.New(baseURL, client.WithRetry(0, 0), client.WithToken(oldToken))
+	if _, err := old.GetLists(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("GetLists with a token issued before the password change: got %v, want ErrUnauthorized", err)
+	}
+	if _, err := auto.GetLists(ctx); err != nil {
+		t.Errorf("GetLists with the new token: %v", err)
+	}
+	if _, err := c.SignIn(ctx, username, password); err == nil {
+		t.Error("SignIn with the old password: expected an error")
+	}
+	if _, err := c.SignIn(ctx, username, "new-secret123"); err != nil {
+		t.Fatalf("SignIn with the new password: %v", err)
+	}
+
+	if _, err := c.CreateList(ctx, models.TodoList{Title: "groceries"}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	archive, err := c.ExportAccount(ctx)
+	if err != nil {
+		t.Fatalf("ExportAccount: %v", err)
+	}
+	files := readZip(t, archive)
+	var account models.AccountExport
+	if err := json.Unmarshal(files["account.json"], &account); err != nil || account.Profile.Name != "Alice Liddell" {
+		t.Errorf("account.json: got %+v, %v", account, err)
+	}
+	var backup models.Backup
+	if err := json.Unmarshal(files["lists.json"], &backup); err != nil || len(backup.Lists) != 1 || backup.Lists[0].Title != "groceries" {
+		t.Errorf("lists.json: got %+v, %v", backup, err)
+	}
+
+	if err := c.DeleteAccount(ctx, models.DeleteAccountInput{Password: "wrong-password"}); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("DeleteAccount with a wrong password: got %v, want ErrValidation", err)
+	}
+	if err := c.DeleteAccount(ctx, models.DeleteAccountInput{}); !errors.Is(err, client.ErrValidation) {
+		t.Errorf("DeleteAccount without a password after a password sign-in: got %v, want ErrValidation", err)
+	}
+	if err := auto.DeleteAccount(ctx, models.DeleteAccountInput{Password: "new-secret123"}); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+	if _, err := c.GetLists(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("GetLists after DeleteAccount: got %v, want ErrUnauthorized", err)
+	}
+	if _, err := c.SignIn(ctx, username, "new-secret123"); err == nil {
+		t.Error("SignIn after DeleteAccount: expected an error")
+	}
+}
+
+// readZip возвращает содержимое файлов архива по именам
+func readZip(t *testing.T, archive []byte) map[string][]byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range reader.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		if files[file.Name], err = io.ReadAll(r); err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+		r.Close()
+	}
+	return files
+}
+
+func testTokenRefresh(t *testing.T) {
+	ctx := context.Background()
+	baseURL := newServer(t, nil)
+	signedIn(t, baseURL)
+
+	// без токена клиент входит сам, а после 401 на испорченный токен - входит заново
+	c := client.New(baseURL, client.WithCredentials(username, password), client.WithToken("not-a-jwt"))
+	if _, err := c.GetLists(ctx); err != nil {
+		t.Fatalf("GetLists with a bad token and credentials: %v", err)
+	}
+	if c.Token() == "not-a-jwt" {
+		t.Error("client did not replace the rejected token")
+	}
+
+	c = client.New(baseURL, client.WithCredentials(username, "wrong-password"))
+	if _, err := c.GetLists(ctx); err == nil {
+		t.Error("GetLists with wrong credentials: expected an error")
+	}
+}
+
+func testRetries(t *testing.T) {
+	ctx := context.Background()
+	var failures atomic.Int32
+	failures.Store(2)
+	flaky := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/auth/sign-up" && r.URL.Path != "/auth/sign-in" && failures.Add(-1) >= 0 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	baseURL := newServer(t, flaky)
+	signedIn(t, baseURL)
+
+	c := client.New(baseURL, client.WithCredentials(username, password), client.WithRetry(3, time.Millisecond))
+	if _, err := c.GetLists(ctx); err != nil {
+		t.Fatalf("GetLists after two 503 responses: %v", err)
+	}
+
+	// POST не идемпотентен и не повторяется
+	failures.Store(1)
+	if _, err := c.CreateList(ctx, models.TodoList{Title: "groceries"}); !errors.Is(err, client.ErrServer) {
+		t.Errorf("CreateList after a 503 response: got %v, want ErrServer", err)
+	}
+	if lists, err := c.GetLists(ctx); err != nil || len(lists) != 0 {
+		t.Errorf("CreateList was retried: got %+v, %v", lists, err)
+	}
+}
+
+func testGraphQL(t *testing.T) {
+	ctx := context.Background()
+	c := signedIn(t, newServer(t, nil))
+
+	if _, err := c.CreateList(ctx, models.TodoList{Title: "groceries"}); err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+
+	var data struct {
+		Lists []struct {
+			Title      string `json:"title"`
+			ItemsCount int    `json:"itemsCount"`
+		} `json:"lists"`
+	}
+	if err := c.GraphQL(ctx, `{ lists { title itemsCount } }`, nil, &data); err != nil {
+		t.Fatalf("GraphQL: %v", err)
+	}
+	if len(data.Lists) != 1 || data.Lists[0].Title != "groceries" {
+		t.Errorf("GraphQL lists: got %+v", data.Lists)
+	}
+
+	var gqlErr *client.GraphQLError
+	if err := c.GraphQL(ctx, `{ nope }`, nil, nil); !errors.As(err, &gqlErr) {
+		t.Errorf("GraphQL with an unknown field: got %v, want *GraphQLError", err)
+	}
+}
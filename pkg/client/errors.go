@@ -0,0 +1,142 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// Ошибки для errors.Is по коду ответа API
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+
+	ErrTwoFactorRequired = errors.New("two-factor code required")
+	ErrOIDCLinkRequired  = errors.New("password is required to link the oidc identity")
+)
+
+// TwoFactorRequiredError возвращают SignIn, SignInOIDC и LinkOIDC, если у пользователя включена 2FA: вход завершает
+// SignIn2FA с ChallengeToken и кодом из приложения или кодом восстановления
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "todo api: " + ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorRequiredError) Is(target error) bool {
+	return target == ErrTwoFactorRequired
+}
+
+// OIDCLinkRequiredError возвращает SignInOIDC, если аккаунт провайдера совпал с существующим пользователем
+// Username: вход завершает LinkOIDC с LinkToken и паролем этого пользователя
+type OIDCLinkRequiredError struct {
+	LinkToken string
+	Username  string
+}
+
+func (e *OIDCLinkRequiredError) Error() string {
+	return "todo api: " + ErrOIDCLinkRequired.Error()
+}
+
+func (e *OIDCLinkRequiredError) Is(target error) bool {
+	return target == ErrOIDCLinkRequired
+}
+
+// Error - ответ API с кодом ошибки. Message берется из errorResponse (поле Message),
+// а для ответов application/problem+json (паника на сервере) - из detail или title.
+type Error struct {
+	StatusCode int
+	Message    string
+	RequestId  string // X-Request-ID ответа, по нему запрос ищется в логах сервера
+}
+
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	if e.RequestId != "" {
+		return fmt.Sprintf("todo api: %d %s (request id %s)", e.StatusCode, message, e.RequestId)
+	}
+	return fmt.Sprintf("todo api: %d %s", e.StatusCode, message)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// ValidationError - ответ 422 с ошибками по каждому полю (validationErrorResponse)
+type ValidationError struct {
+	Err    *Error
+	Fields []models.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		fields = append(fields, field.Field+": "+field.Message)
+	}
+	return e.Err.Error() + ": " + strings.Join(fields, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := Error{StatusCode: resp.StatusCode, RequestId: resp.Header.Get("X-Request-ID")}
+
+	var body struct {
+		Message string
+		Errors  []models.FieldError `json:"errors"`
+		Title   string              `json:"title"`
+		Detail  string              `json:"detail"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return &apiErr
+	}
+
+	switch {
+	case body.Message != "":
+		apiErr.Message = body.Message
+	case body.Detail != "":
+		apiErr.Message = body.Detail
+	default:
+		apiErr.Message = body.Title
+	}
+
+	if resp.StatusCode == http.StatusUnprocessableEntity && len(body.Errors) > 0 {
+		return &ValidationError{Err: &apiErr, Fields: body.Errors}
+	}
+	return &apiErr
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const graphqlPath = "/graphql"
+
+// GraphQLError - ошибки из поля errors ответа /graphql. Сам ответ приходит с кодом 200.
+type GraphQLError struct {
+	Errors []GraphQLErrorItem
+}
+
+type GraphQLErrorItem struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, item := range e.Errors {
+		messages = append(messages, item.Message)
+	}
+	return "todo graphql: " + strings.Join(messages, "; ")
+}
+
+// GraphQL выполняет запрос к /graphql и декодирует поле data в out. Запрос может быть мутацией,
+// поэтому он не повторяется автоматически.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	input := map[string]interface{}{"query": query}
+	if variables != nil {
+		input["variables"] = variables
+	}
+
+	var resp struct {
+		Data   json.RawMessage    `json:"data"`
+		Errors []GraphQLErrorItem `json:"errors"`
+	}
+	if err := c.do(ctx, http.MethodPost, graphqlPath, jsonType, input, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return &GraphQLError{Errors: resp.Errors}
+	}
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, out)
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// StartImport ставит в очередь импорт выгрузки другого сервиса: source - trello или todoist,
+// contentType - application/json или text/csv (шаблон проекта Todoist), name - название списка для CSV.
+// Если какая-то запись не прошла проверку, возвращается *ValidationError и задание не создается.
+func (c *Client) StartImport(ctx context.Context, source, contentType string, data []byte, name string) (models.ImportJob, error) {
+	query := url.Values{"source": {source}}
+	if name != "" {
+		query.Set("name", name)
+	}
+	var job models.ImportJob
+	err := c.do(ctx, http.MethodPost, importJobsPath+"?"+query.Encode(), contentType, data, &job)
+	return job, err
+}
+
+func (c *Client) GetImportJobs(ctx context.Context) ([]models.ImportJob, error) {
+	var resp struct {
+		Data []models.ImportJob `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, importJobsPath, "", nil, &resp)
+	return resp.Data, err
+}
+
+// GetImportJob возвращает состояние и прогресс импорта
+func (c *Client) GetImportJob(ctx context.Context, id int) (models.ImportJob, error) {
+	var job models.ImportJob
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf(importJobPath, id), "", nil, &job)
+	return job, err
+}
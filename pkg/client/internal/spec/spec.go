@@ -0,0 +1,47 @@
+// Package spec сверяет client.Routes со спецификацией swagger: каждый вызываемый клиентом эндпоинт должен быть
+// описан в ней, а каждый эндпоинт спецификации - покрыт клиентом. Используется speccheck и тестами клиента.
+package spec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+// Check возвращает расхождения между routes и спецификацией swagger.yaml в raw, пустой список - расхождений нет
+func Check(raw []byte, routes []client.Route) ([]string, error) {
+	var spec struct {
+		Paths map[string]map[string]interface{} `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	}
+
+	specRoutes := make(map[client.Route]bool)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			specRoutes[client.Route{Method: strings.ToUpper(method), Path: path}] = true
+		}
+	}
+
+	var problems []string
+	for _, route := range routes {
+		if !specRoutes[route] {
+			problems = append(problems, fmt.Sprintf("client route %s %s is not described in the spec", route.Method, route.Path))
+		}
+		delete(specRoutes, route)
+	}
+
+	missing := make([]string, 0, len(specRoutes))
+	for route := range specRoutes {
+		missing = append(missing, route.Method+" "+route.Path)
+	}
+	sort.Strings(missing)
+	for _, route := range missing {
+		problems = append(problems, fmt.Sprintf("spec route %s is not covered by the client", route))
+	}
+	return problems, nil
+}
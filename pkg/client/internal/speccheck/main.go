@@ -0,0 +1,36 @@
+// speccheck сверяет client.Routes со спецификацией swagger, см. пакет spec.
+// Запускается из pkg/client через go generate; та же проверка есть в тестах клиента.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ponomare0v/todo-go-app/pkg/client"
+	"github.com/ponomare0v/todo-go-app/pkg/client/internal/spec"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: speccheck path/to/swagger.yaml")
+		os.Exit(2)
+	}
+
+	raw, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	problems, err := spec.Check(raw, client.Routes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func (c *Client) CreateItem(ctx context.Context, listId int, item models.TodoItem) (int, error) {
+	var resp idResponse
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf(listItemsPath, listId), jsonType, item, &resp)
+	return resp.Id, err
+}
+
+func (c *Client) GetItems(ctx context.Context, listId int) ([]models.TodoItem, error) {
+	var items []models.TodoItem
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf(listItemsPath, listId), "", nil, &items)
+	return items, err
+}
+
+func (c *Client) GetItem(ctx context.Context, itemId int) (models.TodoItem, error) {
+	var item models.TodoItem
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf(itemPath, itemId), "", nil, &item)
+	return item, err
+}
+
+func (c *Client) UpdateItem(ctx context.Context, itemId int, input models.UpdateItemInput) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf(itemPath, itemId), jsonType, input, &statusResponse{})
+}
+
+// PatchItem отправляет patch как есть: patch.Type - models.MergePatchType или models.JSONPatchType
+func (c *Client) PatchItem(ctx context.Context, itemId int, patch models.Patch) error {
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf(itemPath, itemId), patch.Type, patch.Body, &statusResponse{})
+}
+
+func (c *Client) DeleteItem(ctx context.Context, itemId int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf(itemPath, itemId), "", nil, &statusResponse{})
+}
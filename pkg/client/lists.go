@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const jsonType = "application/json"
+
+func (c *Client) CreateList(ctx context.Context, list models.TodoList) (int, error) {
+	var resp idResponse
+	err := c.do(ctx, http.MethodPost, listsPath, jsonType, list, &resp)
+	return resp.Id, err
+}
+
+func (c *Client) GetLists(ctx context.Context) ([]models.TodoList, error) {
+	var resp struct {
+		Data []models.TodoList `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, listsPath, "", nil, &resp)
+	return resp.Data, err
+}
+
+func (c *Client) GetList(ctx context.Context, listId int) (models.TodoList, error) {
+	var list models.TodoList
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf(listPath, listId), "", nil, &list)
+	return list, err
+}
+
+func (c *Client) UpdateList(ctx context.Context, listId int, input models.UpdateListInput) error {
+	return c.do(ctx, http.MethodPut, fmt.Sprintf(listPath, listId), jsonType, input, &statusResponse{})
+}
+
+// PatchList отправляет patch как есть: patch.Type - models.MergePatchType или models.JSONPatchType
+func (c *Client) PatchList(ctx context.Context, listId int, patch models.Patch) error {
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf(listPath, listId), patch.Type, patch.Body, &statusResponse{})
+}
+
+func (c *Client) DeleteList(ctx context.Context, listId int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf(listPath, listId), "", nil, &statusResponse{})
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Authorizer проводит пользователя через страницу входа провайдера: открывает authURL в браузере
+// и возвращает адрес, на который провайдер вернул браузер (<server>/auth/oidc/callback?code=...&state=...)
+type Authorizer func(ctx context.Context, authURL string) (callbackURL string, err error)
+
+// OIDCProviders возвращает имена провайдеров OpenID Connect, через которые можно войти
+func (c *Client) OIDCProviders(ctx context.Context) ([]string, error) {
+	var resp struct {
+		Providers []string `json:"providers"`
+	}
+	err := c.do(ctx, http.MethodGet, oidcProvidersPath, "", nil, &resp)
+	return resp.Providers, err
+}
+
+// SignInOIDC входит через провайдера OpenID Connect и запоминает полученный токен. Сервер должен
+// отвечать на callback JSON, то есть oidc.success_url в его конфиге не задан. Первый вход в существующий
+// аккаунт возвращает *OIDCLinkRequiredError: его нужно подтвердить паролем через LinkOIDC.
+// Если у пользователя включена 2FA, возвращает *TwoFactorRequiredError, как SignIn.
+func (c *Client) SignInOIDC(ctx context.Context, provider string, authorize Authorizer) (string, error) {
+	resp, err := c.sendNoRedirect(ctx, oidcLoginPath+"?provider="+url.QueryEscape(provider), nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusFound {
+		return "", decodeResponse(resp, nil)
+	}
+	resp.Body.Close()
+
+	// сессия входа живет в cookie до возврата с кодом, ее нужно отдать на callback
+	var session *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == oidcLoginCookie {
+			session = cookie
+		}
+	}
+	if session == nil {
+		return "", errors.New("todo api: oidc login did not set the session cookie")
+	}
+
+	callbackURL, err := authorize(ctx, resp.Header.Get("Location"))
+	if err != nil {
+		return "", err
+	}
+	callback, err := url.Parse(callbackURL)
+	if err != nil {
+		return "", fmt.Errorf("todo api: invalid oidc callback url: %w", err)
+	}
+
+	resp, err = c.sendNoRedirect(ctx, oidcCallbackPath+"?"+callback.RawQuery, session)
+	if err != nil {
+		return "", err
+	}
+	var callbackResp struct {
+		Token          string `json:"token"`
+		LinkToken      string `json:"link_token"`
+		Username       string `json:"username"`
+		ChallengeToken string `json:"challenge_token"`
+	}
+	if err := decodeResponse(resp, &callbackResp); err != nil {
+		return "", err
+	}
+	if callbackResp.LinkToken != "" {
+		return "", &OIDCLinkRequiredError{LinkToken: callbackResp.LinkToken, Username: callbackResp.Username}
+	}
+	if callbackResp.ChallengeToken != "" {
+		return "", &TwoFactorRequiredError{ChallengeToken: callbackResp.ChallengeToken}
+	}
+
+	c.setToken(callbackResp.Token)
+	return callbackResp.Token, nil
+}
+
+// LinkOIDC связывает аккаунт провайдера с существующим пользователем по паролю и запоминает токен.
+// Если у пользователя включена 2FA, возвращает *TwoFactorRequiredError, как SignIn.
+func (c *Client) LinkOIDC(ctx context.Context, linkToken, password string) (string, error) {
+	var resp signInResponse
+	input := map[string]string{"link_token": linkToken, "password": password}
+	if err := c.do(ctx, http.MethodPost, oidcLinkPath, jsonType, input, &resp); err != nil {
+		return "", err
+	}
+	if resp.ChallengeToken != "" {
+		return "", &TwoFactorRequiredError{ChallengeToken: resp.ChallengeToken}
+	}
+
+	c.setToken(resp.Token)
+	return resp.Token, nil
+}
+
+// sendNoRedirect отправляет GET без повторов и без перехода по редиректу: ответ 302 нужен вызывающему
+func (c *Client) sendNoRedirect(ctx context.Context, path string, cookie *http.Cookie) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if cookie != nil {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	httpClient := *c.httpClient
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return httpClient.Do(req)
+}
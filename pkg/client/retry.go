@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const maxRetryDelay = 5 * time.Second
+
+var defaultRetryPolicy = retryPolicy{maxRetries: 3, baseDelay: 200 * time.Millisecond}
+
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// next решает, повторять ли запрос после попытки attempt (с нуля), и возвращает задержку перед повтором.
+// Повторяются только идемпотентные методы: POST создал бы дубликат, а JSON Patch с add - применился бы дважды.
+func (p retryPolicy) next(method string, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.maxRetries || !idempotent(method) {
+		return 0, false
+	}
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if delay, ok := retryAfter(resp); ok {
+			return delay, true
+		}
+		return p.backoff(attempt), true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return p.backoff(attempt), true
+	}
+	return 0, false
+}
+
+// backoff - экспоненциальная задержка с полным джиттером, чтобы клиенты не повторяли запросы синхронно
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.baseDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay, true
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
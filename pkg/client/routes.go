@@ -0,0 +1,92 @@
+package client
+
+// Route - метод и путь эндпоинта в нотации swagger ({id} вместо :id)
+type Route struct {
+	Method string
+	Path   string
+}
+
+const (
+	signUpPath    = "/auth/sign-up"
+	signInPath    = "/auth/sign-in"
+	listsPath     = "/api/lists/"
+	listPath      = "/api/lists/%d"
+	listItemsPath = "/api/lists/%d/items/"
+	itemPath      = "/api/items/%d"
+	exportPath    = "/api/export"
+	importPath    = "/api/import"
+	listICSPath   = "/api/lists/%d/export.ics"
+	feedTokenPath = "/api/calendar/feed"
+	feedPath      = "/calendar/%s/todo.ics"
+
+	appPasswordsPath = "/api/app-passwords/"
+	appPasswordPath  = "/api/app-passwords/%d"
+	importJobsPath   = "/api/imports/"
+	importJobPath    = "/api/imports/%d"
+	accessTokensPath = "/api/me/tokens/"
+	accessTokenPath  = "/api/me/tokens/%d"
+
+	oidcProvidersPath = "/auth/oidc/providers"
+	oidcLoginPath     = "/auth/oidc/login"
+	oidcCallbackPath  = "/auth/oidc/callback"
+	oidcLinkPath      = "/auth/oidc/link"
+	oidcLoginCookie   = "oidc_login"
+
+	signIn2FAPath        = "/auth/sign-in/2fa"
+	twoFactorPath        = "/api/me/2fa"
+	twoFactorEnrollPath  = "/api/me/2fa/enroll"
+	twoFactorConfirmPath = "/api/me/2fa/confirm"
+
+	profilePath       = "/api/me"
+	passwordPath      = "/api/me/password"
+	accountExportPath = "/api/me/export"
+)
+
+// Routes - все эндпоинты, которые вызывает клиент. internal/speccheck проверяет, что они
+// совпадают с эндпоинтами спецификации в обе стороны.
+var Routes = []Route{
+	{"POST", "/auth/sign-up"},
+	{"POST", "/auth/sign-in"},
+	{"GET", "/auth/oidc/providers"},
+	{"GET", "/auth/oidc/login"},
+	{"GET", "/auth/oidc/callback"},
+	{"POST", "/auth/oidc/link"},
+	{"POST", "/auth/sign-in/2fa"},
+	{"POST", "/api/lists"},
+	{"GET", "/api/lists"},
+	{"GET", "/api/lists/{id}"},
+	{"PUT", "/api/lists/{id}"},
+	{"PATCH", "/api/lists/{id}"},
+	{"DELETE", "/api/lists/{id}"},
+	{"POST", "/api/lists/{id}/items"},
+	{"GET", "/api/lists/{id}/items"},
+	{"GET", "/api/items/{id}"},
+	{"PUT", "/api/items/{id}"},
+	{"PATCH", "/api/items/{id}"},
+	{"DELETE", "/api/items/{id}"},
+	{"GET", "/api/export"},
+	{"POST", "/api/import"},
+	{"GET", "/api/lists/{id}/export.ics"},
+	{"POST", "/api/calendar/feed"},
+	{"DELETE", "/api/calendar/feed"},
+	{"GET", "/calendar/{token}/todo.ics"},
+	{"POST", "/api/app-passwords"},
+	{"GET", "/api/app-passwords"},
+	{"DELETE", "/api/app-passwords/{id}"},
+	{"POST", "/api/imports"},
+	{"GET", "/api/imports"},
+	{"GET", "/api/imports/{id}"},
+	{"POST", "/api/me/tokens"},
+	{"GET", "/api/me/tokens"},
+	{"DELETE", "/api/me/tokens/{id}"},
+	{"GET", "/api/me/2fa"},
+	{"DELETE", "/api/me/2fa"},
+	{"POST", "/api/me/2fa/enroll"},
+	{"POST", "/api/me/2fa/confirm"},
+	{"GET", "/api/me"},
+	{"PUT", "/api/me"},
+	{"DELETE", "/api/me"},
+	{"PUT", "/api/me/password"},
+	{"GET", "/api/me/export"},
+	{"POST", "/graphql"},
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// refreshBefore - за сколько до истечения токен обновляется заранее, чтобы он не истек посреди запроса
+const refreshBefore = time.Minute
+
+func (c *Client) hasCredentials() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.username != ""
+}
+
+// validToken возвращает токен для запроса. С учетными данными пустой или почти истекший токен
+// сначала обновляется через /auth/sign-in; отдельного refresh-эндпоинта у API нет.
+func (c *Client) validToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, username := c.token, c.username
+	c.mu.Unlock()
+
+	if username == "" || (token != "" && !expiresSoon(token)) {
+		return token, nil
+	}
+	if err := c.signInAgain(ctx, token); err != nil {
+		return "", err
+	}
+	return c.Token(), nil
+}
+
+// signInAgain входит заново, если токен все еще stale: параллельные запросы не должны логиниться по несколько раз
+func (c *Client) signInAgain(ctx context.Context, stale string) error {
+	c.mu.Lock()
+	current, username, password := c.token, c.username, c.password
+	c.mu.Unlock()
+
+	if current != stale && current != "" && !expiresSoon(current) {
+		return nil
+	}
+	_, err := c.SignIn(ctx, username, password)
+	return err
+}
+
+// expiresSoon читает exp из payload JWT без проверки подписи - ее проверяет сервер.
+// Токен, который не удалось разобрать, считается рабочим: если он плохой, сервер ответит 401.
+func expiresSoon(token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	var claims struct {
+		ExpiresAt int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt == 0 {
+		return false
+	}
+	return time.Until(time.Unix(claims.ExpiresAt, 0)) < refreshBefore
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type twoFactorCodeInput struct {
+	Code string `json:"code"`
+}
+
+// GetTwoFactor возвращает, включена ли 2FA и сколько осталось кодов восстановления
+func (c *Client) GetTwoFactor(ctx context.Context) (models.TwoFactorStatus, error) {
+	var status models.TwoFactorStatus
+	err := c.do(ctx, http.MethodGet, twoFactorPath, "", nil, &status)
+	return status, err
+}
+
+// EnrollTwoFactor начинает подключение 2FA: возвращает секрет и otpauth URI для приложения-аутентификатора
+func (c *Client) EnrollTwoFactor(ctx context.Context) (models.TwoFactorEnrollment, error) {
+	var enrollment models.TwoFactorEnrollment
+	err := c.do(ctx, http.MethodPost, twoFactorEnrollPath, "", nil, &enrollment)
+	return enrollment, err
+}
+
+// ConfirmTwoFactor включает 2FA первым кодом из приложения и возвращает одноразовые коды восстановления
+func (c *Client) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	var resp struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	err := c.do(ctx, http.MethodPost, twoFactorConfirmPath, jsonType, twoFactorCodeInput{Code: code}, &resp)
+	return resp.RecoveryCodes, err
+}
+
+// DisableTwoFactor выключает 2FA; code - код из приложения или код восстановления
+func (c *Client) DisableTwoFactor(ctx context.Context, code string) error {
+	return c.do(ctx, http.MethodDelete, twoFactorPath, jsonType, twoFactorCodeInput{Code: code}, &statusResponse{})
+}
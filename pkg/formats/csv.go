@@ -0,0 +1,162 @@
+package formats
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// одна строка CSV - одна задача, колонки списка повторяются в каждой строке.
+// Список без задач - строка с пустыми колонками item_*.
+// Срок item_due_date пишется в RFC 3339, при импорте можно указать и просто дату (2006-01-02, полночь UTC).
+var csvHeader = []string{"list", "list_title", "list_description", "item_title", "item_description", "item_done", "item_due_date"}
+
+// csvEncoder нумерует списки в колонке list: по ней импорт отличает разные списки с одинаковым названием
+type csvEncoder struct {
+	w     *csv.Writer
+	count int
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(list models.ListBackup) error {
+	if e.count == 0 {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	key := strconv.Itoa(e.count)
+	if len(list.Items) == 0 {
+		if err := e.w.Write([]string{key, list.Title, list.Description, "", "", "", ""}); err != nil {
+			return err
+		}
+	}
+	for _, item := range list.Items {
+		due := ""
+		if item.DueDate != nil {
+			due = item.DueDate.Format(time.RFC3339)
+		}
+		if err := e.w.Write([]string{key, list.Title, list.Description, item.Title, item.Description, strconv.FormatBool(item.Done), due}); err != nil {
+			return err
+		}
+	}
+	// сбрасываем буфер после каждого списка, чтобы экспорт уходил клиенту по мере выборки
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	if e.count == 0 {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// decodeCSV находит колонки по заголовку, поэтому их порядок не важен, а лишние колонки пропускаются.
+// Строки с одинаковым значением list попадают в один список; если колонки list нет или она пустая,
+// список определяется по паре list_title, list_description.
+func decodeCSV(r io.Reader) (Decoded, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return Decoded{}, errors.New("csv: missing header")
+	}
+	if err != nil {
+		return Decoded{}, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") // BOM, который добавляет Excel
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"list_title", "item_title"} {
+		if _, ok := columns[required]; !ok {
+			return Decoded{}, fmt.Errorf("csv: missing column %q", required)
+		}
+	}
+
+	var decoded Decoded
+	listIndex := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Decoded{}, err
+		}
+		row, _ := reader.FieldPos(0)
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+
+		key := value("list")
+		if key == "" {
+			key = "\x00" + value("list_title") + "\x00" + value("list_description")
+		}
+		i, ok := listIndex[key]
+		if !ok {
+			i = len(decoded.Lists)
+			listIndex[key] = i
+			decoded.Lists = append(decoded.Lists, models.ListBackup{
+				Title:       value("list_title"),
+				Description: value("list_description"),
+				Row:         row,
+			})
+		}
+
+		item := models.ItemBackup{Title: value("item_title"), Description: value("item_description"), Row: row}
+		done := strings.TrimSpace(value("item_done"))
+		due := strings.TrimSpace(value("item_due_date"))
+		if item.Title == "" && item.Description == "" && done == "" && due == "" {
+			continue // строка только с колонками списка
+		}
+		if done != "" {
+			if item.Done, err = strconv.ParseBool(done); err != nil {
+				decoded.Errors = append(decoded.Errors, models.ImportError{Row: row, FieldError: models.FieldError{
+					Field: "item_done", Code: models.CodeInvalid, Message: "must be true or false",
+				}})
+			}
+		}
+		if due != "" {
+			if item.DueDate, err = parseDueDate(due); err != nil {
+				decoded.Errors = append(decoded.Errors, models.ImportError{Row: row, FieldError: models.FieldError{
+					Field: "item_due_date", Code: models.CodeInvalid, Message: "must be a date (2006-01-02) or an RFC 3339 timestamp",
+				}})
+			}
+		}
+		decoded.Lists[i].Items = append(decoded.Lists[i].Items, item)
+	}
+	return decoded, nil
+}
+
+func parseDueDate(value string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errors.New("invalid due date")
+}
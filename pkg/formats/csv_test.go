@@ -0,0 +1,103 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	encoded := encode(t, CSV, sampleLists())
+	decoded, err := Decode(CSV, strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("Decode: %v\n%s", err, encoded)
+	}
+	assertLists(t, decoded.Lists, sampleLists())
+	assertErrors(t, decoded.Errors, nil)
+
+	if got := encode(t, CSV, nil); got != strings.Join(csvHeader, ",")+"\n" {
+		t.Errorf("empty export: got %q, want only the header", got)
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		input  string
+		lists  []models.ListBackup
+		errors []models.ImportError
+	}{
+		{
+			name:  "BOM and reordered columns",
+			input: "\ufeffitem_title,List_Title,extra\nmilk,groceries,ignored\nbread,groceries,\n",
+			lists: []models.ListBackup{{Title: "groceries", Items: []models.ItemBackup{{Title: "milk"}, {Title: "bread"}}}},
+		},
+		{
+			name:  "lists without the list column are told apart by title and description",
+			input: "list_title,list_description,item_title\na,,x\nb,,y\na,other,z\na,,w\n",
+			lists: []models.ListBackup{
+				{Title: "a", Items: []models.ItemBackup{{Title: "x"}, {Title: "w"}}},
+				{Title: "b", Items: []models.ItemBackup{{Title: "y"}}},
+				{Title: "a", Description: "other", Items: []models.ItemBackup{{Title: "z"}}},
+			},
+		},
+		{
+			name:  "date-only due date and short rows",
+			input: "list_title,item_title,item_done,item_due_date\nwork,report,TRUE,2024-05-01\nwork\n",
+			lists: []models.ListBackup{{Title: "work", Items: []models.ItemBackup{{Title: "report", Done: true, DueDate: &day}}}},
+		},
+		{
+			name:   "bad values are reported with their row and the item is kept",
+			input:  "list_title,item_title,item_done,item_due_date\nwork,a,yes,\nwork,b,,tomorrow\nwork,c,false,2024-05-01\n",
+			lists:  []models.ListBackup{{Title: "work", Items: []models.ItemBackup{{Title: "a"}, {Title: "b"}, {Title: "c", DueDate: &day}}}},
+			errors: []models.ImportError{importError(2, "item_done"), importError(3, "item_due_date")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(CSV, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			assertLists(t, decoded.Lists, tt.lists)
+			assertErrors(t, decoded.Errors, tt.errors)
+		})
+	}
+}
+
+func TestDecodeCSVRows(t *testing.T) {
+	// строка с переводом строки в кавычках занимает две строки файла: номер строки - начало записи
+	decoded, err := Decode(CSV, strings.NewReader("list_title,item_title,item_done\nwork,\"multi\nline\",\nwork,bad,maybe\n"))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(decoded.Lists) != 1 || decoded.Lists[0].Row != 2 || len(decoded.Lists[0].Items) != 2 || decoded.Lists[0].Items[1].Row != 4 {
+		t.Errorf("rows: got %+v", decoded.Lists)
+	}
+	assertErrors(t, decoded.Errors, []models.ImportError{importError(4, "item_done")})
+}
+
+func TestDecodeCSVMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", "missing header"},
+		{"missing list_title", "title,item_title\ngroceries,milk\n", `missing column "list_title"`},
+		{"missing item_title", "list_title\ngroceries\n", `missing column "item_title"`},
+		{"bare quote", "list_title,item_title\ngroceries,\"milk\n", "extraneous or missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(CSV, strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Decode: got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package formats - форматы файлов экспорта и импорта списков с задачами.
+// Экспорт пишется по одному списку, чтобы не собирать в памяти все данные пользователя;
+// импорт читается целиком, потому что создается одной транзакцией.
+package formats
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const (
+	JSON     = "json"
+	CSV      = "csv"
+	TodoTxt  = "todotxt"
+	Markdown = "markdown"
+)
+
+// ErrUnsupportedFormat - формат не поддерживается
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Encoder пишет списки по одному; Close дописывает конец документа
+type Encoder interface {
+	Encode(list models.ListBackup) error
+	Close() error
+}
+
+// Decoded - результат разбора файла импорта. Errors - записи, которые не удалось разобрать
+// (например, неверное значение item_done в CSV); остальные записи при этом все равно в Lists.
+type Decoded struct {
+	Lists  []models.ListBackup
+	Errors []models.ImportError
+}
+
+// ContentType - MIME-тип документа в формате format
+func ContentType(format string) string {
+	switch format {
+	case JSON:
+		return "application/json"
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case TodoTxt:
+		return "text/plain; charset=utf-8"
+	case Markdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Extension - расширение имени файла экспорта
+func Extension(format string) string {
+	switch format {
+	case TodoTxt:
+		return "txt"
+	case Markdown:
+		return "md"
+	default:
+		return format
+	}
+}
+
+// FromContentType подбирает формат по MIME-типу тела запроса, "" - тип не распознан
+func FromContentType(mediaType string) string {
+	switch mediaType {
+	case "application/json":
+		return JSON
+	case "text/csv", "application/csv":
+		return CSV
+	case "text/plain":
+		return TodoTxt
+	case "text/markdown", "text/x-markdown":
+		return Markdown
+	default:
+		return ""
+	}
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case JSON:
+		return newJSONEncoder(w), nil
+	case CSV:
+		return newCSVEncoder(w), nil
+	case TodoTxt:
+		return newTodoTxtEncoder(w), nil
+	case Markdown:
+		return newMarkdownEncoder(w), nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Decode разбирает документ. Ошибка возвращается, только если документ не удалось прочитать вообще
+// (битый JSON, CSV без обязательных колонок, слишком длинная строка в текстовых форматах).
+func Decode(format string, r io.Reader) (Decoded, error) {
+	switch format {
+	case JSON:
+		return decodeJSON(r)
+	case CSV:
+		return decodeCSV(r)
+	case TodoTxt:
+		return decodeTodoTxt(r)
+	case Markdown:
+		return decodeMarkdown(r)
+	default:
+		return Decoded{}, ErrUnsupportedFormat
+	}
+}
@@ -0,0 +1,97 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// sampleLists - списки, которые каждый формат должен пережить без потерь: пустой список, задача без описания,
+// выполненная задача со сроком и два списка с одинаковым названием
+func sampleLists() []models.ListBackup {
+	due := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	return []models.ListBackup{
+		{Title: "groceries", Description: "weekly", Items: []models.ItemBackup{
+			{Title: "milk", Description: "2 bottles"},
+			{Title: "bread", Done: true, DueDate: &due},
+		}},
+		{Title: "empty"},
+		{Title: "groceries", Items: []models.ItemBackup{{Title: "eggs"}}},
+	}
+}
+
+func encode(t *testing.T, format string, lists []models.ListBackup) string {
+	t.Helper()
+	var buf bytes.Buffer
+	encoder, err := NewEncoder(format, &buf)
+	if err != nil {
+		t.Fatalf("NewEncoder(%s): %v", format, err)
+	}
+	for _, list := range lists {
+		if err := encoder.Encode(list); err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.String()
+}
+
+// withoutRows убирает номера строк и приводит пустые списки задач к nil, чтобы сравнивать только данные
+func withoutRows(lists []models.ListBackup) []models.ListBackup {
+	result := make([]models.ListBackup, 0, len(lists))
+	for _, list := range lists {
+		list.Row = 0
+		var items []models.ItemBackup
+		for _, item := range list.Items {
+			item.Row = 0
+			items = append(items, item)
+		}
+		list.Items = items
+		result = append(result, list)
+	}
+	return result
+}
+
+func assertLists(t *testing.T, got, want []models.ListBackup) {
+	t.Helper()
+	if got, want := withoutRows(got), withoutRows(want); !reflect.DeepEqual(got, want) {
+		t.Errorf("lists:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func assertErrors(t *testing.T, got, want []models.ImportError) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("errors: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Row != want[i].Row || got[i].Field != want[i].Field || got[i].Code != want[i].Code {
+			t.Errorf("error %d: got %v (row %d, %s), want row %d, field %s, code %s",
+				i, got[i], got[i].Row, got[i].Code, want[i].Row, want[i].Field, want[i].Code)
+		}
+	}
+}
+
+func importError(row int, field string) models.ImportError {
+	return models.ImportError{Row: row, FieldError: models.FieldError{Field: field, Code: models.CodeInvalid}}
+}
+
+// assertValid проверяет, что импорт из чужого формата проходит валидацию модели, в том числе после обрезки длинных строк
+func assertValid(t *testing.T, lists []models.ListBackup) {
+	t.Helper()
+	for i, list := range lists {
+		if err := list.TodoList().Validate(); err != nil {
+			t.Errorf("list %d: %v", i, err)
+		}
+		for j, item := range list.Items {
+			if err := item.TodoItem().Validate(); err != nil {
+				t.Errorf("list %d, item %d: %v", i, j, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package formats
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const ICalContentType = "text/calendar; charset=utf-8"
+
+// ErrInvalidICal - тело не разбирается как календарь с задачей
+var ErrInvalidICal = errors.New("invalid iCalendar data")
+
+const (
+	icalTimeLayout      = "20060102T150405Z"
+	icalLocalTimeLayout = "20060102T150405"
+	icalDateLayout      = "20060102"
+	icalLineLimit       = 75 // октетов без CRLF, RFC 5545, раздел 3.1
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "")
+
+// ICalEncoder пишет календарь iCalendar (RFC 5545), в котором каждая задача - компонент VTODO.
+// UID задачи строится из ее id, поэтому календарные клиенты обновляют задачу, а не дублируют ее.
+type ICalEncoder struct {
+	w       io.Writer
+	name    string
+	stamp   time.Time
+	started bool
+}
+
+// NewICalEncoder - name попадает в X-WR-CALNAME (название календаря в клиенте),
+// stamp - время выгрузки для обязательного свойства DTSTAMP
+func NewICalEncoder(w io.Writer, name string, stamp time.Time) *ICalEncoder {
+	return &ICalEncoder{w: w, name: name, stamp: stamp.UTC()}
+}
+
+// Encode пишет задачи списка; название списка становится категорией задач
+func (e *ICalEncoder) Encode(list models.TodoList, items []models.TodoItem) error {
+	for _, item := range items {
+		if err := e.EncodeTodo(list, item, models.ItemObject(item).UID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeTodo пишет одну задачу с заданным UID - для ресурсов CalDAV, которые создал клиент
+func (e *ICalEncoder) EncodeTodo(list models.TodoList, item models.TodoItem, uid string) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + icalText(uid),
+		"DTSTAMP:" + e.stamp.Format(icalTimeLayout),
+		"SUMMARY:" + icalText(item.Title),
+	}
+	if item.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icalText(item.Description))
+	}
+	if list.Title != "" {
+		lines = append(lines, "CATEGORIES:"+icalText(list.Title))
+	}
+	if item.DueDate != nil {
+		lines = append(lines, "DUE:"+item.DueDate.UTC().Format(icalTimeLayout))
+	}
+	if item.Done {
+		lines = append(lines, "STATUS:COMPLETED", "PERCENT-COMPLETE:100")
+	} else {
+		lines = append(lines, "STATUS:NEEDS-ACTION")
+	}
+	lines = append(lines, "END:VTODO")
+
+	return e.write(lines...)
+}
+
+func (e *ICalEncoder) Close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	return e.write("END:VCALENDAR")
+}
+
+func (e *ICalEncoder) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	return e.write(
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//todo-go-app//Todo Go App//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:"+icalText(e.name),
+	)
+}
+
+func (e *ICalEncoder) write(lines ...string) error {
+	var b strings.Builder
+	for _, line := range lines {
+		foldICalLine(&b, line)
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+// icalText экранирует значение типа TEXT (RFC 5545, раздел 3.3.11)
+func icalText(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// foldICalLine переносит строки длиннее 75 октетов: продолжение начинается с пробела.
+// Многобайтовые символы UTF-8 не разрываются.
+func foldICalLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalLineLimit - 1 // пробел в начале продолжения тоже считается
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// DecodeICalTodo читает первый компонент VTODO календаря - тело PUT-запроса CalDAV.
+// Берутся только свойства, которые есть у задачи; PRIORITY, RRULE, вложенные VALARM и прочее отбрасываются.
+func DecodeICalTodo(r io.Reader) (models.CalendarObject, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return models.CalendarObject{}, err
+	}
+
+	var (
+		object                 models.CalendarObject
+		depth, todoDepth       int
+		found                  bool
+		hasStatus, hasComplete bool
+	)
+	for n, line := range unfoldICal(string(data)) {
+		if line == "" {
+			continue
+		}
+		name, params, value, ok := parseICalLine(line)
+		if !ok {
+			return models.CalendarObject{}, fmt.Errorf("%w: line %d: missing ':'", ErrInvalidICal, n+1)
+		}
+
+		switch {
+		case name == "BEGIN":
+			depth++
+			if !found && todoDepth == 0 && depth == 2 && strings.EqualFold(value, "VTODO") {
+				todoDepth = depth
+			}
+			continue
+		case name == "END":
+			if todoDepth != 0 && depth == todoDepth {
+				todoDepth, found = 0, true
+			}
+			depth--
+			continue
+		case todoDepth == 0 || depth != todoDepth:
+			continue
+		}
+
+		switch name {
+		case "UID":
+			object.UID = icalUnescape(value)
+		case "SUMMARY":
+			object.Item.Title = icalUnescape(value)
+		case "DESCRIPTION":
+			object.Item.Description = icalUnescape(value)
+		case "STATUS":
+			hasStatus = true
+			object.Item.Done = strings.EqualFold(value, "COMPLETED")
+		case "COMPLETED":
+			hasComplete = true
+		case "DUE":
+			due, err := parseICalTime(value, params)
+			if err != nil {
+				return models.CalendarObject{}, fmt.Errorf("%w: DUE: %s", ErrInvalidICal, err.Error())
+			}
+			object.Item.DueDate = &due
+		}
+	}
+
+	if !found {
+		return models.CalendarObject{}, fmt.Errorf("%w: no VTODO component", ErrInvalidICal)
+	}
+	if !hasStatus && hasComplete {
+		object.Item.Done = true
+	}
+	return object, nil
+}
+
+// unfoldICal склеивает перенесенные строки: продолжение начинается с пробела или табуляции
+func unfoldICal(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICalLine разбирает строку NAME;PARAM=value:VALUE; двоеточие внутри кавычек в параметрах не считается
+func parseICalLine(line string) (name string, params map[string]string, value string, ok bool) {
+	quoted := false
+	colon := -1
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params = make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		key, val, _ := strings.Cut(param, "=")
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseICalTime понимает дату (VALUE=DATE), время в UTC и локальное время с TZID.
+// Описания VTIMEZONE не разбираются: неизвестный TZID и "плавающее" время без зоны считаются UTC.
+func parseICalTime(value string, params map[string]string) (time.Time, error) {
+	switch {
+	case params["VALUE"] == "DATE" || len(value) == len(icalDateLayout):
+		return time.Parse(icalDateLayout, value)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(icalTimeLayout, value)
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+	return time.ParseInLocation(icalLocalTimeLayout, value, loc)
+}
+
+// icalUnescape - обратное к icalText преобразование
+func icalUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if s[i] == 'n' || s[i] == 'N' {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package formats
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func TestFoldICalLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{"short", "SUMMARY:milk", 1},
+		{"exactly the limit", "SUMMARY:" + strings.Repeat("a", icalLineLimit-len("SUMMARY:")), 1},
+		{"one octet over", "SUMMARY:" + strings.Repeat("a", icalLineLimit-len("SUMMARY:")+1), 2},
+		// "ж" - два октета: 75-й октет приходится на середину символа, и перенос сдвигается на октет раньше
+		{"two-byte rune across the limit", "SUMMARY:" + strings.Repeat("a", 66) + strings.Repeat("ж", 10), 2},
+		// "€" - три октета
+		{"three-byte runes", "DESCRIPTION:" + strings.Repeat("€", 100), 5},
+		{"four-byte runes", "SUMMARY:" + strings.Repeat("😀", 60), 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			foldICalLine(&b, tt.line)
+			folded := b.String()
+
+			if !strings.HasSuffix(folded, "\r\n") {
+				t.Fatalf("folded line does not end with CRLF: %q", folded)
+			}
+			physical := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			if len(physical) != tt.lines {
+				t.Errorf("got %d lines, want %d: %q", len(physical), tt.lines, physical)
+			}
+			for i, line := range physical {
+				if len(line) > icalLineLimit {
+					t.Errorf("line %d is %d octets long, limit %d", i, len(line), icalLineLimit)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a rune: %q", i, line)
+				}
+			}
+			if got := unfoldICal(folded); len(got) != 2 || got[0] != tt.line || got[1] != "" {
+				t.Errorf("unfold: got %q, want %q", got, tt.line)
+			}
+		})
+	}
+
+	// 66 + 8 октетов заголовка = 74, следующий "ж" не помещается целиком
+	var b strings.Builder
+	foldICalLine(&b, "SUMMARY:"+strings.Repeat("a", 66)+"жж")
+	if first, _, _ := strings.Cut(b.String(), "\r\n"); len(first) != 74 {
+		t.Errorf("first line: got %d octets, want 74", len(first))
+	}
+}
+
+func TestUnfoldICal(t *testing.T) {
+	got := unfoldICal("SUMMARY:Buy\r\n  milk\r\n\tand bread\nDESCRIPTION:x\r\n")
+	want := []string{"SUMMARY:Buy milkand bread", "DESCRIPTION:x", ""}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("unfoldICal: got %q, want %q", got, want)
+	}
+}
+
+func TestICalTextRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"plain",
+		`a,b;c\d`,
+		"first line\nsecond line",
+		`trailing backslash\`,
+		"windows\r\nnewline",
+		strings.Repeat("длинное описание, с запятыми; ", 10),
+	} {
+		want := strings.ReplaceAll(s, "\r\n", "\n")
+		if got := icalUnescape(icalText(s)); got != want {
+			t.Errorf("icalUnescape(icalText(%q)) = %q", s, got)
+		}
+	}
+	if got := icalUnescape(`a\Nb\,c\\n`); got != "a\nb,c\\n" {
+		t.Errorf("icalUnescape: got %q", got)
+	}
+}
+
+// TestICalEncodeDecode - задача, выгруженная ICalEncoder, читается DecodeICalTodo без потерь
+func TestICalEncodeDecode(t *testing.T) {
+	due := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	item := models.TodoItem{
+		Id:          7,
+		Title:       "Купить молоко, хлеб; яйца " + strings.Repeat("и еще что-нибудь ", 5),
+		Description: "line one\nline two \\ with backslash",
+		Done:        true,
+		DueDate:     &due,
+	}
+
+	var b strings.Builder
+	encoder := NewICalEncoder(&b, "groceries", time.Now())
+	if err := encoder.EncodeTodo(models.TodoList{Title: "groceries, weekly"}, item, "uid-7@example.com"); err != nil {
+		t.Fatalf("EncodeTodo: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	for _, line := range strings.Split(b.String(), "\r\n") {
+		if len(line) > icalLineLimit {
+			t.Errorf("line longer than %d octets: %q", icalLineLimit, line)
+		}
+	}
+
+	object, err := DecodeICalTodo(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("DecodeICalTodo: %v\n%s", err, b.String())
+	}
+	got := object.Item
+	if object.UID != "uid-7@example.com" || got.Title != item.Title || got.Description != item.Description ||
+		got.Done != item.Done || got.DueDate == nil || !got.DueDate.Equal(due) {
+		t.Errorf("DecodeICalTodo: got %q %+v, want %+v", object.UID, got, item)
+	}
+}
+
+func TestDecodeICalTodo(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	vtodo := func(lines ...string) string {
+		return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:1\r\n" + strings.Join(lines, "\r\n") + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		title string
+		done  bool
+		due   time.Time
+	}{
+		{"due date", vtodo("SUMMARY:a", "DUE;VALUE=DATE:20240501"), "a", false, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"due date without VALUE", vtodo("SUMMARY:a", "DUE:20240501"), "a", false, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{"due in UTC", vtodo("SUMMARY:a", "DUE:20240501T103000Z"), "a", false, time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"due with TZID", vtodo("SUMMARY:a", "DUE;TZID=Europe/Berlin:20240501T120000"), "a", false, time.Date(2024, 5, 1, 12, 0, 0, 0, berlin)},
+		{"due with quoted TZID", vtodo("SUMMARY:a", `DUE;TZID="Europe/Berlin":20240101T120000`), "a", false, time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{"unknown TZID is UTC", vtodo("SUMMARY:a", "DUE;TZID=Mars/Olympus:20240501T120000"), "a", false, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"floating time is UTC", vtodo("SUMMARY:a", "DUE:20240501T120000"), "a", false, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{"folded summary", vtodo("SUMMARY:Buy", "  milk"), "Buy milk", false, time.Time{}},
+		{"lowercase names", vtodo("summary:a", "status:completed"), "a", true, time.Time{}},
+		{"COMPLETED without STATUS", vtodo("SUMMARY:a", "COMPLETED:20240501T103000Z"), "a", true, time.Time{}},
+		{"STATUS wins over COMPLETED", vtodo("SUMMARY:a", "STATUS:NEEDS-ACTION", "COMPLETED:20240501T103000Z"), "a", false, time.Time{}},
+		{"nested VALARM is skipped", vtodo("SUMMARY:a", "BEGIN:VALARM", "SUMMARY:alarm", "END:VALARM"), "a", false, time.Time{}},
+		{"only the first VTODO", vtodo("SUMMARY:a") + "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:b\r\nEND:VTODO\r\nEND:VCALENDAR\r\n", "a", false, time.Time{}},
+		{"VTODO after a VEVENT", "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:event\nEND:VEVENT\nBEGIN:VTODO\nSUMMARY:todo\nEND:VTODO\nEND:VCALENDAR\n", "todo", false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := DecodeICalTodo(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("DecodeICalTodo: %v", err)
+			}
+			item := object.Item
+			if item.Title != tt.title || item.Done != tt.done {
+				t.Errorf("got title %q, done %t; want %q, %t", item.Title, item.Done, tt.title, tt.done)
+			}
+			switch {
+			case tt.due.IsZero() && item.DueDate != nil:
+				t.Errorf("due: got %v, want none", item.DueDate)
+			case !tt.due.IsZero() && (item.DueDate == nil || !item.DueDate.Equal(tt.due)):
+				t.Errorf("due: got %v, want %v", item.DueDate, tt.due)
+			}
+		})
+	}
+}
+
+func TestDecodeICalTodoMalformed(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":         "",
+		"no VTODO":      "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:a\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+		"top-level":     "BEGIN:VTODO\r\nSUMMARY:a\r\nEND:VTODO\r\n",
+		"missing colon": "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY a\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+		"bad DUE":       "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nDUE:tomorrow\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+		"unterminated":  "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:a\r\n",
+	} {
+		if _, err := DecodeICalTodo(strings.NewReader(input)); !errors.Is(err, ErrInvalidICal) {
+			t.Errorf("%s: got %v, want ErrInvalidICal", name, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package formats
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// jsonEncoder пишет документ models.Backup - {"lists":[...]} - не собирая его в памяти
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONEncoder(w io.Writer) *jsonEncoder {
+	return &jsonEncoder{w: w}
+}
+
+func (e *jsonEncoder) Encode(list models.ListBackup) error {
+	prefix := ","
+	if e.count == 0 {
+		prefix = `{"lists":[`
+	}
+	if list.Items == nil {
+		list.Items = []models.ItemBackup{}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	e.count++
+
+	_, err = io.WriteString(e.w, prefix+string(data))
+	return err
+}
+
+func (e *jsonEncoder) Close() error {
+	if e.count == 0 {
+		_, err := io.WriteString(e.w, `{"lists":[]}`+"\n")
+		return err
+	}
+	_, err := io.WriteString(e.w, "]}\n")
+	return err
+}
+
+func decodeJSON(r io.Reader) (Decoded, error) {
+	var backup models.Backup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return Decoded{}, err
+	}
+	return Decoded{Lists: backup.Lists}, nil
+}
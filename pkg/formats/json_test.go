@@ -0,0 +1,52 @@
+package formats
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	encoded := encode(t, JSON, sampleLists())
+	if !json.Valid([]byte(encoded)) {
+		t.Fatalf("export is not valid JSON: %s", encoded)
+	}
+	if !strings.Contains(encoded, `"title":"empty","description":"","items":[]`) {
+		t.Errorf("a list without items must be exported with an empty array: %s", encoded)
+	}
+
+	decoded, err := Decode(JSON, strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	assertLists(t, decoded.Lists, sampleLists())
+	assertErrors(t, decoded.Errors, nil)
+
+	if got := encode(t, JSON, nil); got != `{"lists":[]}`+"\n" {
+		t.Errorf("empty export: got %q", got)
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		lists   int
+		wantErr bool
+	}{
+		{"unknown fields are ignored", `{"lists":[{"title":"a","color":"red","items":[{"title":"x","priority":1}]}],"version":2}`, 1, false},
+		{"no lists", `{}`, 0, false},
+		{"empty", ``, 0, true},
+		{"truncated", `{"lists":[{"title":"a"`, 0, true},
+		{"wrong type", `{"lists":{"title":"a"}}`, 0, true},
+		{"bad due date", `{"lists":[{"title":"a","items":[{"title":"x","due_date":"tomorrow"}]}]}`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(JSON, strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr || len(decoded.Lists) != tt.lists {
+				t.Errorf("Decode: got %d lists, %v; want %d lists, error %t", len(decoded.Lists), err, tt.lists, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package formats
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// Markdown - чек-листы в стиле GitHub: заголовок - список, текст под ним - описание списка,
+// "- [ ]" и "- [x]" - задачи, строки с отступом под задачей - ее описание, срок - метка due: в названии.
+//
+//	# Покупки
+//
+//	- [ ] Молоко due:2030-01-02
+//	  обезжиренное
+//	- [x] Хлеб
+
+var (
+	markdownHeading  = regexp.MustCompile(`^#{1,6}\s+(.*?)(?:\s+#+)?\s*$`)
+	markdownCheckbox = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\](?:\s+(.*))?$`)
+)
+
+type markdownEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func newMarkdownEncoder(w io.Writer) *markdownEncoder {
+	return &markdownEncoder{w: w}
+}
+
+func (e *markdownEncoder) Encode(list models.ListBackup) error {
+	var b strings.Builder
+	if e.count > 0 {
+		b.WriteString("\n")
+	}
+	e.count++
+
+	b.WriteString("# " + singleLine(list.Title) + "\n\n")
+	if list.Description != "" {
+		b.WriteString(list.Description + "\n\n")
+	}
+	for _, item := range list.Items {
+		mark := "[ ]"
+		if item.Done {
+			mark = "[x]"
+		}
+		b.WriteString("- " + mark + " " + singleLine(item.Title))
+		if item.DueDate != nil {
+			b.WriteString(" " + dueTag + formatDue(*item.DueDate))
+		}
+		b.WriteString("\n")
+		for _, line := range strings.Split(item.Description, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				b.WriteString("  " + line)
+			}
+			if item.Description != "" {
+				b.WriteString("\n")
+			}
+		}
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *markdownEncoder) Close() error {
+	return nil
+}
+
+// decodeMarkdown: каждый заголовок начинает новый список, задачи до первого заголовка попадают в Inbox.
+// Вложенные задачи становятся задачами того же списка. Текст без отступа после задач дописывается
+// в описание списка, строки, не похожие на чек-лист, не теряются.
+func decodeMarkdown(r io.Reader) (Decoded, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+	var (
+		decoded Decoded
+		item    *models.ItemBackup
+		blank   bool // перед строкой была пустая: в описании это граница абзаца
+	)
+	ensureList := func(row int) *models.ListBackup {
+		if len(decoded.Lists) == 0 {
+			decoded.Lists = append(decoded.Lists, models.ListBackup{Title: inboxTitle, Row: row})
+		}
+		return &decoded.Lists[len(decoded.Lists)-1]
+	}
+
+	for row := 1; scanner.Scan(); row++ {
+		line := scanner.Text()
+		if row == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		text := strings.TrimSpace(line)
+
+		if text == "" {
+			blank = true
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			decoded.Lists = append(decoded.Lists, models.ListBackup{Title: m[1], Row: row})
+			item, blank = nil, false
+			continue
+		}
+		if m := markdownCheckbox.FindStringSubmatch(line); m != nil {
+			list := ensureList(row)
+			list.Items = append(list.Items, models.ItemBackup{Done: m[1] != " ", Row: row})
+			item, blank = &list.Items[len(list.Items)-1], false
+
+			words := strings.Fields(m[2])
+			if n := len(words) - 1; n >= 0 && strings.HasPrefix(words[n], dueTag) && len(words[n]) > len(dueTag) {
+				var err error
+				if item.DueDate, err = parseDueDate(words[n][len(dueTag):]); err != nil {
+					decoded.Errors = append(decoded.Errors, dueImportError(row, "due"))
+				}
+				words = words[:n]
+			}
+			item.Title = strings.Join(words, " ")
+			continue
+		}
+
+		if item != nil && strings.TrimLeft(line, " \t") != line {
+			item.Description = appendParagraph(item.Description, text, blank)
+		} else {
+			list := ensureList(row)
+			list.Description = appendParagraph(list.Description, text, blank)
+			item = nil
+		}
+		blank = false
+	}
+	if err := scanner.Err(); err != nil {
+		return Decoded{}, err
+	}
+	return decoded, nil
+}
+
+// appendParagraph дописывает строку к описанию; после пустой строки начинается новый абзац
+func appendParagraph(description, line string, blank bool) string {
+	switch {
+	case description == "":
+		return line
+	case blank:
+		return description + "\n\n" + line
+	default:
+		return description + "\n" + line
+	}
+}
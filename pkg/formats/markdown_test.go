@@ -0,0 +1,93 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	lists := sampleLists()
+	lists[0].Description = "weekly\n\nfrom the market"
+	lists[0].Items[0].Description = "2 bottles\nlow fat\n\nor kefir"
+
+	encoded := encode(t, Markdown, lists)
+	decoded, err := Decode(Markdown, strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("Decode: %v\n%s", err, encoded)
+	}
+	assertLists(t, decoded.Lists, lists)
+	assertErrors(t, decoded.Errors, nil)
+
+	if got := encode(t, Markdown, nil); got != "" {
+		t.Errorf("empty export: got %q", got)
+	}
+}
+
+func TestDecodeMarkdown(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		input  string
+		lists  []models.ListBackup
+		errors []models.ImportError
+	}{
+		{
+			name:  "tasks before the first heading go to Inbox",
+			input: "- [ ] a\n\n## Work ##\n- [X] b\n",
+			lists: []models.ListBackup{
+				{Title: inboxTitle, Items: []models.ItemBackup{{Title: "a"}}},
+				{Title: "Work", Items: []models.ItemBackup{{Title: "b", Done: true}}},
+			},
+		},
+		{
+			name:  "list markers and nested tasks",
+			input: "# L\n* [ ] a\n+ [x] b\n1. [ ] c\n2) [ ] d\n  - [ ] nested\n- [ ]\n",
+			lists: []models.ListBackup{{Title: "L", Items: []models.ItemBackup{
+				{Title: "a"}, {Title: "b", Done: true}, {Title: "c"}, {Title: "d"}, {Title: "nested"}, {Title: ""},
+			}}},
+		},
+		{
+			name:  "indented text describes the task, other text the list",
+			input: "# L\nabout\n- [ ] a\n  first\n\tsecond\nafter tasks\n    not a task\n",
+			lists: []models.ListBackup{{Title: "L", Description: "about\nafter tasks\nnot a task", Items: []models.ItemBackup{
+				{Title: "a", Description: "first\nsecond"},
+			}}},
+		},
+		{
+			name:  "only a trailing due tag is the due date",
+			input: "# L\n- [ ] pay due:2024-05-01\n- [ ] due:2024-05-01 is not at the end\n",
+			lists: []models.ListBackup{{Title: "L", Items: []models.ItemBackup{
+				{Title: "pay", DueDate: &day}, {Title: "due:2024-05-01 is not at the end"},
+			}}},
+		},
+		{
+			name:   "bad due date is reported with its row",
+			input:  "# L\n\n- [ ] a\n- [ ] b due:someday\n",
+			lists:  []models.ListBackup{{Title: "L", Items: []models.ItemBackup{{Title: "a"}, {Title: "b"}}}},
+			errors: []models.ImportError{importError(4, "due")},
+		},
+		{
+			name:  "not checklists",
+			input: "\ufeff- plain item\n- [y] odd box\n#hashtag\n",
+			lists: []models.ListBackup{{Title: inboxTitle, Description: "- plain item\n- [y] odd box\n#hashtag"}},
+		},
+		{
+			name:  "empty document",
+			input: "\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(Markdown, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			assertLists(t, decoded.Lists, tt.lists)
+			assertErrors(t, decoded.Errors, tt.errors)
+		})
+	}
+}
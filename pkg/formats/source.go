@@ -0,0 +1,79 @@
+package formats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// сервисы, из выгрузок которых работает фоновый импорт (/api/imports)
+const (
+	SourceTrello  = "trello"
+	SourceTodoist = "todoist"
+)
+
+// DecodeSource разбирает выгрузку другого сервиса: доска Trello в JSON, проекты Todoist в JSON (Sync API)
+// или шаблон проекта Todoist в CSV. name - название списка для формата, в котором его нет (CSV Todoist).
+//
+// В модели задачи нет подзадач и меток, поэтому пункты чек-листов и подзадачи становятся задачами того же
+// списка сразу после родительской, а метки дописываются к названию как @метка - так же, как @context в todo.txt.
+// Слишком длинные названия и описания обрезаются до ограничений модели.
+func DecodeSource(source, format string, r io.Reader, name string) (Decoded, error) {
+	switch {
+	case source == SourceTrello && format == JSON:
+		return decodeTrello(r)
+	case source == SourceTodoist && format == JSON:
+		return decodeTodoistJSON(r)
+	case source == SourceTodoist && format == CSV:
+		return decodeTodoistCSV(r, name)
+	default:
+		return Decoded{}, ErrUnsupportedFormat
+	}
+}
+
+// sourceTitle дописывает к названию задачи метки; пробелы внутри метки заменяются на _
+func sourceTitle(title string, tags ...string) string {
+	title = singleLine(title)
+	for _, tag := range tags {
+		if tag = strings.Join(strings.Fields(tag), "_"); tag != "" {
+			title += " @" + tag
+		}
+	}
+	return truncateText(title, models.MaxTitleLength)
+}
+
+// subtaskDescription - описание задачи, которая в исходном сервисе была подзадачей или пунктом чек-листа
+func subtaskDescription(parent, description string) string {
+	text := "Subtask of: " + singleLine(parent)
+	if description = strings.TrimSpace(description); description != "" {
+		text += "\n" + description
+	}
+	return truncateText(text, models.MaxDescriptionLength)
+}
+
+// truncateText обрезает строку до max символов, отмечая обрезку многоточием
+func truncateText(s string, max int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= max {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// parseSourceDue понимает дату, время в RFC 3339 и время без зоны (считается UTC)
+func parseSourceDue(value string) (*time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid due date %q", value)
+}
+
+// itemPath - путь до поля задачи в том же виде, что и в ошибках валидации импорта
+func itemPath(list, item int, field string) string {
+	return fmt.Sprintf("lists[%d].items[%d].%s", list, item, field)
+}
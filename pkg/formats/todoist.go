@@ -0,0 +1,276 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// todoistID - id в Sync API v9 приходят строками, в более старых выгрузках - числами
+type todoistID string
+
+func (id *todoistID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*id = ""
+		return nil
+	}
+	*id = todoistID(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// todoistBool - checked в старых версиях Sync API был числом 0/1
+type todoistBool bool
+
+func (b *todoistBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+type todoistTask struct {
+	Id          todoistID   `json:"id"`
+	ProjectId   todoistID   `json:"project_id"`
+	ParentId    todoistID   `json:"parent_id"`
+	SectionId   todoistID   `json:"section_id"`
+	Content     string      `json:"content"`
+	Description string      `json:"description"`
+	Checked     todoistBool `json:"checked"`
+	IsCompleted bool        `json:"is_completed"` // REST API
+	Priority    int         `json:"priority"`     // 4 - самый высокий (p1), 1 - без приоритета
+	Labels      []string    `json:"labels"`
+	ChildOrder  int         `json:"child_order"`
+	Order       int         `json:"order"` // REST API
+	Due         *struct {
+		Date string `json:"date"`
+	} `json:"due"`
+}
+
+// todoistExport - ответ Sync API (projects, sections, items) или выгрузка REST API (projects, tasks)
+type todoistExport struct {
+	Projects []struct {
+		Id         todoistID `json:"id"`
+		Name       string    `json:"name"`
+		ChildOrder int       `json:"child_order"`
+	} `json:"projects"`
+	Sections []struct {
+		Id   todoistID `json:"id"`
+		Name string    `json:"name"`
+	} `json:"sections"`
+	Items []todoistTask `json:"items"`
+	Tasks []todoistTask `json:"tasks"`
+}
+
+// todoistPriority - приоритет Todoist p1-p3 как приоритет todo.txt в начале названия, p4 - без приоритета
+func todoistPriority(p int) string {
+	if p < 1 || p > 3 {
+		return ""
+	}
+	return "(" + string(rune('A'+p-1)) + ") "
+}
+
+// decodeTodoistJSON: проект - список, задача - задача с метками и разделом в виде @меток,
+// подзадачи любой вложенности - задачи сразу после родительской
+func decodeTodoistJSON(r io.Reader) (Decoded, error) {
+	var export todoistExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Decoded{}, fmt.Errorf("todoist: %w", err)
+	}
+	tasks := append(export.Items, export.Tasks...)
+	if len(export.Projects) == 0 && len(tasks) == 0 {
+		return Decoded{}, errors.New("todoist: no projects or tasks in export")
+	}
+
+	sections := make(map[todoistID]string, len(export.Sections))
+	for _, section := range export.Sections {
+		sections[section.Id] = section.Name
+	}
+
+	projects := export.Projects
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].ChildOrder < projects[j].ChildOrder })
+	listIndex := make(map[todoistID]int, len(projects))
+	var decoded Decoded
+	for _, project := range projects {
+		listIndex[project.Id] = len(decoded.Lists)
+		decoded.Lists = append(decoded.Lists, models.ListBackup{Title: truncateText(project.Name, models.MaxTitleLength), Items: []models.ItemBackup{}})
+	}
+
+	// порядок задается child_order в Sync API или order в REST API, второе поле при этом нулевое
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].ChildOrder+tasks[i].Order < tasks[j].ChildOrder+tasks[j].Order
+	})
+	known := make(map[todoistID]bool, len(tasks))
+	for _, task := range tasks {
+		known[task.Id] = true
+	}
+	children := make(map[todoistID][]todoistTask)
+	for _, task := range tasks {
+		parent := task.ParentId
+		if !known[parent] {
+			parent = "" // родитель не попал в выгрузку - задача становится обычной
+		}
+		children[parent] = append(children[parent], task)
+	}
+
+	inbox := func() (int, bool) {
+		for i, list := range decoded.Lists {
+			if list.Title == inboxTitle {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	var add func(task todoistTask, parent string)
+	add = func(task todoistTask, parent string) {
+		i, ok := listIndex[task.ProjectId]
+		if !ok {
+			// проекта нет в выгрузке - задача попадает во входящие
+			if i, ok = inbox(); !ok {
+				i = len(decoded.Lists)
+				decoded.Lists = append(decoded.Lists, models.ListBackup{Title: inboxTitle, Items: []models.ItemBackup{}})
+			}
+			listIndex[task.ProjectId] = i
+		}
+		list := &decoded.Lists[i]
+
+		tags := task.Labels
+		if section := sections[task.SectionId]; section != "" && parent == "" {
+			tags = append([]string{section}, tags...)
+		}
+		// в Sync API у priority обратная нумерация: 4 - это p1
+		item := models.ItemBackup{
+			Title:       sourceTitle(todoistPriority(5-task.Priority)+task.Content, tags...),
+			Description: truncateText(task.Description, models.MaxDescriptionLength),
+			Done:        bool(task.Checked) || task.IsCompleted,
+		}
+		if parent != "" {
+			item.Description = subtaskDescription(parent, task.Description)
+		}
+		if task.Due != nil && task.Due.Date != "" {
+			var err error
+			if item.DueDate, err = parseSourceDue(task.Due.Date); err != nil {
+				decoded.Errors = append(decoded.Errors, dueImportError(0, itemPath(i, len(list.Items), "due_date")))
+			}
+		}
+		list.Items = append(list.Items, item)
+
+		for _, child := range children[task.Id] {
+			add(child, task.Content)
+		}
+	}
+	for _, task := range children[""] {
+		add(task, "")
+	}
+	return decoded, nil
+}
+
+// decodeTodoistCSV разбирает шаблон проекта Todoist (TYPE, CONTENT, DESCRIPTION, PRIORITY, INDENT, DATE, ...).
+// В шаблоне нет названия проекта, поэтому весь файл - один список name. Раздел (TYPE=section) становится
+// @меткой задач, комментарии (TYPE=note) пропускаются. DATE на естественном языке ("every monday")
+// не разбирается и сохраняется в описании задачи.
+func decodeTodoistCSV(r io.Reader, name string) (Decoded, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return Decoded{}, errors.New("todoist: missing header")
+	}
+	if err != nil {
+		return Decoded{}, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		columns[strings.ToUpper(strings.TrimSpace(column))] = i
+	}
+	for _, required := range []string{"TYPE", "CONTENT"} {
+		if _, ok := columns[required]; !ok {
+			return Decoded{}, fmt.Errorf("todoist: missing column %q", required)
+		}
+	}
+
+	if name = strings.TrimSpace(name); name == "" {
+		name = "Todoist"
+	}
+	list := models.ListBackup{Title: truncateText(name, models.MaxTitleLength), Items: []models.ItemBackup{}, Row: 1}
+	var (
+		decoded Decoded
+		section string
+		parents []string // названия родительских задач по уровням INDENT
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Decoded{}, err
+		}
+		row, _ := reader.FieldPos(0)
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		switch strings.ToLower(value("TYPE")) {
+		case "section":
+			section, parents = value("CONTENT"), nil
+			continue
+		case "task":
+		default:
+			continue
+		}
+
+		indent, err := strconv.Atoi(value("INDENT"))
+		if err != nil || indent < 1 {
+			indent = 1
+		}
+		parents = append(parents[:min(indent-1, len(parents))], value("CONTENT"))
+
+		// в шаблонах приоритет в нумерации интерфейса: 1 - это p1
+		priority, _ := strconv.Atoi(value("PRIORITY"))
+		var tags []string
+		if section != "" && len(parents) == 1 {
+			tags = append(tags, section)
+		}
+		item := models.ItemBackup{
+			Title:       sourceTitle(todoistPriority(priority)+value("CONTENT"), tags...),
+			Description: value("DESCRIPTION"),
+			Row:         row,
+		}
+		if date := value("DATE"); date != "" {
+			if item.DueDate, err = parseSourceDue(date); err != nil {
+				item.Description = strings.TrimSpace("Due: " + date + "\n" + item.Description)
+			}
+		}
+		if len(parents) > 1 {
+			item.Description = subtaskDescription(parents[len(parents)-2], item.Description)
+		} else {
+			item.Description = truncateText(item.Description, models.MaxDescriptionLength)
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	decoded.Lists = []models.ListBackup{list}
+	return decoded, nil
+}
@@ -0,0 +1,143 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func TestDecodeTodoistJSON(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		input  string
+		lists  []models.ListBackup
+		errors []string
+	}{
+		{
+			name: "Sync API",
+			input: `{"sync_token":"x","full_sync":true,
+				"projects":[{"id":"2","name":"Work","child_order":2,"color":"red"},{"id":"1","name":"Home","child_order":1}],
+				"sections":[{"id":"s","name":"Kitchen"}],
+				"items":[
+					{"id":"11","project_id":"1","section_id":"s","content":"Clean","priority":4,"labels":["weekly chores"],"child_order":1,"due":{"date":"2024-05-01","is_recurring":false}},
+					{"id":"12","project_id":"1","parent_id":"11","section_id":"s","content":"Oven","description":"inside too","checked":true,"child_order":1},
+					{"id":"13","project_id":"1","parent_id":"12","content":"Racks","checked":1,"child_order":1,"due":{"date":"2024-05-01T10:30:00"}},
+					{"id":"21","project_id":"2","content":"Report","priority":1,"child_order":1,"due":{"date":"someday"}},
+					{"id":"31","project_id":"99","content":"Orphan","parent_id":"404"}
+				]}`,
+			lists: []models.ListBackup{
+				{Title: "Home", Items: []models.ItemBackup{
+					{Title: "(A) Clean @Kitchen @weekly_chores", DueDate: &day},
+					{Title: "Oven", Description: "Subtask of: Clean\ninside too", Done: true},
+					{Title: "Racks", Description: "Subtask of: Oven", Done: true, DueDate: &at},
+				}},
+				{Title: "Work", Items: []models.ItemBackup{{Title: "Report"}}},
+				{Title: inboxTitle, Items: []models.ItemBackup{{Title: "Orphan"}}},
+			},
+			errors: []string{"lists[1].items[0].due_date"},
+		},
+		{
+			name:  "REST API with numeric ids",
+			input: `{"projects":[{"id":1,"name":"Home"}],"tasks":[{"id":2,"project_id":1,"content":"b","order":2,"is_completed":true},{"id":3,"project_id":1,"content":"a","order":1,"priority":2}]}`,
+			lists: []models.ListBackup{{Title: "Home", Items: []models.ItemBackup{{Title: "(C) a"}, {Title: "b", Done: true}}}},
+		},
+		{
+			name:  "project without tasks",
+			input: `{"projects":[{"id":"1","name":"Empty"}]}`,
+			lists: []models.ListBackup{{Title: "Empty"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := DecodeSource(SourceTodoist, JSON, strings.NewReader(tt.input), "")
+			if err != nil {
+				t.Fatalf("DecodeSource: %v", err)
+			}
+			assertLists(t, decoded.Lists, tt.lists)
+			if len(decoded.Errors) != len(tt.errors) {
+				t.Fatalf("errors: got %v, want %v", decoded.Errors, tt.errors)
+			}
+			for i, field := range tt.errors {
+				if decoded.Errors[i].Field != field {
+					t.Errorf("error %d: got %v, want field %s", i, decoded.Errors[i], field)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeTodoistCSV(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	input := "\ufeffTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"task,Plan,,1,1,,,2024-05-01,en,\n" +
+		"note,a comment,,,,,,,,\n" +
+		"task,Draft,first pass,4,2,,,every monday,en,\n" +
+		"task,Outline,,4,3,,,,,\n" +
+		"section,Later,,,,,,,,\n" +
+		"task,Review,,4,1,,,,,\n" +
+		"task,Deep,,4,x,,,,,\n" +
+		"unknown,Skipped,,,,,,,,\n"
+
+	decoded, err := DecodeSource(SourceTodoist, CSV, strings.NewReader(input), " Template ")
+	if err != nil {
+		t.Fatalf("DecodeSource: %v", err)
+	}
+	assertLists(t, decoded.Lists, []models.ListBackup{{Title: "Template", Items: []models.ItemBackup{
+		{Title: "(A) Plan", DueDate: &day},
+		{Title: "Draft", Description: "Subtask of: Plan\nDue: every monday\nfirst pass"},
+		{Title: "Outline", Description: "Subtask of: Draft"},
+		{Title: "Review @Later"},
+		{Title: "Deep @Later"},
+	}}})
+	assertErrors(t, decoded.Errors, nil)
+	if rows := []int{decoded.Lists[0].Items[0].Row, decoded.Lists[0].Items[3].Row}; rows[0] != 2 || rows[1] != 7 {
+		t.Errorf("rows: got %v, want [2 7]", rows)
+	}
+
+	if decoded, err := DecodeSource(SourceTodoist, CSV, strings.NewReader("TYPE,CONTENT\n"), ""); err != nil || decoded.Lists[0].Title != "Todoist" {
+		t.Errorf("default list name: got %+v, %v", decoded.Lists, err)
+	}
+}
+
+func TestDecodeTodoistLongText(t *testing.T) {
+	long := strings.Repeat("x", 2*models.MaxDescriptionLength)
+	input := `{"projects":[{"id":"1","name":"` + long + `"}],"items":[
+		{"id":"2","project_id":"1","content":"` + long + `","description":"` + long + `","labels":["` + long + `"]},
+		{"id":"3","project_id":"1","parent_id":"2","content":"child","description":"` + long + `"}]}`
+
+	decoded, err := DecodeSource(SourceTodoist, JSON, strings.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("DecodeSource: %v", err)
+	}
+	assertValid(t, decoded.Lists)
+}
+
+func TestDecodeTodoistMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   string
+	}{
+		{"empty JSON", JSON, "", "EOF"},
+		{"no projects or tasks", JSON, `{"user":{"id":1}}`, "no projects or tasks"},
+		{"bad checked", JSON, `{"items":[{"id":"1","content":"a","checked":"yes"}]}`, "invalid boolean"},
+		{"truncated", JSON, `{"projects":[{"id":"1"`, "unexpected EOF"},
+		{"empty CSV", CSV, "", "missing header"},
+		{"missing CONTENT", CSV, "TYPE,TEXT\ntask,a\n", `missing column "CONTENT"`},
+		{"bare quote", CSV, "TYPE,CONTENT\ntask,\"a\n", "extraneous or missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeSource(SourceTodoist, tt.format, strings.NewReader(tt.input), "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("DecodeSource: got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
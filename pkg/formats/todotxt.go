@@ -0,0 +1,160 @@
+package formats
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// todo.txt (https://github.com/todotxt/todo.txt): одна строка - одна задача.
+// Список задается меткой +project (пробелы в названии заменяются на _), срок - меткой due:2006-01-02.
+// Приоритет (A), метки @context и прочие key:value остаются в названии задачи как есть.
+// Описания, даты создания и завершения и пустые списки в формате не сохраняются.
+
+// inboxTitle - список для задач, у которых в файле нет списка (метки +project или заголовка)
+const inboxTitle = "Inbox"
+
+// dueTag - метка срока, общая для todo.txt и Markdown
+const dueTag = "due:"
+
+// максимальная длина строки файла импорта
+const maxLineSize = 1 << 20
+
+type todoTxtEncoder struct {
+	w io.Writer
+}
+
+func newTodoTxtEncoder(w io.Writer) *todoTxtEncoder {
+	return &todoTxtEncoder{w: w}
+}
+
+func (e *todoTxtEncoder) Encode(list models.ListBackup) error {
+	project := "+" + strings.Join(strings.Fields(list.Title), "_")
+
+	var b strings.Builder
+	for _, item := range list.Items {
+		if item.Done {
+			b.WriteString("x ")
+		}
+		b.WriteString(singleLine(item.Title))
+		b.WriteString(" " + project)
+		if item.DueDate != nil {
+			b.WriteString(" " + dueTag + formatDue(*item.DueDate))
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *todoTxtEncoder) Close() error {
+	return nil
+}
+
+// decodeTodoTxt собирает задачи с одинаковым +project в один список. Если меток +project несколько,
+// списком становится последняя: экспорт дописывает ее после названия, в котором тоже могут быть метки.
+func decodeTodoTxt(r io.Reader) (Decoded, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+	var decoded Decoded
+	listIndex := make(map[string]int)
+	for row := 1; scanner.Scan(); row++ {
+		line := scanner.Text()
+		if row == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		item := models.ItemBackup{Row: row}
+		var words []string
+		if fields[0] == "x" {
+			// x [дата завершения] [дата создания]
+			item.Done = true
+			fields = skipDates(fields[1:], 2)
+		} else {
+			if isPriority(fields[0]) {
+				words = append(words, fields[0])
+				fields = fields[1:]
+			}
+			fields = skipDates(fields, 1)
+		}
+
+		project, projectAt := "", -1
+		for _, field := range fields {
+			switch {
+			case strings.HasPrefix(field, dueTag) && len(field) > len(dueTag) && item.DueDate == nil:
+				var err error
+				if item.DueDate, err = parseDueDate(field[len(dueTag):]); err != nil {
+					decoded.Errors = append(decoded.Errors, dueImportError(row, "due"))
+				}
+			case strings.HasPrefix(field, "+") && len(field) > 1:
+				project, projectAt = field[1:], len(words)
+				words = append(words, field)
+			default:
+				words = append(words, field)
+			}
+		}
+		if projectAt >= 0 {
+			words = append(words[:projectAt], words[projectAt+1:]...)
+		}
+		item.Title = strings.Join(words, " ")
+
+		title := inboxTitle
+		if project != "" {
+			title = strings.ReplaceAll(project, "_", " ")
+		}
+		i, ok := listIndex[title]
+		if !ok {
+			i = len(decoded.Lists)
+			listIndex[title] = i
+			decoded.Lists = append(decoded.Lists, models.ListBackup{Title: title, Row: row})
+		}
+		decoded.Lists[i].Items = append(decoded.Lists[i].Items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return Decoded{}, err
+	}
+	return decoded, nil
+}
+
+// isPriority - приоритет todo.txt: заглавная латинская буква в скобках, (A)
+func isPriority(field string) bool {
+	return len(field) == 3 && field[0] == '(' && field[1] >= 'A' && field[1] <= 'Z' && field[2] == ')'
+}
+
+// skipDates отбрасывает до n дат в начале строки: в модели задачи для них нет полей
+func skipDates(fields []string, n int) []string {
+	for ; n > 0 && len(fields) > 0; n-- {
+		if _, err := time.Parse(time.DateOnly, fields[0]); err != nil {
+			break
+		}
+		fields = fields[1:]
+	}
+	return fields
+}
+
+// formatDue пишет срок датой, если это полночь UTC (так его задают при импорте), иначе в RFC 3339
+func formatDue(due time.Time) string {
+	if due.Location() == time.UTC && due.Equal(due.Truncate(24*time.Hour)) {
+		return due.Format(time.DateOnly)
+	}
+	return due.Format(time.RFC3339)
+}
+
+// singleLine склеивает название в одну строку: в обоих текстовых форматах перевод строки начинает новую запись
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func dueImportError(row int, field string) models.ImportError {
+	return models.ImportError{Row: row, FieldError: models.FieldError{
+		Field: field, Code: models.CodeInvalid, Message: "must be a date (2006-01-02) or an RFC 3339 timestamp",
+	}}
+}
@@ -0,0 +1,109 @@
+package formats
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	// описания и пустые списки todo.txt не хранит, поэтому в примере их нет
+	lists := []models.ListBackup{
+		{Title: "home chores", Items: []models.ItemBackup{
+			{Title: "(A) call mom @phone", DueDate: &day},
+			{Title: "vacuum", Done: true, DueDate: &at},
+		}},
+		{Title: "work", Items: []models.ItemBackup{{Title: "report for +client"}}},
+	}
+
+	encoded := encode(t, TodoTxt, lists)
+	if want := "(A) call mom @phone +home_chores due:2024-05-01\n"; !strings.HasPrefix(encoded, want) {
+		t.Errorf("export: got %q, want it to start with %q", encoded, want)
+	}
+	decoded, err := Decode(TodoTxt, strings.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	assertLists(t, decoded.Lists, lists)
+	assertErrors(t, decoded.Errors, nil)
+
+	if got := encode(t, TodoTxt, []models.ListBackup{{Title: "a", Items: []models.ItemBackup{{Title: "two\nlines"}}}}); got != "two lines +a\n" {
+		t.Errorf("multi-line title: got %q", got)
+	}
+}
+
+func TestDecodeTodoTxt(t *testing.T) {
+	day := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		input  string
+		lists  []models.ListBackup
+		errors []models.ImportError
+	}{
+		{
+			name:  "completion and creation dates are dropped",
+			input: "x 2024-05-02 2024-05-01 report +work due:2024-05-03\n",
+			lists: []models.ListBackup{{Title: "work", Items: []models.ItemBackup{{Title: "report", Done: true, DueDate: &day}}}},
+		},
+		{
+			name:  "priority stays in the title, creation date is dropped",
+			input: "(B) 2024-05-01 call @phone +home_chores\n",
+			lists: []models.ListBackup{{Title: "home chores", Items: []models.ItemBackup{{Title: "(B) call @phone"}}}},
+		},
+		{
+			name:  "the last project is the list",
+			input: "a +one b +two\n",
+			lists: []models.ListBackup{{Title: "two", Items: []models.ItemBackup{{Title: "a +one b"}}}},
+		},
+		{
+			name:  "no project goes to Inbox, unknown tags are kept",
+			input: "\ufeffread book rec:+1w t:2024-05-01\nX not done\n",
+			lists: []models.ListBackup{{Title: inboxTitle, Items: []models.ItemBackup{{Title: "read book rec:+1w t:2024-05-01"}, {Title: "X not done"}}}},
+		},
+		{
+			name:  "only the first due tag is the due date",
+			input: "a due:2024-05-03 due:2024-06-01 +p\n",
+			lists: []models.ListBackup{{Title: "p", Items: []models.ItemBackup{{Title: "a due:2024-06-01", DueDate: &day}}}},
+		},
+		{
+			name:   "bad due date is reported with its row, blank lines count",
+			input:  "a +p\n\n   \nb +p due:tomorrow\n",
+			lists:  []models.ListBackup{{Title: "p", Items: []models.ItemBackup{{Title: "a"}, {Title: "b"}}}},
+			errors: []models.ImportError{importError(4, "due")},
+		},
+		{
+			name:  "empty document",
+			input: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := Decode(TodoTxt, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			assertLists(t, decoded.Lists, tt.lists)
+			assertErrors(t, decoded.Errors, tt.errors)
+		})
+	}
+}
+
+func TestDecodeTextTooLongLine(t *testing.T) {
+	input := "a +p\n" + strings.Repeat("a", maxLineSize+1) + "\n"
+	for _, format := range []string{TodoTxt, Markdown} {
+		if _, err := Decode(format, strings.NewReader(input)); !errors.Is(err, bufio.ErrTooLong) {
+			t.Errorf("%s: got %v, want bufio.ErrTooLong", format, err)
+		}
+	}
+	// строка ровно в предел еще читается
+	if _, err := Decode(TodoTxt, strings.NewReader(strings.Repeat("a", maxLineSize-1)+"\n")); err != nil {
+		t.Errorf("line at the limit: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package formats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// trelloBoard - нужная часть выгрузки доски Trello (Menu -> Print, export and share -> Export as JSON)
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Desc  string `json:"desc"`
+	Lists []struct {
+		Id     string  `json:"id"`
+		Name   string  `json:"name"`
+		Closed bool    `json:"closed"`
+		Pos    float64 `json:"pos"`
+	} `json:"lists"`
+	Cards []struct {
+		Id          string  `json:"id"`
+		IdList      string  `json:"idList"`
+		Name        string  `json:"name"`
+		Desc        string  `json:"desc"`
+		Closed      bool    `json:"closed"`
+		Due         string  `json:"due"`
+		DueComplete bool    `json:"dueComplete"`
+		Pos         float64 `json:"pos"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IdCard     string  `json:"idCard"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Due   string  `json:"due"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// decodeTrello: доска - список, карточка - задача с меткой колонки и метками карточки, пункты чек-листов -
+// задачи сразу после карточки. Выполненной считается карточка с отмеченным сроком (dueComplete).
+// Архивные карточки и карточки архивных колонок пропускаются.
+func decodeTrello(r io.Reader) (Decoded, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return Decoded{}, fmt.Errorf("trello: %w", err)
+	}
+	if board.Name == "" {
+		return Decoded{}, errors.New("trello: not a board export")
+	}
+
+	type column struct {
+		name string
+		pos  float64
+	}
+	columns := make(map[string]column, len(board.Lists))
+	for _, list := range board.Lists {
+		if !list.Closed {
+			columns[list.Id] = column{name: list.Name, pos: list.Pos}
+		}
+	}
+
+	cards := board.Cards[:0:0]
+	for _, card := range board.Cards {
+		if _, ok := columns[card.IdList]; ok && !card.Closed {
+			cards = append(cards, card)
+		}
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		if ci, cj := columns[cards[i].IdList], columns[cards[j].IdList]; ci.pos != cj.pos {
+			return ci.pos < cj.pos
+		}
+		return cards[i].Pos < cards[j].Pos
+	})
+
+	checklists := board.Checklists
+	sort.SliceStable(checklists, func(i, j int) bool { return checklists[i].Pos < checklists[j].Pos })
+
+	var decoded Decoded
+	list := models.ListBackup{
+		Title:       truncateText(board.Name, models.MaxTitleLength),
+		Description: truncateText(board.Desc, models.MaxDescriptionLength),
+		Items:       []models.ItemBackup{},
+	}
+	for _, card := range cards {
+		tags := []string{columns[card.IdList].name}
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				tags = append(tags, label.Name)
+			} else {
+				tags = append(tags, label.Color)
+			}
+		}
+
+		item := models.ItemBackup{
+			Title:       sourceTitle(card.Name, tags...),
+			Description: truncateText(card.Desc, models.MaxDescriptionLength),
+			Done:        card.DueComplete,
+		}
+		if card.Due != "" {
+			var err error
+			if item.DueDate, err = parseSourceDue(card.Due); err != nil {
+				decoded.Errors = append(decoded.Errors, dueImportError(0, itemPath(0, len(list.Items), "due_date")))
+			}
+		}
+		list.Items = append(list.Items, item)
+
+		for _, checklist := range checklists {
+			if checklist.IdCard != card.Id {
+				continue
+			}
+			checkItems := checklist.CheckItems
+			sort.SliceStable(checkItems, func(i, j int) bool { return checkItems[i].Pos < checkItems[j].Pos })
+			for _, checkItem := range checkItems {
+				subtask := models.ItemBackup{
+					Title:       sourceTitle(checkItem.Name),
+					Description: subtaskDescription(card.Name, ""),
+					Done:        checkItem.State == "complete",
+				}
+				if checkItem.Due != "" {
+					var err error
+					if subtask.DueDate, err = parseSourceDue(checkItem.Due); err != nil {
+						decoded.Errors = append(decoded.Errors, dueImportError(0, itemPath(0, len(list.Items), "due_date")))
+					}
+				}
+				list.Items = append(list.Items, subtask)
+			}
+		}
+	}
+
+	decoded.Lists = []models.ListBackup{list}
+	return decoded, nil
+}
@@ -0,0 +1,103 @@
+package formats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const trelloBoardJSON = `{
+	"id": "board", "name": "Project", "desc": "board description", "prefs": {"background": "blue"},
+	"lists": [
+		{"id": "l2", "name": "Done", "pos": 2},
+		{"id": "l1", "name": "To Do", "pos": 1},
+		{"id": "l3", "name": "Old", "closed": true, "pos": 3}
+	],
+	"cards": [
+		{"id": "c3", "idList": "l2", "name": "Shipped", "dueComplete": true, "due": "2024-05-01T10:30:00.000Z", "pos": 1},
+		{"id": "c2", "idList": "l1", "name": "Second", "pos": 2, "labels": [{"name": "", "color": "red"}]},
+		{"id": "c1", "idList": "l1", "name": "First", "desc": "details", "pos": 1, "labels": [{"name": "high priority", "color": "green"}]},
+		{"id": "c4", "idList": "l1", "name": "Archived", "closed": true, "pos": 3},
+		{"id": "c5", "idList": "l3", "name": "In archived list", "pos": 1},
+		{"id": "c6", "idList": "l1", "name": "Bad due", "due": "soon", "pos": 4}
+	],
+	"checklists": [
+		{"idCard": "c1", "pos": 2, "checkItems": [{"name": "later list", "state": "incomplete", "pos": 1}]},
+		{"idCard": "c1", "pos": 1, "checkItems": [
+			{"name": "step two", "state": "complete", "pos": 2},
+			{"name": "step one", "state": "incomplete", "pos": 1, "due": "2024-05-02T00:00:00.000Z"}
+		]},
+		{"idCard": "c4", "pos": 1, "checkItems": [{"name": "of archived card", "state": "incomplete", "pos": 1}]}
+	],
+	"actions": [{"type": "createCard"}]
+}`
+
+func TestDecodeTrello(t *testing.T) {
+	shipped := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	stepDue := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	decoded, err := DecodeSource(SourceTrello, JSON, strings.NewReader(trelloBoardJSON), "ignored")
+	if err != nil {
+		t.Fatalf("DecodeSource: %v", err)
+	}
+	// колонки по pos, карточки по pos внутри колонки, чек-листы сразу после карточки; неизвестные поля пропускаются
+	assertLists(t, decoded.Lists, []models.ListBackup{{Title: "Project", Description: "board description", Items: []models.ItemBackup{
+		{Title: "First @To_Do @high_priority", Description: "details"},
+		{Title: "step one", Description: "Subtask of: First", DueDate: &stepDue},
+		{Title: "step two", Description: "Subtask of: First", Done: true},
+		{Title: "later list", Description: "Subtask of: First"},
+		{Title: "Second @To_Do @red"},
+		{Title: "Bad due @To_Do"},
+		{Title: "Shipped @Done", Done: true, DueDate: &shipped},
+	}}})
+	if len(decoded.Errors) != 1 || decoded.Errors[0].Field != "lists[0].items[5].due_date" {
+		t.Errorf("errors: got %v, want one for lists[0].items[5].due_date", decoded.Errors)
+	}
+}
+
+func TestDecodeTrelloLongText(t *testing.T) {
+	long := strings.Repeat("я", 2*models.MaxTitleLength)
+	input := `{"name":"` + long + `","desc":"` + long + `","lists":[{"id":"l","name":"` + long + `"}],
+		"cards":[{"id":"c","idList":"l","name":"` + long + `","desc":"` + long + `"}],
+		"checklists":[{"idCard":"c","checkItems":[{"name":"` + long + `","state":"complete"}]}]}`
+
+	decoded, err := DecodeSource(SourceTrello, JSON, strings.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("DecodeSource: %v", err)
+	}
+	if len(decoded.Lists) != 1 || len(decoded.Lists[0].Items) != 2 {
+		t.Fatalf("got %+v", decoded.Lists)
+	}
+	assertValid(t, decoded.Lists)
+	if title := decoded.Lists[0].Title; !strings.HasSuffix(title, "…") {
+		t.Errorf("truncated title must end with an ellipsis: %q", title)
+	}
+}
+
+func TestDecodeTrelloMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", "EOF"},
+		{"not JSON", "name,desc\n", "invalid character"},
+		{"truncated", `{"name":"Project","cards":[`, "unexpected EOF"},
+		{"not a board", `{"projects":[]}`, "not a board export"},
+		{"wrong type", `{"name":"Project","cards":{}}`, "cannot unmarshal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeSource(SourceTrello, JSON, strings.NewReader(tt.input), "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("DecodeSource: got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := DecodeSource(SourceTrello, CSV, strings.NewReader(""), ""); err != ErrUnsupportedFormat {
+		t.Errorf("Trello CSV: got %v, want ErrUnsupportedFormat", err)
+	}
+}
@@ -0,0 +1,77 @@
+package gql
+
+import (
+	"sync"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// itemLoader собирает id списков, задачи которых запрашиваются в рамках одного GraphQL-запроса,
+// и загружает их одним походом в базу вместо отдельного запроса на каждый список (проблема N+1).
+// Резолверы возвращают thunk-функции, которые graphql-go вызывает уже после обхода всех соседних полей,
+// поэтому к моменту первого вызова в pending накоплены все нужные id.
+type itemLoader struct {
+	fetch func(listIds []int) (map[int][]models.TodoItem, error)
+
+	mu      sync.Mutex
+	pending []int
+	items   map[int][]models.TodoItem
+	errs    map[int]error
+}
+
+func newItemLoader(fetch func(listIds []int) (map[int][]models.TodoItem, error)) *itemLoader {
+	return &itemLoader{
+		fetch: fetch,
+		items: make(map[int][]models.TodoItem),
+		errs:  make(map[int]error),
+	}
+}
+
+// load ставит список в очередь на загрузку и возвращает thunk, который вернет его задачи
+func (l *itemLoader) load(listId int) func() ([]models.TodoItem, error) {
+	l.mu.Lock()
+	if _, ok := l.items[listId]; !ok && !l.isPending(listId) {
+		l.pending = append(l.pending, listId)
+	}
+	l.mu.Unlock()
+
+	return func() ([]models.TodoItem, error) {
+		return l.get(listId)
+	}
+}
+
+func (l *itemLoader) get(listId int) ([]models.TodoItem, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) > 0 {
+		batch := l.pending
+		l.pending = nil
+
+		items, err := l.fetch(batch)
+		for _, id := range batch {
+			if err != nil {
+				l.errs[id] = err
+				continue
+			}
+			if items[id] == nil {
+				items[id] = []models.TodoItem{}
+			}
+			l.items[id] = items[id]
+		}
+	}
+
+	if err, ok := l.errs[listId]; ok {
+		return nil, err
+	}
+	return l.items[listId], nil
+}
+
+func (l *itemLoader) isPending(listId int) bool {
+	for _, id := range l.pending {
+		if id == listId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package gql_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ponomare0v/todo-go-app/pkg/gql"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+// countingItems считает обращения к хранилищу задач, которые загрузчик должен собирать в один запрос
+type countingItems struct {
+	repository.TodoItem
+	byLists int32
+	single  int32
+}
+
+func (r *countingItems) GetAllByLists(ctx context.Context, userId int, listIds []int) (map[int][]models.TodoItem, error) {
+	atomic.AddInt32(&r.byLists, 1)
+	return r.TodoItem.GetAllByLists(ctx, userId, listIds)
+}
+
+func (r *countingItems) GetAll(ctx context.Context, userId, listId int) ([]models.TodoItem, error) {
+	atomic.AddInt32(&r.single, 1)
+	return r.TodoItem.GetAll(ctx, userId, listId)
+}
+
+func TestListItemsAreBatched(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRepository()
+	items := &countingItems{TodoItem: repo.TodoItem}
+	repo.TodoItem = items
+
+	userId, err := repo.Authorization.CreateUser(ctx, models.User{Name: "Alice", Username: "alice", Password: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	const lists = 5
+	for i := 0; i < lists; i++ {
+		listId, err := repo.TodoList.Create(ctx, userId, models.TodoList{Title: "list"})
+		if err != nil {
+			t.Fatalf("TodoList.Create: %v", err)
+		}
+		// в i-м списке i задач, первая из них выполнена
+		for j := 0; j < i; j++ {
+			if _, err := repo.TodoItem.Create(ctx, listId, models.TodoItem{Title: "item", Done: j == 0}); err != nil {
+				t.Fatalf("TodoItem.Create: %v", err)
+			}
+		}
+	}
+
+	executor, err := gql.NewExecutor(service.NewService(repo, service.Config{}))
+	if err != nil {
+		t.Fatalf("NewExecutor: %v", err)
+	}
+	result := executor.Execute(ctx, userId, gql.Request{Query: "{ lists { items { title } itemsCount doneCount } }"})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Execute: %v", result.Errors)
+	}
+
+	if got := atomic.LoadInt32(&items.byLists); got != 1 {
+		t.Errorf("GetAllByLists called %d times, want 1", got)
+	}
+	if got := atomic.LoadInt32(&items.single); got != 0 {
+		t.Errorf("GetAll called %d times, want 0", got)
+	}
+
+	raw, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatalf("marshal result: %v", err)
+	}
+	var data struct {
+		Lists []struct {
+			Items      []struct{ Title string }
+			ItemsCount int
+			DoneCount  int
+		}
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if len(data.Lists) != lists {
+		t.Fatalf("got %d lists, want %d", len(data.Lists), lists)
+	}
+	for i, list := range data.Lists {
+		wantDone := 0
+		if i > 0 {
+			wantDone = 1
+		}
+		if len(list.Items) != i || list.ItemsCount != i || list.DoneCount != wantDone {
+			t.Errorf("list %d: %d items, itemsCount %d, doneCount %d; want %d, %d, %d",
+				i, len(list.Items), list.ItemsCount, list.DoneCount, i, i, wantDone)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package gql
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// резолверы запросов и мутаций - тонкая прослойка над теми же методами сервисов, что вызывают REST-хэндлеры
+
+func (e *Executor) resolveLists(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	return e.services.TodoList.GetAll(p.Context, userId)
+}
+
+func (e *Executor) resolveList(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	return e.services.TodoList.GetById(p.Context, userId, p.Args["id"].(int))
+}
+
+func (e *Executor) resolveItems(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	return e.services.TodoItem.GetAll(p.Context, userId, p.Args["listId"].(int))
+}
+
+func (e *Executor) resolveItem(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+	return e.services.TodoItem.GetById(p.Context, userId, p.Args["id"].(int))
+}
+
+func (e *Executor) resolveSearch(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeRead)
+	if err != nil {
+		return nil, err
+	}
+
+	query := p.Args["query"].(string)
+	lists, err := e.services.TodoList.Search(p.Context, userId, query)
+	if err != nil {
+		return nil, err
+	}
+	items, err := e.services.TodoItem.Search(p.Context, userId, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"lists": lists,
+		"items": items,
+	}, nil
+}
+
+//
+//
+//
+
+func (e *Executor) createList(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeListsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := e.services.TodoList.Create(p.Context, userId, models.TodoList{
+		Title:       p.Args["title"].(string),
+		Description: p.Args["description"].(string),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return e.services.TodoList.GetById(p.Context, userId, id)
+}
+
+func (e *Executor) updateList(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeListsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	id := p.Args["id"].(int)
+	input := models.UpdateListInput{
+		Title:       stringArg(p, "title"),
+		Description: stringArg(p, "description"),
+	}
+	if err := e.services.TodoList.Update(p.Context, userId, id, input); err != nil {
+		return nil, wrapError(err)
+	}
+	return e.services.TodoList.GetById(p.Context, userId, id)
+}
+
+func (e *Executor) deleteList(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeListsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.services.TodoList.Delete(p.Context, userId, p.Args["id"].(int)); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (e *Executor) createItem(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeItemsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := e.services.TodoItem.Create(p.Context, userId, p.Args["listId"].(int), models.TodoItem{
+		Title:       p.Args["title"].(string),
+		Description: p.Args["description"].(string),
+		DueDate:     timeArg(p, "dueDate"),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return e.services.TodoItem.GetById(p.Context, userId, id)
+}
+
+func (e *Executor) updateItem(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeItemsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	id := p.Args["id"].(int)
+	input := models.UpdateItemInput{
+		Title:       stringArg(p, "title"),
+		Description: stringArg(p, "description"),
+		DueDate:     timeArg(p, "dueDate"),
+	}
+	if done, ok := p.Args["done"].(bool); ok {
+		input.Done = &done
+	}
+	if err := e.services.TodoItem.Update(p.Context, userId, id, input); err != nil {
+		return nil, wrapError(err)
+	}
+	return e.services.TodoItem.GetById(p.Context, userId, id)
+}
+
+func (e *Executor) deleteItem(p graphql.ResolveParams) (interface{}, error) {
+	userId, err := userIdFrom(p, models.ScopeItemsWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.services.TodoItem.Delete(p.Context, userId, p.Args["id"].(int)); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// stringArg возвращает указатель на необязательный строковый аргумент или nil, если он не передан
+func stringArg(p graphql.ResolveParams, name string) *string {
+	value, ok := p.Args[name].(string)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func timeArg(p graphql.ResolveParams, name string) *time.Time {
+	value, ok := p.Args[name].(time.Time)
+	if !ok {
+		return nil
+	}
+	return &value
+}
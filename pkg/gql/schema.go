@@ -0,0 +1,265 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+type ctxKey int
+
+const (
+	userIdKey ctxKey = iota
+	itemLoaderKey
+)
+
+// Request - тело запроса к /graphql
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Executor выполняет GraphQL-запросы поверх слоя сервисов
+type Executor struct {
+	schema   graphql.Schema
+	services *service.Service
+}
+
+func NewExecutor(services *service.Service) (*Executor, error) {
+	e := &Executor{services: services}
+
+	schema, err := e.buildSchema()
+	if err != nil {
+		return nil, err
+	}
+	e.schema = schema
+
+	return e, nil
+}
+
+// Execute выполняет запрос от имени пользователя userId. Загрузчик задач создается на каждый запрос,
+// чтобы батчинг и кэш не переживали его и не смешивали данные разных пользователей.
+func (e *Executor) Execute(ctx context.Context, userId int, req Request) *graphql.Result {
+	loader := newItemLoader(func(listIds []int) (map[int][]models.TodoItem, error) {
+		return e.services.TodoItem.GetAllByLists(ctx, userId, listIds)
+	})
+
+	ctx = context.WithValue(ctx, userIdKey, userId)
+	ctx = context.WithValue(ctx, itemLoaderKey, loader)
+
+	return graphql.Do(graphql.Params{
+		Schema:         e.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+}
+
+// userIdFrom возвращает пользователя запроса, если персональный токен, которым он вошел, дает право scope
+func userIdFrom(p graphql.ResolveParams, scope string) (int, error) {
+	userId, ok := p.Context.Value(userIdKey).(int)
+	if !ok {
+		return 0, errors.New("user id not found in context")
+	}
+	if err := service.CheckScope(p.Context, scope); err != nil {
+		return 0, err
+	}
+	return userId, nil
+}
+
+func itemLoaderFrom(p graphql.ResolveParams) *itemLoader {
+	return p.Context.Value(itemLoaderKey).(*itemLoader)
+}
+
+// validationError отдает ошибки валидации полей в extensions, как это делает REST в ответе 422
+type validationError struct {
+	models.ValidationErrors
+}
+
+func (e validationError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   "VALIDATION_FAILED",
+		"fields": e.ValidationErrors,
+	}
+}
+
+func wrapError(err error) error {
+	var validationErrs models.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return validationError{validationErrs}
+	}
+	return err
+}
+
+func (e *Executor) buildSchema() (graphql.Schema, error) {
+	itemType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoItem",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"done":        &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"dueDate": &graphql.Field{
+				Type: graphql.DateTime,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if item, ok := p.Source.(models.TodoItem); ok && item.DueDate != nil {
+						return *item.DueDate, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	listType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoList",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"items": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+				Resolve: resolveListItems(func(items []models.TodoItem) interface{} { return items }),
+			},
+			"itemsCount": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: resolveListItems(func(items []models.TodoItem) interface{} { return len(items) }),
+			},
+			"doneCount": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: resolveListItems(func(items []models.TodoItem) interface{} {
+					done := 0
+					for _, item := range items {
+						if item.Done {
+							done++
+						}
+					}
+					return done
+				}),
+			},
+		},
+	})
+
+	searchResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SearchResult",
+		Fields: graphql.Fields{
+			"lists": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(listType)))},
+			"items": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType)))},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"lists": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(listType))),
+				Resolve: e.resolveLists,
+			},
+			"list": &graphql.Field{
+				Type:    listType,
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: e.resolveList,
+			},
+			"items": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+				Args:    graphql.FieldConfigArgument{"listId": idArg},
+				Resolve: e.resolveItems,
+			},
+			"item": &graphql.Field{
+				Type:    itemType,
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: e.resolveItem,
+			},
+			"search": &graphql.Field{
+				Type:    graphql.NewNonNull(searchResultType),
+				Args:    graphql.FieldConfigArgument{"query": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: e.resolveSearch,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createList": &graphql.Field{
+				Type: graphql.NewNonNull(listType),
+				Args: graphql.FieldConfigArgument{
+					"title":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+				},
+				Resolve: e.createList,
+			},
+			"updateList": &graphql.Field{
+				Type: graphql.NewNonNull(listType),
+				Args: graphql.FieldConfigArgument{
+					"id":          idArg,
+					"title":       &graphql.ArgumentConfig{Type: graphql.String},
+					"description": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: e.updateList,
+			},
+			"deleteList": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: e.deleteList,
+			},
+			"createItem": &graphql.Field{
+				Type: graphql.NewNonNull(itemType),
+				Args: graphql.FieldConfigArgument{
+					"listId":      idArg,
+					"title":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: ""},
+					"dueDate":     &graphql.ArgumentConfig{Type: graphql.DateTime},
+				},
+				Resolve: e.createItem,
+			},
+			"updateItem": &graphql.Field{
+				Type: graphql.NewNonNull(itemType),
+				Args: graphql.FieldConfigArgument{
+					"id":          idArg,
+					"title":       &graphql.ArgumentConfig{Type: graphql.String},
+					"description": &graphql.ArgumentConfig{Type: graphql.String},
+					"done":        &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"dueDate":     &graphql.ArgumentConfig{Type: graphql.DateTime},
+				},
+				Resolve: e.updateItem,
+			},
+			"deleteItem": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: e.deleteItem,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+var idArg = &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+
+// resolveListItems достает задачи списка через загрузчик и превращает их в значение поля с помощью project
+func resolveListItems(project func(items []models.TodoItem) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		list, ok := p.Source.(models.TodoList)
+		if !ok {
+			return nil, errors.New("unexpected source for list field")
+		}
+
+		thunk := itemLoaderFrom(p).load(list.Id)
+		return func() (interface{}, error) {
+			items, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			return project(items), nil
+		}, nil
+	}
+}
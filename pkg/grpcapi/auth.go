@@ -0,0 +1,55 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	todov1 "github.com/ponomare0v/todo-go-app/pkg/api/todo/v1"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *Handler) SignUp(ctx context.Context, req *todov1.SignUpRequest) (*todov1.SignUpResponse, error) {
+	id, err := h.services.Authorization.CreateUser(ctx, models.User{
+		Name:     req.GetName(),
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.SignUpResponse{Id: int64(id)}, nil
+}
+
+func (h *Handler) SignIn(ctx context.Context, req *todov1.SignInRequest) (*todov1.SignInResponse, error) {
+	token, err := h.services.Authorization.GenerateToken(ctx, req.GetUsername(), req.GetPassword())
+	var twoFactorRequired *service.TwoFactorRequiredError
+	if errors.As(err, &twoFactorRequired) {
+		return &todov1.SignInResponse{ChallengeToken: twoFactorRequired.ChallengeToken}, nil
+	}
+	if err != nil {
+		return nil, signInStatus(err)
+	}
+
+	return &todov1.SignInResponse{Token: token}, nil
+}
+
+func (h *Handler) SignInTwoFactor(ctx context.Context, req *todov1.SignInTwoFactorRequest) (*todov1.SignInResponse, error) {
+	token, err := h.services.Authorization.GenerateTokenWithCode(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		return nil, signInStatus(err)
+	}
+
+	return &todov1.SignInResponse{Token: token}, nil
+}
+
+func signInStatus(err error) error {
+	if errors.Is(err, ratelimit.ErrLocked) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
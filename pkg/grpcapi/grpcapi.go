@@ -0,0 +1,69 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	todov1 "github.com/ponomare0v/todo-go-app/pkg/api/todo/v1"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Config - настройки gRPC-слоя
+type Config struct {
+	RequestTimeout time.Duration // дедлайн на унарный вызов, если клиент не передал свой более короткий
+}
+
+// Handler - реализация gRPC-сервисов поверх того же слоя сервисов, что и REST-хэндлеры
+type Handler struct {
+	todov1.UnimplementedAuthServiceServer
+	todov1.UnimplementedListServiceServer
+	todov1.UnimplementedItemServiceServer
+
+	services *service.Service
+	cfg      Config
+}
+
+func NewHandler(services *service.Service, cfg Config) *Handler {
+	return &Handler{services: services, cfg: cfg}
+}
+
+// NewServer собирает grpc.Server с перехватчиками аутентификации и регистрирует в нем все сервисы
+func (h *Handler) NewServer() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()), // спан на вызов и traceparent из метаданных
+		grpc.ChainUnaryInterceptor(h.unaryTimeout, h.unaryAuth),
+		grpc.StreamInterceptor(h.streamAuth),
+	)
+
+	todov1.RegisterAuthServiceServer(srv, h)
+	todov1.RegisterListServiceServer(srv, h)
+	todov1.RegisterItemServiceServer(srv, h)
+
+	return srv
+}
+
+// toStatus переводит ошибку сервиса в статус gRPC, по смыслу повторяя коды ответов REST
+func toStatus(err error) error {
+	var validationErrs models.ValidationErrors
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.As(err, &validationErrs):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, service.ErrListNotFound), errors.Is(err, service.ErrItemNotFound):
+		return status.Error(codes.NotFound, "not found")
+	default:
+		logrus.Error(err.Error())
+		return status.Error(codes.Internal, err.Error())
+	}
+}
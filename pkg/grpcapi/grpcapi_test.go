@@ -0,0 +1,207 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	todov1 "github.com/ponomare0v/todo-go-app/pkg/api/todo/v1"
+	"github.com/ponomare0v/todo-go-app/pkg/grpcapi"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const password = "secret123"
+
+type testServer struct {
+	services *service.Service
+	auth     todov1.AuthServiceClient
+	lists    todov1.ListServiceClient
+	items    todov1.ItemServiceClient
+}
+
+// newTestServer поднимает gRPC-сервер поверх хранилища в памяти на соединении в памяти процесса
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	services := service.NewService(repository.NewMemoryRepository(), service.Config{})
+	srv := grpcapi.NewHandler(services, grpcapi.Config{RequestTimeout: 5 * time.Second}).NewServer()
+
+	listener := bufconn.Listen(1 << 20)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &testServer{
+		services: services,
+		auth:     todov1.NewAuthServiceClient(conn),
+		lists:    todov1.NewListServiceClient(conn),
+		items:    todov1.NewItemServiceClient(conn),
+	}
+}
+
+// signIn регистрирует пользователя и возвращает контекст с его токеном в метаданных
+func (s *testServer) signIn(t *testing.T, username string) context.Context {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := s.auth.SignUp(ctx, &todov1.SignUpRequest{Name: username, Username: username, Password: password}); err != nil {
+		t.Fatalf("SignUp %s: %v", username, err)
+	}
+	resp, err := s.auth.SignIn(ctx, &todov1.SignInRequest{Username: username, Password: password})
+	if err != nil {
+		t.Fatalf("SignIn %s: %v", username, err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+resp.GetToken())
+}
+
+func (s *testServer) createList(t *testing.T, ctx context.Context, title string) int64 {
+	t.Helper()
+	resp, err := s.lists.CreateList(ctx, &todov1.CreateListRequest{Title: title})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	return resp.GetId()
+}
+
+func (s *testServer) createItem(t *testing.T, ctx context.Context, listId int64, title string) int64 {
+	t.Helper()
+	resp, err := s.items.CreateItem(ctx, &todov1.CreateItemRequest{ListId: listId, Title: title})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	return resp.GetId()
+}
+
+// watch открывает стрим и ждет заголовков: сервер отправляет их, когда уже подписал стрим на события
+func (s *testServer) watch(t *testing.T, ctx context.Context, listId int64) todov1.ItemService_WatchItemsClient {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second) // пропущенное событие должно ронять тест, а не вешать его
+	t.Cleanup(cancel)
+	stream, err := s.items.WatchItems(ctx, &todov1.WatchItemsRequest{ListId: listId})
+	if err != nil {
+		t.Fatalf("WatchItems: %v", err)
+	}
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("WatchItems header: %v", err)
+	}
+	return stream
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	s := newTestServer(t)
+	ctx := s.signIn(t, "alice") // SignUp и SignIn доступны без токена
+
+	if _, err := s.lists.GetAllLists(ctx, &todov1.GetAllListsRequest{}); err != nil {
+		t.Errorf("GetAllLists with a token: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"no metadata", context.Background()},
+		{"malformed", metadata.AppendToOutgoingContext(context.Background(), "authorization", "token")},
+		{"forged token", metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer forged")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.lists.GetAllLists(tt.ctx, &todov1.GetAllListsRequest{}); status.Code(err) != codes.Unauthenticated {
+				t.Errorf("GetAllLists: got %v, want Unauthenticated", err)
+			}
+
+			stream, err := s.items.WatchItems(tt.ctx, &todov1.WatchItemsRequest{})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			if status.Code(err) != codes.Unauthenticated {
+				t.Errorf("WatchItems: got %v, want Unauthenticated", err)
+			}
+		})
+	}
+}
+
+func TestWatchItems(t *testing.T) {
+	s := newTestServer(t)
+	alice, bob := s.signIn(t, "alice"), s.signIn(t, "bob")
+	groceries := s.createList(t, alice, "groceries")
+	work := s.createList(t, alice, "work")
+	bobs := s.createList(t, bob, "bob's")
+
+	all := s.watch(t, alice, 0)
+	one := s.watch(t, alice, groceries)
+
+	s.createItem(t, bob, bobs, "not for alice")
+	s.createItem(t, alice, work, "report")
+	s.createItem(t, alice, groceries, "milk")
+
+	// стрим всех списков пропускает чужой список, стрим одного списка - остальные списки
+	for _, want := range []struct {
+		stream todov1.ItemService_WatchItemsClient
+		listId int64
+		title  string
+	}{{all, work, "report"}, {all, groceries, "milk"}, {one, groceries, "milk"}} {
+		event, err := want.stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if event.GetType() != todov1.WatchItemsResponse_TYPE_CREATED || event.GetListId() != want.listId || event.GetItem().GetTitle() != want.title {
+			t.Errorf("Recv: got %v, want %q created in list %d", event, want.title, want.listId)
+		}
+	}
+
+	foreign, err := s.items.WatchItems(bob, &todov1.WatchItemsRequest{ListId: groceries})
+	if err == nil {
+		_, err = foreign.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("WatchItems on a foreign list: got %v, want NotFound", err)
+	}
+
+	if _, err := s.lists.DeleteList(alice, &todov1.DeleteListRequest{Id: groceries}); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	if _, err := one.Recv(); status.Code(err) != codes.NotFound {
+		t.Errorf("Recv after the list was deleted: got %v, want NotFound", err)
+	}
+}
+
+// TestWatchItemsAccessRevoked - права проверяются на каждое событие, а не только при подписке
+func TestWatchItemsAccessRevoked(t *testing.T) {
+	s := newTestServer(t)
+	alice, bob := s.signIn(t, "alice"), s.signIn(t, "bob")
+	listId := s.createList(t, alice, "groceries")
+
+	all := s.watch(t, alice, 0)
+	one := s.watch(t, alice, listId)
+
+	if err := s.services.Admin.TransferList(context.Background(), int(listId), "alice", "bob"); err != nil {
+		t.Fatalf("TransferList: %v", err)
+	}
+	s.createItem(t, bob, listId, "secret")
+	marker := s.createList(t, alice, "marker")
+	s.createItem(t, alice, marker, "visible")
+
+	if _, err := one.Recv(); status.Code(err) != codes.NotFound {
+		t.Errorf("Recv on a list that is no longer shared: got %v, want NotFound", err)
+	}
+	event, err := all.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	if event.GetItem().GetTitle() != "visible" {
+		t.Errorf("Recv: got %v, want only the item from alice's own list", event)
+	}
+}
@@ -0,0 +1,100 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	todov1 "github.com/ponomare0v/todo-go-app/pkg/api/todo/v1"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type ctxKey int
+
+const userCtx ctxKey = iota
+
+// методы, доступные без токена - аналог группы /auth в REST
+var publicMethods = map[string]bool{
+	todov1.AuthService_SignUp_FullMethodName:          true,
+	todov1.AuthService_SignIn_FullMethodName:          true,
+	todov1.AuthService_SignInTwoFactor_FullMethodName: true,
+}
+
+func (h *Handler) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	ctx, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (h *Handler) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if publicMethods[info.FullMethod] {
+		return handler(srv, ss)
+	}
+
+	ctx, err := h.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// unaryTimeout ставит серверный дедлайн на вызов; context.WithTimeout не продлевает более ранний дедлайн клиента
+func (h *Handler) unaryTimeout(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if h.cfg.RequestTimeout <= 0 {
+		return handler(ctx, req)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.RequestTimeout)
+	defer cancel()
+
+	return handler(ctx, req)
+}
+
+// authenticate разбирает метаданные authorization: Bearer <token> так же, как userIdentity разбирает заголовок
+func (h *Handler) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "empty authorization metadata")
+	}
+
+	headerParts := strings.Split(values[0], " ")
+	if len(headerParts) != 2 {
+		return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata")
+	}
+
+	session, err := h.services.Authorization.ParseToken(ctx, headerParts[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return context.WithValue(service.WithSession(ctx, session), userCtx, session.UserId), nil
+}
+
+func getUserId(ctx context.Context) (int, error) {
+	userId, ok := ctx.Value(userCtx).(int)
+	if !ok {
+		return 0, status.Error(codes.Internal, errors.New("user id not found in context").Error())
+	}
+	return userId, nil
+}
+
+// authenticatedStream подменяет контекст стрима на контекст с id пользователя
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,161 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	todov1 "github.com/ponomare0v/todo-go-app/pkg/api/todo/v1"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *Handler) CreateItem(ctx context.Context, req *todov1.CreateItemRequest) (*todov1.CreateItemResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := h.services.TodoItem.Create(ctx, userId, int(req.GetListId()), models.TodoItem{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		DueDate:     timeFromProto(req.GetDueDate()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.CreateItemResponse{Id: int64(id)}, nil
+}
+
+func (h *Handler) GetAllItems(ctx context.Context, req *todov1.GetAllItemsRequest) (*todov1.GetAllItemsResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := h.services.TodoItem.GetAll(ctx, userId, int(req.GetListId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &todov1.GetAllItemsResponse{Items: make([]*todov1.TodoItem, 0, len(items))}
+	for _, item := range items {
+		resp.Items = append(resp.Items, itemToProto(item))
+	}
+	return resp, nil
+}
+
+func (h *Handler) GetItem(ctx context.Context, req *todov1.GetItemRequest) (*todov1.GetItemResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := h.services.TodoItem.GetById(ctx, userId, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.GetItemResponse{Item: itemToProto(item)}, nil
+}
+
+func (h *Handler) UpdateItem(ctx context.Context, req *todov1.UpdateItemRequest) (*todov1.UpdateItemResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input := models.UpdateItemInput{Title: req.Title, Description: req.Description, Done: req.Done, DueDate: timeFromProto(req.GetDueDate())}
+	if err := h.services.TodoItem.Update(ctx, userId, int(req.GetId()), input); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.UpdateItemResponse{}, nil
+}
+
+func (h *Handler) DeleteItem(ctx context.Context, req *todov1.DeleteItemRequest) (*todov1.DeleteItemResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.services.TodoItem.Delete(ctx, userId, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.DeleteItemResponse{}, nil
+}
+
+// WatchItems держит стрим открытым, пока клиент не отключится или сервер не начнет остановку.
+// Стрим одного списка завершается с NotFound, когда пользователь теряет доступ к списку или список удаляют.
+func (h *Handler) WatchItems(req *todov1.WatchItemsRequest, stream grpc.ServerStreamingServer[todov1.WatchItemsResponse]) error {
+	ctx := stream.Context()
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return err
+	}
+
+	// канал закроется сам, когда клиент отключится и ctx стрима будет отменен
+	events, err := h.services.TodoItem.Subscribe(ctx, userId, int(req.GetListId()))
+	if err != nil {
+		return toStatus(err)
+	}
+	// заголовки уходят сразу после подписки: получив их, клиент знает, что следующие изменения попадут в стрим
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for event := range events {
+		if err := stream.Send(eventToProto(event)); err != nil {
+			return err
+		}
+	}
+	// без отмены ctx канал закрывается, только если список стал недоступен
+	if ctx.Err() == nil {
+		return status.Error(codes.NotFound, "list is no longer available")
+	}
+	return nil
+}
+
+func itemToProto(item models.TodoItem) *todov1.TodoItem {
+	return &todov1.TodoItem{
+		Id:          int64(item.Id),
+		Title:       item.Title,
+		Description: item.Description,
+		Done:        item.Done,
+		DueDate:     timeToProto(item.DueDate),
+	}
+}
+
+func timeFromProto(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func timeToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+var eventTypes = map[string]todov1.WatchItemsResponse_Type{
+	models.ItemCreated: todov1.WatchItemsResponse_TYPE_CREATED,
+	models.ItemUpdated: todov1.WatchItemsResponse_TYPE_UPDATED,
+	models.ItemDeleted: todov1.WatchItemsResponse_TYPE_DELETED,
+}
+
+func eventToProto(event models.ItemEvent) *todov1.WatchItemsResponse {
+	return &todov1.WatchItemsResponse{
+		Type:   eventTypes[event.Type],
+		ListId: int64(event.ListId),
+		Item:   itemToProto(event.Item),
+	}
+}
@@ -0,0 +1,92 @@
+package grpcapi
+
+import (
+	"context"
+
+	todov1 "github.com/ponomare0v/todo-go-app/pkg/api/todo/v1"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+func (h *Handler) CreateList(ctx context.Context, req *todov1.CreateListRequest) (*todov1.CreateListResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := h.services.TodoList.Create(ctx, userId, models.TodoList{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.CreateListResponse{Id: int64(id)}, nil
+}
+
+func (h *Handler) GetAllLists(ctx context.Context, req *todov1.GetAllListsRequest) (*todov1.GetAllListsResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := h.services.TodoList.GetAll(ctx, userId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &todov1.GetAllListsResponse{Lists: make([]*todov1.TodoList, 0, len(lists))}
+	for _, list := range lists {
+		resp.Lists = append(resp.Lists, listToProto(list))
+	}
+	return resp, nil
+}
+
+func (h *Handler) GetList(ctx context.Context, req *todov1.GetListRequest) (*todov1.GetListResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := h.services.TodoList.GetById(ctx, userId, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.GetListResponse{List: listToProto(list)}, nil
+}
+
+func (h *Handler) UpdateList(ctx context.Context, req *todov1.UpdateListRequest) (*todov1.UpdateListResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	input := models.UpdateListInput{Title: req.Title, Description: req.Description}
+	if err := h.services.TodoList.Update(ctx, userId, int(req.GetId()), input); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.UpdateListResponse{}, nil
+}
+
+func (h *Handler) DeleteList(ctx context.Context, req *todov1.DeleteListRequest) (*todov1.DeleteListResponse, error) {
+	userId, err := getUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.services.TodoList.Delete(ctx, userId, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &todov1.DeleteListResponse{}, nil
+}
+
+func listToProto(list models.TodoList) *todov1.TodoList {
+	return &todov1.TodoList{
+		Id:          int64(list.Id),
+		Title:       list.Title,
+		Description: list.Description,
+	}
+}
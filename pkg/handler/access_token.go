@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type accessTokenInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes" example:"read,items:write"`
+	ExpiresAt *time.Time `json:"expires_at"` // пусто - бессрочный токен
+}
+
+type getAccessTokensResponse struct {
+	Data []models.AccessToken `json:"data"`
+}
+
+// @Summary Create personal access token
+// @Security ApiKeyAuth
+// @Tags tokens
+// @Description create a long-lived token for scripts, sent as "Authorization: Bearer todo_pat_..." instead of a JWT.
+// @Description Scopes: read (lists, items, export, import status), lists:write, items:write. The token is returned only once.
+// @Description Tokens can not manage tokens, app passwords or the calendar feed.
+// @ID create-access-token
+// @Accept json
+// @Produce json
+// @Param input body accessTokenInput true "token name, scopes and optional expiry"
+// @Success 201 {object} models.AccessToken
+// @Failure 400,403 {object} errorResponse
+// @Failure 422 {object} validationErrorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/tokens [post]
+func (h *Handler) createAccessToken(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input accessTokenInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.services.AccessToken.CreateAccessToken(c.Request.Context(), userId, models.AccessToken{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// @Summary Get personal access tokens
+// @Security ApiKeyAuth
+// @Tags tokens
+// @Description get personal access tokens of the authenticated user with their scopes, expiry and last use, without the tokens themselves
+// @ID get-access-tokens
+// @Produce json
+// @Success 200 {object} getAccessTokensResponse
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/tokens [get]
+func (h *Handler) getAccessTokens(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	tokens, err := h.services.AccessToken.GetAccessTokens(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getAccessTokensResponse{
+		Data: tokens,
+	})
+}
+
+// @Summary Revoke personal access token
+// @Security ApiKeyAuth
+// @Tags tokens
+// @Description revoke the token, requests with it get 401 from now on
+// @ID delete-access-token
+// @Produce json
+// @Param id path int true "access token id"
+// @Success 200 {object} statusResponse
+// @Failure 400,403,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/tokens/{id} [delete]
+func (h *Handler) deleteAccessToken(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.AccessToken.DeleteAccessToken(c.Request.Context(), userId, id); err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
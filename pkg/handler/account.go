@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/formats"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// @Summary Get profile
+// @Security ApiKeyAuth
+// @Tags account
+// @Description get the profile of the signed-in user
+// @ID get-profile
+// @Produce json
+// @Success 200 {object} models.Profile
+// @Failure 401,403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me [get]
+func (h *Handler) getProfile(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	profile, err := h.services.Account.GetProfile(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Update profile
+// @Security ApiKeyAuth
+// @Tags account
+// @Description change the display name; the username can not be changed
+// @ID update-profile
+// @Accept json
+// @Produce json
+// @Param input body models.UpdateProfileInput true "profile info"
+// @Success 200 {object} models.Profile
+// @Failure 400,401,403 {object} errorResponse
+// @Failure 422 {object} validationErrorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me [put]
+func (h *Handler) updateProfile(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input models.UpdateProfileInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile, err := h.services.Account.UpdateProfile(c.Request.Context(), userId, input)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Change password
+// @Security ApiKeyAuth
+// @Tags account
+// @Description change the password and sign out all other sessions: previously issued tokens stop working.
+// @Description The response contains a new token for the current client. Personal access tokens and app passwords are kept.
+// @Description current_password may be omitted within 10 minutes after an OIDC sign-in: this sets the first password
+// @Description of a user provisioned by OIDC.
+// @ID change-password
+// @Accept json
+// @Produce json
+// @Param input body models.ChangePasswordInput true "current and new password"
+// @Success 200 {object} signInResponse "new token"
+// @Failure 400,401,403 {object} errorResponse
+// @Failure 422 {object} validationErrorResponse "invalid new password or wrong current password"
+// @Failure 429 {object} errorResponse "too many failed attempts, see Retry-After"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/password [put]
+func (h *Handler) changePassword(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input models.ChangePasswordInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.services.Account.ChangePassword(c.Request.Context(), userId, input)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, signInResponse{Token: token})
+}
+
+// @Summary Delete account
+// @Security ApiKeyAuth
+// @Tags account
+// @Description delete the user with all tokens, app passwords and lists no one else uses. Shared lists stay with
+// @Description the other users. Requires the current password or a two-factor code; both may be omitted
+// @Description within 10 minutes after an OIDC sign-in.
+// @ID delete-account
+// @Accept json
+// @Produce json
+// @Param input body models.DeleteAccountInput true "current password or two-factor code"
+// @Success 200 {object} statusResponse
+// @Failure 400,401,403 {object} errorResponse
+// @Failure 422 {object} validationErrorResponse "wrong password or code"
+// @Failure 429 {object} errorResponse "too many failed attempts, see Retry-After"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me [delete]
+func (h *Handler) deleteAccount(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input models.DeleteAccountInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.Account.DeleteAccount(c.Request.Context(), userId, input); err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary Export account data
+// @Security ApiKeyAuth
+// @Tags account
+// @Description download a ZIP archive with everything stored about the user: account.json (profile, linked
+// @Description OIDC accounts, 2FA status, access tokens, app passwords and import jobs, without secrets)
+// @Description and lists.json (all lists with items in the /api/export format, can be imported back with /api/import)
+// @ID export-account
+// @Produce application/zip
+// @Success 200 {file} file "ZIP archive"
+// @Failure 401,403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/export [get]
+func (h *Handler) exportAccount(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	account, err := h.services.Account.ExportAccount(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	// архив пишется потоком: после первого файла ответить ошибкой уже нельзя, остается оборвать ответ
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="todo-account.zip"`)
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	if err := h.writeAccountArchive(c, archive, userId, account); err != nil {
+		logging.FromContext(c.Request.Context()).Errorf("account export: %s", err.Error())
+		c.Abort()
+		return
+	}
+	if err := archive.Close(); err != nil {
+		logging.FromContext(c.Request.Context()).Errorf("account export: %s", err.Error())
+	}
+}
+
+// writeAccountArchive пишет account.json и lists.json; время изменения файлов - время выгрузки
+func (h *Handler) writeAccountArchive(c *gin.Context, archive *zip.Writer, userId int, account models.AccountExport) error {
+	create := func(name string) (io.Writer, error) {
+		return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: account.ExportedAt})
+	}
+
+	w, err := create("account.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(account); err != nil {
+		return err
+	}
+
+	if w, err = create("lists.json"); err != nil {
+		return err
+	}
+	lists, err := formats.NewEncoder(formats.JSON, w)
+	if err != nil {
+		return err
+	}
+	if err := h.services.Backup.Export(c.Request.Context(), userId, lists.Encode); err != nil {
+		return err
+	}
+	return lists.Close()
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+// TestConfirmIdentityLockout - неверный пароль или код при смене пароля и удалении аккаунта блокирует вход так же, как при входе
+func TestConfirmIdentityLockout(t *testing.T) {
+	const threshold = 3
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"change password", http.MethodPut, "/api/me/password", `{"current_password":"wrong","new_password":"secret456"}`},
+		{"delete account with password", http.MethodDelete, "/api/me", `{"password":"wrong"}`},
+		{"delete account with code", http.MethodDelete, "/api/me", `{"code":"000000"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore(),
+				ratelimit.LockoutPolicy{Threshold: threshold, Base: time.Minute, Window: time.Minute})
+			router := newTestRouter(t, service.Config{Lockout: lockout}, Config{})
+			token := signUpToken(t, router, "alice")
+
+			for i := 1; i < threshold; i++ {
+				if rec := serve(router, tt.method, tt.path, token, "application/json", tt.body); rec.Code != http.StatusUnprocessableEntity {
+					t.Fatalf("attempt %d: got %d %s, want 422", i, rec.Code, rec.Body)
+				}
+			}
+			rec := serve(router, tt.method, tt.path, token, "application/json", tt.body)
+			if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+				t.Fatalf("attempt %d: got %d %s, want 429 with Retry-After", threshold, rec.Code, rec.Body)
+			}
+
+			// во время блокировки не проверяется и верный пароль, и вход
+			if rec := serve(router, http.MethodPut, "/api/me/password", token, "application/json",
+				`{"current_password":"secret123","new_password":"secret456"}`); rec.Code != http.StatusTooManyRequests {
+				t.Errorf("right password while locked: got %d %s, want 429", rec.Code, rec.Body)
+			}
+			if rec := serve(router, http.MethodPost, "/auth/sign-in", "", "application/json",
+				`{"username":"alice","password":"secret123"}`); rec.Code != http.StatusTooManyRequests {
+				t.Errorf("sign-in while locked: got %d %s, want 429", rec.Code, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type appPasswordInput struct {
+	Name string `json:"name"`
+}
+
+type getAppPasswordsResponse struct {
+	Data []models.AppPassword `json:"data"`
+}
+
+// @Summary Create app password
+// @Security ApiKeyAuth
+// @Tags app-passwords
+// @Description create a password for HTTP Basic sign-in of CalDAV clients; the password is returned only once
+// @ID create-app-password
+// @Accept json
+// @Produce json
+// @Param input body appPasswordInput true "device or application name"
+// @Success 201 {object} models.AppPassword
+// @Failure 400 {object} errorResponse
+// @Failure 422 {object} validationErrorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/app-passwords [post]
+func (h *Handler) createAppPassword(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input appPasswordInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	password, err := h.services.CalDAV.CreateAppPassword(c.Request.Context(), userId, models.AppPassword{Name: input.Name})
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, password)
+}
+
+// @Summary Get app passwords
+// @Security ApiKeyAuth
+// @Tags app-passwords
+// @Description get app passwords of the authenticated user, without the passwords themselves
+// @ID get-app-passwords
+// @Produce json
+// @Success 200 {object} getAppPasswordsResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/app-passwords [get]
+func (h *Handler) getAppPasswords(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	passwords, err := h.services.CalDAV.GetAppPasswords(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getAppPasswordsResponse{
+		Data: passwords,
+	})
+}
+
+// @Summary Revoke app password
+// @Security ApiKeyAuth
+// @Tags app-passwords
+// @Description revoke the app password, clients using it can no longer sign in
+// @ID delete-app-password
+// @Produce json
+// @Param id path int true "app password id"
+// @Success 200 {object} statusResponse
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/app-passwords/{id} [delete]
+func (h *Handler) deleteAppPassword(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	if err := h.services.CalDAV.DeleteAppPassword(c.Request.Context(), userId, id); err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
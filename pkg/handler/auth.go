@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
 )
 
 // @Summary SignUp
@@ -16,6 +18,8 @@ import (
 // @Param input body models.User true "account info"
 // @Success 200 {integer} integer 1
 // @Failure 400,404 {object} errorResponse
+// @Failure 422 {object} validationErrorResponse
+// @Failure 429 {object} errorResponse "rate limit exceeded, see Retry-After"
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /auth/sign-up [post]
@@ -25,12 +29,13 @@ func (h *Handler) signUp(c *gin.Context) {
 	//парсим тело запроса и валидируем его
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	//передаем на слой ниже в сервис, из которого получаем id созданного юзера в бд
-	id, err := h.services.Authorization.CreateUser(input)
+	id, err := h.services.Authorization.CreateUser(c.Request.Context(), input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error()) //внутрення ошибка на сервере
+		newServiceErrorResponse(c, err) //внутрення ошибка на сервере или ошибка валидации
 		return
 	}
 
@@ -51,15 +56,23 @@ type signInInput struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// signInResponse - token или, если у пользователя включена 2FA, challenge_token для /auth/sign-in/2fa
+type signInResponse struct {
+	Token          string `json:"token,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
 // @Summary SignIn
 // @Tags auth
-// @Description authenticate user and return token
+// @Description authenticate user and return token. If two-factor authentication is enabled, challenge_token
+// @Description is returned instead: pass it with a code to /auth/sign-in/2fa within 5 minutes.
 // @ID sign-in
 // @Accept json
 // @Produce json
 // @Param input body signInInput true "credentials"
-// @Success 200 {object} map[string]string "token"
+// @Success 200 {object} signInResponse
 // @Failure 400,404 {object} errorResponse
+// @Failure 429 {object} errorResponse "rate limit exceeded or too many failed attempts, see Retry-After"
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /auth/sign-in [post]
@@ -68,16 +81,56 @@ func (h *Handler) signIn(c *gin.Context) {
 
 	if err := c.BindJSON(&input); err != nil {
 		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	token, err := h.services.Authorization.GenerateToken(input.Username, input.Password)
+	token, err := h.services.Authorization.GenerateToken(c.Request.Context(), input.Username, input.Password)
+	var twoFactorRequired *service.TwoFactorRequiredError
+	if errors.As(err, &twoFactorRequired) {
+		c.JSON(http.StatusOK, signInResponse{ChallengeToken: twoFactorRequired.ChallengeToken})
+		return
+	}
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
 	// возврат токена
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"token": token,
-	})
+	c.JSON(http.StatusOK, signInResponse{Token: token})
+}
+
+type signIn2FAInput struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"` // код из приложения или код восстановления
+}
+
+// @Summary SignIn2FA
+// @Tags auth
+// @Description finish sign-in with two-factor authentication: challenge_token from /auth/sign-in
+// @Description and a code from the authenticator app or a one-time recovery code
+// @ID sign-in-2fa
+// @Accept json
+// @Produce json
+// @Param input body signIn2FAInput true "challenge and code"
+// @Success 200 {object} signInResponse
+// @Failure 400,401 {object} errorResponse
+// @Failure 429 {object} errorResponse "rate limit exceeded or too many failed attempts, see Retry-After"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /auth/sign-in/2fa [post]
+func (h *Handler) signIn2FA(c *gin.Context) {
+	var input signIn2FAInput
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.services.Authorization.GenerateTokenWithCode(c.Request.Context(), input.ChallengeToken, input.Code)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, signInResponse{Token: token})
 }
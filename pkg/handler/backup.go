@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/formats"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// ограничение на размер файла импорта: весь файл разбирается в памяти
+const maxImportSize = 10 << 20
+
+// importErrorResponse - ответ 422: отчет импорта с ошибками по каждой записи, ничего не создано
+type importErrorResponse struct {
+	Message string
+	models.ImportReport
+}
+
+// newImportErrorResponse отвечает 422: ошибки разбора файла и проверки записей вместе, по порядку строк
+func newImportErrorResponse(c *gin.Context, report models.ImportReport, decodeErrors []models.ImportError) {
+	if len(decodeErrors) > 0 {
+		report.Errors = append(decodeErrors, report.Errors...)
+		sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	}
+	logging.FromContext(c.Request.Context()).Errorf("import failed: %d invalid records", len(report.Errors))
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, importErrorResponse{Message: "import failed", ImportReport: report})
+}
+
+// @Summary Export lists and items
+// @Security ApiKeyAuth
+// @Tags backup
+// @Description stream all lists the user can access with their items as JSON, CSV, todo.txt or a Markdown checklist
+// @ID export
+// @Produce json,text/csv,text/plain,text/markdown
+// @Param format query string false "export format" Enums(json, csv, todotxt, markdown) default(json)
+// @Success 200 {object} models.Backup "lists with items"
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/export [get]
+func (h *Handler) exportData(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	format := c.DefaultQuery("format", formats.JSON)
+	encoder, err := formats.NewEncoder(format, c.Writer)
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "format must be json, csv, todotxt or markdown")
+		return
+	}
+
+	// заголовки отправляются с первым списком: пока ничего не записано, на ошибку еще можно ответить кодом
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		c.Header("Content-Type", formats.ContentType(format))
+		c.Header("Content-Disposition", `attachment; filename="todo-export.`+formats.Extension(format)+`"`)
+		c.Status(http.StatusOK)
+	}
+
+	err = h.services.Backup.Export(c.Request.Context(), userId, func(list models.ListBackup) error {
+		start()
+		return encoder.Encode(list)
+	})
+	if err != nil && !started {
+		newServiceErrorResponse(c, err)
+		return
+	}
+	if err != nil {
+		// ответ уже начат - остается его оборвать, клиент получит недописанный документ
+		logging.FromContext(c.Request.Context()).Errorf("export: %s", err.Error())
+		c.Abort()
+		return
+	}
+
+	start()
+	if err := encoder.Close(); err != nil {
+		logging.FromContext(c.Request.Context()).Errorf("export: %s", err.Error())
+	}
+}
+
+// @Summary Import lists and items
+// @Security ApiKeyAuth
+// @Tags backup
+// @Description create lists and items from a JSON, CSV, todo.txt or Markdown checklist file in a single transaction.
+// @Description With dry_run=true nothing is created and the report shows what would be.
+// @Description If any record is invalid nothing is created and the report lists errors per record (row for CSV, todo.txt and Markdown).
+// @ID import
+// @Accept json,text/csv,text/plain,text/markdown
+// @Produce json
+// @Param format query string false "import format, by default taken from Content-Type" Enums(json, csv, todotxt, markdown)
+// @Param dry_run query bool false "only validate and report"
+// @Param input body models.Backup true "lists with items"
+// @Success 200 {object} models.ImportReport "dry run report"
+// @Success 201 {object} models.ImportReport "created lists"
+// @Failure 400,413,415 {object} errorResponse
+// @Failure 422 {object} importErrorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/import [post]
+func (h *Handler) importData(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		format = formats.FromContentType(mediaType)
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "dry_run must be true or false")
+		return
+	}
+
+	decoded, err := formats.Decode(format, http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, formats.ErrUnsupportedFormat):
+		newErrorResponse(c, http.StatusUnsupportedMediaType, "import format must be json, csv, todotxt or markdown")
+		return
+	case errors.As(err, &tooLarge):
+		newErrorResponse(c, http.StatusRequestEntityTooLarge, "import file is too large")
+		return
+	case err != nil:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// если часть записей не разобралась, создавать нельзя, но остальные все равно проверяем - отчет должен быть полным
+	report, err := h.services.Backup.Import(c.Request.Context(), userId, decoded.Lists, dryRun || len(decoded.Errors) > 0)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+	report.DryRun = dryRun
+
+	if len(decoded.Errors) > 0 || len(report.Errors) > 0 {
+		newImportErrorResponse(c, report, decoded.Errors)
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,396 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/formats"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/sirupsen/logrus"
+)
+
+// CalDAV-дерево: /caldav/ - принципал пользователя, /caldav/lists/ - домашняя коллекция,
+// /caldav/lists/<id>/ - календарь списка, /caldav/lists/<id>/<name>.ics - задача
+const (
+	caldavPrincipalPath = "/caldav/"
+	caldavHomePath      = "/caldav/lists/"
+
+	caldavObjectContentType = "text/calendar; charset=utf-8; component=VTODO"
+	maxCalDAVObjectBytes    = 1 << 20
+)
+
+func caldavCalendarPath(listId int) string {
+	return caldavHomePath + strconv.Itoa(listId) + "/"
+}
+
+// caldavIdentity - аутентификация CalDAV-клиентов по HTTP Basic: логин и пароль приложения
+func (h *Handler) caldavIdentity(c *gin.Context) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		caldavUnauthorized(c, "basic authorization required")
+		return
+	}
+
+	userId, err := h.services.CalDAV.Authenticate(c.Request.Context(), username, password)
+	if errors.Is(err, service.ErrInvalidAppPassword) {
+		caldavUnauthorized(c, err.Error())
+		return
+	}
+	if err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+
+	c.Set(userCtx, userId)
+	c.Request = c.Request.WithContext(logging.WithFields(c.Request.Context(), logrus.Fields{
+		"user_id": userId,
+	}))
+}
+
+func caldavUnauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Basic realm="todo-go-app", charset="UTF-8"`)
+	davErrorResponse(c, http.StatusUnauthorized, message)
+}
+
+// caldavServiceError подбирает код ответа для ошибки сервиса; WebDAV использует 422 для невалидных данных (RFC 4918, раздел 11.2)
+func caldavServiceError(c *gin.Context, err error) {
+	status := errorStatus(err)
+	var validationErrs models.ValidationErrors
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, service.ErrObjectNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrObjectConflict):
+		status = http.StatusConflict
+	case errors.Is(err, formats.ErrInvalidICal):
+		status = http.StatusBadRequest
+	case errors.As(err, &validationErrs):
+		status = http.StatusUnprocessableEntity
+	}
+	davErrorResponse(c, status, err.Error())
+}
+
+// caldavWellKnown направляет клиентов, которым дали только адрес сервера, к корню CalDAV (RFC 6764)
+func (h *Handler) caldavWellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, caldavPrincipalPath)
+}
+
+func (h *Handler) caldavOptions(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) caldavPropfindPrincipal(c *gin.Context) {
+	req, ok := parsePropfind(c)
+	if !ok {
+		return
+	}
+
+	resources := []davResource{{href: caldavPrincipalPath, props: davProps{
+		{Space: davNS, Local: "resourcetype"}:               "<D:principal/>",
+		{Space: davNS, Local: "current-user-principal"}:     davHref(caldavPrincipalPath),
+		{Space: davNS, Local: "principal-URL"}:              davHref(caldavPrincipalPath),
+		{Space: caldavNS, Local: "calendar-home-set"}:       davHref(caldavHomePath),
+		{Space: davNS, Local: "current-user-privilege-set"}: "<D:privilege><D:read/></D:privilege>",
+	}}}
+	if davDepth(c) > 0 {
+		resources = append(resources, caldavHomeResource())
+	}
+	writeMultistatus(c, req, resources, nil)
+}
+
+func (h *Handler) caldavPropfindHome(c *gin.Context) {
+	req, ok := parsePropfind(c)
+	if !ok {
+		return
+	}
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	resources := []davResource{caldavHomeResource()}
+	if davDepth(c) > 0 {
+		calendars, err := h.services.CalDAV.GetCalendars(c.Request.Context(), userId)
+		if err != nil {
+			caldavServiceError(c, err)
+			return
+		}
+		for _, calendar := range calendars {
+			resources = append(resources, caldavCalendarResource(calendar))
+		}
+	}
+	writeMultistatus(c, req, resources, nil)
+}
+
+func (h *Handler) caldavPropfindCalendar(c *gin.Context) {
+	req, ok := parsePropfind(c)
+	if !ok {
+		return
+	}
+	userId, listId, ok := caldavListParams(c)
+	if !ok {
+		return
+	}
+
+	calendar, objects, err := h.services.CalDAV.GetCalendar(c.Request.Context(), userId, listId)
+	if err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+
+	resources := []davResource{caldavCalendarResource(calendar)}
+	if davDepth(c) > 0 {
+		stamp := time.Now()
+		for _, object := range objects {
+			resources = append(resources, caldavObjectResource(calendar.List, object, stamp))
+		}
+	}
+	writeMultistatus(c, req, resources, nil)
+}
+
+// caldavReport отвечает на calendar-multiget и calendar-query. У calendar-query учитывается только
+// фильтр по компонентам: запрос событий (VEVENT) получает пустой ответ, остальные условия (time-range
+// и т.п.) не проверяются, и клиент получает все задачи списка - это допустимое надмножество.
+func (h *Handler) caldavReport(c *gin.Context) {
+	var report davReport
+	empty, err := readDAVBody(c, &report)
+	if err != nil || empty {
+		davErrorResponse(c, http.StatusBadRequest, "invalid REPORT body")
+		return
+	}
+	if report.XMLName.Space != caldavNS || (report.XMLName.Local != "calendar-multiget" && report.XMLName.Local != "calendar-query") {
+		davErrorResponse(c, http.StatusForbidden, "unsupported report "+report.XMLName.Local)
+		return
+	}
+
+	userId, listId, ok := caldavListParams(c)
+	if !ok {
+		return
+	}
+	calendar, objects, err := h.services.CalDAV.GetCalendar(c.Request.Context(), userId, listId)
+	if err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+
+	req := davPropRequest{names: report.Prop}
+	stamp := time.Now()
+	var resources []davResource
+
+	if report.XMLName.Local == "calendar-query" {
+		if report.Filter == nil || matchesTodo(report.Filter.CompFilter) {
+			for _, object := range objects {
+				resources = append(resources, caldavObjectResource(calendar.List, object, stamp))
+			}
+		}
+		writeMultistatus(c, req, resources, nil)
+		return
+	}
+
+	byName := make(map[string]models.CalendarObject, len(objects))
+	for _, object := range objects {
+		byName[object.Name] = object
+	}
+	var missing []string
+	for _, href := range report.Hrefs {
+		name, ok := caldavObjectName(href, listId)
+		object, found := byName[name]
+		if !ok || !found {
+			missing = append(missing, href)
+			continue
+		}
+		resources = append(resources, caldavObjectResource(calendar.List, object, stamp))
+	}
+	writeMultistatus(c, req, resources, missing)
+}
+
+// caldavDeleteCalendar удаляет список целиком, как DELETE /api/lists/:id
+func (h *Handler) caldavDeleteCalendar(c *gin.Context) {
+	userId, listId, ok := caldavListParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.TodoList.Delete(c.Request.Context(), userId, listId); err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) caldavPropfindObject(c *gin.Context) {
+	req, ok := parsePropfind(c)
+	if !ok {
+		return
+	}
+	userId, listId, ok := caldavListParams(c)
+	if !ok {
+		return
+	}
+
+	list, object, err := h.services.CalDAV.GetObject(c.Request.Context(), userId, listId, c.Param("object"))
+	if err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+	writeMultistatus(c, req, []davResource{caldavObjectResource(list, object, time.Now())}, nil)
+}
+
+func (h *Handler) caldavGetObject(c *gin.Context) {
+	userId, listId, ok := caldavListParams(c)
+	if !ok {
+		return
+	}
+
+	list, object, err := h.services.CalDAV.GetObject(c.Request.Context(), userId, listId, c.Param("object"))
+	if err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+
+	etag := `"` + object.ETag + `"`
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, caldavObjectContentType, []byte(caldavObjectData(list, object, time.Now())))
+}
+
+// caldavPutObject создает или заменяет задачу. ETag в ответе не отдается: сервер сохраняет не все
+// свойства VTODO, и клиент должен перечитать ресурс (RFC 4791, раздел 5.3.4)
+func (h *Handler) caldavPutObject(c *gin.Context) {
+	userId, listId, ok := caldavListParams(c)
+	if !ok {
+		return
+	}
+
+	object, err := formats.DecodeICalTodo(http.MaxBytesReader(c.Writer, c.Request.Body, maxCalDAVObjectBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		davErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+
+	created, err := h.services.CalDAV.PutObject(c.Request.Context(), userId, listId, c.Param("object"), object,
+		c.GetHeader("If-Match"), c.GetHeader("If-None-Match"))
+	if err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+
+	if created {
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) caldavDeleteObject(c *gin.Context) {
+	userId, listId, ok := caldavListParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.CalDAV.DeleteObject(c.Request.Context(), userId, listId, c.Param("object"), c.GetHeader("If-Match")); err != nil {
+		caldavServiceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// caldavListParams достает пользователя и id списка; на ошибку ответ уже отправлен
+func caldavListParams(c *gin.Context) (userId, listId int, ok bool) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return 0, 0, false
+	}
+	listId, err = strconv.Atoi(c.Param("id"))
+	if err != nil {
+		davErrorResponse(c, http.StatusNotFound, "invalid id param")
+		return 0, 0, false
+	}
+	return userId, listId, true
+}
+
+// caldavObjectName достает имя ресурса из href запроса calendar-multiget, если он указывает в этот список
+func caldavObjectName(href string, listId int) (string, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+	name, ok := strings.CutPrefix(u.Path, caldavCalendarPath(listId))
+	return name, ok && name != "" && !strings.Contains(name, "/")
+}
+
+// matchesTodo проверяет фильтр calendar-query: VCALENDAR без условий или с условием на VTODO
+func matchesTodo(filter davCompFilter) bool {
+	if filter.Name != "" && !strings.EqualFold(filter.Name, "VCALENDAR") {
+		return false
+	}
+	for _, inner := range filter.Filters {
+		if !strings.EqualFold(inner.Name, "VTODO") {
+			return false
+		}
+	}
+	return true
+}
+
+func caldavHomeResource() davResource {
+	return davResource{href: caldavHomePath, props: davProps{
+		{Space: davNS, Local: "resourcetype"}:           "<D:collection/>",
+		{Space: davNS, Local: "displayname"}:            "Todo",
+		{Space: davNS, Local: "current-user-principal"}: davHref(caldavPrincipalPath),
+		{Space: davNS, Local: "owner"}:                  davHref(caldavPrincipalPath),
+	}}
+}
+
+func caldavCalendarResource(calendar models.Calendar) davResource {
+	return davResource{href: caldavCalendarPath(calendar.List.Id), props: davProps{
+		{Space: davNS, Local: "resourcetype"}:                        "<D:collection/><C:calendar/>",
+		{Space: davNS, Local: "displayname"}:                         davText(calendar.List.Title),
+		{Space: caldavNS, Local: "calendar-description"}:             davText(calendar.List.Description),
+		{Space: caldavNS, Local: "supported-calendar-component-set"}: `<C:comp name="VTODO"/>`,
+		{Space: csNS, Local: "getctag"}:                              davText(calendar.CTag),
+		{Space: davNS, Local: "getetag"}:                             davText(`"` + calendar.CTag + `"`),
+		{Space: davNS, Local: "current-user-principal"}:              davHref(caldavPrincipalPath),
+		{Space: davNS, Local: "owner"}:                               davHref(caldavPrincipalPath),
+		{Space: davNS, Local: "supported-report-set"}: "<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>" +
+			"<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>",
+		{Space: davNS, Local: "current-user-privilege-set"}: "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>",
+	}}
+}
+
+func caldavObjectResource(list models.TodoList, object models.CalendarObject, stamp time.Time) davResource {
+	return davResource{href: caldavCalendarPath(list.Id) + url.PathEscape(object.Name), props: davProps{
+		{Space: davNS, Local: "resourcetype"}:     "",
+		{Space: davNS, Local: "getetag"}:          davText(`"` + object.ETag + `"`),
+		{Space: davNS, Local: "getcontenttype"}:   caldavObjectContentType,
+		{Space: caldavNS, Local: "calendar-data"}: davText(caldavObjectData(list, object, stamp)),
+	}}
+}
+
+// caldavObjectData - ресурс задачи: календарь с единственным VTODO
+func caldavObjectData(list models.TodoList, object models.CalendarObject, stamp time.Time) string {
+	var b strings.Builder
+	encoder := formats.NewICalEncoder(&b, list.Title, stamp)
+	// strings.Builder не возвращает ошибок записи
+	_ = encoder.EncodeTodo(list, object.Item, object.UID)
+	_ = encoder.Close()
+	return b.String()
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/formats"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const feedPath = "/calendar/%s/todo.ics"
+
+type calendarFeedResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"` // ссылка для подписки в календарном клиенте
+}
+
+// @Summary Export todo list as iCalendar
+// @Security ApiKeyAuth
+// @Tags calendar
+// @Description export items of the list as RFC 5545 VTODO entries
+// @ID export-list-ics
+// @Produce text/calendar
+// @Param id path int true "list id"
+// @Success 200 {string} string "iCalendar document"
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/lists/{id}/export.ics [get]
+func (h *Handler) exportListICS(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	listId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	list, err := h.services.TodoList.GetById(c.Request.Context(), userId, listId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+	items, err := h.services.TodoItem.GetAll(c.Request.Context(), userId, listId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", formats.ICalContentType)
+	c.Header("Content-Disposition", `attachment; filename="list-`+strconv.Itoa(listId)+`.ics"`)
+	c.Status(http.StatusOK)
+
+	encoder := formats.NewICalEncoder(c.Writer, list.Title, time.Now())
+	if err := encoder.Encode(list, items); err != nil {
+		logging.FromContext(c.Request.Context()).Errorf("ics export: %s", err.Error())
+		return
+	}
+	if err := encoder.Close(); err != nil {
+		logging.FromContext(c.Request.Context()).Errorf("ics export: %s", err.Error())
+	}
+}
+
+// @Summary Create calendar feed link
+// @Security ApiKeyAuth
+// @Tags calendar
+// @Description issue a secret ICS feed URL with all lists of the user; a previously issued URL stops working
+// @ID create-calendar-feed
+// @Produce json
+// @Success 201 {object} calendarFeedResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/calendar/feed [post]
+func (h *Handler) createCalendarFeed(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	token, err := h.services.Calendar.CreateFeedToken(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, calendarFeedResponse{
+		Token: token,
+		URL:   h.publicURL(c) + fmt.Sprintf(feedPath, token),
+	})
+}
+
+// @Summary Revoke calendar feed link
+// @Security ApiKeyAuth
+// @Tags calendar
+// @Description revoke the ICS feed URL of the user
+// @ID delete-calendar-feed
+// @Produce json
+// @Success 200 {object} statusResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/calendar/feed [delete]
+func (h *Handler) deleteCalendarFeed(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.services.Calendar.DeleteFeedToken(c.Request.Context(), userId); err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
+
+// @Summary Calendar feed
+// @Tags calendar
+// @Description ICS feed with items of all lists of the user. Authorized by the secret token in the path
+// @Description because calendar clients can not send the Authorization header.
+// @ID calendar-feed
+// @Produce text/calendar
+// @Param token path string true "feed token"
+// @Success 200 {string} string "iCalendar document"
+// @Failure 404 {object} errorResponse
+// @Failure 429 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /calendar/{token}/todo.ics [get]
+func (h *Handler) calendarFeed(c *gin.Context) {
+	ctx := c.Request.Context()
+	userId, err := h.services.Calendar.GetFeedUser(ctx, c.Param("token"))
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	// заголовки отправляются с первым списком: пока ничего не записано, на ошибку еще можно ответить кодом
+	started := false
+	start := func() {
+		if started {
+			return
+		}
+		started = true
+		c.Header("Content-Type", formats.ICalContentType)
+		c.Header("Cache-Control", "private")
+		c.Status(http.StatusOK)
+	}
+
+	encoder := formats.NewICalEncoder(c.Writer, "Todo", time.Now())
+	err = h.services.Calendar.GetFeed(ctx, userId, func(list models.TodoList, items []models.TodoItem) error {
+		start()
+		return encoder.Encode(list, items)
+	})
+	if err != nil && !started {
+		newServiceErrorResponse(c, err)
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Errorf("calendar feed: %s", err.Error())
+		c.Abort()
+		return
+	}
+
+	start()
+	if err := encoder.Close(); err != nil {
+		logging.FromContext(ctx).Errorf("calendar feed: %s", err.Error())
+	}
+}
+
+// publicURL - внешний адрес API для ссылок в ответах: Config.PublicURL или, если он не задан, адрес из запроса
+func (h *Handler) publicURL(c *gin.Context) string {
+	if h.cfg.PublicURL != "" {
+		return strings.TrimRight(h.cfg.PublicURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/gql"
+)
+
+// @Summary GraphQL
+// @Security ApiKeyAuth
+// @Tags graphql
+// @Description execute a GraphQL query or mutation over lists and items
+// @ID graphql
+// @Accept json
+// @Produce json
+// @Param input body gql.Request true "GraphQL request"
+// @Success 200 {object} map[string]interface{} "data and errors"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Router /graphql [post]
+func (h *Handler) graphqlQuery(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input gql.Request
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// по соглашению GraphQL ошибки резолверов возвращаются в поле errors с кодом 200
+	c.JSON(http.StatusOK, h.graphql.Execute(c.Request.Context(), userId, input))
+}
+
+// graphqlPlayground отдает страницу GraphiQL, токен передается через редактор заголовков.
+// Маршрут есть, только если включен Config.GraphQLPlayground.
+func (h *Handler) graphqlPlayground(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(playgroundPage))
+}
+
+// версии закреплены точно, а integrity (SRI) не даст браузеру выполнить подмененный на CDN файл
+const playgroundPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8" />
+  <title>Todo App GraphQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@4.1.2/graphiql.min.css"
+    integrity="sha256-MEh+B2NdMSpj9kexQNN3QKc8UzMrCXW/Sx/phcpuyIU=" crossorigin="anonymous" />
+</head>
+<body style="margin: 0;">
+  <div id="graphiql" style="height: 100vh;"></div>
+  <script src="https://unpkg.com/react@18.2.0/umd/react.production.min.js"
+    integrity="sha256-S0lp+k7zWUMk2ixteM6HZvu8L9Eh//OVrt+ZfbCpmgY=" crossorigin="anonymous"></script>
+  <script src="https://unpkg.com/react-dom@18.2.0/umd/react-dom.production.min.js"
+    integrity="sha256-IXWO0ITNDjfnNXIu5POVfqlgYoop36bDzhodR6LW5Pc=" crossorigin="anonymous"></script>
+  <script src="https://unpkg.com/graphiql@4.1.2/graphiql.min.js"
+    integrity="sha256-hnImuor1znlJkD/FOTL3jayfS/xsyNoP04abi8bFJWs=" crossorigin="anonymous"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: '/graphql' });
+    ReactDOM.createRoot(document.getElementById('graphiql')).render(
+      React.createElement(GraphiQL, {
+        fetcher: fetcher,
+        defaultHeaders: '{"Authorization": "Bearer <token from /auth/sign-in>"}',
+      }),
+    );
+  </script>
+</body>
+</html>`
@@ -1,7 +1,13 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/gql"
+	"github.com/ponomare0v/todo-go-app/pkg/health"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
 	"github.com/ponomare0v/todo-go-app/pkg/service"
 
 	_ "github.com/ponomare0v/todo-go-app/docs" // путь до документации
@@ -9,47 +15,177 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 )
 
+// Config - настройки HTTP-слоя
+type Config struct {
+	RequestTimeout time.Duration   // дедлайн на обработку одного запроса, 0 - без ограничения
+	Health         *health.Checker // состояние для проб /healthz, /startupz, /readyz
+
+	AuthLimiter    *ratelimit.Limiter // лимит на /auth/* по IP, nil - без ограничения
+	APILimiter     *ratelimit.Limiter // лимит на /api/* и /graphql по id пользователя, nil - без ограничения
+	TrustedProxies []string           // прокси, которым можно верить в X-Forwarded-For, пусто - никому
+
+	PublicURL      string // внешний адрес API для ссылок на ICS-ленту и адреса возврата OIDC, пусто - берется из запроса
+	OIDCSuccessURL string // куда вернуть браузер после входа через OIDC (токен во фрагменте #token=), пусто - ответ JSON
+
+	GraphQLPlayground bool // отдавать GraphiQL на /graphql/playground; страница грузит скрипты с unpkg.com
+}
+
 type Handler struct {
 	services *service.Service
+	graphql  *gql.Executor
+	health   *health.Checker
+	cfg      Config
 }
 
-func NewHandler(services *service.Service) *Handler {
-	return &Handler{services: services}
+func NewHandler(services *service.Service, cfg Config) *Handler {
+	// схема GraphQL описана статически, ошибка при ее сборке - это ошибка в коде, а не в окружении
+	executor, err := gql.NewExecutor(services)
+	if err != nil {
+		panic(err)
+	}
+
+	return &Handler{services: services, graphql: executor, health: healthOrDefault(cfg.Health), cfg: cfg}
 }
 
 func (h *Handler) InitRoutes() *gin.Engine {
 	router := gin.New()
+	if err := router.SetTrustedProxies(h.cfg.TrustedProxies); err != nil {
+		panic(err) // адреса проверяются при чтении конфигурации
+	}
+
+	// пробы дергаются каждые несколько секунд, поэтому регистрируются до middleware: в логи, метрики и трейсы они не попадают
+	router.GET("/healthz", h.healthz)
+	router.GET("/startupz", h.startupz)
+	router.GET("/readyz", h.readyz)
+
+	router.Use(requestId, tracing, httpMetrics, accessLog, recovery, h.requestTimeout) // порядок важен: трейс, метрики и access log должны увидеть и ответ 500 после паники
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler)) //swag
 
-	auth := router.Group("/auth")
+	authLimit := rateLimit(h.cfg.AuthLimiter, clientIPKey)
+	apiLimit := rateLimit(h.cfg.APILimiter, userKey)
+
+	router.POST("/graphql", h.userIdentity, apiLimit, h.graphqlQuery)
+	if h.cfg.GraphQLPlayground {
+		router.GET("/graphql/playground", h.graphqlPlayground)
+	}
+
+	// календарные клиенты не умеют отправлять Authorization, лента авторизуется токеном в пути.
+	// Лимит по IP, как у /auth, - подбирать токены перебором бессмысленно.
+	router.GET("/calendar/:token/todo.ics", authLimit, h.calendarFeed)
+
+	// CalDAV-клиенты входят по HTTP Basic с паролем приложения, лимит - по пользователю, как у /api.
+	// WebDAV-методы регистрируются через Handle; /.well-known/caldav ведет на корень дерева (RFC 6764).
+	router.GET("/.well-known/caldav", h.caldavWellKnown)
+	router.Handle("PROPFIND", "/.well-known/caldav", h.caldavWellKnown)
+	caldav := router.Group("/caldav", h.caldavIdentity, apiLimit)
+	{
+		for _, path := range []string{"/", "/lists/", "/lists/:id/", "/lists/:id/:object"} {
+			caldav.OPTIONS(path, h.caldavOptions)
+		}
+		caldav.Handle("PROPFIND", "/", h.caldavPropfindPrincipal)
+		caldav.Handle("PROPFIND", "/lists/", h.caldavPropfindHome)
+
+		caldav.Handle("PROPFIND", "/lists/:id/", h.caldavPropfindCalendar)
+		caldav.Handle("REPORT", "/lists/:id/", h.caldavReport)
+		caldav.DELETE("/lists/:id/", h.caldavDeleteCalendar)
+
+		caldav.Handle("PROPFIND", "/lists/:id/:object", h.caldavPropfindObject)
+		caldav.GET("/lists/:id/:object", h.caldavGetObject)
+		caldav.HEAD("/lists/:id/:object", h.caldavGetObject)
+		caldav.PUT("/lists/:id/:object", h.caldavPutObject)
+		caldav.DELETE("/lists/:id/:object", h.caldavDeleteObject)
+	}
+
+	auth := router.Group("/auth", authLimit)
 	{
 		auth.POST("/sign-up", h.signUp)
 		auth.POST("/sign-in", h.signIn)
+		auth.POST("/sign-in/2fa", h.signIn2FA)
+
+		oidc := auth.Group("/oidc")
+		{
+			oidc.GET("/providers", h.getOIDCProviders)
+			oidc.GET("/login", h.oidcLogin)
+			oidc.GET("/callback", h.oidcCallback)
+			oidc.POST("/link", h.oidcLink)
+		}
 	}
 
-	api := router.Group("/api", h.userIdentity) // группа для работы с защищенными эндпоинтами списков и их задачами
+	// персональным токенам доступны только действия из их прав, см. requireScope
+	read, writeLists, writeItems := requireScope(models.ScopeRead), requireScope(models.ScopeListsWrite), requireScope(models.ScopeItemsWrite)
+	api := router.Group("/api", h.userIdentity, apiLimit) // группа для работы с защищенными эндпоинтами списков и их задачами
 	{
 		lists := api.Group("/lists") // группа для работы со списками (создание списка, получение всех, получение по id и удаление)
 		{
-			lists.POST("/", h.createList)
-			lists.GET("/", h.getAllLists)
-			lists.GET("/:id", h.getListById)
-			lists.PUT("/:id", h.updateList)
-			lists.DELETE("/:id", h.deleteList)
+			lists.POST("/", writeLists, h.createList)
+			lists.GET("/", read, h.getAllLists)
+			lists.GET("/:id", read, h.getListById)
+			lists.PUT("/:id", writeLists, h.updateList)
+			lists.PATCH("/:id", writeLists, h.patchList)
+			lists.DELETE("/:id", writeLists, h.deleteList)
 
 			items := lists.Group(":id/items")
 			{
-				items.POST("/", h.createItem)
-				items.GET("/", h.getAllItems)
+				items.POST("/", writeItems, h.createItem)
+				items.GET("/", read, h.getAllItems)
 			}
+			lists.GET("/:id/export.ics", read, h.exportListICS)
 		}
 
 		items := api.Group("items")
 		{
-			items.GET("/:id", h.getItemById)
-			items.PUT("/:id", h.updateItem)
-			items.DELETE("/:id", h.deleteItem)
+			items.GET("/:id", read, h.getItemById)
+			items.PUT("/:id", writeItems, h.updateItem)
+			items.PATCH("/:id", writeItems, h.patchItem)
+			items.DELETE("/:id", writeItems, h.deleteItem)
+		}
+
+		api.GET("/export", read, h.exportData)
+		api.POST("/import", writeLists, writeItems, h.importData)
+
+		calendar := api.Group("/calendar", requireSession)
+		{
+			calendar.POST("/feed", h.createCalendarFeed)
+			calendar.DELETE("/feed", h.deleteCalendarFeed)
+		}
+
+		appPasswords := api.Group("/app-passwords", requireSession)
+		{
+			appPasswords.POST("/", h.createAppPassword)
+			appPasswords.GET("/", h.getAppPasswords)
+			appPasswords.DELETE("/:id", h.deleteAppPassword)
+		}
+
+		imports := api.Group("/imports")
+		{
+			imports.POST("/", writeLists, writeItems, h.createImportJob)
+			imports.GET("/", read, h.getImportJobs)
+			imports.GET("/:id", read, h.getImportJob)
+		}
+
+		me := api.Group("/me")
+		{
+			me.GET("", read, h.getProfile)
+			me.PUT("", requireSession, h.updateProfile)
+			me.DELETE("", requireSession, h.deleteAccount)
+			me.PUT("/password", requireSession, h.changePassword)
+			me.GET("/export", requireSession, h.exportAccount)
+
+			tokens := me.Group("/tokens", requireSession)
+			{
+				tokens.POST("/", h.createAccessToken)
+				tokens.GET("/", h.getAccessTokens)
+				tokens.DELETE("/:id", h.deleteAccessToken)
+			}
+
+			twoFactor := me.Group("/2fa", requireSession)
+			{
+				twoFactor.GET("", h.getTwoFactor)
+				twoFactor.DELETE("", h.disableTwoFactor)
+				twoFactor.POST("/enroll", h.enrollTwoFactor)
+				twoFactor.POST("/confirm", h.confirmTwoFactor)
+			}
 		}
 	}
 	return router
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+// newTestRouter собирает роутер поверх сервисов и хранилища в памяти
+func newTestRouter(t *testing.T, services service.Config, cfg Config) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	if cfg.RequestTimeout == 0 {
+		cfg.RequestTimeout = 5 * time.Second
+	}
+	return NewHandler(service.NewService(repository.NewMemoryRepository(), services), cfg).InitRoutes()
+}
+
+// serve выполняет запрос к роутеру; token - JWT для заголовка Authorization, пустой - без него
+func serve(router http.Handler, method, path, token, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return serveRequest(router, req)
+}
+
+func serveRequest(router http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// signUpToken регистрирует пользователя username и возвращает его JWT
+func signUpToken(t *testing.T, router http.Handler, username string) string {
+	t.Helper()
+	credentials := `{"name":"` + username + `","username":"` + username + `","password":"secret123"}`
+	if rec := serve(router, http.MethodPost, "/auth/sign-up", "", "application/json", credentials); rec.Code != http.StatusOK {
+		t.Fatalf("sign-up %s: %d %s", username, rec.Code, rec.Body)
+	}
+
+	rec := serve(router, http.MethodPost, "/auth/sign-in", "", "application/json", credentials)
+	var response signInResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || response.Token == "" {
+		t.Fatalf("sign-in %s: %d %s", username, rec.Code, rec.Body)
+	}
+	return response.Token
+}
+
+// createID отправляет POST и возвращает id созданной сущности
+func createID(t *testing.T, router http.Handler, path, token, body string) int {
+	t.Helper()
+	rec := serve(router, http.MethodPost, path, token, "application/json", body)
+	var response struct {
+		Id int `json:"id"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || response.Id == 0 {
+		t.Fatalf("POST %s: %d %s", path, rec.Code, rec.Body)
+	}
+	return response.Id
+}
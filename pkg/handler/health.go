@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/health"
+)
+
+// healthz - liveness: раз ответили, процесс жив
+func (h *Handler) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, h.health.Live())
+}
+
+// startupz - startup: 503, пока приложение не закончило запуск
+func (h *Handler) startupz(c *gin.Context) {
+	report, ok := h.health.Startup()
+	c.JSON(probeStatus(ok), report)
+}
+
+// readyz - readiness: БД доступна, схема нужной версии, фоновые воркеры работают, приложение не останавливается
+func (h *Handler) readyz(c *gin.Context) {
+	report, ok := h.health.Ready(c.Request.Context())
+	c.JSON(probeStatus(ok), report)
+}
+
+func probeStatus(ok bool) int {
+	if ok {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// healthOrDefault - без Checker пробы считают приложение запущенным и готовым, как до их появления
+func healthOrDefault(checker *health.Checker) *health.Checker {
+	if checker != nil {
+		return checker
+	}
+	checker = health.New(0)
+	checker.Started()
+	return checker
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ponomare0v/todo-go-app/pkg/health"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+func TestProbes(t *testing.T) {
+	checker := health.New(0)
+	var dbErr error
+	checker.Add("db", func(context.Context) error { return dbErr })
+	router := newTestRouter(t, service.Config{}, Config{Health: checker})
+
+	probe := func(path string) (int, health.Report) {
+		rec := serve(router, http.MethodGet, path, "", "", "")
+		var report health.Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("GET %s: %d %s", path, rec.Code, rec.Body)
+		}
+		return rec.Code, report
+	}
+
+	steps := []struct {
+		name                 string
+		do                   func()
+		live, startup, ready int
+		readyStatus          string
+	}{
+		{"starting", func() {}, http.StatusOK, http.StatusServiceUnavailable, http.StatusServiceUnavailable, health.StatusStarting},
+		{"started", checker.Started, http.StatusOK, http.StatusOK, http.StatusOK, health.StatusOK},
+		{"db down", func() { dbErr = errors.New("connection refused") }, http.StatusOK, http.StatusOK, http.StatusServiceUnavailable, health.StatusUnavailable},
+		{"db back", func() { dbErr = nil }, http.StatusOK, http.StatusOK, http.StatusOK, health.StatusOK},
+		{"draining", checker.Drain, http.StatusOK, http.StatusOK, http.StatusServiceUnavailable, health.StatusDraining},
+	}
+	for _, step := range steps {
+		step.do()
+		if code, _ := probe("/healthz"); code != step.live {
+			t.Errorf("%s: /healthz got %d, want %d", step.name, code, step.live)
+		}
+		if code, _ := probe("/startupz"); code != step.startup {
+			t.Errorf("%s: /startupz got %d, want %d", step.name, code, step.startup)
+		}
+		if code, report := probe("/readyz"); code != step.ready || report.Status != step.readyStatus {
+			t.Errorf("%s: /readyz got %d %+v, want %d %s", step.name, code, report, step.ready, step.readyStatus)
+		}
+	}
+}
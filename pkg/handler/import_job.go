@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/formats"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// выгрузка доски Trello с историей действий бывает заметно больше обычного файла импорта
+const maxSourceImportSize = 50 << 20
+
+type getImportJobsResponse struct {
+	Data []models.ImportJob `json:"data"`
+}
+
+// @Summary Start import from Trello or Todoist
+// @Security ApiKeyAuth
+// @Tags imports
+// @Description start a background import of a Trello board export (JSON) or a Todoist export (Sync API JSON or project template CSV).
+// @Description Boards and projects become lists, cards and tasks become items. Checklist items and subtasks become items
+// @Description right after their parent, labels and Trello columns are appended to titles as @label.
+// @Description The whole file is validated first: if any record is invalid no job is created and the report lists errors.
+// @ID create-import-job
+// @Accept json,text/csv
+// @Produce json
+// @Param source query string true "export source" Enums(trello, todoist)
+// @Param format query string false "file format, by default taken from Content-Type" Enums(json, csv)
+// @Param name query string false "list title for a Todoist CSV template" default(Todoist)
+// @Param input body object true "export file"
+// @Success 202 {object} models.ImportJob "queued job"
+// @Failure 400,413,415 {object} errorResponse
+// @Failure 422 {object} importErrorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/imports [post]
+func (h *Handler) createImportJob(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	source := c.Query("source")
+	if source != formats.SourceTrello && source != formats.SourceTodoist {
+		newErrorResponse(c, http.StatusBadRequest, "source must be trello or todoist")
+		return
+	}
+	format := c.Query("format")
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		format = formats.FromContentType(mediaType)
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSourceImportSize)
+	decoded, err := formats.DecodeSource(source, format, body, c.Query("name"))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.Is(err, formats.ErrUnsupportedFormat):
+		newErrorResponse(c, http.StatusUnsupportedMediaType, "trello export must be json, todoist export must be json or csv")
+		return
+	case errors.As(err, &tooLarge):
+		newErrorResponse(c, http.StatusRequestEntityTooLarge, "import file is too large")
+		return
+	case err != nil:
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// задание ставится в очередь, только если разобрался весь файл; иначе отчет собирается проверкой без создания
+	if len(decoded.Errors) > 0 {
+		report, err := h.services.Backup.Import(c.Request.Context(), userId, decoded.Lists, true)
+		if err != nil {
+			newServiceErrorResponse(c, err)
+			return
+		}
+		report.DryRun = false
+		newImportErrorResponse(c, report, decoded.Errors)
+		return
+	}
+
+	job, report, err := h.services.ImportJob.CreateImportJob(c.Request.Context(), userId, source, decoded.Lists)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+	if len(report.Errors) > 0 {
+		newImportErrorResponse(c, report, nil)
+		return
+	}
+
+	c.Header("Location", "/api/imports/"+strconv.Itoa(job.Id))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// @Summary Get import jobs
+// @Security ApiKeyAuth
+// @Tags imports
+// @Description get background imports of the authenticated user with their progress
+// @ID get-import-jobs
+// @Produce json
+// @Success 200 {object} getImportJobsResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/imports [get]
+func (h *Handler) getImportJobs(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	jobs, err := h.services.ImportJob.GetImportJobs(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getImportJobsResponse{
+		Data: jobs,
+	})
+}
+
+// @Summary Get import job
+// @Security ApiKeyAuth
+// @Tags imports
+// @Description get status and progress of a background import: done_lists and done_items out of total_lists and total_items
+// @ID get-import-job
+// @Produce json
+// @Param id path int true "import job id"
+// @Success 200 {object} models.ImportJob
+// @Failure 400,404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/imports/{id} [get]
+func (h *Handler) getImportJob(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	job, err := h.services.ImportJob.GetImportJob(c.Request.Context(), userId, id)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -19,6 +19,7 @@ import (
 // @Param input body models.TodoItem true "Item data"
 // @Success 200 {object} map[string]interface{} "Item created successfully"
 // @Failure 400 {object} errorResponse "Invalid data or list ID"
+// @Failure 422 {object} validationErrorResponse "Validation failed"
 // @Failure 500 {object} errorResponse "Internal server error"
 // @Router /api/lists/{id}/items [post]
 func (h *Handler) createItem(c *gin.Context) {
@@ -39,9 +40,9 @@ func (h *Handler) createItem(c *gin.Context) {
 		return
 	}
 
-	id, err := h.services.TodoItem.Create(userId, listId, input)
+	id, err := h.services.TodoItem.Create(c.Request.Context(), userId, listId, input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -74,9 +75,9 @@ func (h *Handler) getAllItems(c *gin.Context) {
 		return
 	}
 
-	items, err := h.services.TodoItem.GetAll(userId, listId)
+	items, err := h.services.TodoItem.GetAll(c.Request.Context(), userId, listId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -108,9 +109,9 @@ func (h *Handler) getItemById(c *gin.Context) {
 		return
 	}
 
-	item, err := h.services.TodoItem.GetById(userId, itemId)
+	item, err := h.services.TodoItem.GetById(c.Request.Context(), userId, itemId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -128,6 +129,7 @@ func (h *Handler) getItemById(c *gin.Context) {
 // @Param item body models.UpdateItemInput true "Item data to update"
 // @Success 200 {object} statusResponse "Update successful"
 // @Failure 400 {object} errorResponse "Invalid input or ID"
+// @Failure 422 {object} validationErrorResponse "Validation failed"
 // @Failure 500 {object} errorResponse "Internal server error"
 // @Router /api/items/{id} [put]
 func (h *Handler) updateItem(c *gin.Context) {
@@ -148,8 +150,51 @@ func (h *Handler) updateItem(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.TodoItem.Update(userId, id, input); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := h.services.TodoItem.Update(c.Request.Context(), userId, id, input); err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary Patch an item by its ID
+// @Security ApiKeyAuth
+// @Tags items
+// @Description Partially update an item with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) document
+// @ID patch-item-by-id
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "Item ID"
+// @Param item body object true "Merge patch object or array of patch operations"
+// @Success 200 {object} statusResponse "Patch successful"
+// @Failure 400 {object} errorResponse "Invalid ID or malformed patch"
+// @Failure 404 {object} errorResponse "Item not found"
+// @Failure 409 {object} errorResponse "Patch test operation failed"
+// @Failure 413 {object} errorResponse "Patch is larger than 64 KiB"
+// @Failure 415 {object} errorResponse "Unsupported patch media type"
+// @Failure 422 {object} validationErrorResponse "Validation failed"
+// @Failure 500 {object} errorResponse "Internal server error"
+// @Router /api/items/{id} [patch]
+func (h *Handler) patchItem(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	patch, err := readPatch(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.services.TodoItem.Patch(c.Request.Context(), userId, id, patch); err != nil {
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -178,9 +223,9 @@ func (h *Handler) deleteItem(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoItem.Delete(userId, itemId)
+	err = h.services.TodoItem.Delete(c.Request.Context(), userId, itemId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
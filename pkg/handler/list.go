@@ -18,6 +18,7 @@ import (
 // @Param input body models.TodoList true "list info"
 // @Success 200 {integer} integer 1
 // @Failure 400,404 {object} errorResponse
+// @Failure 422 {object} validationErrorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /api/lists [post]
@@ -35,9 +36,9 @@ func (h *Handler) createList(c *gin.Context) { // хэндлеры для раб
 
 	//// вот здесь передаем id из начала где получаем его из контекста, но получаем мы интерфейс,
 	//  а передавать надо int, чтобы не приводить постоянно к int создадим функцию в middleware под названием getUserId
-	id, err := h.services.TodoList.Create(userId, input)
+	id, err := h.services.TodoList.Create(c.Request.Context(), userId, input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -69,9 +70,9 @@ func (h *Handler) getAllLists(c *gin.Context) {
 		return
 	}
 
-	lists, err := h.services.TodoList.GetAll(userId)
+	lists, err := h.services.TodoList.GetAll(c.Request.Context(), userId)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -106,9 +107,9 @@ func (h *Handler) getListById(c *gin.Context) {
 		return
 	}
 
-	list, err := h.services.TodoList.GetById(userId, id)
+	list, err := h.services.TodoList.GetById(c.Request.Context(), userId, id)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -127,6 +128,7 @@ func (h *Handler) getListById(c *gin.Context) {
 // @Success 200 {object} statusResponse "List updated successfully"
 // @Failure 400 {object} errorResponse "Invalid ID param or request body"
 // @Failure 404 {object} errorResponse "List not found"
+// @Failure 422 {object} validationErrorResponse "Validation failed"
 // @Failure 500 {object} errorResponse "Internal server error"
 // @Router /api/lists/{id} [put]
 func (h *Handler) updateList(c *gin.Context) {
@@ -149,8 +151,51 @@ func (h *Handler) updateList(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.TodoList.Update(userId, id, input); err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := h.services.TodoList.Update(c.Request.Context(), userId, id, input); err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{"ok"})
+}
+
+// @Summary Patch todo list by ID
+// @Security ApiKeyAuth
+// @Tags lists
+// @Description Partially update a todo list with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) document
+// @ID patch-list
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param input body object true "Merge patch object or array of patch operations"
+// @Success 200 {object} statusResponse "List patched successfully"
+// @Failure 400 {object} errorResponse "Invalid ID param or malformed patch"
+// @Failure 404 {object} errorResponse "List not found"
+// @Failure 409 {object} errorResponse "Patch test operation failed"
+// @Failure 413 {object} errorResponse "Patch is larger than 64 KiB"
+// @Failure 415 {object} errorResponse "Unsupported patch media type"
+// @Failure 422 {object} validationErrorResponse "Validation failed"
+// @Failure 500 {object} errorResponse "Internal server error"
+// @Router /api/lists/{id} [patch]
+func (h *Handler) patchList(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, "invalid id param")
+		return
+	}
+
+	patch, err := readPatch(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.services.TodoList.Patch(c.Request.Context(), userId, id, patch); err != nil {
+		newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -184,9 +229,9 @@ func (h *Handler) deleteList(c *gin.Context) {
 		return
 	}
 
-	err = h.services.TodoList.Delete(userId, id)
+	err = h.services.TodoList.Delete(c.Request.Context(), userId, id)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, err)
 		return
 	}
 
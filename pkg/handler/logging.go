@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	requestIdHeader    = "X-Request-ID"
+	maxRequestIdLength = 128
+)
+
+// успешные запросы к этим путям не пишутся в access log, чтобы не засорять его статикой
+var accessLogSkipPaths = []string{"/swagger/", "/graphql/playground"}
+
+// problemResponse - тело ответа по RFC 7807 (application/problem+json)
+type problemResponse struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestId string `json:"request_id,omitempty"`
+}
+
+// requestId берет X-Request-ID клиента или генерирует новый, возвращает его в ответе
+// и кладет в контекст запроса логгер с полем request_id
+func requestId(c *gin.Context) {
+	id := c.GetHeader(requestIdHeader)
+	if !validRequestId(id) {
+		id = uuid.NewString()
+	}
+
+	c.Header(requestIdHeader, id)
+	c.Set(requestIdHeader, id)
+	c.Request = c.Request.WithContext(logging.WithFields(c.Request.Context(), logrus.Fields{
+		"request_id": id,
+	}))
+
+	c.Next()
+}
+
+func validRequestId(id string) bool {
+	if id == "" || len(id) > maxRequestIdLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e { // только видимые ASCII-символы, чтобы id нельзя было использовать для инъекций в логи
+			return false
+		}
+	}
+	return true
+}
+
+// accessLog пишет одну строку на запрос; уровень зависит от статуса ответа
+func accessLog(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	status := c.Writer.Status()
+	if status < http.StatusBadRequest && skipAccessLog(c.Request.URL.Path) {
+		return
+	}
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	fields := logrus.Fields{
+		"method":     c.Request.Method,
+		"route":      route,
+		"status":     status,
+		"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		"bytes":      c.Writer.Size(),
+		"client_ip":  c.ClientIP(),
+	}
+	if userId, ok := c.Get(userCtx); ok {
+		fields["user_id"] = userId
+	}
+
+	entry := logging.FromContext(c.Request.Context()).WithFields(fields)
+	switch {
+	case status >= http.StatusInternalServerError:
+		entry.Error("request completed")
+	case status >= http.StatusBadRequest:
+		entry.Warn("request completed")
+	default:
+		entry.Info("request completed")
+	}
+}
+
+func skipAccessLog(path string) bool {
+	for _, prefix := range accessLogSkipPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// recovery перехватывает панику в обработчике, логирует стек и отвечает 500 в формате problem+json,
+// не разрывая соединение с клиентом
+func recovery(c *gin.Context) {
+	defer func() {
+		rec := recover()
+		if rec == nil {
+			return
+		}
+
+		// клиент уже ушел - отвечать некому (так же делает gin.Recovery)
+		if rec == http.ErrAbortHandler {
+			panic(rec)
+		}
+
+		logging.FromContext(c.Request.Context()).WithFields(logrus.Fields{
+			"panic": fmt.Sprint(rec),
+			"stack": string(debug.Stack()),
+		}).Error("panic recovered")
+
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+
+		c.Header("Content-Type", "application/problem+json")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, problemResponse{
+			Type:      "about:blank",
+			Title:     http.StatusText(http.StatusInternalServerError),
+			Status:    http.StatusInternalServerError,
+			Detail:    "the server encountered an unexpected condition",
+			Instance:  c.Request.URL.Path,
+			RequestId: c.GetString(requestIdHeader),
+		})
+	}()
+
+	c.Next()
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/sirupsen/logrus"
+)
+
+// captureLogs перенаправляет стандартный логгер logrus в буфер и возвращает функцию, разбирающую записанные строки
+func captureLogs(t *testing.T) func() []map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := logrus.StandardLogger()
+	out, formatter, level := logger.Out, logger.Formatter, logger.GetLevel()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.InfoLevel)
+	t.Cleanup(func() {
+		logger.SetOutput(out)
+		logger.SetFormatter(formatter)
+		logger.SetLevel(level)
+	})
+
+	return func() []map[string]interface{} {
+		var entries []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("log line is not JSON: %q", line)
+			}
+			entries = append(entries, entry)
+		}
+		buf.Reset()
+		return entries
+	}
+}
+
+func findLog(entries []map[string]interface{}, msg string) map[string]interface{} {
+	for _, entry := range entries {
+		if entry["msg"] == msg {
+			return entry
+		}
+	}
+	return nil
+}
+
+func TestRequestId(t *testing.T) {
+	router := newTestRouter(t, service.Config{}, Config{})
+	logs := captureLogs(t)
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"client id is kept", "req-42.abc", true},
+		{"no id", "", false},
+		{"id with a space", "req 42", false},
+		{"id with a newline", "req\n42", false},
+		{"too long", strings.Repeat("a", maxRequestIdLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/lists/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestIdHeader, tt.header)
+			}
+			rec := serveRequest(router, req)
+
+			id := rec.Header().Get(requestIdHeader)
+			if tt.keep && id != tt.header || !tt.keep && (id == "" || id == tt.header) {
+				t.Errorf("%s: got %q, sent %q", requestIdHeader, id, tt.header)
+			}
+			entry := findLog(logs(), "request completed")
+			if entry == nil || entry["request_id"] != id {
+				t.Errorf("access log: got %v, want request_id %q", entry, id)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	router := newTestRouter(t, service.Config{}, Config{})
+	token := signUpToken(t, router, "alice")
+	listId := createID(t, router, "/api/lists/", token, `{"title":"groceries"}`)
+	logs := captureLogs(t)
+
+	serve(router, http.MethodGet, "/api/lists/"+strconv.Itoa(listId), token, "", "")
+	entry := findLog(logs(), "request completed")
+	if entry == nil {
+		t.Fatal("no access log line")
+	}
+	for field, want := range map[string]interface{}{
+		"level": "info", "method": "GET", "route": "/api/lists/:id", "status": float64(200), "user_id": float64(1),
+	} {
+		if entry[field] != want {
+			t.Errorf("%s: got %v, want %v", field, entry[field], want)
+		}
+	}
+	for _, field := range []string{"latency_ms", "bytes", "client_ip", "request_id"} {
+		if _, ok := entry[field]; !ok {
+			t.Errorf("access log has no %s: %v", field, entry)
+		}
+	}
+
+	serve(router, http.MethodGet, "/no/such/path", "", "", "")
+	if entry := findLog(logs(), "request completed"); entry == nil || entry["route"] != "unmatched" || entry["level"] != "warning" {
+		t.Errorf("unmatched route: got %v", entry)
+	}
+
+	serve(router, http.MethodGet, "/swagger/index.html", "", "", "")
+	if entry := findLog(logs(), "request completed"); entry != nil {
+		t.Errorf("successful swagger request is logged: %v", entry)
+	}
+}
+
+// failingStore - хранилище блокировок, которое всегда недоступно: лог об этом пишет слой сервисов
+type failingStore struct{ ratelimit.Store }
+
+func (failingStore) TTL(context.Context, string) (time.Duration, error) {
+	return 0, errors.New("store is down")
+}
+
+func (failingStore) Incr(context.Context, string, time.Duration) (int64, error) {
+	return 0, errors.New("store is down")
+}
+
+func (failingStore) Delete(context.Context, string) error {
+	return errors.New("store is down")
+}
+
+// TestRequestIdInServiceLogs - request_id попадает и в строки, которые пишут сервисы ниже обработчика
+func TestRequestIdInServiceLogs(t *testing.T) {
+	lockout := ratelimit.NewLockout(failingStore{}, ratelimit.LockoutPolicy{Threshold: 3, Base: time.Minute, Window: time.Minute})
+	router := newTestRouter(t, service.Config{Lockout: lockout}, Config{})
+	logs := captureLogs(t)
+
+	req, _ := http.NewRequest(http.MethodPost, "/auth/sign-in", strings.NewReader(`{"username":"nobody","password":"wrong"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestIdHeader, "req-service")
+	serveRequest(router, req)
+
+	entries := logs()
+	entry := findLog(entries, "rate limit store: store is down")
+	if entry == nil || entry["request_id"] != "req-service" {
+		t.Errorf("service log: got %v, want request_id req-service; all logs: %v", entry, entries)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	router := newTestRouter(t, service.Config{}, Config{})
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+	logs := captureLogs(t)
+
+	req, _ := http.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(requestIdHeader, "req-panic")
+	rec := serveRequest(router, req)
+
+	var problem problemResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d %s, want 500 problem+json", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+		t.Errorf("Content-Type: got %q", ct)
+	}
+	if problem.Status != http.StatusInternalServerError || problem.Instance != "/panic" || problem.RequestId != "req-panic" {
+		t.Errorf("problem: got %+v", problem)
+	}
+
+	entries := logs()
+	if entry := findLog(entries, "panic recovered"); entry == nil || entry["panic"] != "boom" || entry["request_id"] != "req-panic" ||
+		!strings.Contains(entry["stack"].(string), "logging_test.go") {
+		t.Errorf("panic log: got %v", entry)
+	}
+	if entry := findLog(entries, "request completed"); entry == nil || entry["status"] != float64(500) || entry["level"] != "error" {
+		t.Errorf("access log after a panic: got %v", entry)
+	}
+}
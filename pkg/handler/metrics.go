@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+)
+
+// httpMetrics считает запросы и их длительность по шаблону маршрута (/api/lists/:id), а не по пути,
+// чтобы число рядов метрики не росло с каждым новым id
+func httpMetrics(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	metrics.ObserveRequest(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), time.Since(start))
+}
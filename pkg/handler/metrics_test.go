@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+// scrape читает /metrics и возвращает значения рядов по строке вида name{label="value",...}
+func scrape(t *testing.T) map[string]float64 {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics: %d %s", rec.Code, rec.Body)
+	}
+
+	samples := make(map[string]float64)
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		value, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("bad sample %q: %v", line, err)
+		}
+		samples[line[:i]] = value
+	}
+	return samples
+}
+
+func TestMetrics(t *testing.T) {
+	services := service.NewService(repository.NewMemoryRepository(), service.Config{})
+	router := NewHandler(services, Config{RequestTimeout: 5 * time.Second}).InitRoutes()
+	if err := metrics.RegisterBusiness(services.Admin.GetTotals); err != nil {
+		t.Fatalf("RegisterBusiness: %v", err)
+	}
+
+	token := signUpToken(t, router, "alice")
+	listId := createID(t, router, "/api/lists/", token, `{"title":"groceries"}`)
+	otherId := createID(t, router, "/api/lists/", token, `{"title":"work"}`)
+	itemsPath := "/api/lists/" + strconv.Itoa(listId) + "/items/"
+	createID(t, router, itemsPath, token, `{"title":"milk"}`)
+	createID(t, router, itemsPath, token, `{"title":"bread","done":true}`)
+
+	before := scrape(t)
+	serve(router, http.MethodGet, "/api/lists/"+strconv.Itoa(listId), token, "", "")
+	serve(router, http.MethodGet, "/api/lists/"+strconv.Itoa(otherId), token, "", "")
+	serve(router, http.MethodGet, "/api/lists/"+strconv.Itoa(listId), "", "", "")
+	serve(router, http.MethodGet, "/no/such/path", "", "", "")
+	serve(router, http.MethodGet, "/healthz", "", "", "")
+	serve(router, http.MethodPost, "/auth/sign-in", "", "application/json", `{"username":"alice","password":"wrong"}`)
+	after := scrape(t)
+
+	// ряды - по шаблону маршрута, а не по пути с id; пробы в метрики не попадают
+	tests := []struct {
+		series string
+		delta  float64
+	}{
+		{`todo_http_requests_total{method="GET",route="/api/lists/:id",status="200"}`, 2},
+		{`todo_http_request_duration_seconds_count{method="GET",route="/api/lists/:id",status="200"}`, 2},
+		{`todo_http_requests_total{method="GET",route="/api/lists/:id",status="401"}`, 1},
+		{`todo_http_requests_total{method="GET",route="unmatched",status="404"}`, 1},
+		{`todo_auth_attempts_total{operation="sign_in",result="failure"}`, 1},
+	}
+	for _, tt := range tests {
+		if got := after[tt.series] - before[tt.series]; got != tt.delta {
+			t.Errorf("%s: grew by %v, want %v", tt.series, got, tt.delta)
+		}
+	}
+	for series := range after {
+		if strings.Contains(series, "/api/lists/"+strconv.Itoa(listId)) || strings.Contains(series, "/healthz") {
+			t.Errorf("series with a raw path: %s", series)
+		}
+	}
+
+	for series, want := range map[string]float64{
+		"todo_users":                  1,
+		"todo_lists":                  2,
+		`todo_items{state="open"}`:    1,
+		`todo_items{state="done"}`:    1,
+		"todo_business_metrics_error": 0,
+	} {
+		if got, ok := after[series]; !ok || got != want {
+			t.Errorf("%s: got %v (present %t), want %v", series, got, ok, want)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -26,8 +30,14 @@ func (h *Handler) userIdentity(c *gin.Context) {
 		return
 	}
 
+	// персональный токен узнается по префиксу, все остальное разбирается как JWT
+	if strings.HasPrefix(headerParts[1], service.AccessTokenPrefix) {
+		h.accessTokenIdentity(c, headerParts[1])
+		return
+	}
+
 	// parse token
-	userId, err := h.services.ParseToken(headerParts[1])
+	session, err := h.services.ParseToken(c.Request.Context(), headerParts[1])
 	if err != nil {
 		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
@@ -35,7 +45,52 @@ func (h *Handler) userIdentity(c *gin.Context) {
 
 	// запишем значение id в контекст (для того чтобы иметь доступ к id пользователя,
 	//  который делает запрос в последующих обработчиках, которые вызываются после данной прослойки middleware)
-	c.Set(userCtx, userId)
+	c.Set(userCtx, session.UserId)
+	ctx := service.WithSession(c.Request.Context(), session)
+	c.Request = c.Request.WithContext(logging.WithFields(ctx, logrus.Fields{
+		"user_id": session.UserId,
+	}))
+}
+
+// accessTokenIdentity авторизует запрос персональным токеном; его права проверяют requireScope и requireSession
+func (h *Handler) accessTokenIdentity(c *gin.Context, secret string) {
+	token, err := h.services.AccessToken.Authenticate(c.Request.Context(), secret)
+	if errors.Is(err, service.ErrInvalidAccessToken) {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Set(userCtx, token.UserId)
+	ctx := service.WithAccessToken(c.Request.Context(), token)
+	c.Request = c.Request.WithContext(logging.WithFields(ctx, logrus.Fields{
+		"user_id":         token.UserId,
+		"access_token_id": token.Id,
+	}))
+}
+
+// requireScope пропускает запрос с персональным токеном, только если у токена есть все права scopes.
+// После входа по паролю (JWT) доступно все.
+func requireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, scope := range scopes {
+			if err := service.CheckScope(c.Request.Context(), scope); err != nil {
+				newServiceErrorResponse(c, err)
+				return
+			}
+		}
+	}
+}
+
+// requireSession закрывает от персональных токенов выпуск учетных данных: утекший токен
+// не должен давать возможность создать себе новый токен, пароль приложения или ссылку на ленту
+func requireSession(c *gin.Context) {
+	if err := service.CheckSession(c.Request.Context()); err != nil {
+		newServiceErrorResponse(c, err)
+	}
 }
 
 // функция приведения интерфейса id из контекста к инту
@@ -53,3 +108,18 @@ func getUserId(c *gin.Context) (int, error) {
 	}
 	return idInt, nil
 }
+
+// requestTimeout ограничивает время обработки запроса: контекст с дедлайном уходит в сервисы и репозитории,
+// так что запросы в БД отменяются и по таймауту, и при отключении клиента
+func (h *Handler) requestTimeout(c *gin.Context) {
+	if h.cfg.RequestTimeout <= 0 {
+		c.Next()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.cfg.RequestTimeout)
+	defer cancel()
+
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+}
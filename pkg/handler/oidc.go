@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+const (
+	oidcCallbackPath = "/auth/oidc/callback"
+	oidcLoginCookie  = "oidc_login"
+)
+
+type oidcProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// oidcCallbackResponse - token или, если аккаунт провайдера совпал с существующим пользователем,
+// link_token и его логин: связывание подтверждается паролем в /auth/oidc/link. С включенной 2FA -
+// challenge_token для /auth/sign-in/2fa, как после /auth/sign-in.
+type oidcCallbackResponse struct {
+	Token          string `json:"token,omitempty"`
+	LinkToken      string `json:"link_token,omitempty"`
+	Username       string `json:"username,omitempty"`
+	ChallengeToken string `json:"challenge_token,omitempty"`
+}
+
+type oidcLinkInput struct {
+	LinkToken string `json:"link_token" binding:"required"`
+	Password  string `json:"password" binding:"required"`
+}
+
+// @Summary Get OIDC providers
+// @Tags auth
+// @Description list configured OpenID Connect providers available for sign-in
+// @ID get-oidc-providers
+// @Produce json
+// @Success 200 {object} oidcProvidersResponse
+// @Failure 429 {object} errorResponse "rate limit exceeded, see Retry-After"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /auth/oidc/providers [get]
+func (h *Handler) getOIDCProviders(c *gin.Context) {
+	providers, err := h.services.OIDC.GetOIDCProviders(c.Request.Context())
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, oidcProvidersResponse{Providers: providers})
+}
+
+// @Summary Start OIDC sign-in
+// @Tags auth
+// @Description redirect to the provider's sign-in page (authorization code flow with PKCE)
+// @ID oidc-login
+// @Param provider query string true "provider name from /auth/oidc/providers"
+// @Success 302 "redirect to the provider, sets the oidc_login cookie"
+// @Failure 400,404 {object} errorResponse
+// @Failure 429 {object} errorResponse "rate limit exceeded, see Retry-After"
+// @Failure 502 {object} errorResponse "provider discovery failed"
+// @Failure default {object} errorResponse
+// @Router /auth/oidc/login [get]
+func (h *Handler) oidcLogin(c *gin.Context) {
+	provider := c.Query("provider")
+	if provider == "" {
+		newErrorResponse(c, http.StatusBadRequest, "provider query param is required")
+		return
+	}
+
+	authURL, session, err := h.services.OIDC.StartOIDCLogin(c.Request.Context(), provider, h.oidcRedirectURL(c))
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	h.setOIDCLoginCookie(c, session, int(service.OIDCLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary Finish OIDC sign-in
+// @Tags auth
+// @Description exchange the authorization code, sign in or provision the linked user and return token.
+// @Description If the identity matches an existing user by link_by, link_token and the username are returned instead:
+// @Description the user confirms linking with their password at /auth/oidc/link within 10 minutes.
+// @Description If two-factor authentication is enabled, challenge_token is returned: finish with /auth/sign-in/2fa.
+// @ID oidc-callback
+// @Produce json
+// @Param state query string true "state returned by the provider"
+// @Param code query string false "authorization code"
+// @Param error query string false "error returned by the provider"
+// @Success 200 {object} oidcCallbackResponse
+// @Success 302 "redirect to the configured success URL with #token=, #link_token=...&username= or #challenge_token="
+// @Failure 400,401,403 {object} errorResponse
+// @Failure 429 {object} errorResponse "rate limit exceeded, see Retry-After"
+// @Failure 502 {object} errorResponse "provider is unavailable"
+// @Failure default {object} errorResponse
+// @Router /auth/oidc/callback [get]
+func (h *Handler) oidcCallback(c *gin.Context) {
+	session, _ := c.Cookie(oidcLoginCookie)
+	h.setOIDCLoginCookie(c, "", -1) // сессия входа одноразовая
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		message := "oidc provider returned error: " + providerErr
+		if description := c.Query("error_description"); description != "" {
+			message += ": " + description
+		}
+		newErrorResponse(c, http.StatusUnauthorized, message)
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		newErrorResponse(c, http.StatusBadRequest, "code query param is required")
+		return
+	}
+
+	var resp oidcCallbackResponse
+	token, err := h.services.OIDC.FinishOIDCLogin(c.Request.Context(), session, c.Query("state"), code, h.oidcRedirectURL(c))
+	var linkRequired *service.OIDCLinkRequiredError
+	var twoFactorRequired *service.TwoFactorRequiredError
+	switch {
+	case errors.As(err, &linkRequired):
+		resp = oidcCallbackResponse{LinkToken: linkRequired.LinkToken, Username: linkRequired.Username}
+	case errors.As(err, &twoFactorRequired):
+		resp = oidcCallbackResponse{ChallengeToken: twoFactorRequired.ChallengeToken}
+	case err != nil:
+		newServiceErrorResponse(c, err)
+		return
+	default:
+		resp = oidcCallbackResponse{Token: token}
+	}
+
+	// браузерному фронтенду токен передается во фрагменте: он не уходит на сервер и не попадает в логи
+	if h.cfg.OIDCSuccessURL != "" {
+		c.Redirect(http.StatusFound, h.cfg.OIDCSuccessURL+"#"+resp.fragment())
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (r oidcCallbackResponse) fragment() string {
+	values := url.Values{}
+	fields := map[string]string{"token": r.Token, "link_token": r.LinkToken, "username": r.Username, "challenge_token": r.ChallengeToken}
+	for key, value := range fields {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values.Encode()
+}
+
+// @Summary Link OIDC identity
+// @Tags auth
+// @Description finish the first OIDC sign-in into an existing account: link_token from /auth/oidc/callback
+// @Description and the password of that account. Wrong passwords count towards the sign-in lockout.
+// @Description If two-factor authentication is enabled, challenge_token is returned: finish with /auth/sign-in/2fa.
+// @ID oidc-link
+// @Accept json
+// @Produce json
+// @Param input body oidcLinkInput true "link token and password"
+// @Success 200 {object} signInResponse
+// @Failure 400,401,403 {object} errorResponse
+// @Failure 429 {object} errorResponse "rate limit exceeded or too many failed attempts, see Retry-After"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /auth/oidc/link [post]
+func (h *Handler) oidcLink(c *gin.Context) {
+	var input oidcLinkInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.services.OIDC.LinkOIDCIdentity(c.Request.Context(), input.LinkToken, input.Password)
+	var twoFactorRequired *service.TwoFactorRequiredError
+	if errors.As(err, &twoFactorRequired) {
+		c.JSON(http.StatusOK, signInResponse{ChallengeToken: twoFactorRequired.ChallengeToken})
+		return
+	}
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, signInResponse{Token: token})
+}
+
+func (h *Handler) oidcRedirectURL(c *gin.Context) string {
+	return h.publicURL(c) + oidcCallbackPath
+}
+
+// setOIDCLoginCookie хранит сессию входа до возврата от провайдера. SameSite=Lax: cookie должна прийти
+// на callback после перехода со страницы провайдера
+func (h *Handler) setOIDCLoginCookie(c *gin.Context, session string, maxAge int) {
+	secure := strings.HasPrefix(h.publicURL(c), "https://")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcLoginCookie, session, maxAge, "/auth/oidc", "", secure, true)
+}
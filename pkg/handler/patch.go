@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+// патч одного списка или задачи - небольшой JSON, больше не нужно
+const maxPatchBytes = 64 << 10
+
+// readPatch читает тело PATCH-запроса и определяет его тип по заголовку Content-Type
+func readPatch(c *gin.Context) (models.Patch, error) {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || (mediaType != models.MergePatchType && mediaType != models.JSONPatchType) {
+		c.Header("Accept-Patch", models.MergePatchType+", "+models.JSONPatchType)
+		newErrorResponse(c, http.StatusUnsupportedMediaType, "unsupported patch media type")
+		return models.Patch{}, service.ErrUnsupportedPatch
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		newErrorResponse(c, http.StatusRequestEntityTooLarge, "patch is too large")
+		return models.Patch{}, err
+	}
+	if err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return models.Patch{}, err
+	}
+
+	return models.Patch{Type: mediaType, Body: body}, nil
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+)
+
+func TestPatchStatus(t *testing.T) {
+	router := newTestRouter(t, service.Config{}, Config{})
+	alice, bob := signUpToken(t, router, "alice"), signUpToken(t, router, "bob")
+	listId := createID(t, router, "/api/lists/", alice, `{"title":"groceries"}`)
+	itemId := createID(t, router, "/api/lists/"+strconv.Itoa(listId)+"/items/", alice, `{"title":"milk"}`)
+	list, item := "/api/lists/"+strconv.Itoa(listId), "/api/items/"+strconv.Itoa(itemId)
+
+	tests := []struct {
+		name        string
+		path        string
+		token       string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"list", list, alice, "application/merge-patch+json", `{"title":"food"}`, http.StatusOK},
+		{"item", item, alice, "application/json-patch+json", `[{"op":"replace","path":"/done","value":true}]`, http.StatusOK},
+		{"missing list", "/api/lists/100000", alice, "application/merge-patch+json", `{"title":"x"}`, http.StatusNotFound},
+		{"missing item", "/api/items/100000", alice, "application/merge-patch+json", `{"title":"x"}`, http.StatusNotFound},
+		{"foreign list", list, bob, "application/merge-patch+json", `{"title":"x"}`, http.StatusNotFound},
+		{"foreign item", item, bob, "application/merge-patch+json", `{"title":"x"}`, http.StatusNotFound},
+		{"malformed", list, alice, "application/merge-patch+json", `{`, http.StatusBadRequest},
+		{"unsupported type", list, alice, "application/json", `{"title":"x"}`, http.StatusUnsupportedMediaType},
+		{"failed test op", item, alice, "application/json-patch+json", `[{"op":"test","path":"/title","value":"bread"}]`, http.StatusConflict},
+		{"invalid result", list, alice, "application/merge-patch+json", `{"title":""}`, http.StatusUnprocessableEntity},
+		{"too large", list, alice, "application/merge-patch+json", `{"title":"` + strings.Repeat("x", maxPatchBytes) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(router, http.MethodPatch, tt.path, tt.token, tt.contentType, tt.body)
+			if rec.Code != tt.want {
+				t.Errorf("PATCH %s: got %d %s, want %d", tt.path, rec.Code, rec.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+)
+
+// rateLimit расходует токен лимитера на каждый запрос и отвечает 429, когда ведро пусто.
+// Заголовки RateLimit-Limit, RateLimit-Remaining и RateLimit-Reset (draft-ietf-httpapi-ratelimit-headers)
+// отдаются на каждый ответ. Если хранилище лимитов недоступно, запрос пропускается: лучше временно
+// остаться без лимитов, чем отказать всем.
+func rateLimit(limiter *ratelimit.Limiter, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil || !limiter.Limit().Enabled() {
+			return
+		}
+
+		res, err := limiter.Allow(c.Request.Context(), key(c))
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Errorf("rate limit store: %s", err.Error())
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", seconds(res.Reset))
+		if res.Allowed {
+			return
+		}
+
+		metrics.ObserveRateLimited(limiter.Name())
+		c.Header("Retry-After", seconds(res.RetryAfter))
+		newErrorResponse(c, http.StatusTooManyRequests, "rate limit exceeded")
+	}
+}
+
+// clientIPKey - для /auth/*: пользователь еще не известен. Адрес берется из X-Forwarded-For
+// только от прокси из Config.TrustedProxies, иначе лимит обходился бы подделкой заголовка.
+func clientIPKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// userKey - для /api/*: вызывается после userIdentity, поэтому id пользователя уже в контексте
+func userKey(c *gin.Context) string {
+	return strconv.Itoa(c.GetInt(userCtx))
+}
+
+// seconds округляет вверх: Retry-After: 0 при еще пустом ведре провоцировал бы немедленный повтор
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -1,19 +1,90 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/oidc"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
 )
 
 type errorResponse struct {
 	Message string // 'json:"message"'
 }
 
+// validationErrorResponse - ответ 422 со списком ошибок по каждому полю запроса
+type validationErrorResponse struct {
+	Message string
+	Errors  models.ValidationErrors `json:"errors"`
+}
+
 type statusResponse struct {
 	Status string `json:"status"`
 }
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
-	logrus.Error(message)
+	logging.FromContext(c.Request.Context()).Error(message)
 	c.AbortWithStatusJSON(statusCode, errorResponse{message})
 }
+
+// newServiceErrorResponse отвечает на ошибку из слоя сервисов: ошибки валидации отдаются
+// с кодом 422 и списком полей, для остальных код подбирается в errorStatus
+func newServiceErrorResponse(c *gin.Context, err error) {
+	var validationErrs models.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		logging.FromContext(c.Request.Context()).Error(err.Error())
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, validationErrorResponse{
+			Message: "validation failed",
+			Errors:  validationErrs,
+		})
+		return
+	}
+
+	var locked *ratelimit.LockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", seconds(locked.RetryAfter))
+	}
+
+	newErrorResponse(c, errorStatus(err), err.Error())
+}
+
+// errorStatus подбирает код ответа для ошибки сервиса, для патчей - по RFC 5789, раздел 2.2
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ratelimit.ErrLocked):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrInsufficientScope), errors.Is(err, service.ErrSessionRequired),
+		errors.Is(err, service.ErrOIDCNoAccount), errors.Is(err, service.ErrUserDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrOIDCLoginExpired):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrOIDCLoginFailed), errors.Is(err, service.ErrOIDCLinkFailed),
+		errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, service.ErrInvalidChallenge):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrTwoFactorEnabled), errors.Is(err, service.ErrTwoFactorNotEnabled), errors.Is(err, service.ErrTwoFactorNotEnrolled):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrOIDCUnavailable):
+		return http.StatusBadGateway
+	case errors.Is(err, service.ErrFeedNotFound), errors.Is(err, service.ErrAppPasswordNotFound), errors.Is(err, service.ErrImportJobNotFound),
+		errors.Is(err, service.ErrAccessTokenNotFound), errors.Is(err, oidc.ErrUnknownProvider),
+		errors.Is(err, service.ErrListNotFound), errors.Is(err, service.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUnsupportedPatch):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, service.ErrMalformedPatch):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidPatch):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrPatchConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
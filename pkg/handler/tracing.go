@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ponomare0v/todo-go-app/pkg/handler")
+
+// tracing открывает серверный спан на запрос, продолжая трейс из заголовка traceparent, если клиент его прислал.
+// Имя спана - шаблон маршрута, как и в метриках. trace_id добавляется в логи запроса.
+func tracing(c *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+	route := c.FullPath()
+	name := c.Request.Method + " " + route
+	if route == "" {
+		name = c.Request.Method
+	}
+
+	path := c.Request.URL.Path
+	if c.Param("token") != "" {
+		path = route // в пути секретный токен ICS-ленты, в трейс он попасть не должен
+	}
+
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(path),
+			semconv.UserAgentOriginal(c.Request.UserAgent()),
+		),
+	)
+	defer span.End()
+
+	if sc := span.SpanContext(); sc.IsSampled() {
+		ctx = logging.WithFields(ctx, logrus.Fields{"trace_id": sc.TraceID().String()})
+	}
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+
+	status := c.Writer.Status()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	parentTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	parentSpanId  = "00f067aa0ba902b7"
+)
+
+var (
+	spanExporter = tracetest.NewInMemoryExporter()
+	installSpans sync.Once
+)
+
+// recordSpans ставит TracerProvider и propagator так же, как это делает tracing.Init, но спаны пишутся в память.
+// Провайдер ставится один раз на пакет: tracer пакета, полученный до первой установки, остается привязан к нему.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	installSpans.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	spanExporter.Reset()
+	return spanExporter
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func spanAttribute(span *tracetest.SpanStub, key attribute.Key) string {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestTracing(t *testing.T) {
+	recorder := recordSpans(t)
+	services := service.Trace(service.NewService(repository.NewMemoryRepository(), service.Config{}))
+	router := NewHandler(services, Config{RequestTimeout: 5 * time.Second}).InitRoutes()
+	token := signUpToken(t, router, "alice")
+	logs := captureLogs(t)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/lists/", strings.NewReader(`{"title":"groceries"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", "00-"+parentTraceId+"-"+parentSpanId+"-01")
+	if rec := serveRequest(router, req); rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/lists/: %d %s", rec.Code, rec.Body)
+	}
+
+	spans := recorder.GetSpans()
+	server := findSpan(spans, "POST /api/lists/")
+	if server == nil {
+		t.Fatalf("no server span, got %d spans", len(spans))
+	}
+	// трейс клиента продолжается, а не начинается заново
+	if got := server.SpanContext.TraceID().String(); got != parentTraceId {
+		t.Errorf("trace id: got %s, want %s", got, parentTraceId)
+	}
+	if got := server.Parent.SpanID().String(); got != parentSpanId || !server.Parent.IsRemote() {
+		t.Errorf("parent: got %s (remote %t), want remote %s", got, server.Parent.IsRemote(), parentSpanId)
+	}
+	if server.SpanKind != trace.SpanKindServer || spanAttribute(server, semconv.HTTPRouteKey) != "/api/lists/" ||
+		spanAttribute(server, semconv.HTTPResponseStatusCodeKey) != "200" {
+		t.Errorf("server span: kind %s, attributes %v", server.SpanKind, server.Attributes)
+	}
+
+	// сервис - дочерний спан запроса
+	create := findSpan(spans, "TodoListService.Create")
+	if create == nil || create.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("service span: got %v, want a child of the server span", create)
+	}
+
+	if entry := findLog(logs(), "request completed"); entry == nil || entry["trace_id"] != parentTraceId {
+		t.Errorf("access log: got %v, want trace_id %s", entry, parentTraceId)
+	}
+}
+
+func TestTracingHidesFeedToken(t *testing.T) {
+	recorder := recordSpans(t)
+	router := newTestRouter(t, service.Config{}, Config{})
+
+	serve(router, http.MethodGet, "/calendar/secret-feed-token/todo.ics", "", "", "")
+
+	span := findSpan(recorder.GetSpans(), "GET /calendar/:token/todo.ics")
+	if span == nil {
+		t.Fatal("no span for the calendar feed")
+	}
+	for _, kv := range span.Attributes {
+		if strings.Contains(kv.Value.Emit(), "secret-feed-token") {
+			t.Errorf("attribute %s leaks the feed token: %s", kv.Key, kv.Value.Emit())
+		}
+	}
+	if got := spanAttribute(span, semconv.URLPathKey); got != "/calendar/:token/todo.ics" {
+		t.Errorf("url.path: got %q, want the route template", got)
+	}
+}
+
+func TestTracingMarksServerErrors(t *testing.T) {
+	recorder := recordSpans(t)
+	router := newTestRouter(t, service.Config{}, Config{})
+	router.GET("/panic", func(*gin.Context) { panic("boom") })
+
+	serve(router, http.MethodGet, "/panic", "", "", "")
+	serve(router, http.MethodGet, "/no/such/path", "", "", "")
+
+	spans := recorder.GetSpans()
+	if span := findSpan(spans, "GET /panic"); span == nil || span.Status.Code != codes.Error {
+		t.Errorf("span of a 500 response: got %v, want status Error", span)
+	}
+	// у ненайденного маршрута нет шаблона, и путь в имя спана не попадает
+	if span := findSpan(spans, "GET"); span == nil || span.Status.Code == codes.Error {
+		t.Errorf("span of an unmatched route: got %v", span)
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type twoFactorCodeInput struct {
+	Code string `json:"code" binding:"required" example:"123456"` // код из приложения или код восстановления
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// @Summary Get two-factor authentication status
+// @Security ApiKeyAuth
+// @Tags 2fa
+// @Description whether TOTP two-factor authentication is enabled and how many recovery codes are left
+// @ID get-two-factor
+// @Produce json
+// @Success 200 {object} models.TwoFactorStatus
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/2fa [get]
+func (h *Handler) getTwoFactor(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	status, err := h.services.TwoFactor.GetTwoFactor(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// @Summary Start two-factor enrollment
+// @Security ApiKeyAuth
+// @Tags 2fa
+// @Description generate a new TOTP secret and an otpauth URI for a QR code. Two-factor authentication is enabled
+// @Description only after the first code is confirmed; calling this again before that replaces the secret.
+// @ID enroll-two-factor
+// @Produce json
+// @Success 200 {object} models.TwoFactorEnrollment
+// @Failure 403 {object} errorResponse
+// @Failure 409 {object} errorResponse "already enabled"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/2fa/enroll [post]
+func (h *Handler) enrollTwoFactor(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	enrollment, err := h.services.TwoFactor.EnrollTwoFactor(c.Request.Context(), userId)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// @Summary Confirm two-factor enrollment
+// @Security ApiKeyAuth
+// @Tags 2fa
+// @Description enable two-factor authentication with the first code from the app and return one-time recovery codes.
+// @Description Recovery codes are shown only once.
+// @ID confirm-two-factor
+// @Accept json
+// @Produce json
+// @Param input body twoFactorCodeInput true "code from the authenticator app"
+// @Success 200 {object} recoveryCodesResponse
+// @Failure 400,403 {object} errorResponse
+// @Failure 409 {object} errorResponse "enrollment is not started or already confirmed"
+// @Failure 422 {object} validationErrorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/2fa/confirm [post]
+func (h *Handler) confirmTwoFactor(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input twoFactorCodeInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	codes, err := h.services.TwoFactor.ConfirmTwoFactor(c.Request.Context(), userId, input.Code)
+	if err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable two-factor authentication
+// @Security ApiKeyAuth
+// @Tags 2fa
+// @Description disable two-factor authentication and delete recovery codes; requires a code from the app or a recovery code
+// @ID disable-two-factor
+// @Accept json
+// @Produce json
+// @Param input body twoFactorCodeInput true "code from the authenticator app or a recovery code"
+// @Success 200 {object} statusResponse
+// @Failure 400,403 {object} errorResponse
+// @Failure 409 {object} errorResponse "not enabled"
+// @Failure 422 {object} validationErrorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/me/2fa [delete]
+func (h *Handler) disableTwoFactor(c *gin.Context) {
+	userId, err := getUserId(c)
+	if err != nil {
+		return
+	}
+
+	var input twoFactorCodeInput
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.TwoFactor.DisableTwoFactor(c.Request.Context(), userId, input.Code); err != nil {
+		newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		Status: "ok",
+	})
+}
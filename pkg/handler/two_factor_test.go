@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/service"
+	"github.com/ponomare0v/todo-go-app/pkg/totp"
+)
+
+// TestTwoFactorCodeReplay - код из приложения принимается один раз: ни повторно, ни кодом шага до уже принятого
+func TestTwoFactorCodeReplay(t *testing.T) {
+	router := newTestRouter(t, service.Config{}, Config{})
+	token := signUpToken(t, router, "alice")
+
+	rec := serve(router, http.MethodPost, "/api/me/2fa/enroll", token, "", "")
+	var enrollment models.TwoFactorEnrollment
+	if err := json.Unmarshal(rec.Body.Bytes(), &enrollment); err != nil || enrollment.Secret == "" {
+		t.Fatalf("enroll: %d %s", rec.Code, rec.Body)
+	}
+	code := func(step int64) string {
+		c, err := totp.Code(enrollment.Secret, step)
+		if err != nil {
+			t.Fatalf("totp.Code: %v", err)
+		}
+		return c
+	}
+	current := totp.Step(time.Now())
+
+	if rec := serve(router, http.MethodPost, "/api/me/2fa/confirm", token, "application/json", `{"code":"`+code(current)+`"}`); rec.Code != http.StatusOK {
+		t.Fatalf("confirm: %d %s", rec.Code, rec.Body)
+	}
+
+	signIn2FA := func(code string) int {
+		rec := serve(router, http.MethodPost, "/auth/sign-in", "", "application/json", `{"username":"alice","password":"secret123"}`)
+		var response signInResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || response.ChallengeToken == "" {
+			t.Fatalf("sign-in: %d %s", rec.Code, rec.Body)
+		}
+		body := `{"challenge_token":"` + response.ChallengeToken + `","code":"` + code + `"}`
+		return serve(router, http.MethodPost, "/auth/sign-in/2fa", "", "application/json", body).Code
+	}
+
+	tests := []struct {
+		name string
+		step int64
+		want int
+	}{
+		{"code used to confirm", current, http.StatusUnauthorized},
+		{"earlier step", current - 1, http.StatusUnauthorized},
+		{"next step", current + 1, http.StatusOK},
+		{"next step again", current + 1, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := signIn2FA(code(tt.step)); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+)
+
+// пространства имен WebDAV (RFC 4918), CalDAV (RFC 4791) и расширения calendarserver с getctag
+const (
+	davNS    = "DAV:"
+	caldavNS = "urn:ietf:params:xml:ns:caldav"
+	csNS     = "http://calendarserver.org/ns/"
+)
+
+// префиксы объявляются в корне multistatus, поэтому значения свойств пишутся готовым XML с ними
+var davPrefixes = map[string]string{davNS: "D", caldavNS: "C", csNS: "CS"}
+
+// свойства, которые не отдаются на allprop: содержимое ресурса клиент запрашивает явно
+var davNotInAllProp = map[xml.Name]bool{{Space: caldavNS, Local: "calendar-data"}: true}
+
+// davProps - свойства ресурса: имя -> содержимое элемента в виде XML
+type davProps map[xml.Name]string
+
+type davResource struct {
+	href  string
+	props davProps
+}
+
+// davPropRequest - какие свойства запросил клиент; пустой список означает allprop
+type davPropRequest struct {
+	names    []xml.Name
+	nameOnly bool // propname: только имена свойств, без значений
+}
+
+// davPropNames собирает имена дочерних элементов <prop>
+type davPropNames []xml.Name
+
+func (p *davPropNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type davPropfind struct {
+	PropName *struct{}    `xml:"DAV: propname"`
+	Prop     davPropNames `xml:"DAV: prop"`
+}
+
+// davCompFilter - фильтр calendar-query по компонентам (RFC 4791, раздел 9.7.1)
+type davCompFilter struct {
+	Name    string          `xml:"name,attr"`
+	Filters []davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type davReport struct {
+	XMLName xml.Name
+	Prop    davPropNames `xml:"DAV: prop"`
+	Hrefs   []string     `xml:"DAV: href"`
+	Filter  *struct {
+		CompFilter davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// тело запросов WebDAV - небольшой XML, больше не нужно
+const maxDAVBodyBytes = 1 << 20
+
+// readDAVBody читает XML-тело запроса в v; пустое тело не ошибка - для PROPFIND оно означает allprop
+func readDAVBody(c *gin.Context, v interface{}) (empty bool, err error) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxDAVBodyBytes))
+	if err != nil {
+		return false, err
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return true, nil
+	}
+	return false, xml.Unmarshal(body, v)
+}
+
+// parsePropfind разбирает тело PROPFIND; на ошибку уже отправлен ответ 400
+func parsePropfind(c *gin.Context) (davPropRequest, bool) {
+	var propfind davPropfind
+	if _, err := readDAVBody(c, &propfind); err != nil {
+		davErrorResponse(c, http.StatusBadRequest, "invalid PROPFIND body: "+err.Error())
+		return davPropRequest{}, false
+	}
+	return davPropRequest{names: propfind.Prop, nameOnly: propfind.PropName != nil}, true
+}
+
+// davDepth читает заголовок Depth. infinity обрабатывается как 1: глубже ресурсов задач ничего нет
+func davDepth(c *gin.Context) int {
+	if c.GetHeader("Depth") == "0" {
+		return 0
+	}
+	return 1
+}
+
+// writeMultistatus отвечает 207: для каждого ресурса найденные свойства - в propstat 200,
+// запрошенные, но неизвестные - в propstat 404 (RFC 4918, раздел 9.1). missing - href без ресурса.
+func writeMultistatus(c *gin.Context, req davPropRequest, resources []davResource, missing []string) {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav" xmlns:CS="http://calendarserver.org/ns/">`)
+	for _, resource := range resources {
+		b.WriteString("<D:response><D:href>" + davText(resource.href) + "</D:href>")
+		found, notFound := resource.props.selectProps(req.names)
+		writePropstat(&b, resource.props, found, req.nameOnly, "200 OK")
+		writePropstat(&b, resource.props, notFound, true, "404 Not Found")
+		b.WriteString("</D:response>")
+	}
+	for _, href := range missing {
+		b.WriteString("<D:response><D:href>" + davText(href) + "</D:href><D:status>HTTP/1.1 404 Not Found</D:status></D:response>")
+	}
+	b.WriteString("</D:multistatus>")
+
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", []byte(b.String()))
+}
+
+// selectProps делит запрошенные свойства на известные и неизвестные ресурсу
+func (p davProps) selectProps(names []xml.Name) (found, notFound []xml.Name) {
+	if len(names) == 0 {
+		for name := range p {
+			if !davNotInAllProp[name] {
+				found = append(found, name)
+			}
+		}
+		sort.Slice(found, func(i, j int) bool {
+			return found[i].Space+" "+found[i].Local < found[j].Space+" "+found[j].Local
+		})
+		return found, nil
+	}
+
+	for _, name := range names {
+		if _, ok := p[name]; ok {
+			found = append(found, name)
+		} else {
+			notFound = append(notFound, name)
+		}
+	}
+	return found, notFound
+}
+
+func writePropstat(b *strings.Builder, props davProps, names []xml.Name, empty bool, status string) {
+	if len(names) == 0 {
+		return
+	}
+	b.WriteString("<D:propstat><D:prop>")
+	for _, name := range names {
+		value := props[name]
+		if empty {
+			value = ""
+		}
+		b.WriteString(davElement(name, value))
+	}
+	b.WriteString("</D:prop><D:status>HTTP/1.1 " + status + "</D:status></D:propstat>")
+}
+
+// davElement пишет элемент с префиксом из davPrefixes, а для чужого пространства имен объявляет его на месте
+func davElement(name xml.Name, inner string) string {
+	tag, attrs := name.Local, ""
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag, attrs = "X:"+name.Local, ` xmlns:X="`+davText(name.Space)+`"`
+	}
+	if inner == "" {
+		return "<" + tag + attrs + "/>"
+	}
+	return "<" + tag + attrs + ">" + inner + "</" + tag + ">"
+}
+
+// davHref - значение свойства со ссылкой на ресурс
+func davHref(href string) string {
+	return "<D:href>" + davText(href) + "</D:href>"
+}
+
+func davText(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// davErrorResponse отвечает на ошибку текстом: клиенты WebDAV смотрят только на код ответа
+func davErrorResponse(c *gin.Context, statusCode int, message string) {
+	logging.FromContext(c.Request.Context()).Error(message)
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.AbortWithStatus(statusCode)
+	_, _ = c.Writer.WriteString(message + "\n")
+}
@@ -0,0 +1,146 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusStarting    = "starting"
+	StatusDraining    = "draining"
+	StatusUnavailable = "unavailable"
+)
+
+// Report - ответ пробы: общий статус и результат каждой проверки (ok или текст ошибки)
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type check struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Checker отвечает на пробы оркестратора:
+//   - liveness - процесс жив и обслуживает HTTP, зависимости не проверяются, чтобы недоступная БД не приводила к рестартам;
+//   - startup - запуск завершен (схема проверена, серверы запущены);
+//   - readiness - запуск завершен, приложение не останавливается и все проверки проходят.
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+
+	started  atomic.Bool
+	draining atomic.Bool
+}
+
+// New - timeout ограничивает время всех проверок одной пробы readiness
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add регистрирует проверку готовности, например пинг БД
+func (c *Checker) Add(name string, fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// Worker регистрирует фоновую горутину (сервер, обработчик очереди) и возвращает функцию,
+// которую горутина вызывает при завершении. После этого readiness не проходит.
+func (c *Checker) Worker(name string) (stopped func(err error)) {
+	var (
+		mu   sync.Mutex
+		done bool
+		last error
+	)
+	c.Add(name, func(context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case !done:
+			return nil
+		case last != nil:
+			return fmt.Errorf("stopped: %w", last)
+		default:
+			return errors.New("stopped")
+		}
+	})
+
+	return func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		done, last = true, err
+	}
+}
+
+// Started отмечает, что запуск завершен
+func (c *Checker) Started() {
+	c.started.Store(true)
+}
+
+// Drain переводит readiness в false перед остановкой: балансировщик перестает слать новые запросы,
+// а уже принятые спокойно дорабатывают
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+func (c *Checker) Live() Report {
+	return Report{Status: StatusOK}
+}
+
+func (c *Checker) Startup() (Report, bool) {
+	if !c.started.Load() {
+		return Report{Status: StatusStarting}, false
+	}
+	return Report{Status: StatusOK}, true
+}
+
+// Ready выполняет все проверки параллельно и возвращает отчет и признак готовности
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	if report, ok := c.Startup(); !ok {
+		return report, false
+	}
+	if c.draining.Load() {
+		return Report{Status: StatusDraining}, false
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = ch.fn(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]string, len(checks))}
+	for i, ch := range checks {
+		if results[i] != nil {
+			report.Status = StatusUnavailable
+			report.Checks[ch.name] = results[i].Error()
+			continue
+		}
+		report.Checks[ch.name] = StatusOK
+	}
+	return report, report.Status == StatusOK
+}
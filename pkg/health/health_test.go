@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChecker(t *testing.T) {
+	ctx := context.Background()
+	c := New(time.Second)
+	var dbErr error
+	c.Add("db", func(context.Context) error { return dbErr })
+	stopped := c.Worker("grpc")
+
+	if report := c.Live(); report.Status != StatusOK {
+		t.Errorf("Live before start: got %+v", report)
+	}
+	if report, ok := c.Startup(); ok || report.Status != StatusStarting {
+		t.Errorf("Startup before start: got %+v, %t", report, ok)
+	}
+	if report, ok := c.Ready(ctx); ok || report.Status != StatusStarting {
+		t.Errorf("Ready before start: got %+v, %t", report, ok)
+	}
+
+	c.Started()
+	if report, ok := c.Ready(ctx); !ok || report.Status != StatusOK || report.Checks["db"] != StatusOK || report.Checks["grpc"] != StatusOK {
+		t.Errorf("Ready: got %+v, %t", report, ok)
+	}
+
+	dbErr = errors.New("connection refused")
+	if report, ok := c.Ready(ctx); ok || report.Status != StatusUnavailable || report.Checks["db"] != "connection refused" || report.Checks["grpc"] != StatusOK {
+		t.Errorf("Ready with the db down: got %+v, %t", report, ok)
+	}
+	dbErr = nil
+
+	stopped(errors.New("listener closed"))
+	if report, ok := c.Ready(ctx); ok || report.Checks["grpc"] != "stopped: listener closed" {
+		t.Errorf("Ready with a stopped worker: got %+v, %t", report, ok)
+	}
+	if report := c.Live(); report.Status != StatusOK {
+		t.Errorf("Live does not depend on checks: got %+v", report)
+	}
+}
+
+func TestCheckerDraining(t *testing.T) {
+	ctx := context.Background()
+	c := New(time.Second)
+	calls := 0
+	c.Add("db", func(context.Context) error { calls++; return nil })
+	c.Started()
+
+	if _, ok := c.Ready(ctx); !ok {
+		t.Fatal("Ready before Drain: not ready")
+	}
+	c.Drain()
+	report, ok := c.Ready(ctx)
+	if ok || report.Status != StatusDraining {
+		t.Errorf("Ready while draining: got %+v, %t", report, ok)
+	}
+	if calls != 1 {
+		t.Errorf("checks ran %d times, want only before Drain", calls)
+	}
+	// остановка не влияет на liveness и startup: процесс жив и дорабатывает принятые запросы
+	if _, ok := c.Startup(); !ok || c.Live().Status != StatusOK {
+		t.Error("Startup or Live failed while draining")
+	}
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	c := New(20 * time.Millisecond)
+	c.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	c.Add("fast", func(context.Context) error { return nil })
+	c.Started()
+
+	start := time.Now()
+	report, ok := c.Ready(context.Background())
+	if ok || report.Checks["slow"] != context.DeadlineExceeded.Error() || report.Checks["fast"] != StatusOK {
+		t.Errorf("Ready: got %+v, %t", report, ok)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Ready took %s, the timeout is 20ms", elapsed)
+	}
+}
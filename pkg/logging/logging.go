@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ctxKey struct{}
+
+// WithEntry кладет в контекст логгер с полями запроса (request_id, user_id и т.д.)
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// FromContext возвращает логгер запроса, а если его нет - стандартный логгер logrus,
+// поэтому вызывать его можно из любого слоя, в том числе вне HTTP-запроса
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(ctxKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// WithFields добавляет поля к логгеру из контекста и возвращает обновленный контекст
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return WithEntry(ctx, FromContext(ctx).WithFields(fields))
+}
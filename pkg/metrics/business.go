@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// businessTimeout ограничивает запрос счетчиков при сборе метрик, чтобы медленная база не вешала скрейп
+const businessTimeout = 5 * time.Second
+
+var (
+	usersDesc     = prometheus.NewDesc(namespace+"_users", "Registered users.", nil, nil)
+	listsDesc     = prometheus.NewDesc(namespace+"_lists", "Todo lists.", nil, nil)
+	itemsDesc     = prometheus.NewDesc(namespace+"_items", "Todo items by state (open, done).", []string{"state"}, nil)
+	businessError = prometheus.NewDesc(namespace+"_business_metrics_error", "1 if the last attempt to count business metrics failed.", nil, nil)
+)
+
+// businessCollector считает бизнес-показатели в момент скрейпа, а не по таймеру: так они всегда актуальны,
+// а база не нагружается, если Prometheus их не спрашивает
+type businessCollector struct {
+	totals func(ctx context.Context) (models.Totals, error)
+}
+
+// RegisterBusiness регистрирует гауги пользователей, списков и задач; totals обычно - services.Admin.GetTotals
+func RegisterBusiness(totals func(ctx context.Context) (models.Totals, error)) error {
+	return Registry.Register(&businessCollector{totals: totals})
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- usersDesc
+	ch <- listsDesc
+	ch <- itemsDesc
+	ch <- businessError
+}
+
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), businessTimeout)
+	defer cancel()
+
+	totals, err := c.totals(ctx)
+	if err != nil {
+		logrus.Errorf("business metrics: %s", err.Error())
+		ch <- prometheus.MustNewConstMetric(businessError, prometheus.GaugeValue, 1)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(businessError, prometheus.GaugeValue, 0)
+	ch <- prometheus.MustNewConstMetric(usersDesc, prometheus.GaugeValue, float64(totals.Users))
+	ch <- prometheus.MustNewConstMetric(listsDesc, prometheus.GaugeValue, float64(totals.Lists))
+	ch <- prometheus.MustNewConstMetric(itemsDesc, prometheus.GaugeValue, float64(totals.Items-totals.DoneItems), "open")
+	ch <- prometheus.MustNewConstMetric(itemsDesc, prometheus.GaugeValue, float64(totals.DoneItems), "done")
+}
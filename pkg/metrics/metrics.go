@@ -0,0 +1,103 @@
+// Package metrics - метрики Prometheus приложения. Все они зарегистрированы в собственном Registry,
+// который отдает Handler, поэтому в выдачу не попадают метрики сторонних библиотек из глобального реестра.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "todo"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	repoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_call_duration_seconds",
+		Help:      "Repository method latency, result is ok or error.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method", "result"})
+
+	authAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_attempts_total",
+		Help:      "Authentication attempts by operation (sign_up, sign_in, token) and result (success, failure, locked).",
+	}, []string{"operation", "result"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requests rejected with 429 by limiter (auth, api).",
+	}, []string{"limiter"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, repoDuration, authAttempts, rateLimited,
+	)
+}
+
+// Handler отдает метрики из Registry в текстовом формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest записывает обработанный HTTP-запрос
+func ObserveRequest(method, route, status string, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(duration.Seconds())
+}
+
+// ObserveRepositoryCall записывает длительность вызова метода репозитория
+func ObserveRepositoryCall(repository, method string, started time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	repoDuration.WithLabelValues(repository, method, result).Observe(time.Since(started).Seconds())
+}
+
+// ObserveAuth считает попытку аутентификации: operation - sign_up, sign_in, sign_in_2fa, password_change, token, app_password, access_token, oidc или oidc_link
+func ObserveAuth(operation string, err error) {
+	result := "success"
+	switch {
+	case errors.Is(err, ratelimit.ErrLocked):
+		result = "locked"
+	case err != nil:
+		result = "failure"
+	}
+	authAttempts.WithLabelValues(operation, result).Inc()
+}
+
+// ObserveRateLimited считает запрос, отклоненный лимитером
+func ObserveRateLimited(limiter string) {
+	rateLimited.WithLabelValues(limiter).Inc()
+}
+
+// RegisterDB добавляет статистику пула соединений (sql.DB.Stats) с меткой db_name
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// права персональных токенов доступа. Чтение и запись разделены: токену скрипта отчетов хватает ScopeRead.
+const (
+	ScopeRead       = "read"        // чтение списков, задач, экспорт и статус импортов
+	ScopeListsWrite = "lists:write" // создание, изменение и удаление списков
+	ScopeItemsWrite = "items:write" // создание, изменение и удаление задач
+)
+
+var knownScopes = map[string]bool{ScopeRead: true, ScopeListsWrite: true, ScopeItemsWrite: true}
+
+// AccessToken - долгоживущий токен для скриптов и интеграций, передается в Authorization вместо JWT.
+// Сам токен хранится только в виде хэша, поэтому Token заполнен лишь в ответе на создание.
+type AccessToken struct {
+	Id         int        `json:"id" db:"id"`
+	UserId     int        `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Scopes     []string   `json:"scopes" db:"-"`
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"` // nil - бессрочный
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	Token      string     `json:"token,omitempty" db:"-"`
+}
+
+func (t *AccessToken) Normalize() {
+	t.Name = strings.TrimSpace(t.Name)
+	t.ExpiresAt = NormalizeDueDate(t.ExpiresAt)
+
+	seen := make(map[string]bool, len(t.Scopes))
+	scopes := make([]string, 0, len(t.Scopes))
+	for _, scope := range t.Scopes {
+		if scope = strings.TrimSpace(scope); !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	t.Scopes = scopes
+}
+
+// Validate проверяет имя, права и срок; CreatedAt должен быть уже заполнен - срок отсчитывается от него
+func (t AccessToken) Validate() error {
+	var v validator
+	if v.required("name", t.Name) {
+		v.length("name", t.Name, 1, maxNameLength)
+	}
+	if len(t.Scopes) == 0 {
+		v.add("scopes", CodeRequired, "at least one scope is required")
+	}
+	for _, scope := range t.Scopes {
+		if !knownScopes[scope] {
+			v.add("scopes", CodeInvalid, "unknown scope "+scope+", must be read, lists:write or items:write")
+		}
+	}
+	if t.ExpiresAt != nil && !t.ExpiresAt.After(t.CreatedAt) {
+		v.add("expires_at", CodeInvalid, "must be in the future")
+	}
+	return v.err()
+}
+
+// HasScope сообщает, разрешено ли токену действие
+func (t AccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Profile - данные пользователя в /api/me
+type Profile struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+type UpdateProfileInput struct {
+	Name string `json:"name"`
+}
+
+// способ входа, которым выпущен JWT
+const (
+	AuthMethodPassword = "password"
+	AuthMethodOIDC     = "oidc"
+)
+
+// Session - проверенный JWT: пользователь, способ входа и время выпуска токена
+type Session struct {
+	UserId     int
+	AuthMethod string
+	IssuedAt   time.Time
+}
+
+// ChangePasswordInput - CurrentPassword можно не указывать сразу после входа через OIDC:
+// так пользователь, созданный через провайдера, задает себе первый пароль
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// DeleteAccountInput - удаление подтверждается текущим паролем или кодом 2FA; сразу после входа через OIDC
+// можно не указывать ни то, ни другое
+type DeleteAccountInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code" example:"123456"` // код из приложения или код восстановления
+}
+
+// AccountExport - все, что хранится о пользователе, кроме списков с задачами: они выгружаются
+// отдельно в формате /api/export. Секреты (хэши паролей и токенов, секрет TOTP) не выгружаются.
+type AccountExport struct {
+	ExportedAt   time.Time       `json:"exported_at"`
+	Profile      Profile         `json:"profile"`
+	TwoFactor    TwoFactorStatus `json:"two_factor"`
+	Identities   []Identity      `json:"identities"`
+	AccessTokens []AccessToken   `json:"access_tokens"`
+	AppPasswords []AppPassword   `json:"app_passwords"`
+	ImportJobs   []ImportJob     `json:"import_jobs"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// ListBackup - список вместе с задачами в формате экспорта и импорта (/api/export, /api/import).
+// Id не переносятся: при импорте списки и задачи всегда создаются заново.
+type ListBackup struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Items       []ItemBackup `json:"items"`
+
+	Row int `json:"-"` // строка исходного файла, с которой начинается список, 0 - формат без строк (JSON)
+}
+
+type ItemBackup struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+
+	Row int `json:"-"`
+}
+
+// Backup - документ экспорта в формате JSON
+type Backup struct {
+	Lists []ListBackup `json:"lists"`
+}
+
+// ImportError - ошибка в одной записи импорта. Field - путь до поля (lists[0].items[1].title)
+// или колонка CSV, Row - строка файла (в CSV с учетом заголовка), для JSON не заполняется.
+type ImportError struct {
+	Row int `json:"row,omitempty"`
+	FieldError
+}
+
+func (e ImportError) Error() string {
+	if e.Row == 0 {
+		return e.Field + ": " + e.Message
+	}
+	return "row " + strconv.Itoa(e.Row) + ": " + e.Field + ": " + e.Message
+}
+
+// ImportReport - что создано (или было бы создано в режиме dry run) и какие записи не прошли проверку.
+// Если ошибки есть, не создается ничего.
+type ImportReport struct {
+	DryRun  bool          `json:"dry_run"`
+	Lists   int           `json:"lists"`
+	Items   int           `json:"items"`
+	ListIds []int         `json:"list_ids,omitempty"`
+	Errors  []ImportError `json:"errors,omitempty"`
+}
+
+func (l *ListBackup) Normalize() {
+	list := l.TodoList()
+	list.Normalize()
+	l.Title, l.Description = list.Title, list.Description
+	for i := range l.Items {
+		item := l.Items[i].TodoItem()
+		item.Normalize()
+		l.Items[i].Title, l.Items[i].Description, l.Items[i].DueDate = item.Title, item.Description, item.DueDate
+	}
+}
+
+func (l ListBackup) TodoList() TodoList {
+	return TodoList{Title: l.Title, Description: l.Description}
+}
+
+func (i ItemBackup) TodoItem() TodoItem {
+	return TodoItem{Title: i.Title, Description: i.Description, Done: i.Done, DueDate: i.DueDate}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AppPassword - пароль приложения для входа по HTTP Basic (CalDAV-клиенты не умеют JWT).
+// Сам пароль хранится только в виде хэша, поэтому Password заполнен лишь в ответе на создание.
+type AppPassword struct {
+	Id        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"` // название устройства или программы, чтобы пароль было проще отозвать
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	Password  string    `json:"password,omitempty" db:"-"`
+}
+
+func (p *AppPassword) Normalize() {
+	p.Name = strings.TrimSpace(p.Name)
+}
+
+func (p AppPassword) Validate() error {
+	var v validator
+	if v.required("name", p.Name) {
+		v.length("name", p.Name, 1, maxNameLength)
+	}
+	return v.err()
+}
+
+// CalendarObject - задача как ресурс CalDAV-коллекции списка
+type CalendarObject struct {
+	Name string   `db:"name"` // последний сегмент пути ресурса, например item-1.ics
+	UID  string   `db:"uid"`  // UID компонента VTODO
+	ETag string   `db:"-"`
+	Item TodoItem `db:"-"`
+}
+
+// Validate проверяет имя и UID, которые задал клиент; они хранятся в колонках VARCHAR(255)
+func (o CalendarObject) Validate() error {
+	var v validator
+	if v.required("name", o.Name) {
+		v.length("name", o.Name, 1, maxNameLength)
+	}
+	if v.required("uid", o.UID) {
+		v.length("uid", o.UID, 1, maxNameLength)
+	}
+	return v.err()
+}
+
+// ItemObject - ресурс задачи, которую создали не через CalDAV: имя и UID строятся из ее id
+func ItemObject(item TodoItem) CalendarObject {
+	id := strconv.Itoa(item.Id)
+	return CalendarObject{Name: "item-" + id + ".ics", UID: "item-" + id + "@todo-go-app", Item: item}
+}
+
+// Calendar - список как CalDAV-коллекция; CTag меняется при любом изменении списка или его задач
+type Calendar struct {
+	List TodoList
+	CTag string
+}
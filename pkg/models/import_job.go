@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// статусы фонового импорта
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// ImportJob - фоновый импорт из другого сервиса (Trello, Todoist). Списки создаются по одному,
+// Done* показывают, сколько уже создано из Total*.
+type ImportJob struct {
+	Id         int       `json:"id" db:"id"`
+	UserId     int       `json:"-" db:"user_id"`
+	Source     string    `json:"source" db:"source"`
+	Status     string    `json:"status" db:"status"`
+	TotalLists int       `json:"total_lists" db:"total_lists"`
+	TotalItems int       `json:"total_items" db:"total_items"`
+	DoneLists  int       `json:"done_lists" db:"done_lists"`
+	DoneItems  int       `json:"done_items" db:"done_items"`
+	Error      string    `json:"error,omitempty" db:"error"`
+	Attempts   int       `json:"-" db:"attempts"` // сколько раз задание брали в работу без прогресса
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Finished - импорт завершен успешно или с ошибкой и больше не выполняется
+func (j ImportJob) Finished() bool {
+	return j.Status == ImportCompleted || j.Status == ImportFailed
+}
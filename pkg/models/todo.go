@@ -1,11 +1,11 @@
 package models
 
-import "errors"
+import "time"
 
 // теги db в наши модели, чтобы иметь возможность сделать выборки из базы
 type TodoList struct {
 	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
+	Title       string `json:"title" db:"title"`
 	Description string `json:"description" db:"description"`
 }
 
@@ -16,10 +16,11 @@ type UserLists struct {
 }
 
 type TodoItem struct {
-	Id          int    `json:"id" db:"id"`
-	Title       string `json:"title" db:"title" binding:"required"`
-	Description string `json:"description" db:"description"`
-	Done        bool   `json:"done" db:"done"`
+	Id          int        `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title"`
+	Description string     `json:"description" db:"description"`
+	Done        bool       `json:"done" db:"done"`
+	DueDate     *time.Time `json:"due_date,omitempty" db:"due_date"` // срок выполнения, nil - без срока
 }
 
 type ListItem struct {
@@ -37,26 +38,88 @@ type UpdateListInput struct {
 	Description *string `json:"description"`
 }
 
+func (i *UpdateListInput) Normalize() {
+	trimPtr(i.Title)
+	trimPtr(i.Description)
+}
+
 func (i UpdateListInput) Validate() error {
+	var v validator
 	if i.Title == nil && i.Description == nil {
-		return errors.New("update sttructure has no values")
+		v.add("", CodeRequired, "update structure has no values")
 	}
-	return nil
+	if i.Title != nil && v.required("title", *i.Title) {
+		v.length("title", *i.Title, 1, MaxTitleLength)
+	}
+	if i.Description != nil {
+		v.length("description", *i.Description, 0, MaxDescriptionLength)
+	}
+	return v.err()
 }
 
 //
 //
 //
 
+// UpdateItemInput - полное обновление через PUT; снять срок выполнения можно только PATCH-запросом с "due_date": null
 type UpdateItemInput struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Done        *bool   `json:"done"`
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Done        *bool      `json:"done"`
+	DueDate     *time.Time `json:"due_date"`
+}
+
+func (i *UpdateItemInput) Normalize() {
+	trimPtr(i.Title)
+	trimPtr(i.Description)
+	i.DueDate = NormalizeDueDate(i.DueDate)
 }
 
 func (i UpdateItemInput) Validate() error {
-	if i.Title == nil && i.Description == nil && i.Done == nil {
-		return errors.New("update sttructure has no values")
+	var v validator
+	if i.Title == nil && i.Description == nil && i.Done == nil && i.DueDate == nil {
+		v.add("", CodeRequired, "update structure has no values")
+	}
+	if i.Title != nil && v.required("title", *i.Title) {
+		v.length("title", *i.Title, 1, MaxTitleLength)
 	}
-	return nil
+	if i.Description != nil {
+		v.length("description", *i.Description, 0, MaxDescriptionLength)
+	}
+	return v.err()
+}
+
+//
+//
+//
+
+// типы тела PATCH-запроса, которые мы умеем применять
+const (
+	MergePatchType = "application/merge-patch+json" // RFC 7396
+	JSONPatchType  = "application/json-patch+json"  // RFC 6902
+)
+
+// Patch - сырое тело PATCH-запроса вместе с его типом
+type Patch struct {
+	Type string
+	Body []byte
+}
+
+//
+//
+//
+
+// типы событий изменения задач
+const (
+	ItemCreated = "created"
+	ItemUpdated = "updated"
+	ItemDeleted = "deleted"
+	ListDeleted = "list_deleted" // подписчикам не отдается: по нему стрим списка узнает, что списка больше нет
+)
+
+// ItemEvent - изменение задачи, которое рассылается подписчикам (например, стриму gRPC)
+type ItemEvent struct {
+	Type   string
+	ListId int
+	Item   TodoItem
 }
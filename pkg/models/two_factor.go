@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// TOTP - секрет двухфакторной аутентификации пользователя. ConfirmedAt == nil - подключение начато,
+// но еще не подтверждено кодом из приложения, и при входе код не спрашивается.
+type TOTP struct {
+	UserId      int        `db:"user_id"`
+	Secret      string     `db:"secret"`
+	LastStep    int64      `db:"last_step"` // последний принятый шаг времени, см. totp.Validate
+	ConfirmedAt *time.Time `db:"confirmed_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+func (t TOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// TwoFactorStatus - состояние 2FA пользователя для /api/me/2fa
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment - начатое подключение: секрет для ручного ввода и otpauth URI для QR-кода
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
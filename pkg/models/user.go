@@ -1,8 +1,44 @@
 package models
 
+import "time"
+
 type User struct {
 	Id       int    `json:"-" db:"id"`
-	Name     string `json:"name" binding:"required"`
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// UserSummary - пользователь в выдаче админских команд, без хэша пароля
+type UserSummary struct {
+	Id       int    `json:"id" db:"id"`
+	Name     string `json:"name" db:"name"`
+	Username string `json:"username" db:"username"`
+	Disabled bool   `json:"disabled" db:"disabled"`
+}
+
+// UserStats - сколько данных у пользователя. SharedLists - списки, доступные еще кому-то кроме него.
+type UserStats struct {
+	UserId      int `json:"user_id" db:"user_id"`
+	Lists       int `json:"lists" db:"lists"`
+	SharedLists int `json:"shared_lists" db:"shared_lists"`
+	Items       int `json:"items" db:"items"`
+	DoneItems   int `json:"done_items" db:"done_items"`
+}
+
+// Totals - объем данных во всем хранилище, для метрик
+type Totals struct {
+	Users     int `json:"users" db:"users"`
+	Lists     int `json:"lists" db:"lists"`
+	Items     int `json:"items" db:"items"`
+	DoneItems int `json:"done_items" db:"done_items"`
+}
+
+// Identity - связка пользователя с аккаунтом у провайдера OpenID Connect: провайдер и sub из ID-токена
+type Identity struct {
+	UserId    int       `json:"-" db:"user_id"`
+	Provider  string    `json:"provider" db:"provider"`
+	Subject   string    `json:"subject" db:"subject"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
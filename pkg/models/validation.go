@@ -0,0 +1,193 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ограничения совпадают с размерами колонок VARCHAR(255) в schema/000001_init.up.sql.
+// Длины названия и описания экспортируются: по ним обрезаются поля при импорте из Trello и Todoist.
+const (
+	MaxTitleLength       = 255
+	MaxDescriptionLength = 255
+	maxNameLength        = 255
+	minUsernameLength    = 3
+	maxUsernameLength    = 32
+	minPasswordLength    = 8
+	maxPasswordLength    = 128
+)
+
+// коды ошибок валидации, на которые может опираться клиент
+const (
+	CodeRequired = "required"
+	CodeTooShort = "too_short"
+	CodeTooLong  = "too_long"
+	CodeInvalid  = "invalid"
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// FieldError - ошибка валидации конкретного поля запроса
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors собирает все ошибки валидации, а не только первую найденную
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		if fieldErr.Field == "" {
+			messages = append(messages, fieldErr.Message)
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// validator накапливает ошибки по мере проверки полей
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+func (v *validator) required(field, value string) bool {
+	if value == "" {
+		v.add(field, CodeRequired, "field is required")
+		return false
+	}
+	return true
+}
+
+func (v *validator) length(field, value string, min, max int) {
+	n := utf8.RuneCountInString(value)
+	if n < min {
+		v.add(field, CodeTooShort, fmt.Sprintf("must be at least %d characters long", min))
+	}
+	if n > max {
+		v.add(field, CodeTooLong, fmt.Sprintf("must be at most %d characters long", max))
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func trimPtr(s *string) {
+	if s != nil {
+		*s = strings.TrimSpace(*s)
+	}
+}
+
+//
+//
+//
+
+func (l *TodoList) Normalize() {
+	l.Title = strings.TrimSpace(l.Title)
+	l.Description = strings.TrimSpace(l.Description)
+}
+
+func (l TodoList) Validate() error {
+	var v validator
+	if v.required("title", l.Title) {
+		v.length("title", l.Title, 1, MaxTitleLength)
+	}
+	v.length("description", l.Description, 0, MaxDescriptionLength)
+	return v.err()
+}
+
+func (i *TodoItem) Normalize() {
+	i.Title = strings.TrimSpace(i.Title)
+	i.Description = strings.TrimSpace(i.Description)
+	i.DueDate = NormalizeDueDate(i.DueDate)
+}
+
+// NormalizeDueDate приводит срок к UTC с точностью до секунды: так он одинаково хранится
+// в Postgres, SQLite и памяти и без потерь попадает в iCalendar
+func NormalizeDueDate(due *time.Time) *time.Time {
+	if due == nil {
+		return nil
+	}
+	t := due.UTC().Truncate(time.Second)
+	return &t
+}
+
+func (i TodoItem) Validate() error {
+	var v validator
+	if v.required("title", i.Title) {
+		v.length("title", i.Title, 1, MaxTitleLength)
+	}
+	v.length("description", i.Description, 0, MaxDescriptionLength)
+	return v.err()
+}
+
+//
+//
+//
+
+func (u *User) Normalize() {
+	u.Name = strings.TrimSpace(u.Name)
+	u.Username = strings.TrimSpace(u.Username)
+}
+
+// Validate проверяет политику имени пользователя и пароля. Пароль не триммится - пробелы в нем допустимы.
+func (u User) Validate() error {
+	var v validator
+	if v.required("name", u.Name) {
+		v.length("name", u.Name, 1, maxNameLength)
+	}
+	if v.required("username", u.Username) {
+		v.length("username", u.Username, minUsernameLength, maxUsernameLength)
+		if !usernamePattern.MatchString(u.Username) {
+			v.add("username", CodeInvalid, "may contain only latin letters, digits, '_', '.' and '-'")
+		}
+	}
+	v.password("password", u.Password)
+	return v.err()
+}
+
+// ValidatePassword проверяет только политику пароля - для смены и сброса пароля
+func ValidatePassword(password string) error {
+	var v validator
+	v.password("password", password)
+	return v.err()
+}
+
+func (i *UpdateProfileInput) Normalize() {
+	i.Name = strings.TrimSpace(i.Name)
+}
+
+func (i UpdateProfileInput) Validate() error {
+	var v validator
+	if v.required("name", i.Name) {
+		v.length("name", i.Name, 1, maxNameLength)
+	}
+	return v.err()
+}
+
+// Validate проверяет политику нового пароля; текущий пароль или недавний вход через OIDC проверяет сервис
+func (i ChangePasswordInput) Validate() error {
+	var v validator
+	v.password("new_password", i.NewPassword)
+	return v.err()
+}
+
+func (v *validator) password(field, value string) {
+	if v.required(field, value) {
+		v.length(field, value, minPasswordLength, maxPasswordLength)
+	}
+}
@@ -0,0 +1,206 @@
+// Package oidc - вход через внешних провайдеров OpenID Connect по authorization code с PKCE.
+// Адреса провайдера берутся из discovery (/.well-known/openid-configuration) при первом входе
+// и кэшируются, поэтому недоступный провайдер не мешает запуску приложения.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// связывание с существующим пользователем по значению claim, совпадающему с users.username
+const (
+	LinkByEmail    = "email"
+	LinkByUsername = "preferred_username"
+)
+
+var ErrUnknownProvider = errors.New("unknown oidc provider")
+
+// ProviderConfig - настройки одного провайдера, Name используется в /auth/oidc/login?provider=<name>
+type ProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string   // пусто - публичный клиент, защищенный только PKCE
+	Scopes       []string // кроме openid, по умолчанию email и profile
+
+	// LinkBy - claim, по которому первый вход связывается с существующим пользователем (email или preferred_username),
+	// пусто - не связывать. Включать только для провайдеров, которые контролирует компания: иначе владелец
+	// чужого аккаунта у провайдера получит доступ к пользователю с тем же логином.
+	LinkBy string
+	// LinkDomains - домены, которым доверяет link_by: email: user@domain связывается с логином user, только если
+	// domain в списке. Для link_by: email обязателен, иначе alice@чужой-домен получила бы аккаунт alice.
+	LinkDomains []string
+	// AutoProvision - создавать пользователя при первом входе, если связать не с кем
+	AutoProvision bool
+}
+
+type Config struct {
+	Providers  []ProviderConfig
+	HTTPClient *http.Client // для discovery, обмена кода и ключей провайдера; nil - клиент с таймаутом 10 секунд
+}
+
+// Claims - нужные приложению claims из ID-токена
+type Claims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// Providers - настроенные провайдеры; нулевое значение и nil - ни одного провайдера
+type Providers struct {
+	configs map[string]ProviderConfig
+	client  *http.Client
+
+	mu         sync.Mutex
+	discovered map[string]*gooidc.Provider
+}
+
+func New(cfg Config) (*Providers, error) {
+	p := &Providers{
+		configs:    make(map[string]ProviderConfig, len(cfg.Providers)),
+		client:     cfg.HTTPClient,
+		discovered: make(map[string]*gooidc.Provider),
+	}
+	if p.client == nil {
+		p.client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	for _, provider := range cfg.Providers {
+		switch {
+		case provider.Name == "":
+			return nil, errors.New("oidc: provider name is required")
+		case provider.Issuer == "" || provider.ClientID == "":
+			return nil, fmt.Errorf("oidc: provider %q needs issuer and client_id", provider.Name)
+		case provider.LinkBy != "" && provider.LinkBy != LinkByEmail && provider.LinkBy != LinkByUsername:
+			return nil, fmt.Errorf("oidc: provider %q: link_by must be %s or %s", provider.Name, LinkByEmail, LinkByUsername)
+		case provider.LinkBy == LinkByEmail && len(provider.LinkDomains) == 0:
+			return nil, fmt.Errorf("oidc: provider %q: link_by %s needs link_domains", provider.Name, LinkByEmail)
+		}
+		if _, ok := p.configs[provider.Name]; ok {
+			return nil, fmt.Errorf("oidc: duplicate provider %q", provider.Name)
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"email", "profile"}
+		}
+		provider.LinkDomains = append([]string(nil), provider.LinkDomains...)
+		for i, domain := range provider.LinkDomains {
+			provider.LinkDomains[i] = strings.ToLower(strings.TrimSpace(domain))
+		}
+		p.configs[provider.Name] = provider
+	}
+	return p, nil
+}
+
+// Names возвращает имена провайдеров по алфавиту
+func (p *Providers) Names() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.configs))
+	for name := range p.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config возвращает настройки провайдера или ErrUnknownProvider
+func (p *Providers) Config(name string) (ProviderConfig, error) {
+	if p == nil {
+		return ProviderConfig{}, ErrUnknownProvider
+	}
+	cfg, ok := p.configs[name]
+	if !ok {
+		return ProviderConfig{}, ErrUnknownProvider
+	}
+	return cfg, nil
+}
+
+// AuthCodeURL - адрес страницы входа провайдера. verifier - PKCE code verifier (oauth2.GenerateVerifier),
+// nonce попадет в ID-токен и проверяется в Exchange.
+func (p *Providers) AuthCodeURL(ctx context.Context, name, redirectURL, state, nonce, verifier string) (string, error) {
+	config, _, err := p.oauth2Config(ctx, name, redirectURL)
+	if err != nil {
+		return "", err
+	}
+	return config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), gooidc.Nonce(nonce)), nil
+}
+
+// Exchange меняет код на токены и проверяет ID-токен: подпись ключом провайдера, issuer, audience, срок и nonce
+func (p *Providers) Exchange(ctx context.Context, name, redirectURL, code, verifier, nonce string) (Claims, error) {
+	config, provider, err := p.oauth2Config(ctx, name, redirectURL)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	token, err := config.Exchange(gooidc.ClientContext(ctx, p.client), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Claims{}, fmt.Errorf("oidc: exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return Claims{}, errors.New("oidc: token response has no id_token")
+	}
+
+	idToken, err := provider.Verifier(&gooidc.Config{ClientID: config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return Claims{}, fmt.Errorf("oidc: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return Claims{}, errors.New("oidc: id token nonce does not match")
+	}
+
+	var claims Claims
+	if err := idToken.Claims(&claims); err != nil {
+		return Claims{}, fmt.Errorf("oidc: %w", err)
+	}
+	claims.Subject = idToken.Subject
+	return claims, nil
+}
+
+func (p *Providers) oauth2Config(ctx context.Context, name, redirectURL string) (oauth2.Config, *gooidc.Provider, error) {
+	cfg, err := p.Config(name)
+	if err != nil {
+		return oauth2.Config{}, nil, err
+	}
+	provider, err := p.discover(ctx, cfg)
+	if err != nil {
+		return oauth2.Config{}, nil, err
+	}
+
+	return oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+		RedirectURL:  redirectURL,
+		Scopes:       append([]string{gooidc.ScopeOpenID}, cfg.Scopes...),
+	}, provider, nil
+}
+
+// discover загружает и кэширует метаданные провайдера; ошибка не кэшируется, следующий вход попробует снова
+func (p *Providers) discover(ctx context.Context, cfg ProviderConfig) (*gooidc.Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if provider, ok := p.discovered[cfg.Name]; ok {
+		return provider, nil
+	}
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, p.client), cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: discovery for %q: %w", cfg.Name, err)
+	}
+	p.discovered[cfg.Name] = provider
+	return provider, nil
+}
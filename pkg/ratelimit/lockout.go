@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
+)
+
+// ErrLocked - вход временно запрещен после серии неудачных попыток
+var ErrLocked = errors.New("too many failed sign-in attempts")
+
+// LockedError сообщает, через сколько можно пробовать снова
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// LockoutPolicy - после Threshold неудачных попыток подряд вход блокируется на Base,
+// каждая следующая неудача после разблокировки удваивает срок, но не больше Max.
+// Счетчик неудач забывается через Window без попыток и сбрасывается успешным входом.
+type LockoutPolicy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Window    time.Duration
+}
+
+// Enabled - нулевой порог, срок или окно означают, что блокировки нет
+func (p LockoutPolicy) Enabled() bool {
+	return p.Threshold > 0 && p.Base > 0 && p.Window > 0
+}
+
+// duration - срок блокировки после failures неудач
+func (p LockoutPolicy) duration(failures int64) time.Duration {
+	d := p.Base
+	for i := int64(p.Threshold); i < failures; i++ {
+		d *= 2
+		if p.Max > 0 && d >= p.Max {
+			return p.Max
+		}
+	}
+	return d
+}
+
+// Lockout ведет неудачные попытки входа по ключу (логину). Ошибки хранилища только логируются:
+// недоступный Redis не должен запрещать вход всем пользователям.
+type Lockout struct {
+	store  Store
+	policy LockoutPolicy
+}
+
+func NewLockout(store Store, policy LockoutPolicy) *Lockout {
+	return &Lockout{store: store, policy: policy}
+}
+
+// Check возвращает *LockedError, если ключ сейчас заблокирован
+func (l *Lockout) Check(ctx context.Context, key string) error {
+	if l == nil || !l.policy.Enabled() {
+		return nil
+	}
+	ttl, err := l.store.TTL(ctx, "lock:"+key)
+	if err != nil {
+		logStoreError(ctx, err)
+		return nil
+	}
+	if ttl > 0 {
+		return &LockedError{RetryAfter: ttl}
+	}
+	return nil
+}
+
+// Fail учитывает неудачную попытку и, если порог пройден, блокирует ключ.
+// Возвращает *LockedError, если эта попытка привела к блокировке.
+func (l *Lockout) Fail(ctx context.Context, key string) error {
+	if l == nil || !l.policy.Enabled() {
+		return nil
+	}
+	failures, err := l.store.Incr(ctx, "fail:"+key, l.policy.Window)
+	if err != nil {
+		logStoreError(ctx, err)
+		return nil
+	}
+	if failures < int64(l.policy.Threshold) {
+		return nil
+	}
+
+	d := l.policy.duration(failures)
+	if err := l.store.Lock(ctx, "lock:"+key, d); err != nil {
+		logStoreError(ctx, err)
+		return nil
+	}
+	return &LockedError{RetryAfter: d}
+}
+
+// Succeed сбрасывает счетчик неудач после успешного входа
+func (l *Lockout) Succeed(ctx context.Context, key string) {
+	if l == nil || !l.policy.Enabled() {
+		return
+	}
+	if err := l.store.Delete(ctx, "fail:"+key); err != nil {
+		logStoreError(ctx, err)
+	}
+}
+
+func logStoreError(ctx context.Context, err error) {
+	logging.FromContext(ctx).Errorf("rate limit store: %s", err.Error())
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// через столько вызовов MemoryStore выбрасывает полные ведра и истекшие ключи, чтобы карта не росла бесконечно
+const memorySweepEvery = 1024
+
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+type memoryKey struct {
+	value   int64
+	expires time.Time
+}
+
+// MemoryStore хранит состояние в памяти процесса: у каждой реплики свои лимиты
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	keys    map[string]memoryKey
+	calls   int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+		keys:    make(map[string]memoryKey),
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (bool, float64, error) {
+	if err := ctx.Err(); err != nil {
+		return false, 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = bucket
+	}
+	bucket.tokens = refill(bucket.tokens, bucket.last, now, limit)
+	bucket.last = now
+	bucket.limit = limit
+
+	if bucket.tokens < 1 {
+		return false, bucket.tokens, nil
+	}
+	bucket.tokens--
+	return true, bucket.tokens, nil
+}
+
+func (s *MemoryStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	entry, ok := s.keys[key]
+	if !ok || !now.Before(entry.expires) {
+		entry = memoryKey{}
+	}
+	entry.value++
+	entry.expires = now.Add(ttl)
+	s.keys[key] = entry
+	return entry.value, nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[key] = memoryKey{value: 1, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.keys[key]
+	if !ok {
+		return 0, nil
+	}
+	if ttl := time.Until(entry.expires); ttl > 0 {
+		return ttl, nil
+	}
+	return 0, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.keys, key)
+	delete(s.buckets, key)
+	return nil
+}
+
+// sweep вызывается под мьютексом: полное ведро ничем не отличается от отсутствующего
+func (s *MemoryStore) sweep(now time.Time) {
+	s.calls++
+	if s.calls%memorySweepEvery != 0 {
+		return
+	}
+	for key, bucket := range s.buckets {
+		if refill(bucket.tokens, bucket.last, now, bucket.limit) >= float64(bucket.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, entry := range s.keys {
+		if !now.Before(entry.expires) {
+			delete(s.keys, key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package ratelimit - ограничение частоты запросов по алгоритму token bucket и блокировка входа
+// после серии неудачных попыток. Состояние хранится в Store: в памяти процесса (одна реплика)
+// или в Redis (общий лимит для всех реплик).
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit - ведро на Burst токенов, которое пополняется на Requests токенов за Period.
+// Каждый запрос забирает один токен; пустое ведро - запрос отклоняется.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Enabled - нулевой лимит означает, что ограничение выключено
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0 && l.Burst > 0
+}
+
+// interval - за сколько времени в ведро возвращается один токен
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Result - решение по одному запросу, из него заполняются заголовки RateLimit-*
+type Result struct {
+	Allowed    bool
+	Limit      int           // емкость ведра
+	Remaining  int           // сколько запросов можно сделать прямо сейчас
+	Reset      time.Duration // через сколько ведро наполнится полностью
+	RetryAfter time.Duration // для отклоненного запроса - через сколько появится токен
+}
+
+// Store хранит ведра и счетчики. Реализации должны выполнять каждый метод атомарно:
+// одно и то же ведро одновременно расходуют все реплики.
+type Store interface {
+	// Take пополняет ведро key на время, прошедшее с прошлого вызова, и пытается забрать из него токен.
+	// Возвращает остаток токенов (дробный, ведро пополняется непрерывно).
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (allowed bool, tokens float64, err error)
+	// Incr увеличивает счетчик key; счетчик удаляется через ttl после последнего увеличения
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Lock создает ключ key, который живет ttl
+	Lock(ctx context.Context, key string, ttl time.Duration) error
+	// TTL - сколько еще живет ключ key, 0 - ключа нет
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// refill - сколько токенов в ведре к моменту now, если в момент last их было tokens
+func refill(tokens float64, last, now time.Time, limit Limit) float64 {
+	if elapsed := now.Sub(last); elapsed > 0 {
+		tokens += float64(elapsed) / float64(limit.interval())
+	}
+	return math.Min(tokens, float64(limit.Burst))
+}
+
+func result(allowed bool, tokens float64, limit Limit) Result {
+	interval := float64(limit.interval())
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration(math.Ceil((float64(limit.Burst) - tokens) * interval)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) * interval))
+	}
+	return res
+}
+
+// Limiter применяет один лимит к ключам из своего пространства имен (auth, api)
+type Limiter struct {
+	store Store
+	name  string
+	limit Limit
+}
+
+func NewLimiter(store Store, name string, limit Limit) *Limiter {
+	return &Limiter{store: store, name: name, limit: limit}
+}
+
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+func (l *Limiter) Limit() Limit {
+	return l.limit
+}
+
+// Allow расходует токен ключа key (IP, id пользователя)
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	if !l.limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	allowed, tokens, err := l.store.Take(ctx, "rl:"+l.name+":"+key, l.limit, time.Now())
+	if err != nil {
+		return Result{}, err
+	}
+	return result(allowed, tokens, l.limit), nil
+}
@@ -0,0 +1,11 @@
+package ratelimit_test
+
+import "testing"
+
+func TestMemoryStore(t *testing.T) {
+	runSuite(t, memoryFactory())
+}
+
+func TestRedisStore(t *testing.T) {
+	runSuite(t, miniredisFactory()) // локальная замена Redis, сервер не нужен
+}
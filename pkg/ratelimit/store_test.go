@@ -0,0 +1,191 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+// storeFactory создает пустое хранилище для одной проверки и функцию, которая сдвигает время
+// для истечения ключей (для miniredis время искусственное, для остальных - просто ожидание)
+type storeFactory func(t *testing.T) (store ratelimit.Store, advance func(time.Duration))
+
+// runSuite прогоняет общий набор проверок на хранилище из factory: в памяти процесса и в Redis
+// ведра и блокировки должны вести себя одинаково
+func runSuite(t *testing.T, factory storeFactory) {
+	t.Run("Bucket", func(t *testing.T) { testBucket(t, factory) })
+	t.Run("BucketKeys", func(t *testing.T) { testBucketKeys(t, factory) })
+	t.Run("Counters", func(t *testing.T) { testCounters(t, factory) })
+	t.Run("Lockout", func(t *testing.T) { testLockout(t, factory) })
+}
+
+func testBucket(t *testing.T, factory storeFactory) {
+	store, _ := factory(t)
+	ctx := context.Background()
+	limit := ratelimit.Limit{Requests: 10, Period: time.Second, Burst: 3} // токен каждые 100ms
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		allowed, tokens, err := store.Take(ctx, "k", limit, now)
+		if err != nil {
+			t.Fatalf("Take %d: %v", i, err)
+		}
+		if !allowed {
+			t.Fatalf("Take %d: denied within burst", i)
+		}
+		if want := float64(2 - i); tokens != want {
+			t.Errorf("Take %d: %v tokens left, want %v", i, tokens, want)
+		}
+	}
+
+	if allowed, _, err := store.Take(ctx, "k", limit, now); err != nil || allowed {
+		t.Fatalf("Take over burst: allowed=%v err=%v, want denied", allowed, err)
+	}
+
+	// половина интервала - токена еще нет
+	if allowed, tokens, _ := store.Take(ctx, "k", limit, now.Add(50*time.Millisecond)); allowed || tokens < 0.49 || tokens > 0.51 {
+		t.Errorf("Take after 50ms: allowed=%v tokens=%v, want denied with 0.5", allowed, tokens)
+	}
+	if allowed, _, _ := store.Take(ctx, "k", limit, now.Add(100*time.Millisecond)); !allowed {
+		t.Error("Take after 100ms: denied, want a refilled token")
+	}
+
+	// ведро не наполняется больше Burst
+	allowed, tokens, err := store.Take(ctx, "k", limit, now.Add(time.Hour))
+	if err != nil || !allowed || tokens != 2 {
+		t.Errorf("Take after an hour: allowed=%v tokens=%v err=%v, want allowed with 2 left", allowed, tokens, err)
+	}
+}
+
+func testBucketKeys(t *testing.T, factory storeFactory) {
+	store, _ := factory(t)
+	ctx := context.Background()
+	limit := ratelimit.Limit{Requests: 1, Period: time.Minute, Burst: 1}
+
+	alice := ratelimit.NewLimiter(store, "api", limit)
+	if res, err := alice.Allow(ctx, "1"); err != nil || !res.Allowed || res.Remaining != 0 || res.Limit != 1 {
+		t.Fatalf("Allow user 1: %+v, %v", res, err)
+	}
+	res, err := alice.Allow(ctx, "1")
+	if err != nil || res.Allowed {
+		t.Fatalf("second Allow user 1: %+v, %v, want denied", res, err)
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want within a minute", res.RetryAfter)
+	}
+
+	if res, err := alice.Allow(ctx, "2"); err != nil || !res.Allowed {
+		t.Errorf("Allow user 2: %+v, %v, want its own bucket", res, err)
+	}
+	// одинаковый ключ в другом лимитере - другое ведро
+	if res, err := ratelimit.NewLimiter(store, "auth", limit).Allow(ctx, "1"); err != nil || !res.Allowed {
+		t.Errorf("Allow key 1 in another limiter: %+v, %v", res, err)
+	}
+
+	if res, err := ratelimit.NewLimiter(store, "off", ratelimit.Limit{}).Allow(ctx, "1"); err != nil || !res.Allowed {
+		t.Errorf("disabled limit: %+v, %v, want allowed", res, err)
+	}
+}
+
+func testCounters(t *testing.T, factory storeFactory) {
+	store, advance := factory(t)
+	ctx := context.Background()
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := store.Incr(ctx, "c", 200*time.Millisecond)
+		if err != nil {
+			t.Fatalf("Incr: %v", err)
+		}
+		if got != want {
+			t.Errorf("Incr = %d, want %d", got, want)
+		}
+	}
+	advance(300 * time.Millisecond)
+	if got, err := store.Incr(ctx, "c", 200*time.Millisecond); err != nil || got != 1 {
+		t.Errorf("Incr after ttl: %d, %v, want a fresh counter", got, err)
+	}
+
+	if ttl, err := store.TTL(ctx, "missing"); err != nil || ttl != 0 {
+		t.Errorf("TTL of a missing key: %v, %v, want 0", ttl, err)
+	}
+	if err := store.Lock(ctx, "l", time.Minute); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if ttl, err := store.TTL(ctx, "l"); err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL after Lock: %v, %v", ttl, err)
+	}
+	if err := store.Delete(ctx, "l"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if ttl, err := store.TTL(ctx, "l"); err != nil || ttl != 0 {
+		t.Errorf("TTL after Delete: %v, %v, want 0", ttl, err)
+	}
+}
+
+func testLockout(t *testing.T, factory storeFactory) {
+	store, advance := factory(t)
+	ctx := context.Background()
+	lockout := ratelimit.NewLockout(store, ratelimit.LockoutPolicy{
+		Threshold: 3,
+		Base:      100 * time.Millisecond,
+		Max:       300 * time.Millisecond,
+		Window:    time.Hour,
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := lockout.Fail(ctx, "alice"); err != nil {
+			t.Fatalf("Fail %d: %v, want no lock below threshold", i, err)
+		}
+	}
+	if err := lockout.Check(ctx, "alice"); err != nil {
+		t.Fatalf("Check below threshold: %v", err)
+	}
+
+	// третья неудача блокирует на Base, каждая следующая - вдвое дольше, но не больше Max
+	for i, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond} {
+		err := lockout.Fail(ctx, "alice")
+		var locked *ratelimit.LockedError
+		if !errors.As(err, &locked) || locked.RetryAfter != want {
+			t.Fatalf("failure %d over threshold: %v, want lock for %v", i+1, err, want)
+		}
+		if err := lockout.Check(ctx, "alice"); !errors.Is(err, ratelimit.ErrLocked) {
+			t.Fatalf("Check while locked: %v, want ErrLocked", err)
+		}
+		advance(want + 50*time.Millisecond)
+		if err := lockout.Check(ctx, "alice"); err != nil {
+			t.Fatalf("Check after the lock expired: %v", err)
+		}
+	}
+
+	if err := lockout.Check(ctx, "bob"); err != nil {
+		t.Errorf("Check for another user: %v", err)
+	}
+
+	lockout.Succeed(ctx, "alice")
+	if err := lockout.Fail(ctx, "alice"); err != nil {
+		t.Errorf("Fail after a successful sign-in: %v, want the counter reset", err)
+	}
+}
+
+// memoryFactory - хранилище в памяти процесса
+func memoryFactory() storeFactory {
+	return func(t *testing.T) (ratelimit.Store, func(time.Duration)) {
+		return ratelimit.NewMemoryStore(), time.Sleep
+	}
+}
+
+// miniredisFactory - RedisStore поверх miniredis, совместимого с Redis сервера в памяти теста
+func miniredisFactory() storeFactory {
+	return func(t *testing.T) (ratelimit.Store, func(time.Duration)) {
+		t.Helper()
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return ratelimit.NewRedisStore(client, "test:"), server.FastForward
+	}
+}
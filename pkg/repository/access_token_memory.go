@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type AccessTokenMemory struct {
+	store *memoryStore
+}
+
+func (r *AccessTokenMemory) CreateAccessToken(ctx context.Context, userId int, token models.AccessToken, tokenHash string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", userId) // в Postgres сработал бы внешний ключ access_tokens
+	}
+	for _, row := range r.store.accessTokens {
+		if row.tokenHash == tokenHash {
+			return 0, errors.New("access token already exists") // ограничение UNIQUE (token_hash)
+		}
+	}
+
+	r.store.lastAccessTokenId++
+	token.Id = r.store.lastAccessTokenId
+	token.UserId = userId
+	token.Token = ""
+	token.LastUsedAt = nil
+	token.Scopes = append([]string(nil), token.Scopes...)
+	r.store.accessTokens[token.Id] = accessTokenRow{tokenHash: tokenHash, AccessToken: token}
+	return token.Id, nil
+}
+
+func (r *AccessTokenMemory) GetAccessTokens(ctx context.Context, userId int) ([]models.AccessToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tokens := []models.AccessToken{}
+	for _, row := range r.store.accessTokens {
+		if row.UserId == userId {
+			tokens = append(tokens, row.AccessToken)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Id < tokens[j].Id })
+	return tokens, nil
+}
+
+func (r *AccessTokenMemory) DeleteAccessToken(ctx context.Context, userId, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.accessTokens[id]
+	if !ok || row.UserId != userId {
+		return sql.ErrNoRows
+	}
+	delete(r.store.accessTokens, id)
+	return nil
+}
+
+func (r *AccessTokenMemory) GetAccessTokenByHash(ctx context.Context, tokenHash string) (models.AccessToken, error) {
+	if err := ctx.Err(); err != nil {
+		return models.AccessToken{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, row := range r.store.accessTokens {
+		if row.tokenHash == tokenHash && !r.store.disabled[row.UserId] {
+			return row.AccessToken, nil
+		}
+	}
+	return models.AccessToken{}, sql.ErrNoRows
+}
+
+func (r *AccessTokenMemory) TouchAccessToken(ctx context.Context, id int, usedAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if row, ok := r.store.accessTokens[id]; ok {
+		row.LastUsedAt = &usedAt
+		r.store.accessTokens[id] = row
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const accessTokensTable = "access_tokens"
+
+// AccessTokenSQL - запросы без диалектных особенностей, общие для Postgres и SQLite
+type AccessTokenSQL struct {
+	db *sqlx.DB
+}
+
+func NewAccessTokenSQL(db *sqlx.DB) *AccessTokenSQL {
+	return &AccessTokenSQL{db: db}
+}
+
+// accessTokenScan - строка access_tokens: права хранятся одной строкой через пробел
+type accessTokenScan struct {
+	models.AccessToken
+	ScopeList string `db:"scopes"`
+}
+
+func (row accessTokenScan) token() models.AccessToken {
+	token := row.AccessToken
+	token.Scopes = strings.Fields(row.ScopeList)
+	return token
+}
+
+const accessTokenColumns = "t.id, t.user_id, t.name, t.scopes, t.expires_at, t.last_used_at, t.created_at"
+
+func (r *AccessTokenSQL) CreateAccessToken(ctx context.Context, userId int, token models.AccessToken, tokenHash string) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, name, token_hash, scopes, expires_at, created_at)
+							VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, accessTokensTable)
+	row := r.db.QueryRowContext(ctx, query, userId, token.Name, tokenHash, strings.Join(token.Scopes, " "), token.ExpiresAt, token.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *AccessTokenSQL) GetAccessTokens(ctx context.Context, userId int) ([]models.AccessToken, error) {
+	var rows []accessTokenScan
+	query := fmt.Sprintf("SELECT %s FROM %s t WHERE t.user_id = $1 ORDER BY t.id", accessTokenColumns, accessTokensTable)
+	if err := r.db.SelectContext(ctx, &rows, query, userId); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]models.AccessToken, 0, len(rows))
+	for _, row := range rows {
+		tokens = append(tokens, row.token())
+	}
+	return tokens, nil
+}
+
+func (r *AccessTokenSQL) DeleteAccessToken(ctx context.Context, userId, id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", accessTokensTable)
+	res, err := r.db.ExecContext(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *AccessTokenSQL) GetAccessTokenByHash(ctx context.Context, tokenHash string) (models.AccessToken, error) {
+	var row accessTokenScan
+	query := fmt.Sprintf(`SELECT %s FROM %s t INNER JOIN %s u on u.id = t.user_id
+							WHERE t.token_hash = $1 AND NOT u.disabled`, accessTokenColumns, accessTokensTable, usersTable)
+	if err := r.db.GetContext(ctx, &row, query, tokenHash); err != nil {
+		return models.AccessToken{}, err
+	}
+
+	return row.token(), nil
+}
+
+func (r *AccessTokenSQL) TouchAccessToken(ctx context.Context, id int, usedAt time.Time) error {
+	query := fmt.Sprintf("UPDATE %s SET last_used_at = $1 WHERE id = $2", accessTokensTable)
+	_, err := r.db.ExecContext(ctx, query, usedAt, id)
+
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type AccountMemory struct {
+	store *memoryStore
+}
+
+func (r *AccountMemory) UpdateName(ctx context.Context, userId int, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	user.Name = name
+	r.store.users[userId] = user
+	return nil
+}
+
+func (r *AccountMemory) CheckPassword(ctx context.Context, userId int, passwordHash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[userId]
+	if !ok || user.Password != passwordHash {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *AccountMemory) ChangePassword(ctx context.Context, userId int, passwordHash string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	user.Password = passwordHash
+	r.store.users[userId] = user
+	r.store.sessionVersions[userId]++
+	return r.store.sessionVersions[userId], nil
+}
+
+func (r *AccountMemory) GetSessionVersion(ctx context.Context, userId int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if _, ok := r.store.users[userId]; !ok || r.store.disabled[userId] {
+		return 0, sql.ErrNoRows
+	}
+	return r.store.sessionVersions[userId], nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AccountSQL - запросы без диалектных особенностей, общие для Postgres и SQLite
+type AccountSQL struct {
+	db *sqlx.DB
+}
+
+func NewAccountSQL(db *sqlx.DB) *AccountSQL {
+	return &AccountSQL{db: db}
+}
+
+func (r *AccountSQL) UpdateName(ctx context.Context, userId int, name string) error {
+	query := fmt.Sprintf("UPDATE %s SET name = $1 WHERE id = $2", usersTable)
+	return execAffected(ctx, r.db, query, name, userId)
+}
+
+func (r *AccountSQL) CheckPassword(ctx context.Context, userId int, passwordHash string) error {
+	var id int
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id = $1 AND password_hash = $2", usersTable)
+	return r.db.GetContext(ctx, &id, query, userId, passwordHash)
+}
+
+func (r *AccountSQL) ChangePassword(ctx context.Context, userId int, passwordHash string) (int, error) {
+	var version int
+	query := fmt.Sprintf("UPDATE %s SET password_hash = $1, session_version = session_version + 1 WHERE id = $2 RETURNING session_version", usersTable)
+	err := r.db.GetContext(ctx, &version, query, passwordHash, userId)
+
+	return version, err
+}
+
+func (r *AccountSQL) GetSessionVersion(ctx context.Context, userId int) (int, error) {
+	var version int
+	query := fmt.Sprintf("SELECT session_version FROM %s WHERE id = $1 AND NOT disabled", usersTable)
+	err := r.db.GetContext(ctx, &version, query, userId)
+
+	return version, err
+}
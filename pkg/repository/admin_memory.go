@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type AdminMemory struct {
+	store *memoryStore
+}
+
+func (r *AdminMemory) summary(user models.User) models.UserSummary {
+	return models.UserSummary{Id: user.Id, Name: user.Name, Username: user.Username, Disabled: r.store.disabled[user.Id]}
+}
+
+func (r *AdminMemory) GetUsers(ctx context.Context) ([]models.UserSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	users := make([]models.UserSummary, 0, len(r.store.users))
+	for _, user := range r.store.users {
+		users = append(users, r.summary(user))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Id < users[j].Id })
+
+	return users, nil
+}
+
+func (r *AdminMemory) GetUserByUsername(ctx context.Context, username string) (models.UserSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return models.UserSummary{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Username == username {
+			return r.summary(user), nil
+		}
+	}
+	return models.UserSummary{}, sql.ErrNoRows
+}
+
+func (r *AdminMemory) GetUserById(ctx context.Context, userId int) (models.UserSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return models.UserSummary{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return models.UserSummary{}, sql.ErrNoRows
+	}
+	return r.summary(user), nil
+}
+
+func (r *AdminMemory) SetDisabled(ctx context.Context, userId int, disabled bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userId]; !ok {
+		return sql.ErrNoRows
+	}
+	if disabled {
+		r.store.disabled[userId] = true
+	} else {
+		delete(r.store.disabled, userId)
+	}
+	r.store.sessionVersions[userId]++
+	return nil
+}
+
+func (r *AdminMemory) SetPassword(ctx context.Context, userId int, passwordHash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userId]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	user.Password = passwordHash
+	r.store.users[userId] = user
+	r.store.sessionVersions[userId]++
+	return nil
+}
+
+func (r *AdminMemory) TransferList(ctx context.Context, listId, fromUserId, toUserId int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !r.store.hasList(fromUserId, listId) {
+		return sql.ErrNoRows
+	}
+	if _, ok := r.store.users[toUserId]; !ok {
+		return fmt.Errorf("user %d does not exist", toUserId) // в SQL сработал бы внешний ключ users_lists
+	}
+
+	delete(r.store.usersLists[fromUserId], listId)
+	if r.store.usersLists[toUserId] == nil {
+		r.store.usersLists[toUserId] = make(map[int]bool)
+	}
+	r.store.usersLists[toUserId][listId] = true
+	return nil
+}
+
+func (r *AdminMemory) GetStats(ctx context.Context, userId int) (models.UserStats, error) {
+	stats := models.UserStats{UserId: userId}
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for listId := range r.store.usersLists[userId] {
+		stats.Lists++
+		if !r.soloList(userId, listId) {
+			stats.SharedLists++
+		}
+	}
+	for itemId, listId := range r.store.listsItems {
+		if !r.store.hasList(userId, listId) {
+			continue
+		}
+		stats.Items++
+		if r.store.items[itemId].Done {
+			stats.DoneItems++
+		}
+	}
+	return stats, nil
+}
+
+func (r *AdminMemory) DeleteUser(ctx context.Context, userId int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userId]; !ok {
+		return sql.ErrNoRows
+	}
+
+	for listId := range r.store.usersLists[userId] {
+		if r.soloList(userId, listId) {
+			r.store.deleteList(listId)
+		}
+	}
+	delete(r.store.usersLists, userId)
+	delete(r.store.disabled, userId)
+	delete(r.store.sessionVersions, userId)
+	delete(r.store.feeds, userId)
+	for id, password := range r.store.appPasswords {
+		if password.userId == userId {
+			delete(r.store.appPasswords, id)
+		}
+	}
+	for id, job := range r.store.importJobs {
+		if job.UserId == userId {
+			delete(r.store.importJobs, id)
+		}
+	}
+	for id, token := range r.store.accessTokens {
+		if token.UserId == userId {
+			delete(r.store.accessTokens, id)
+		}
+	}
+	for key, identity := range r.store.identities {
+		if identity.UserId == userId {
+			delete(r.store.identities, key)
+		}
+	}
+	delete(r.store.totp, userId)
+	delete(r.store.recoveryCodes, userId)
+	delete(r.store.users, userId)
+	return nil
+}
+
+func (r *AdminMemory) GetTotals(ctx context.Context) (models.Totals, error) {
+	if err := ctx.Err(); err != nil {
+		return models.Totals{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	totals := models.Totals{Users: len(r.store.users), Lists: len(r.store.lists), Items: len(r.store.items)}
+	for _, item := range r.store.items {
+		if item.Done {
+			totals.DoneItems++
+		}
+	}
+	return totals, nil
+}
+
+// soloList - к списку есть доступ только у userId
+func (r *AdminMemory) soloList(userId, listId int) bool {
+	for otherId, lists := range r.store.usersLists {
+		if otherId != userId && lists[listId] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// AdminSQL - админские запросы без диалектных конструкций (UPDATE ... FROM, ILIKE и т.п.),
+// поэтому одна реализация работает и в Postgres, и в SQLite
+type AdminSQL struct {
+	db *sqlx.DB
+}
+
+func NewAdminSQL(db *sqlx.DB) *AdminSQL {
+	return &AdminSQL{db: db}
+}
+
+// soloListsQuery - списки пользователя $1, к которым больше ни у кого нет доступа
+var soloListsQuery = fmt.Sprintf(`SELECT ul.list_id FROM %s ul WHERE ul.user_id = $1
+							AND NOT EXISTS (SELECT 1 FROM %s o WHERE o.list_id = ul.list_id AND o.user_id <> $1)`,
+	usersListsTable, usersListsTable)
+
+func (r *AdminSQL) GetUsers(ctx context.Context) ([]models.UserSummary, error) {
+	var users []models.UserSummary
+	query := fmt.Sprintf("SELECT id, name, username, disabled FROM %s ORDER BY id", usersTable)
+	err := r.db.SelectContext(ctx, &users, query)
+
+	return users, err
+}
+
+func (r *AdminSQL) GetUserByUsername(ctx context.Context, username string) (models.UserSummary, error) {
+	var user models.UserSummary
+	query := fmt.Sprintf("SELECT id, name, username, disabled FROM %s WHERE username = $1", usersTable)
+	err := r.db.GetContext(ctx, &user, query, username)
+
+	return user, err
+}
+
+func (r *AdminSQL) GetUserById(ctx context.Context, userId int) (models.UserSummary, error) {
+	var user models.UserSummary
+	query := fmt.Sprintf("SELECT id, name, username, disabled FROM %s WHERE id = $1", usersTable)
+	err := r.db.GetContext(ctx, &user, query, userId)
+
+	return user, err
+}
+
+// SetDisabled и SetPassword увеличивают версию сессий, как Account.ChangePassword: выпущенные JWT отзываются
+func (r *AdminSQL) SetDisabled(ctx context.Context, userId int, disabled bool) error {
+	query := fmt.Sprintf("UPDATE %s SET disabled = $1, session_version = session_version + 1 WHERE id = $2", usersTable)
+	return execAffected(ctx, r.db, query, disabled, userId)
+}
+
+func (r *AdminSQL) SetPassword(ctx context.Context, userId int, passwordHash string) error {
+	query := fmt.Sprintf("UPDATE %s SET password_hash = $1, session_version = session_version + 1 WHERE id = $2", usersTable)
+	return execAffected(ctx, r.db, query, passwordHash, userId)
+}
+
+// TransferList переносит связку списка в users_lists с одного пользователя на другого.
+// Если у получателя список уже есть, у отправителя связка просто удаляется.
+func (r *AdminSQL) TransferList(ctx context.Context, listId, fromUserId, toUserId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND list_id = $2", usersListsTable)
+	if err := execAffected(ctx, tx, deleteQuery, fromUserId, listId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2) ON CONFLICT (user_id, list_id) DO NOTHING", usersListsTable)
+	if _, err := tx.ExecContext(ctx, insertQuery, toUserId, listId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AdminSQL) GetStats(ctx context.Context, userId int) (models.UserStats, error) {
+	stats := models.UserStats{UserId: userId}
+	query := fmt.Sprintf(`SELECT
+			(SELECT COUNT(*) FROM %[1]s WHERE user_id = $1) AS lists,
+			(SELECT COUNT(*) FROM %[1]s ul WHERE ul.user_id = $1
+				AND EXISTS (SELECT 1 FROM %[1]s o WHERE o.list_id = ul.list_id AND o.user_id <> $1)) AS shared_lists,
+			(SELECT COUNT(*) FROM %[2]s li INNER JOIN %[1]s ul on ul.list_id = li.list_id WHERE ul.user_id = $1) AS items,
+			(SELECT COUNT(*) FROM %[3]s ti INNER JOIN %[2]s li on li.item_id = ti.id INNER JOIN %[1]s ul on ul.list_id = li.list_id
+				WHERE ul.user_id = $1 AND ti.done) AS done_items`,
+		usersListsTable, listsItemsTable, todoItemsTable)
+	row := r.db.QueryRowContext(ctx, query, userId)
+	err := row.Scan(&stats.Lists, &stats.SharedLists, &stats.Items, &stats.DoneItems)
+
+	return stats, err
+}
+
+// DeleteUser удаляет пользователя вместе со списками и задачами, которыми больше никто не пользуется.
+// Общие списки остаются остальным участникам, у пользователя пропадает только связка в users_lists (ON DELETE CASCADE).
+func (r *AdminSQL) DeleteUser(ctx context.Context, userId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	deleteItemsQuery := fmt.Sprintf("DELETE FROM %s WHERE id IN (SELECT item_id FROM %s WHERE list_id IN (%s))",
+		todoItemsTable, listsItemsTable, soloListsQuery)
+	if _, err := tx.ExecContext(ctx, deleteItemsQuery, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	deleteListsQuery := fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)", todoListsTable, soloListsQuery)
+	if _, err := tx.ExecContext(ctx, deleteListsQuery, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	deleteUserQuery := fmt.Sprintf("DELETE FROM %s WHERE id = $1", usersTable)
+	if err := execAffected(ctx, tx, deleteUserQuery, userId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetTotals считает все строки todo_items: задачи удаляются вместе со списком, поэтому задач без списка нет
+func (r *AdminSQL) GetTotals(ctx context.Context) (models.Totals, error) {
+	var totals models.Totals
+	query := fmt.Sprintf(`SELECT
+			(SELECT COUNT(*) FROM %[1]s) AS users,
+			(SELECT COUNT(*) FROM %[2]s) AS lists,
+			(SELECT COUNT(*) FROM %[3]s) AS items,
+			(SELECT COUNT(*) FROM %[3]s WHERE done) AS done_items`,
+		usersTable, todoListsTable, todoItemsTable)
+	err := r.db.GetContext(ctx, &totals, query)
+
+	return totals, err
+}
+
+// execAffected выполняет запрос и возвращает sql.ErrNoRows, если он не затронул ни одной строки
+func execAffected(ctx context.Context, db sqlx.ExecerContext, query string, args ...interface{}) error {
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type AppPasswordMemory struct {
+	store *memoryStore
+}
+
+func (r *AppPasswordMemory) CreateAppPassword(ctx context.Context, userId int, password models.AppPassword, passwordHash string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", userId) // в Postgres сработал бы внешний ключ app_passwords
+	}
+	for _, row := range r.store.appPasswords {
+		if row.passwordHash == passwordHash {
+			return 0, errors.New("app password already exists") // ограничение UNIQUE (password_hash)
+		}
+	}
+
+	r.store.lastAppPasswordId++
+	password.Id = r.store.lastAppPasswordId
+	password.Password = ""
+	r.store.appPasswords[password.Id] = appPasswordRow{userId: userId, passwordHash: passwordHash, AppPassword: password}
+	return password.Id, nil
+}
+
+func (r *AppPasswordMemory) GetAppPasswords(ctx context.Context, userId int) ([]models.AppPassword, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	passwords := []models.AppPassword{}
+	for _, row := range r.store.appPasswords {
+		if row.userId == userId {
+			passwords = append(passwords, row.AppPassword)
+		}
+	}
+	sort.Slice(passwords, func(i, j int) bool { return passwords[i].Id < passwords[j].Id })
+	return passwords, nil
+}
+
+func (r *AppPasswordMemory) DeleteAppPassword(ctx context.Context, userId, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.appPasswords[id]
+	if !ok || row.userId != userId {
+		return sql.ErrNoRows
+	}
+	delete(r.store.appPasswords, id)
+	return nil
+}
+
+func (r *AppPasswordMemory) GetAppPasswordUser(ctx context.Context, username, passwordHash string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, row := range r.store.appPasswords {
+		if row.passwordHash == passwordHash && r.store.users[row.userId].Username == username && !r.store.disabled[row.userId] {
+			return row.userId, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
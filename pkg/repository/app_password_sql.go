@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const appPasswordsTable = "app_passwords"
+
+// AppPasswordSQL - запросы без диалектных особенностей, общие для Postgres и SQLite
+type AppPasswordSQL struct {
+	db *sqlx.DB
+}
+
+func NewAppPasswordSQL(db *sqlx.DB) *AppPasswordSQL {
+	return &AppPasswordSQL{db: db}
+}
+
+func (r *AppPasswordSQL) CreateAppPassword(ctx context.Context, userId int, password models.AppPassword, passwordHash string) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (user_id, name, password_hash, created_at) VALUES ($1, $2, $3, $4) RETURNING id", appPasswordsTable)
+	row := r.db.QueryRowContext(ctx, query, userId, password.Name, passwordHash, password.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *AppPasswordSQL) GetAppPasswords(ctx context.Context, userId int) ([]models.AppPassword, error) {
+	passwords := []models.AppPassword{}
+	query := fmt.Sprintf("SELECT id, name, created_at FROM %s WHERE user_id = $1 ORDER BY id", appPasswordsTable)
+	err := r.db.SelectContext(ctx, &passwords, query, userId)
+
+	return passwords, err
+}
+
+func (r *AppPasswordSQL) DeleteAppPassword(ctx context.Context, userId, id int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND user_id = $2", appPasswordsTable)
+	res, err := r.db.ExecContext(ctx, query, id, userId)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *AppPasswordSQL) GetAppPasswordUser(ctx context.Context, username, passwordHash string) (int, error) {
+	var userId int
+	query := fmt.Sprintf(`SELECT ap.user_id FROM %s ap INNER JOIN %s u on u.id = ap.user_id
+							WHERE u.username = $1 AND ap.password_hash = $2 AND NOT u.disabled`, appPasswordsTable, usersTable)
+	err := r.db.GetContext(ctx, &userId, query, username, passwordHash)
+
+	return userId, err
+}
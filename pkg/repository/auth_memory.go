@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type AuthMemory struct {
+	store *memoryStore
+}
+
+func (r *AuthMemory) CreateUser(ctx context.Context, user models.User) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, existing := range r.store.users {
+		if existing.Username == user.Username {
+			return 0, fmt.Errorf("user %q already exists", user.Username)
+		}
+	}
+
+	r.store.lastUserId++
+	user.Id = r.store.lastUserId
+	r.store.users[user.Id] = user
+
+	return user.Id, nil
+}
+
+func (r *AuthMemory) GetUser(ctx context.Context, username, password string) (models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return models.User{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Username == username && user.Password == password && !r.store.disabled[user.Id] {
+			return models.User{Id: user.Id}, nil // Postgres-реализация тоже выбирает только id
+		}
+	}
+	return models.User{}, sql.ErrNoRows
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
@@ -15,11 +16,11 @@ func NewAuthPostgres(db *sqlx.DB) *AuthPostgres {
 	return &AuthPostgres{db: db}
 }
 
-func (r *AuthPostgres) CreateUser(user models.User) (int, error) {
+func (r *AuthPostgres) CreateUser(ctx context.Context, user models.User) (int, error) {
 	var id int
 	query := fmt.Sprintf("INSERT INTO %s (name, username, password_hash) VALUES ($1, $2, $3) RETURNING id", usersTable)
 
-	row := r.db.QueryRow(query, user.Name, user.Username, user.Password)
+	row := r.db.QueryRowContext(ctx, query, user.Name, user.Username, user.Password)
 	//Метод QueryRaw возвращает объект raw, то есть он хранит в себе информацию о возвращаемой строке из базы (в нашем случае запрос в
 	//  бд возвращает одну строку со значением поля id).
 	if err := row.Scan(&id); err != nil {
@@ -28,11 +29,11 @@ func (r *AuthPostgres) CreateUser(user models.User) (int, error) {
 	return id, nil
 }
 
-func (r *AuthPostgres) GetUser(username, password string) (models.User, error) {
+func (r *AuthPostgres) GetUser(ctx context.Context, username, password string) (models.User, error) {
 	var user models.User
 
-	query := fmt.Sprintf("SELECT id from %s WHERE username=$1 AND password_hash=$2", usersTable)
-	err := r.db.Get(&user, query, username, password)
+	query := fmt.Sprintf("SELECT id from %s WHERE username=$1 AND password_hash=$2 AND NOT disabled", usersTable)
+	err := r.db.GetContext(ctx, &user, query, username, password)
 
 	return user, err
 }
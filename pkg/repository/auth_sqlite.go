@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type AuthSQLite struct {
+	db *sqlx.DB
+}
+
+func NewAuthSQLite(db *sqlx.DB) *AuthSQLite {
+	return &AuthSQLite{db: db}
+}
+
+func (r *AuthSQLite) CreateUser(ctx context.Context, user models.User) (int, error) {
+	var id int
+	query := fmt.Sprintf("INSERT INTO %s (name, username, password_hash) VALUES ($1, $2, $3) RETURNING id", usersTable)
+
+	row := r.db.QueryRowContext(ctx, query, user.Name, user.Username, user.Password)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *AuthSQLite) GetUser(ctx context.Context, username, password string) (models.User, error) {
+	var user models.User
+
+	query := fmt.Sprintf("SELECT id from %s WHERE username=$1 AND password_hash=$2 AND NOT disabled", usersTable)
+	err := r.db.GetContext(ctx, &user, query, username, password)
+
+	return user, err
+}
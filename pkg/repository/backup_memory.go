@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type BackupMemory struct {
+	store *memoryStore
+}
+
+// ImportLists держит блокировку хранилища на весь импорт, поэтому другие запросы не увидят его наполовину
+func (r *BackupMemory) ImportLists(ctx context.Context, userId int, lists []models.ListBackup) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userId]; !ok {
+		return nil, fmt.Errorf("user %d does not exist", userId) // в Postgres сработал бы внешний ключ users_lists
+	}
+
+	ids := make([]int, 0, len(lists))
+	for _, list := range lists {
+		ids = append(ids, r.store.insertListBackup(userId, list))
+	}
+
+	return ids, nil
+}
+
+// insertListBackup создает список пользователя с задачами; блокировку держит вызывающий
+func (s *memoryStore) insertListBackup(userId int, list models.ListBackup) int {
+	if s.usersLists[userId] == nil {
+		s.usersLists[userId] = make(map[int]bool)
+	}
+
+	s.lastListId++
+	listId := s.lastListId
+	s.lists[listId] = models.TodoList{Id: listId, Title: list.Title, Description: list.Description}
+	s.usersLists[userId][listId] = true
+
+	for _, item := range list.Items {
+		s.lastItemId++
+		itemId := s.lastItemId
+		s.items[itemId] = models.TodoItem{Id: itemId, Title: item.Title, Description: item.Description, Done: item.Done, DueDate: item.DueDate}
+		s.listsItems[itemId] = listId
+	}
+	return listId
+}
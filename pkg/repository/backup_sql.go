@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// BackupSQL - импорт обычными INSERT ... RETURNING, которые понимают и Postgres, и SQLite
+type BackupSQL struct {
+	db *sqlx.DB
+}
+
+func NewBackupSQL(db *sqlx.DB) *BackupSQL {
+	return &BackupSQL{db: db}
+}
+
+func (r *BackupSQL) ImportLists(ctx context.Context, userId int, lists []models.ListBackup) ([]int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(lists))
+	for _, list := range lists {
+		listId, err := insertListBackup(ctx, tx, userId, list)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		ids = append(ids, listId)
+	}
+
+	return ids, tx.Commit()
+}
+
+// insertListBackup создает список пользователя с задачами в транзакции tx, которую откатывает вызывающий
+func insertListBackup(ctx context.Context, tx *sqlx.Tx, userId int, list models.ListBackup) (int, error) {
+	createListQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id", todoListsTable)
+	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2)", usersListsTable)
+	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description, done, due_date) VALUES ($1, $2, $3, $4) RETURNING id", todoItemsTable)
+	createListItemQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) VALUES ($1, $2)", listsItemsTable)
+
+	var listId int
+	if err := tx.QueryRowContext(ctx, createListQuery, list.Title, list.Description).Scan(&listId); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, createUsersListQuery, userId, listId); err != nil {
+		return 0, err
+	}
+
+	for _, item := range list.Items {
+		var itemId int
+		if err := tx.QueryRowContext(ctx, createItemQuery, item.Title, item.Description, item.Done, item.DueDate).Scan(&itemId); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, createListItemQuery, listId, itemId); err != nil {
+			return 0, err
+		}
+	}
+	return listId, nil
+}
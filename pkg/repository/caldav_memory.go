@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type CalDAVMemory struct {
+	store *memoryStore
+}
+
+func (r *CalDAVMemory) GetObjects(ctx context.Context, userId int, listIds []int) (map[int]models.CalendarObject, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(listIds))
+	for _, listId := range listIds {
+		wanted[listId] = r.store.hasList(userId, listId)
+	}
+
+	objects := make(map[int]models.CalendarObject)
+	for itemId, object := range r.store.caldavObjects {
+		if wanted[r.store.listsItems[itemId]] {
+			objects[itemId] = object
+		}
+	}
+	return objects, nil
+}
+
+func (r *CalDAVMemory) SetObject(ctx context.Context, itemId int, object models.CalendarObject) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.items[itemId]; !ok {
+		return fmt.Errorf("item %d does not exist", itemId) // в Postgres сработал бы внешний ключ caldav_objects
+	}
+	r.store.caldavObjects[itemId] = models.CalendarObject{Name: object.Name, UID: object.UID}
+	return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const caldavObjectsTable = "caldav_objects"
+
+// CalDAVSQL - список id разворачивается через sqlx.In и Rebind, поэтому запросы общие для Postgres и SQLite
+type CalDAVSQL struct {
+	db *sqlx.DB
+}
+
+func NewCalDAVSQL(db *sqlx.DB) *CalDAVSQL {
+	return &CalDAVSQL{db: db}
+}
+
+func (r *CalDAVSQL) GetObjects(ctx context.Context, userId int, listIds []int) (map[int]models.CalendarObject, error) {
+	objects := make(map[int]models.CalendarObject)
+	if len(listIds) == 0 {
+		return objects, nil // sqlx.In не умеет разворачивать пустой слайс
+	}
+
+	var rows []struct {
+		ItemId int `db:"item_id"`
+		models.CalendarObject
+	}
+	query, args, err := sqlx.In(fmt.Sprintf(`SELECT co.item_id, co.name, co.uid FROM %s co INNER JOIN %s li on li.item_id = co.item_id
+							INNER JOIN %s ul on ul.list_id = li.list_id WHERE li.list_id IN (?) AND ul.user_id = ?`,
+		caldavObjectsTable, listsItemsTable, usersListsTable), listIds, userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		objects[row.ItemId] = row.CalendarObject
+	}
+	return objects, nil
+}
+
+func (r *CalDAVSQL) SetObject(ctx context.Context, itemId int, object models.CalendarObject) error {
+	query := fmt.Sprintf(`INSERT INTO %s (item_id, name, uid) VALUES ($1, $2, $3)
+							ON CONFLICT (item_id) DO UPDATE SET name = excluded.name, uid = excluded.uid`, caldavObjectsTable)
+	_, err := r.db.ExecContext(ctx, query, itemId, object.Name, object.UID)
+	return err
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+type CalendarFeedMemory struct {
+	store *memoryStore
+}
+
+func (r *CalendarFeedMemory) SetFeedToken(ctx context.Context, userId int, tokenHash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userId]; !ok {
+		return fmt.Errorf("user %d does not exist", userId) // в Postgres сработал бы внешний ключ calendar_feeds
+	}
+	for otherId, hash := range r.store.feeds {
+		if hash == tokenHash && otherId != userId {
+			return errors.New("feed token already exists") // ограничение UNIQUE (token_hash)
+		}
+	}
+	r.store.feeds[userId] = tokenHash
+	return nil
+}
+
+func (r *CalendarFeedMemory) DeleteFeedToken(ctx context.Context, userId int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.feeds, userId)
+	return nil
+}
+
+func (r *CalendarFeedMemory) GetFeedUser(ctx context.Context, tokenHash string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for userId, hash := range r.store.feeds {
+		if hash == tokenHash && !r.store.disabled[userId] {
+			return userId, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const calendarFeedsTable = "calendar_feeds"
+
+// CalendarFeedSQL - INSERT ... ON CONFLICT DO UPDATE одинаково работает в Postgres и SQLite
+type CalendarFeedSQL struct {
+	db *sqlx.DB
+}
+
+func NewCalendarFeedSQL(db *sqlx.DB) *CalendarFeedSQL {
+	return &CalendarFeedSQL{db: db}
+}
+
+func (r *CalendarFeedSQL) SetFeedToken(ctx context.Context, userId int, tokenHash string) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, token_hash) VALUES ($1, $2)
+							ON CONFLICT (user_id) DO UPDATE SET token_hash = excluded.token_hash`, calendarFeedsTable)
+	_, err := r.db.ExecContext(ctx, query, userId, tokenHash)
+	return err
+}
+
+func (r *CalendarFeedSQL) DeleteFeedToken(ctx context.Context, userId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", calendarFeedsTable)
+	_, err := r.db.ExecContext(ctx, query, userId)
+	return err
+}
+
+func (r *CalendarFeedSQL) GetFeedUser(ctx context.Context, tokenHash string) (int, error) {
+	var userId int
+	query := fmt.Sprintf(`SELECT cf.user_id FROM %s cf INNER JOIN %s u on u.id = cf.user_id
+							WHERE cf.token_hash = $1 AND NOT u.disabled`, calendarFeedsTable, usersTable)
+	err := r.db.GetContext(ctx, &userId, query, tokenHash)
+
+	return userId, err
+}
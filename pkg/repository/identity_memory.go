@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type IdentityMemory struct {
+	store *memoryStore
+}
+
+func (r *IdentityMemory) GetIdentityUser(ctx context.Context, provider, subject string) (models.UserSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return models.UserSummary{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	identity, ok := r.store.identities[identityKey{provider, subject}]
+	if !ok {
+		return models.UserSummary{}, sql.ErrNoRows
+	}
+	user := r.store.users[identity.UserId]
+	return models.UserSummary{Id: user.Id, Name: user.Name, Username: user.Username, Disabled: r.store.disabled[user.Id]}, nil
+}
+
+func (r *IdentityMemory) CreateIdentity(ctx context.Context, identity models.Identity) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return r.store.insertIdentity(identity)
+}
+
+func (r *IdentityMemory) CreateUserWithIdentity(ctx context.Context, user models.User, identity models.Identity) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// проверки до вставки заменяют откат транзакции
+	for _, existing := range r.store.users {
+		if existing.Username == user.Username {
+			return 0, fmt.Errorf("user %q already exists", user.Username)
+		}
+	}
+	if _, ok := r.store.identities[identityKey{identity.Provider, identity.Subject}]; ok {
+		return 0, errors.New("identity already exists") // ограничение UNIQUE (provider, subject)
+	}
+
+	r.store.lastUserId++
+	user.Id = r.store.lastUserId
+	r.store.users[user.Id] = user
+
+	identity.UserId = user.Id
+	return user.Id, r.store.insertIdentity(identity)
+}
+
+func (r *IdentityMemory) GetIdentities(ctx context.Context, userId int) ([]models.Identity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	identities := []models.Identity{}
+	for _, identity := range r.store.identities {
+		if identity.UserId == userId {
+			identities = append(identities, identity)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		if identities[i].Provider != identities[j].Provider {
+			return identities[i].Provider < identities[j].Provider
+		}
+		return identities[i].Subject < identities[j].Subject
+	})
+	return identities, nil
+}
+
+func (s *memoryStore) insertIdentity(identity models.Identity) error {
+	if _, ok := s.users[identity.UserId]; !ok {
+		return fmt.Errorf("user %d does not exist", identity.UserId) // в Postgres сработал бы внешний ключ user_identities
+	}
+	key := identityKey{identity.Provider, identity.Subject}
+	if _, ok := s.identities[key]; ok {
+		return errors.New("identity already exists") // ограничение UNIQUE (provider, subject)
+	}
+	s.identities[key] = identity
+	return nil
+}
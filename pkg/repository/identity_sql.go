@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const userIdentitiesTable = "user_identities"
+
+// IdentitySQL - запросы без диалектных особенностей, общие для Postgres и SQLite
+type IdentitySQL struct {
+	db *sqlx.DB
+}
+
+func NewIdentitySQL(db *sqlx.DB) *IdentitySQL {
+	return &IdentitySQL{db: db}
+}
+
+func (r *IdentitySQL) GetIdentityUser(ctx context.Context, provider, subject string) (models.UserSummary, error) {
+	var user models.UserSummary
+	query := fmt.Sprintf(`SELECT u.id, u.name, u.username, u.disabled FROM %s u INNER JOIN %s ui on ui.user_id = u.id
+							WHERE ui.provider = $1 AND ui.subject = $2`, usersTable, userIdentitiesTable)
+	err := r.db.GetContext(ctx, &user, query, provider, subject)
+
+	return user, err
+}
+
+func (r *IdentitySQL) CreateIdentity(ctx context.Context, identity models.Identity) error {
+	return insertIdentity(ctx, r.db, identity)
+}
+
+func (r *IdentitySQL) CreateUserWithIdentity(ctx context.Context, user models.User, identity models.Identity) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	createUserQuery := fmt.Sprintf("INSERT INTO %s (name, username, password_hash) VALUES ($1, $2, $3) RETURNING id", usersTable)
+	if err := tx.QueryRowContext(ctx, createUserQuery, user.Name, user.Username, user.Password).Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	identity.UserId = id
+	if err := insertIdentity(ctx, tx, identity); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *IdentitySQL) GetIdentities(ctx context.Context, userId int) ([]models.Identity, error) {
+	identities := []models.Identity{}
+	query := fmt.Sprintf(`SELECT user_id, provider, subject, email, created_at FROM %s WHERE user_id = $1
+							ORDER BY provider, subject`, userIdentitiesTable)
+	err := r.db.SelectContext(ctx, &identities, query, userId)
+
+	return identities, err
+}
+
+func insertIdentity(ctx context.Context, db sqlx.ExecerContext, identity models.Identity) error {
+	query := fmt.Sprintf("INSERT INTO %s (user_id, provider, subject, email, created_at) VALUES ($1, $2, $3, $4, $5)", userIdentitiesTable)
+	_, err := db.ExecContext(ctx, query, identity.UserId, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt)
+
+	return err
+}
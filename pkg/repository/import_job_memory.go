@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type ImportJobMemory struct {
+	store *memoryStore
+}
+
+func (r *ImportJobMemory) CreateImportJob(ctx context.Context, job models.ImportJob, lists []models.ListBackup) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[job.UserId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", job.UserId) // в Postgres сработал бы внешний ключ import_jobs
+	}
+
+	r.store.lastImportJobId++
+	job.Id = r.store.lastImportJobId
+	r.store.importJobs[job.Id] = &importJobRow{ImportJob: job, lists: lists}
+	return job.Id, nil
+}
+
+func (r *ImportJobMemory) GetImportJobs(ctx context.Context, userId int) ([]models.ImportJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	jobs := []models.ImportJob{}
+	for _, row := range r.store.importJobs {
+		if row.UserId == userId {
+			jobs = append(jobs, row.ImportJob)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Id < jobs[j].Id })
+	return jobs, nil
+}
+
+func (r *ImportJobMemory) GetImportJob(ctx context.Context, userId, id int) (models.ImportJob, error) {
+	if err := ctx.Err(); err != nil {
+		return models.ImportJob{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	row, ok := r.store.importJobs[id]
+	if !ok || row.UserId != userId {
+		return models.ImportJob{}, sql.ErrNoRows
+	}
+	return row.ImportJob, nil
+}
+
+func (r *ImportJobMemory) ClaimImportJob(ctx context.Context, now, lockedUntil time.Time) (models.ImportJob, []models.ListBackup, error) {
+	if err := ctx.Err(); err != nil {
+		return models.ImportJob{}, nil, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var claimed *importJobRow
+	for _, row := range r.store.importJobs {
+		if row.Finished() || row.lockedUntil.After(now) {
+			continue
+		}
+		if claimed == nil || row.Id < claimed.Id {
+			claimed = row
+		}
+	}
+	if claimed == nil {
+		return models.ImportJob{}, nil, sql.ErrNoRows
+	}
+
+	claimed.Status, claimed.Attempts, claimed.UpdatedAt = models.ImportRunning, claimed.Attempts+1, now
+	claimed.lockedUntil = lockedUntil
+	return claimed.ImportJob, claimed.lists, nil
+}
+
+func (r *ImportJobMemory) ImportJobList(ctx context.Context, job models.ImportJob, list models.ListBackup, lockedUntil time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	row, ok := r.store.importJobs[job.Id]
+	if !ok || row.DoneLists != job.DoneLists || row.Status != models.ImportRunning {
+		return sql.ErrNoRows
+	}
+
+	r.store.insertListBackup(row.UserId, list)
+	row.DoneLists++
+	row.DoneItems += len(list.Items)
+	row.Attempts, row.UpdatedAt, row.lockedUntil = 0, job.UpdatedAt, lockedUntil
+	return nil
+}
+
+func (r *ImportJobMemory) FinishImportJob(ctx context.Context, job models.ImportJob) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if row, ok := r.store.importJobs[job.Id]; ok {
+		row.Status, row.Error, row.UpdatedAt = job.Status, job.Error, job.UpdatedAt
+		row.lists, row.lockedUntil = nil, time.Time{}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const importJobsTable = "import_jobs"
+
+const importJobColumns = "id, user_id, source, status, total_lists, total_items, done_lists, done_items, error, attempts, created_at, updated_at"
+
+// ImportJobSQL - запросы без диалектных особенностей, общие для Postgres и SQLite.
+// Списки задания хранятся в колонке data в JSON: читаются они только обработчиком и целиком.
+type ImportJobSQL struct {
+	db *sqlx.DB
+}
+
+func NewImportJobSQL(db *sqlx.DB) *ImportJobSQL {
+	return &ImportJobSQL{db: db}
+}
+
+func (r *ImportJobSQL) CreateImportJob(ctx context.Context, job models.ImportJob, lists []models.ListBackup) (int, error) {
+	data, err := json.Marshal(lists)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, source, status, data, total_lists, total_items, created_at, updated_at)
+							VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, importJobsTable)
+	row := r.db.QueryRowContext(ctx, query, job.UserId, job.Source, job.Status, string(data), job.TotalLists, job.TotalItems, job.CreatedAt, job.UpdatedAt)
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r *ImportJobSQL) GetImportJobs(ctx context.Context, userId int) ([]models.ImportJob, error) {
+	jobs := []models.ImportJob{}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE user_id = $1 ORDER BY id", importJobColumns, importJobsTable)
+	err := r.db.SelectContext(ctx, &jobs, query, userId)
+
+	return jobs, err
+}
+
+func (r *ImportJobSQL) GetImportJob(ctx context.Context, userId, id int) (models.ImportJob, error) {
+	var job models.ImportJob
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 AND user_id = $2", importJobColumns, importJobsTable)
+	err := r.db.GetContext(ctx, &job, query, id, userId)
+
+	return job, err
+}
+
+// ClaimImportJob сначала находит задание, а потом забирает его условным UPDATE: если между ними
+// задание успела взять другая реплика, UPDATE ничего не изменит и вернется sql.ErrNoRows
+func (r *ImportJobSQL) ClaimImportJob(ctx context.Context, now, lockedUntil time.Time) (models.ImportJob, []models.ListBackup, error) {
+	var row struct {
+		models.ImportJob
+		Data string `db:"data"`
+	}
+	query := fmt.Sprintf(`SELECT %s, data FROM %s WHERE status IN ($1, $2) AND locked_until <= $3 ORDER BY id LIMIT 1`,
+		importJobColumns, importJobsTable)
+	if err := r.db.GetContext(ctx, &row, query, models.ImportPending, models.ImportRunning, now.Unix()); err != nil {
+		return models.ImportJob{}, nil, err
+	}
+
+	claimQuery := fmt.Sprintf(`UPDATE %s SET status = $1, attempts = attempts + 1, locked_until = $2, updated_at = $3
+								WHERE id = $4 AND locked_until <= $5`, importJobsTable)
+	res, err := r.db.ExecContext(ctx, claimQuery, models.ImportRunning, lockedUntil.Unix(), now, row.Id, now.Unix())
+	if err != nil {
+		return models.ImportJob{}, nil, err
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return models.ImportJob{}, nil, err
+	}
+	if claimed == 0 {
+		return models.ImportJob{}, nil, sql.ErrNoRows
+	}
+
+	var lists []models.ListBackup
+	if err := json.Unmarshal([]byte(row.Data), &lists); err != nil {
+		return models.ImportJob{}, nil, fmt.Errorf("import job %d: %w", row.Id, err)
+	}
+	job := row.ImportJob
+	job.Status, job.Attempts, job.UpdatedAt = models.ImportRunning, job.Attempts+1, now
+	return job, lists, nil
+}
+
+func (r *ImportJobSQL) ImportJobList(ctx context.Context, job models.ImportJob, list models.ListBackup, lockedUntil time.Time) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// прогресс отмечается первым: если задание уже продвинул другой обработчик, списки не создаются
+	query := fmt.Sprintf(`UPDATE %s SET done_lists = done_lists + 1, done_items = done_items + $1, attempts = 0,
+							locked_until = $2, updated_at = $3 WHERE id = $4 AND done_lists = $5 AND status = $6`, importJobsTable)
+	res, err := tx.ExecContext(ctx, query, len(list.Items), lockedUntil.Unix(), job.UpdatedAt, job.Id, job.DoneLists, models.ImportRunning)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if updated == 0 {
+		tx.Rollback()
+		return sql.ErrNoRows
+	}
+
+	if _, err := insertListBackup(ctx, tx, job.UserId, list); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ImportJobSQL) FinishImportJob(ctx context.Context, job models.ImportJob) error {
+	query := fmt.Sprintf(`UPDATE %s SET status = $1, error = $2, data = '', locked_until = 0, updated_at = $3 WHERE id = $4`, importJobsTable)
+	_, err := r.db.ExecContext(ctx, query, job.Status, job.Error, job.UpdatedAt, job.Id)
+
+	return err
+}
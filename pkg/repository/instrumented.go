@@ -0,0 +1,401 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// Instrument оборачивает репозитории так, что длительность и результат каждого вызова
+// попадают в метрику todo_repository_call_duration_seconds. Реализация хранилища не важна.
+func Instrument(repos *Repository) *Repository {
+	return &Repository{
+		Authorization: &authInstrumented{next: repos.Authorization},
+		Admin:         &adminInstrumented{next: repos.Admin},
+		TodoList:      &todoListInstrumented{next: repos.TodoList},
+		TodoItem:      &todoItemInstrumented{next: repos.TodoItem},
+		Backup:        &backupInstrumented{next: repos.Backup},
+		CalendarFeed:  &calendarFeedInstrumented{next: repos.CalendarFeed},
+		AppPassword:   &appPasswordInstrumented{next: repos.AppPassword},
+		CalDAV:        &caldavInstrumented{next: repos.CalDAV},
+		ImportJob:     &importJobInstrumented{next: repos.ImportJob},
+		AccessToken:   &accessTokenInstrumented{next: repos.AccessToken},
+		Identity:      &identityInstrumented{next: repos.Identity},
+		TwoFactor:     &twoFactorInstrumented{next: repos.TwoFactor},
+		Account:       &accountInstrumented{next: repos.Account},
+	}
+}
+
+// observe вызывается через defer, поэтому ошибку получает по указателю - к моменту вызова она уже записана
+func observe(repository, method string, started time.Time, err *error) {
+	metrics.ObserveRepositoryCall(repository, method, started, *err)
+}
+
+type authInstrumented struct {
+	next Authorization
+}
+
+func (r *authInstrumented) CreateUser(ctx context.Context, user models.User) (res int, err error) {
+	defer observe("authorization", "CreateUser", time.Now(), &err)
+	return r.next.CreateUser(ctx, user)
+}
+
+func (r *authInstrumented) GetUser(ctx context.Context, username, password string) (res models.User, err error) {
+	defer observe("authorization", "GetUser", time.Now(), &err)
+	return r.next.GetUser(ctx, username, password)
+}
+
+type adminInstrumented struct {
+	next Admin
+}
+
+func (r *adminInstrumented) GetUsers(ctx context.Context) (res []models.UserSummary, err error) {
+	defer observe("admin", "GetUsers", time.Now(), &err)
+	return r.next.GetUsers(ctx)
+}
+
+func (r *adminInstrumented) GetUserByUsername(ctx context.Context, username string) (res models.UserSummary, err error) {
+	defer observe("admin", "GetUserByUsername", time.Now(), &err)
+	return r.next.GetUserByUsername(ctx, username)
+}
+
+func (r *adminInstrumented) GetUserById(ctx context.Context, userId int) (res models.UserSummary, err error) {
+	defer observe("admin", "GetUserById", time.Now(), &err)
+	return r.next.GetUserById(ctx, userId)
+}
+
+func (r *adminInstrumented) SetDisabled(ctx context.Context, userId int, disabled bool) (err error) {
+	defer observe("admin", "SetDisabled", time.Now(), &err)
+	return r.next.SetDisabled(ctx, userId, disabled)
+}
+
+func (r *adminInstrumented) SetPassword(ctx context.Context, userId int, passwordHash string) (err error) {
+	defer observe("admin", "SetPassword", time.Now(), &err)
+	return r.next.SetPassword(ctx, userId, passwordHash)
+}
+
+func (r *adminInstrumented) TransferList(ctx context.Context, listId, fromUserId, toUserId int) (err error) {
+	defer observe("admin", "TransferList", time.Now(), &err)
+	return r.next.TransferList(ctx, listId, fromUserId, toUserId)
+}
+
+func (r *adminInstrumented) GetStats(ctx context.Context, userId int) (res models.UserStats, err error) {
+	defer observe("admin", "GetStats", time.Now(), &err)
+	return r.next.GetStats(ctx, userId)
+}
+
+func (r *adminInstrumented) DeleteUser(ctx context.Context, userId int) (err error) {
+	defer observe("admin", "DeleteUser", time.Now(), &err)
+	return r.next.DeleteUser(ctx, userId)
+}
+
+func (r *adminInstrumented) GetTotals(ctx context.Context) (res models.Totals, err error) {
+	defer observe("admin", "GetTotals", time.Now(), &err)
+	return r.next.GetTotals(ctx)
+}
+
+type todoListInstrumented struct {
+	next TodoList
+}
+
+func (r *todoListInstrumented) Create(ctx context.Context, userId int, list models.TodoList) (res int, err error) {
+	defer observe("todo_list", "Create", time.Now(), &err)
+	return r.next.Create(ctx, userId, list)
+}
+
+func (r *todoListInstrumented) GetAll(ctx context.Context, userId int) (res []models.TodoList, err error) {
+	defer observe("todo_list", "GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx, userId)
+}
+
+func (r *todoListInstrumented) GetById(ctx context.Context, userId, listId int) (res models.TodoList, err error) {
+	defer observe("todo_list", "GetById", time.Now(), &err)
+	return r.next.GetById(ctx, userId, listId)
+}
+
+func (r *todoListInstrumented) Search(ctx context.Context, userId int, query string) (res []models.TodoList, err error) {
+	defer observe("todo_list", "Search", time.Now(), &err)
+	return r.next.Search(ctx, userId, query)
+}
+
+func (r *todoListInstrumented) Delete(ctx context.Context, userId, listId int) (err error) {
+	defer observe("todo_list", "Delete", time.Now(), &err)
+	return r.next.Delete(ctx, userId, listId)
+}
+
+func (r *todoListInstrumented) Update(ctx context.Context, userId, listId int, input models.UpdateListInput) (err error) {
+	defer observe("todo_list", "Update", time.Now(), &err)
+	return r.next.Update(ctx, userId, listId, input)
+}
+
+func (r *todoListInstrumented) Patch(ctx context.Context, userId, listId int, changes map[string]interface{}) (err error) {
+	defer observe("todo_list", "Patch", time.Now(), &err)
+	return r.next.Patch(ctx, userId, listId, changes)
+}
+
+type todoItemInstrumented struct {
+	next TodoItem
+}
+
+func (r *todoItemInstrumented) Create(ctx context.Context, listId int, item models.TodoItem) (res int, err error) {
+	defer observe("todo_item", "Create", time.Now(), &err)
+	return r.next.Create(ctx, listId, item)
+}
+
+func (r *todoItemInstrumented) GetAll(ctx context.Context, userId, listId int) (res []models.TodoItem, err error) {
+	defer observe("todo_item", "GetAll", time.Now(), &err)
+	return r.next.GetAll(ctx, userId, listId)
+}
+
+func (r *todoItemInstrumented) GetAllByLists(ctx context.Context, userId int, listIds []int) (res map[int][]models.TodoItem, err error) {
+	defer observe("todo_item", "GetAllByLists", time.Now(), &err)
+	return r.next.GetAllByLists(ctx, userId, listIds)
+}
+
+func (r *todoItemInstrumented) Search(ctx context.Context, userId int, query string) (res []models.TodoItem, err error) {
+	defer observe("todo_item", "Search", time.Now(), &err)
+	return r.next.Search(ctx, userId, query)
+}
+
+func (r *todoItemInstrumented) GetById(ctx context.Context, userId, itemId int) (res models.TodoItem, err error) {
+	defer observe("todo_item", "GetById", time.Now(), &err)
+	return r.next.GetById(ctx, userId, itemId)
+}
+
+func (r *todoItemInstrumented) GetListId(ctx context.Context, userId, itemId int) (res int, err error) {
+	defer observe("todo_item", "GetListId", time.Now(), &err)
+	return r.next.GetListId(ctx, userId, itemId)
+}
+
+func (r *todoItemInstrumented) Delete(ctx context.Context, userId, itemId int) (err error) {
+	defer observe("todo_item", "Delete", time.Now(), &err)
+	return r.next.Delete(ctx, userId, itemId)
+}
+
+func (r *todoItemInstrumented) Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) (err error) {
+	defer observe("todo_item", "Update", time.Now(), &err)
+	return r.next.Update(ctx, userId, itemId, input)
+}
+
+func (r *todoItemInstrumented) Patch(ctx context.Context, userId, itemId int, changes map[string]interface{}) (err error) {
+	defer observe("todo_item", "Patch", time.Now(), &err)
+	return r.next.Patch(ctx, userId, itemId, changes)
+}
+
+type backupInstrumented struct {
+	next Backup
+}
+
+func (r *backupInstrumented) ImportLists(ctx context.Context, userId int, lists []models.ListBackup) (res []int, err error) {
+	defer observe("backup", "ImportLists", time.Now(), &err)
+	return r.next.ImportLists(ctx, userId, lists)
+}
+
+type calendarFeedInstrumented struct {
+	next CalendarFeed
+}
+
+func (r *calendarFeedInstrumented) SetFeedToken(ctx context.Context, userId int, tokenHash string) (err error) {
+	defer observe("calendar_feed", "SetFeedToken", time.Now(), &err)
+	return r.next.SetFeedToken(ctx, userId, tokenHash)
+}
+
+func (r *calendarFeedInstrumented) DeleteFeedToken(ctx context.Context, userId int) (err error) {
+	defer observe("calendar_feed", "DeleteFeedToken", time.Now(), &err)
+	return r.next.DeleteFeedToken(ctx, userId)
+}
+
+func (r *calendarFeedInstrumented) GetFeedUser(ctx context.Context, tokenHash string) (res int, err error) {
+	defer observe("calendar_feed", "GetFeedUser", time.Now(), &err)
+	return r.next.GetFeedUser(ctx, tokenHash)
+}
+
+type appPasswordInstrumented struct {
+	next AppPassword
+}
+
+func (r *appPasswordInstrumented) CreateAppPassword(ctx context.Context, userId int, password models.AppPassword, passwordHash string) (res int, err error) {
+	defer observe("app_password", "CreateAppPassword", time.Now(), &err)
+	return r.next.CreateAppPassword(ctx, userId, password, passwordHash)
+}
+
+func (r *appPasswordInstrumented) GetAppPasswords(ctx context.Context, userId int) (res []models.AppPassword, err error) {
+	defer observe("app_password", "GetAppPasswords", time.Now(), &err)
+	return r.next.GetAppPasswords(ctx, userId)
+}
+
+func (r *appPasswordInstrumented) DeleteAppPassword(ctx context.Context, userId, id int) (err error) {
+	defer observe("app_password", "DeleteAppPassword", time.Now(), &err)
+	return r.next.DeleteAppPassword(ctx, userId, id)
+}
+
+func (r *appPasswordInstrumented) GetAppPasswordUser(ctx context.Context, username, passwordHash string) (res int, err error) {
+	defer observe("app_password", "GetAppPasswordUser", time.Now(), &err)
+	return r.next.GetAppPasswordUser(ctx, username, passwordHash)
+}
+
+type caldavInstrumented struct {
+	next CalDAV
+}
+
+func (r *caldavInstrumented) GetObjects(ctx context.Context, userId int, listIds []int) (res map[int]models.CalendarObject, err error) {
+	defer observe("caldav", "GetObjects", time.Now(), &err)
+	return r.next.GetObjects(ctx, userId, listIds)
+}
+
+func (r *caldavInstrumented) SetObject(ctx context.Context, itemId int, object models.CalendarObject) (err error) {
+	defer observe("caldav", "SetObject", time.Now(), &err)
+	return r.next.SetObject(ctx, itemId, object)
+}
+
+type importJobInstrumented struct {
+	next ImportJob
+}
+
+func (r *importJobInstrumented) CreateImportJob(ctx context.Context, job models.ImportJob, lists []models.ListBackup) (res int, err error) {
+	defer observe("import_job", "CreateImportJob", time.Now(), &err)
+	return r.next.CreateImportJob(ctx, job, lists)
+}
+
+func (r *importJobInstrumented) GetImportJobs(ctx context.Context, userId int) (res []models.ImportJob, err error) {
+	defer observe("import_job", "GetImportJobs", time.Now(), &err)
+	return r.next.GetImportJobs(ctx, userId)
+}
+
+func (r *importJobInstrumented) GetImportJob(ctx context.Context, userId, id int) (res models.ImportJob, err error) {
+	defer observe("import_job", "GetImportJob", time.Now(), &err)
+	return r.next.GetImportJob(ctx, userId, id)
+}
+
+func (r *importJobInstrumented) ClaimImportJob(ctx context.Context, now, lockedUntil time.Time) (res models.ImportJob, lists []models.ListBackup, err error) {
+	defer observe("import_job", "ClaimImportJob", time.Now(), &err)
+	return r.next.ClaimImportJob(ctx, now, lockedUntil)
+}
+
+func (r *importJobInstrumented) ImportJobList(ctx context.Context, job models.ImportJob, list models.ListBackup, lockedUntil time.Time) (err error) {
+	defer observe("import_job", "ImportJobList", time.Now(), &err)
+	return r.next.ImportJobList(ctx, job, list, lockedUntil)
+}
+
+func (r *importJobInstrumented) FinishImportJob(ctx context.Context, job models.ImportJob) (err error) {
+	defer observe("import_job", "FinishImportJob", time.Now(), &err)
+	return r.next.FinishImportJob(ctx, job)
+}
+
+type accessTokenInstrumented struct {
+	next AccessToken
+}
+
+func (r *accessTokenInstrumented) CreateAccessToken(ctx context.Context, userId int, token models.AccessToken, tokenHash string) (res int, err error) {
+	defer observe("access_token", "CreateAccessToken", time.Now(), &err)
+	return r.next.CreateAccessToken(ctx, userId, token, tokenHash)
+}
+
+func (r *accessTokenInstrumented) GetAccessTokens(ctx context.Context, userId int) (res []models.AccessToken, err error) {
+	defer observe("access_token", "GetAccessTokens", time.Now(), &err)
+	return r.next.GetAccessTokens(ctx, userId)
+}
+
+func (r *accessTokenInstrumented) DeleteAccessToken(ctx context.Context, userId, id int) (err error) {
+	defer observe("access_token", "DeleteAccessToken", time.Now(), &err)
+	return r.next.DeleteAccessToken(ctx, userId, id)
+}
+
+func (r *accessTokenInstrumented) GetAccessTokenByHash(ctx context.Context, tokenHash string) (res models.AccessToken, err error) {
+	defer observe("access_token", "GetAccessTokenByHash", time.Now(), &err)
+	return r.next.GetAccessTokenByHash(ctx, tokenHash)
+}
+
+func (r *accessTokenInstrumented) TouchAccessToken(ctx context.Context, id int, usedAt time.Time) (err error) {
+	defer observe("access_token", "TouchAccessToken", time.Now(), &err)
+	return r.next.TouchAccessToken(ctx, id, usedAt)
+}
+
+type identityInstrumented struct {
+	next Identity
+}
+
+func (r *identityInstrumented) GetIdentityUser(ctx context.Context, provider, subject string) (res models.UserSummary, err error) {
+	defer observe("identity", "GetIdentityUser", time.Now(), &err)
+	return r.next.GetIdentityUser(ctx, provider, subject)
+}
+
+func (r *identityInstrumented) CreateIdentity(ctx context.Context, identity models.Identity) (err error) {
+	defer observe("identity", "CreateIdentity", time.Now(), &err)
+	return r.next.CreateIdentity(ctx, identity)
+}
+
+func (r *identityInstrumented) CreateUserWithIdentity(ctx context.Context, user models.User, identity models.Identity) (res int, err error) {
+	defer observe("identity", "CreateUserWithIdentity", time.Now(), &err)
+	return r.next.CreateUserWithIdentity(ctx, user, identity)
+}
+
+func (r *identityInstrumented) GetIdentities(ctx context.Context, userId int) (res []models.Identity, err error) {
+	defer observe("identity", "GetIdentities", time.Now(), &err)
+	return r.next.GetIdentities(ctx, userId)
+}
+
+type twoFactorInstrumented struct {
+	next TwoFactor
+}
+
+func (r *twoFactorInstrumented) GetTOTP(ctx context.Context, userId int) (res models.TOTP, err error) {
+	defer observe("two_factor", "GetTOTP", time.Now(), &err)
+	return r.next.GetTOTP(ctx, userId)
+}
+
+func (r *twoFactorInstrumented) SetTOTP(ctx context.Context, totp models.TOTP) (err error) {
+	defer observe("two_factor", "SetTOTP", time.Now(), &err)
+	return r.next.SetTOTP(ctx, totp)
+}
+
+func (r *twoFactorInstrumented) ConfirmTOTP(ctx context.Context, userId int, step int64, confirmedAt time.Time, recoveryHashes []string) (err error) {
+	defer observe("two_factor", "ConfirmTOTP", time.Now(), &err)
+	return r.next.ConfirmTOTP(ctx, userId, step, confirmedAt, recoveryHashes)
+}
+
+func (r *twoFactorInstrumented) UseTOTPStep(ctx context.Context, userId int, step int64) (res bool, err error) {
+	defer observe("two_factor", "UseTOTPStep", time.Now(), &err)
+	return r.next.UseTOTPStep(ctx, userId, step)
+}
+
+func (r *twoFactorInstrumented) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (err error) {
+	defer observe("two_factor", "UseRecoveryCode", time.Now(), &err)
+	return r.next.UseRecoveryCode(ctx, userId, codeHash)
+}
+
+func (r *twoFactorInstrumented) CountRecoveryCodes(ctx context.Context, userId int) (res int, err error) {
+	defer observe("two_factor", "CountRecoveryCodes", time.Now(), &err)
+	return r.next.CountRecoveryCodes(ctx, userId)
+}
+
+func (r *twoFactorInstrumented) DeleteTOTP(ctx context.Context, userId int) (err error) {
+	defer observe("two_factor", "DeleteTOTP", time.Now(), &err)
+	return r.next.DeleteTOTP(ctx, userId)
+}
+
+type accountInstrumented struct {
+	next Account
+}
+
+func (r *accountInstrumented) UpdateName(ctx context.Context, userId int, name string) (err error) {
+	defer observe("account", "UpdateName", time.Now(), &err)
+	return r.next.UpdateName(ctx, userId, name)
+}
+
+func (r *accountInstrumented) CheckPassword(ctx context.Context, userId int, passwordHash string) (err error) {
+	defer observe("account", "CheckPassword", time.Now(), &err)
+	return r.next.CheckPassword(ctx, userId, passwordHash)
+}
+
+func (r *accountInstrumented) ChangePassword(ctx context.Context, userId int, passwordHash string) (res int, err error) {
+	defer observe("account", "ChangePassword", time.Now(), &err)
+	return r.next.ChangePassword(ctx, userId, passwordHash)
+}
+
+func (r *accountInstrumented) GetSessionVersion(ctx context.Context, userId int) (res int, err error) {
+	defer observe("account", "GetSessionVersion", time.Now(), &err)
+	return r.next.GetSessionVersion(ctx, userId)
+}
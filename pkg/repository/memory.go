@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// memoryStore - общее хранилище для in-memory репозиториев. Таблицы и связки повторяют schema/,
+// а выборки - те же INNER JOIN по users_lists и lists_items, что и в Postgres-репозиториях,
+// поэтому правила доступа к спискам и задачам у обеих реализаций совпадают.
+type memoryStore struct {
+	mu sync.RWMutex
+
+	users      map[int]models.User // Password хранит уже хэш пароля, как колонка password_hash
+	disabled   map[int]bool        // заблокированные пользователи, колонка users.disabled
+	lists      map[int]models.TodoList
+	items      map[int]models.TodoItem
+	usersLists map[int]map[int]bool // user_id -> list_id
+	listsItems map[int]int          // item_id -> list_id
+	feeds      map[int]string       // user_id -> хэш токена ICS-ленты, таблица calendar_feeds
+
+	appPasswords  map[int]appPasswordRow          // таблица app_passwords
+	caldavObjects map[int]models.CalendarObject   // item_id -> имя и UID ресурса, таблица caldav_objects
+	importJobs    map[int]*importJobRow           // таблица import_jobs
+	accessTokens  map[int]accessTokenRow          // таблица access_tokens
+	identities    map[identityKey]models.Identity // таблица user_identities
+	totp          map[int]models.TOTP             // user_id -> секрет, таблица user_totp
+	recoveryCodes map[int]map[string]bool         // user_id -> хэши кодов, таблица recovery_codes
+
+	sessionVersions map[int]int // колонка users.session_version, отсутствующий ключ - 0
+
+	lastUserId, lastListId, lastItemId, lastAppPasswordId, lastImportJobId, lastAccessTokenId int
+}
+
+type appPasswordRow struct {
+	userId       int
+	passwordHash string
+	models.AppPassword
+}
+
+// identityKey - уникальный ключ user_identities (provider, subject)
+type identityKey struct {
+	provider, subject string
+}
+
+type accessTokenRow struct {
+	tokenHash string
+	models.AccessToken
+}
+
+// importJobRow хранится по указателю: обработчик меняет прогресс задания на месте
+type importJobRow struct {
+	models.ImportJob
+	lists       []models.ListBackup // колонка data
+	lockedUntil time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		users:      make(map[int]models.User),
+		disabled:   make(map[int]bool),
+		lists:      make(map[int]models.TodoList),
+		items:      make(map[int]models.TodoItem),
+		usersLists: make(map[int]map[int]bool),
+		listsItems: make(map[int]int),
+		feeds:      make(map[int]string),
+
+		appPasswords:  make(map[int]appPasswordRow),
+		caldavObjects: make(map[int]models.CalendarObject),
+		importJobs:    make(map[int]*importJobRow),
+		accessTokens:  make(map[int]accessTokenRow),
+		identities:    make(map[identityKey]models.Identity),
+		totp:          make(map[int]models.TOTP),
+		recoveryCodes: make(map[int]map[string]bool),
+
+		sessionVersions: make(map[int]int),
+	}
+}
+
+// NewMemoryRepository собирает репозитории, которые хранят данные в памяти процесса.
+// Подходит для тестов и демо-режима: после перезапуска данные теряются.
+func NewMemoryRepository() *Repository {
+	store := newMemoryStore()
+	return &Repository{
+		Authorization: &AuthMemory{store: store},
+		Admin:         &AdminMemory{store: store},
+		TodoList:      &TodoListMemory{store: store},
+		TodoItem:      &TodoItemMemory{store: store},
+		Backup:        &BackupMemory{store: store},
+		CalendarFeed:  &CalendarFeedMemory{store: store},
+		AppPassword:   &AppPasswordMemory{store: store},
+		CalDAV:        &CalDAVMemory{store: store},
+		ImportJob:     &ImportJobMemory{store: store},
+		AccessToken:   &AccessTokenMemory{store: store},
+		Identity:      &IdentityMemory{store: store},
+		TwoFactor:     &TwoFactorMemory{store: store},
+		Account:       &AccountMemory{store: store},
+	}
+}
+
+// hasList повторяет условие JOIN users_lists ul ... WHERE ul.user_id = $1 AND ul.list_id = $2
+func (s *memoryStore) hasList(userId, listId int) bool {
+	return s.usersLists[userId][listId]
+}
+
+// hasItem повторяет JOIN lists_items li ... JOIN users_lists ul для задачи
+func (s *memoryStore) hasItem(userId, itemId int) bool {
+	listId, ok := s.listsItems[itemId]
+	return ok && s.hasList(userId, listId)
+}
+
+func (s *memoryStore) deleteList(listId int) {
+	delete(s.lists, listId)
+	for _, lists := range s.usersLists {
+		delete(lists, listId)
+	}
+	for itemId, itemListId := range s.listsItems {
+		if itemListId == listId {
+			s.deleteItem(itemId)
+		}
+	}
+}
+
+// deleteItem удаляет задачу вместе со строками, которые в базе удалил бы ON DELETE CASCADE
+func (s *memoryStore) deleteItem(itemId int) {
+	delete(s.items, itemId)
+	delete(s.listsItems, itemId)
+	delete(s.caldavObjects, itemId)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// applyChanges записывает значения из changes в поля структуры target по тегам db - аналог buildSetQuery
+func applyChanges(target interface{}, changes map[string]interface{}, allowed map[string]bool) error {
+	if len(changes) == 0 {
+		return errors.New("update structure has no values")
+	}
+
+	v := reflect.ValueOf(target).Elem()
+	for column, value := range changes {
+		if !allowed[column] {
+			return fmt.Errorf("column %q can not be updated", column)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).Tag.Get("db") != column {
+				continue
+			}
+			newValue := reflect.ValueOf(value)
+			if !newValue.Type().AssignableTo(v.Field(i).Type()) {
+				return fmt.Errorf("invalid value for column %q", column)
+			}
+			v.Field(i).Set(newValue)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/schema"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrSchemaTooNew - база мигрирована более новой версией приложения, чем запущенная
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// ErrSchemaDirty - прошлая миграция упала на середине, схему нужно поправить руками и выставить версию через goto
+var ErrSchemaDirty = errors.New("database schema is dirty")
+
+// MigrationStatus - текущая версия схемы в базе и последняя версия, встроенная в бинарник
+type MigrationStatus struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	Latest  uint `json:"latest"`
+}
+
+// Migrator управляет версией схемы по миграциям, встроенным в бинарник (пакет schema)
+type Migrator struct {
+	m      *migrate.Migrate
+	latest uint
+	close  func() error
+}
+
+// NewPostgresMigrator работает через отдельное соединение из пула db. Up, Down и Goto берут
+// pg_advisory_lock на время миграции, поэтому несколько реплик, стартующих одновременно, не мешают друг другу:
+// первая накатывает схему, остальные дожидаются блокировки и видят, что менять уже нечего.
+func NewPostgresMigrator(db *sqlx.DB) (*Migrator, error) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	m, err := newMigrator(schema.Postgres, ".", "postgres", driver)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	m.close = func() error {
+		srcErr, dbErr := m.m.Close() // закрывает только взятое соединение, пул db остается открытым
+		return errors.Join(srcErr, dbErr)
+	}
+	return m, nil
+}
+
+// NewSQLiteMigrator - в SQLite одновременно пишет только одно соединение, отдельная блокировка не нужна
+func NewSQLiteMigrator(db *sqlx.DB) (*Migrator, error) {
+	driver, err := sqlite.WithInstance(db.DB, &sqlite.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := newMigrator(schema.SQLite, "sqlite", "sqlite", driver)
+	if err != nil {
+		return nil, err
+	}
+	// драйвер sqlite при закрытии закрыл бы и саму базу db, поэтому его не закрываем
+	m.close = func() error { return nil }
+	return m, nil
+}
+
+func newMigrator(fsys fs.FS, path, driverName string, driver database.Driver) (*Migrator, error) {
+	src, err := iofs.New(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := latestVersion(src)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driverName, driver)
+	if err != nil {
+		return nil, err
+	}
+	m.Log = migrateLogger{}
+
+	return &Migrator{m: m, latest: latest}, nil
+}
+
+// latestVersion проходит по всем миграциям источника и возвращает номер последней
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	for err == nil {
+		var next uint
+		next, err = src.Next(version)
+		if err == nil {
+			version = next
+		}
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return 0, err
+	}
+	return version, nil
+}
+
+// Up накатывает все миграции, которых еще нет в базе
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down откатывает одну последнюю миграцию
+func (m *Migrator) Down() error {
+	return m.m.Steps(-1)
+}
+
+// Goto приводит схему к версии version, накатывая или откатывая миграции. Также снимает флаг dirty.
+func (m *Migrator) Goto(version uint) error {
+	if version > m.latest {
+		return fmt.Errorf("unknown migration version %d, latest is %d", version, m.latest)
+	}
+	if version == 0 {
+		if err := m.m.Down(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return err
+		}
+		return nil
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		// после ручной правки схемы просто фиксируем версию, как migrate force
+		return m.m.Force(int(version))
+	}
+
+	if err := m.m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+func (m *Migrator) Status() (MigrationStatus, error) {
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return MigrationStatus{}, err
+	}
+	return MigrationStatus{Version: version, Dirty: dirty, Latest: m.latest}, nil
+}
+
+// CheckVersion не дает работать со схемой, которую бинарник не знает или которая осталась недокаченной
+func (m *Migrator) CheckVersion() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.Version > status.Latest {
+		return fmt.Errorf("%w: database is at version %d, binary knows up to %d", ErrSchemaTooNew, status.Version, status.Latest)
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w at version %d", ErrSchemaDirty, status.Version)
+	}
+	return nil
+}
+
+func (m *Migrator) Close() error {
+	return m.close()
+}
+
+// CheckSchema сверяет версию схемы с ожидаемой по таблице schema_migrations, которую ведет golang-migrate.
+// В отличие от Migrator не занимает отдельное соединение, поэтому подходит для частых проверок readiness.
+func CheckSchema(ctx context.Context, db *sqlx.DB, expected uint) error {
+	var status MigrationStatus
+	err := db.QueryRowxContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&status.Version, &status.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w at version %d", ErrSchemaDirty, status.Version)
+	}
+	if status.Version != expected {
+		return fmt.Errorf("database schema is at version %d, expected %d", status.Version, expected)
+	}
+	return nil
+}
+
+// migrateLogger пишет ход миграций в общий лог приложения
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	logrus.Info("migrate: " + strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// колонки, которые разрешено менять через PATCH, берем из тегов db моделей (кроме id)
+var (
+	todoListColumns = patchableColumns(models.TodoList{})
+	todoItemColumns = patchableColumns(models.TodoItem{})
+)
+
+func patchableColumns(model interface{}) map[string]bool {
+	columns := make(map[string]bool)
+	t := reflect.TypeOf(model)
+	for i := 0; i < t.NumField(); i++ {
+		column := t.Field(i).Tag.Get("db")
+		if column == "" || column == "-" || column == "id" {
+			continue
+		}
+		columns[column] = true
+	}
+	return columns
+}
+
+// buildSetQuery генерирует часть SET для UPDATE вида "description=$1, title=$2" и слайс аргументов к ней.
+// Колонки сортируются, чтобы запрос для одного и того же набора полей всегда был одинаковым.
+func buildSetQuery(changes map[string]interface{}, allowed map[string]bool) (string, []interface{}, error) {
+	if len(changes) == 0 {
+		return "", nil, errors.New("update structure has no values")
+	}
+
+	columns := make([]string, 0, len(changes))
+	for column := range changes {
+		if !allowed[column] {
+			return "", nil, fmt.Errorf("column %q can not be updated", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	setValues := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns))
+	for i, column := range columns {
+		setValues = append(setValues, fmt.Sprintf("%s=$%d", column, i+1))
+		args = append(args, changes[column])
+	}
+
+	return strings.Join(setValues, ", "), args, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -24,7 +25,7 @@ type Config struct {
 }
 
 func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres", fmt.Sprintf("host=%s user=%s port=%s password=%s dbname=%s sslmode=%s",
+	db, err := openDB("postgres", fmt.Sprintf("host=%s user=%s port=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Username, cfg.Port, cfg.Password, cfg.DBName, cfg.SSLMode))
 	if err != nil {
 		return nil, err
@@ -36,3 +37,9 @@ func NewPostgresDB(cfg Config) (*sqlx.DB, error) {
 	}
 	return db, nil
 }
+
+// likePattern экранирует спецсимволы LIKE в пользовательском запросе и ищет подстроку
+func likePattern(query string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(query) + "%"
+}
@@ -1,42 +1,189 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/ponomare0v/todo-go-app/pkg/models"
 )
 
 type Authorization interface {
-	CreateUser(user models.User) (int, error)
-	GetUser(username, password string) (models.User, error)
+	CreateUser(ctx context.Context, user models.User) (int, error)
+	GetUser(ctx context.Context, username, password string) (models.User, error)
+}
+
+// Admin - операции над пользователями для todoctl, без проверки прав: их вызывает только оператор
+type Admin interface {
+	GetUsers(ctx context.Context) ([]models.UserSummary, error)
+	GetUserByUsername(ctx context.Context, username string) (models.UserSummary, error)
+	GetUserById(ctx context.Context, userId int) (models.UserSummary, error)
+	// SetDisabled и SetPassword увеличивают версию сессий: выпущенные пользователю JWT отзываются
+	SetDisabled(ctx context.Context, userId int, disabled bool) error
+	SetPassword(ctx context.Context, userId int, passwordHash string) error
+	TransferList(ctx context.Context, listId, fromUserId, toUserId int) error
+	GetStats(ctx context.Context, userId int) (models.UserStats, error)
+	DeleteUser(ctx context.Context, userId int) error
+	GetTotals(ctx context.Context) (models.Totals, error)
 }
 
 type TodoList interface {
-	Create(userId int, list models.TodoList) (int, error)
-	GetAll(userId int) ([]models.TodoList, error)
-	GetById(userId, listId int) (models.TodoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input models.UpdateListInput) error
+	Create(ctx context.Context, userId int, list models.TodoList) (int, error)
+	GetAll(ctx context.Context, userId int) ([]models.TodoList, error)
+	GetById(ctx context.Context, userId, listId int) (models.TodoList, error)
+	Search(ctx context.Context, userId int, query string) ([]models.TodoList, error)
+	Delete(ctx context.Context, userId, listId int) error
+	Update(ctx context.Context, userId, listId int, input models.UpdateListInput) error
+	Patch(ctx context.Context, userId, listId int, changes map[string]interface{}) error
 }
 
 type TodoItem interface {
-	Create(listId int, item models.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]models.TodoItem, error)
-	GetById(userId, itemId int) (models.TodoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, input models.UpdateItemInput) error
+	Create(ctx context.Context, listId int, item models.TodoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int) ([]models.TodoItem, error)
+	GetAllByLists(ctx context.Context, userId int, listIds []int) (map[int][]models.TodoItem, error)
+	Search(ctx context.Context, userId int, query string) ([]models.TodoItem, error)
+	GetById(ctx context.Context, userId, itemId int) (models.TodoItem, error)
+	GetListId(ctx context.Context, userId, itemId int) (int, error)
+	Delete(ctx context.Context, userId, itemId int) error
+	Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) error
+	Patch(ctx context.Context, userId, itemId int, changes map[string]interface{}) error
+}
+
+// Backup - массовая загрузка списков с задачами при импорте
+type Backup interface {
+	// ImportLists создает все списки и задачи в одной транзакции и возвращает id новых списков
+	ImportLists(ctx context.Context, userId int, lists []models.ListBackup) ([]int, error)
+}
+
+// CalendarFeed - секретные токены ICS-лент; токен хранится только в виде хэша
+type CalendarFeed interface {
+	// SetFeedToken заменяет токен пользователя, прежняя ссылка на ленту перестает работать
+	SetFeedToken(ctx context.Context, userId int, tokenHash string) error
+	DeleteFeedToken(ctx context.Context, userId int) error
+	// GetFeedUser возвращает владельца ленты; для неизвестного токена и заблокированного пользователя - sql.ErrNoRows
+	GetFeedUser(ctx context.Context, tokenHash string) (int, error)
+}
+
+// AppPassword - пароли приложений для HTTP Basic; пароль хранится только в виде хэша
+type AppPassword interface {
+	CreateAppPassword(ctx context.Context, userId int, password models.AppPassword, passwordHash string) (int, error)
+	GetAppPasswords(ctx context.Context, userId int) ([]models.AppPassword, error)
+	// DeleteAppPassword возвращает sql.ErrNoRows, если у пользователя нет такого пароля
+	DeleteAppPassword(ctx context.Context, userId, id int) error
+	// GetAppPasswordUser возвращает владельца пароля; для неизвестной пары и заблокированного пользователя - sql.ErrNoRows
+	GetAppPasswordUser(ctx context.Context, username, passwordHash string) (int, error)
+}
+
+// CalDAV - имена ресурсов и UID, с которыми задачи создали CalDAV-клиенты
+type CalDAV interface {
+	// GetObjects возвращает записи задач из списков listIds, доступных пользователю, по id задачи
+	GetObjects(ctx context.Context, userId int, listIds []int) (map[int]models.CalendarObject, error)
+	SetObject(ctx context.Context, itemId int, object models.CalendarObject) error
+}
+
+// ImportJob - фоновые импорты из других сервисов. Списки задания создаются по одному, и каждый - в одной
+// транзакции с отметкой прогресса, поэтому после остановки задание продолжается без дублей.
+type ImportJob interface {
+	// CreateImportJob сохраняет задание вместе со списками, которые нужно создать
+	CreateImportJob(ctx context.Context, job models.ImportJob, lists []models.ListBackup) (int, error)
+	GetImportJobs(ctx context.Context, userId int) ([]models.ImportJob, error)
+	// GetImportJob возвращает sql.ErrNoRows, если у пользователя нет такого задания
+	GetImportJob(ctx context.Context, userId, id int) (models.ImportJob, error)
+	// ClaimImportJob берет самое старое незавершенное задание, которое никто не держит, до lockedUntil
+	// и возвращает его со всеми списками; если таких нет - sql.ErrNoRows
+	ClaimImportJob(ctx context.Context, now, lockedUntil time.Time) (models.ImportJob, []models.ListBackup, error)
+	// ImportJobList создает очередной список задания и продлевает его до lockedUntil. Если прогресс задания уже
+	// не job.DoneLists (список создал другой обработчик) или задание удалено - sql.ErrNoRows и ничего не создается.
+	ImportJobList(ctx context.Context, job models.ImportJob, list models.ListBackup, lockedUntil time.Time) error
+	// FinishImportJob сохраняет итоговые Status и Error задания и удаляет его списки
+	FinishImportJob(ctx context.Context, job models.ImportJob) error
+}
+
+// AccessToken - персональные токены доступа; токен хранится только в виде хэша
+type AccessToken interface {
+	CreateAccessToken(ctx context.Context, userId int, token models.AccessToken, tokenHash string) (int, error)
+	GetAccessTokens(ctx context.Context, userId int) ([]models.AccessToken, error)
+	// DeleteAccessToken возвращает sql.ErrNoRows, если у пользователя нет такого токена
+	DeleteAccessToken(ctx context.Context, userId, id int) error
+	// GetAccessTokenByHash возвращает токен вместе с владельцем; для неизвестного токена и заблокированного
+	// пользователя - sql.ErrNoRows. Срок действия проверяет вызывающий.
+	GetAccessTokenByHash(ctx context.Context, tokenHash string) (models.AccessToken, error)
+	// TouchAccessToken запоминает время последнего использования токена
+	TouchAccessToken(ctx context.Context, id int, usedAt time.Time) error
+}
+
+// Identity - связки пользователей с аккаунтами у провайдеров OpenID Connect
+type Identity interface {
+	// GetIdentityUser возвращает пользователя, связанного с аккаунтом провайдера; если связки нет - sql.ErrNoRows
+	GetIdentityUser(ctx context.Context, provider, subject string) (models.UserSummary, error)
+	CreateIdentity(ctx context.Context, identity models.Identity) error
+	// CreateUserWithIdentity создает пользователя и связку в одной транзакции
+	CreateUserWithIdentity(ctx context.Context, user models.User, identity models.Identity) (int, error)
+	GetIdentities(ctx context.Context, userId int) ([]models.Identity, error)
+}
+
+// TwoFactor - секреты TOTP и коды восстановления; коды хранятся только в виде хэшей
+type TwoFactor interface {
+	// GetTOTP возвращает sql.ErrNoRows, если пользователь не начинал подключение 2FA
+	GetTOTP(ctx context.Context, userId int) (models.TOTP, error)
+	// SetTOTP начинает подключение заново: неподтвержденный секрет заменяет прежний
+	SetTOTP(ctx context.Context, totp models.TOTP) error
+	// ConfirmTOTP включает 2FA и заменяет коды восстановления в одной транзакции;
+	// sql.ErrNoRows - подключение не начато или уже подтверждено
+	ConfirmTOTP(ctx context.Context, userId int, step int64, confirmedAt time.Time, recoveryHashes []string) error
+	// UseTOTPStep запоминает принятый шаг кода; false - код этого или более позднего шага уже принимался
+	UseTOTPStep(ctx context.Context, userId int, step int64) (bool, error)
+	// UseRecoveryCode удаляет код восстановления; sql.ErrNoRows - у пользователя нет такого кода
+	UseRecoveryCode(ctx context.Context, userId int, codeHash string) error
+	CountRecoveryCodes(ctx context.Context, userId int) (int, error)
+	// DeleteTOTP выключает 2FA вместе с кодами восстановления
+	DeleteTOTP(ctx context.Context, userId int) error
+}
+
+// Account - изменение профиля и пароля самим пользователем
+type Account interface {
+	// UpdateName возвращает sql.ErrNoRows, если пользователя нет
+	UpdateName(ctx context.Context, userId int, name string) error
+	// CheckPassword возвращает sql.ErrNoRows, если хэш пароля не совпадает
+	CheckPassword(ctx context.Context, userId int, passwordHash string) error
+	// ChangePassword меняет пароль и тем же запросом увеличивает версию сессий; возвращает новую версию
+	ChangePassword(ctx context.Context, userId int, passwordHash string) (int, error)
+	// GetSessionVersion возвращает версию сессий, выпущенные JWT действуют только с ней;
+	// sql.ErrNoRows - пользователя нет или он отключен
+	GetSessionVersion(ctx context.Context, userId int) (int, error)
 }
 
 // структура, собирающая все репозитории в одном месте
 type Repository struct {
 	Authorization
+	Admin
 	TodoList
 	TodoItem
+	Backup
+	CalendarFeed
+	AppPassword
+	CalDAV
+	ImportJob
+	AccessToken
+	Identity
+	TwoFactor
+	Account
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
 	return &Repository{
 		Authorization: NewAuthPostgres(db),
+		Admin:         NewAdminSQL(db),
 		TodoList:      NewTodoListPostgres(db),
 		TodoItem:      NewTodoItemPostgres(db),
+		Backup:        NewBackupSQL(db),
+		CalendarFeed:  NewCalendarFeedSQL(db),
+		AppPassword:   NewAppPasswordSQL(db),
+		CalDAV:        NewCalDAVSQL(db),
+		ImportJob:     NewImportJobSQL(db),
+		AccessToken:   NewAccessTokenSQL(db),
+		Identity:      NewIdentitySQL(db),
+		TwoFactor:     NewTwoFactorSQL(db),
+		Account:       NewAccountSQL(db),
 	}
 }
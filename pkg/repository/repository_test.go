@@ -0,0 +1,47 @@
+package repository_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+// postgresDSNEnv - строка подключения к пустой базе Postgres для проверок, без нее проверки Postgres пропускаются.
+// Данные в базе удаляются перед каждой проверкой.
+const postgresDSNEnv = "TODO_TEST_POSTGRES_DSN"
+
+func TestMemoryRepository(t *testing.T) {
+	runSuite(t, func(t *testing.T) *repository.Repository {
+		return repository.NewMemoryRepository()
+	})
+}
+
+func TestSQLiteRepository(t *testing.T) {
+	runSuite(t, sqliteFactory())
+}
+
+func TestPostgresRepository(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connect postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := repository.NewPostgresMigrator(db)
+	if err != nil {
+		t.Fatalf("postgres migrator: %v", err)
+	}
+	defer migrator.Close()
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("migrate postgres: %v", err)
+	}
+
+	runSuite(t, postgresFactory(db))
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite" // Импорт драйвера SQLite (без cgo)
+)
+
+type SQLiteConfig struct {
+	Path string // путь к файлу базы, ":memory:" - база в памяти
+}
+
+// NewSQLiteDB открывает файл базы SQLite, схему накатывает NewSQLiteMigrator.
+// Внешние ключи в SQLite по умолчанию выключены, без них не сработает ON DELETE CASCADE, поэтому включаем их в DSN.
+func NewSQLiteDB(cfg SQLiteConfig) (*sqlx.DB, error) {
+	db, err := openDB("sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", cfg.Path))
+	if err != nil {
+		return nil, err
+	}
+	// SQLite допускает только одного писателя, а база ":memory:" живет в рамках одного соединения
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewSQLiteRepository собирает репозитории поверх базы SQLite
+func NewSQLiteRepository(db *sqlx.DB) *Repository {
+	return &Repository{
+		Authorization: NewAuthSQLite(db),
+		Admin:         NewAdminSQL(db),
+		TodoList:      NewTodoListSQLite(db),
+		TodoItem:      NewTodoItemSQLite(db),
+		Backup:        NewBackupSQL(db),
+		CalendarFeed:  NewCalendarFeedSQL(db),
+		AppPassword:   NewAppPasswordSQL(db),
+		CalDAV:        NewCalDAVSQL(db),
+		ImportJob:     NewImportJobSQL(db),
+		AccessToken:   NewAccessTokenSQL(db),
+		Identity:      NewIdentitySQL(db),
+		TwoFactor:     NewTwoFactorSQL(db),
+		Account:       NewAccountSQL(db),
+	}
+}
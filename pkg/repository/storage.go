@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StorageConfig - выбранное хранилище (storage.driver) и параметры подключения к нему
+type StorageConfig struct {
+	Driver   string
+	Postgres Config
+	SQLite   SQLiteConfig
+}
+
+// OpenDB открывает базу хранилища; у in-memory хранилища базы нет
+func OpenDB(cfg StorageConfig) (*sqlx.DB, error) {
+	switch cfg.Driver {
+	case "postgres":
+		return NewPostgresDB(cfg.Postgres)
+	case "sqlite":
+		return NewSQLiteDB(cfg.SQLite)
+	case "memory":
+		return nil, errors.New("in-memory storage has no database")
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// NewMigrator возвращает мигратор для базы, открытой через OpenDB
+func NewMigrator(driver string, db *sqlx.DB) (*Migrator, error) {
+	if driver == "sqlite" {
+		return NewSQLiteMigrator(db)
+	}
+	return NewPostgresMigrator(db)
+}
+
+// NewDBRepository собирает репозитории поверх базы, открытой через OpenDB
+func NewDBRepository(driver string, db *sqlx.DB) *Repository {
+	if driver == "sqlite" {
+		return NewSQLiteRepository(db)
+	}
+	return NewRepository(db)
+}
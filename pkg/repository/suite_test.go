@@ -0,0 +1,987 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+// repoFactory создает новое пустое хранилище для одной проверки
+type repoFactory func(t *testing.T) *repository.Repository
+
+// runSuite прогоняет общий набор проверок на хранилище из factory. Любое хранилище (Postgres, память и т.д.)
+// должно проходить его одинаково, прежде всего в части правил доступа: пользователь видит и меняет только
+// списки из users_lists и задачи в них. factory вызывается для каждой проверки и возвращает пустое хранилище.
+func runSuite(t *testing.T, factory repoFactory) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, factory(t)) })
+	t.Run("ListOwnership", func(t *testing.T) { testListOwnership(t, factory(t)) })
+	t.Run("ListUpdates", func(t *testing.T) { testListUpdates(t, factory(t)) })
+	t.Run("ItemOwnership", func(t *testing.T) { testItemOwnership(t, factory(t)) })
+	t.Run("ItemUpdates", func(t *testing.T) { testItemUpdates(t, factory(t)) })
+	t.Run("BatchAndSearch", func(t *testing.T) { testBatchAndSearch(t, factory(t)) })
+	t.Run("ListDeleteRemovesItems", func(t *testing.T) { testListDeleteRemovesItems(t, factory(t)) })
+	t.Run("Admin", func(t *testing.T) { testAdmin(t, factory(t)) })
+	t.Run("Import", func(t *testing.T) { testImport(t, factory(t)) })
+	t.Run("DueDate", func(t *testing.T) { testDueDate(t, factory(t)) })
+	t.Run("CalendarFeed", func(t *testing.T) { testCalendarFeed(t, factory(t)) })
+	t.Run("AppPasswords", func(t *testing.T) { testAppPasswords(t, factory(t)) })
+	t.Run("CalDAVObjects", func(t *testing.T) { testCalDAVObjects(t, factory(t)) })
+	t.Run("ImportJobs", func(t *testing.T) { testImportJobs(t, factory(t)) })
+	t.Run("AccessTokens", func(t *testing.T) { testAccessTokens(t, factory(t)) })
+	t.Run("Identities", func(t *testing.T) { testIdentities(t, factory(t)) })
+	t.Run("TwoFactor", func(t *testing.T) { testTwoFactor(t, factory(t)) })
+	t.Run("Account", func(t *testing.T) { testAccount(t, factory(t)) })
+}
+
+func testUsers(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+
+	id, err := repo.Authorization.CreateUser(ctx, models.User{Name: "Alice", Username: "alice", Password: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+
+	if _, err := repo.Authorization.CreateUser(ctx, models.User{Name: "Other", Username: "alice", Password: "x"}); err == nil {
+		t.Error("CreateUser with a duplicate username: expected an error")
+	}
+
+	user, err := repo.Authorization.GetUser(ctx, "alice", "hash")
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if user.Id != id {
+		t.Errorf("GetUser: got id %d, want %d", user.Id, id)
+	}
+
+	if _, err := repo.Authorization.GetUser(ctx, "alice", "wrong"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser with a wrong password: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testListOwnership(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	listId := createList(t, repo, alice, "groceries")
+
+	lists, err := repo.TodoList.GetAll(ctx, alice)
+	if err != nil || len(lists) != 1 || lists[0].Id != listId {
+		t.Fatalf("GetAll(owner): got %v, %v", lists, err)
+	}
+
+	lists, err = repo.TodoList.GetAll(ctx, bob)
+	if err != nil || len(lists) != 0 {
+		t.Errorf("GetAll(other user): got %v, %v; want no lists", lists, err)
+	}
+
+	if _, err := repo.TodoList.GetById(ctx, bob, listId); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetById(other user): got %v, want sql.ErrNoRows", err)
+	}
+
+	title := "hijacked"
+	if err := repo.TodoList.Update(ctx, bob, listId, models.UpdateListInput{Title: &title}); err != nil {
+		t.Errorf("Update(other user): %v", err)
+	}
+	if err := repo.TodoList.Delete(ctx, bob, listId); err != nil {
+		t.Errorf("Delete(other user): %v", err)
+	}
+
+	list, err := repo.TodoList.GetById(ctx, alice, listId)
+	if err != nil {
+		t.Fatalf("GetById(owner) after foreign update and delete: %v", err)
+	}
+	if list.Title != "groceries" {
+		t.Errorf("foreign Update changed the title to %q", list.Title)
+	}
+}
+
+func testListUpdates(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "alice")
+	listId := createList(t, repo, alice, "groceries")
+
+	title := "shopping"
+	if err := repo.TodoList.Update(ctx, alice, listId, models.UpdateListInput{Title: &title}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repo.TodoList.Patch(ctx, alice, listId, map[string]interface{}{"description": "weekly"}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if err := repo.TodoList.Patch(ctx, alice, listId, map[string]interface{}{"id": 42}); err == nil {
+		t.Error("Patch of the id column: expected an error")
+	}
+
+	list, err := repo.TodoList.GetById(ctx, alice, listId)
+	if err != nil {
+		t.Fatalf("GetById: %v", err)
+	}
+	if list.Title != "shopping" || list.Description != "weekly" {
+		t.Errorf("after Update and Patch: got %+v", list)
+	}
+
+	if err := repo.TodoList.Delete(ctx, alice, listId); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.TodoList.GetById(ctx, alice, listId); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetById after Delete: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testItemOwnership(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	listId := createList(t, repo, alice, "groceries")
+	itemId := createItem(t, repo, listId, "milk")
+
+	if got, err := repo.TodoItem.GetListId(ctx, alice, itemId); err != nil || got != listId {
+		t.Errorf("GetListId(owner): got %d, %v; want %d", got, err, listId)
+	}
+	if _, err := repo.TodoItem.GetListId(ctx, bob, itemId); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetListId(other user): got %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repo.TodoItem.GetById(ctx, bob, itemId); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetById(other user): got %v, want sql.ErrNoRows", err)
+	}
+
+	items, err := repo.TodoItem.GetAll(ctx, bob, listId)
+	if err != nil || len(items) != 0 {
+		t.Errorf("GetAll(other user): got %v, %v; want no items", items, err)
+	}
+
+	done := true
+	if err := repo.TodoItem.Update(ctx, bob, itemId, models.UpdateItemInput{Done: &done}); err != nil {
+		t.Errorf("Update(other user): %v", err)
+	}
+	if err := repo.TodoItem.Delete(ctx, bob, itemId); err != nil {
+		t.Errorf("Delete(other user): %v", err)
+	}
+
+	item, err := repo.TodoItem.GetById(ctx, alice, itemId)
+	if err != nil {
+		t.Fatalf("GetById(owner) after foreign update and delete: %v", err)
+	}
+	if item.Done {
+		t.Error("foreign Update marked the item as done")
+	}
+}
+
+func testItemUpdates(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "alice")
+	listId := createList(t, repo, alice, "groceries")
+	itemId := createItem(t, repo, listId, "milk")
+
+	done := true
+	if err := repo.TodoItem.Update(ctx, alice, itemId, models.UpdateItemInput{Done: &done}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repo.TodoItem.Patch(ctx, alice, itemId, map[string]interface{}{"title": "oat milk", "description": "2l"}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if err := repo.TodoItem.Patch(ctx, alice, itemId, map[string]interface{}{}); err == nil {
+		t.Error("Patch without changes: expected an error")
+	}
+
+	item, err := repo.TodoItem.GetById(ctx, alice, itemId)
+	if err != nil {
+		t.Fatalf("GetById: %v", err)
+	}
+	want := models.TodoItem{Id: itemId, Title: "oat milk", Description: "2l", Done: true}
+	if item != want {
+		t.Errorf("after Update and Patch: got %+v, want %+v", item, want)
+	}
+
+	if err := repo.TodoItem.Delete(ctx, alice, itemId); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.TodoItem.GetById(ctx, alice, itemId); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetById after Delete: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testBatchAndSearch(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	groceries := createList(t, repo, alice, "Groceries")
+	work := createList(t, repo, alice, "Work")
+	foreign := createList(t, repo, bob, "Bob's groceries")
+
+	milk := createItem(t, repo, groceries, "Milk")
+	createItem(t, repo, groceries, "Bread")
+	report := createItem(t, repo, work, "Write report about 100% growth")
+	createItem(t, repo, foreign, "Milk for Bob")
+
+	byList, err := repo.TodoItem.GetAllByLists(ctx, alice, []int{groceries, work, foreign})
+	if err != nil {
+		t.Fatalf("GetAllByLists: %v", err)
+	}
+	if len(byList[groceries]) != 2 || len(byList[work]) != 1 || len(byList[foreign]) != 0 {
+		t.Errorf("GetAllByLists: got %v", byList)
+	}
+
+	lists, err := repo.TodoList.Search(ctx, alice, "grocer")
+	if err != nil || len(lists) != 1 || lists[0].Id != groceries {
+		t.Errorf("TodoList.Search: got %v, %v", lists, err)
+	}
+
+	items, err := repo.TodoItem.Search(ctx, alice, "milk")
+	if err != nil || len(items) != 1 || items[0].Id != milk {
+		t.Errorf("TodoItem.Search is case-insensitive and limited to own lists: got %v, %v", items, err)
+	}
+
+	items, err = repo.TodoItem.Search(ctx, alice, "100%")
+	if err != nil || len(items) != 1 || items[0].Id != report {
+		t.Errorf("TodoItem.Search treats %% literally: got %v, %v", items, err)
+	}
+}
+
+func testListDeleteRemovesItems(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "alice")
+	listId := createList(t, repo, alice, "groceries")
+	itemId := createItem(t, repo, listId, "milk")
+
+	if err := repo.TodoList.Delete(ctx, alice, listId); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.TodoItem.GetById(ctx, alice, itemId); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("item of a deleted list is still visible: %v", err)
+	}
+
+	// GetTotals считает все задачи хранилища, в том числе оставшиеся без списка
+	totals, err := repo.Admin.GetTotals(ctx)
+	if err != nil {
+		t.Fatalf("GetTotals: %v", err)
+	}
+	if totals.Lists != 0 || totals.Items != 0 {
+		t.Errorf("GetTotals after Delete: got %+v, want no lists and no items", totals)
+	}
+}
+
+//
+//
+//
+
+func testAdmin(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "alice")
+	bob := createUser(t, repo, "bob")
+	listId := createList(t, repo, alice, "groceries")
+	itemId := createItem(t, repo, listId, "milk")
+	done := true
+	if err := repo.TodoItem.Update(ctx, alice, itemId, models.UpdateItemInput{Done: &done}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	createItem(t, repo, listId, "bread")
+
+	stats, err := repo.Admin.GetStats(ctx, alice)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if want := (models.UserStats{UserId: alice, Lists: 1, Items: 2, DoneItems: 1}); stats != want {
+		t.Errorf("GetStats: got %+v, want %+v", stats, want)
+	}
+
+	if err := repo.Admin.SetDisabled(ctx, alice, true); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if _, err := repo.Authorization.GetUser(ctx, "alice", "hash"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser of a disabled user: got %v, want sql.ErrNoRows", err)
+	}
+	if user, err := repo.Admin.GetUserByUsername(ctx, "alice"); err != nil || !user.Disabled {
+		t.Errorf("GetUserByUsername: got %+v, %v, want disabled user", user, err)
+	}
+	if user, err := repo.Admin.GetUserById(ctx, alice); err != nil || user.Username != "alice" || !user.Disabled {
+		t.Errorf("GetUserById: got %+v, %v, want disabled alice", user, err)
+	}
+	if err := repo.Admin.SetDisabled(ctx, alice, false); err != nil {
+		t.Fatalf("SetDisabled(false): %v", err)
+	}
+
+	if err := repo.Admin.SetPassword(ctx, alice, "new-hash"); err != nil {
+		t.Fatalf("SetPassword: %v", err)
+	}
+	if _, err := repo.Authorization.GetUser(ctx, "alice", "new-hash"); err != nil {
+		t.Errorf("GetUser with the new password: %v", err)
+	}
+	if err := repo.Admin.SetPassword(ctx, 1000, "x"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("SetPassword of a missing user: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.Admin.TransferList(ctx, listId, bob, alice); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("TransferList of a foreign list: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.Admin.TransferList(ctx, listId, alice, bob); err != nil {
+		t.Fatalf("TransferList: %v", err)
+	}
+	if _, err := repo.TodoList.GetById(ctx, alice, listId); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("list is still visible to the previous owner: %v", err)
+	}
+	if _, err := repo.TodoItem.GetById(ctx, bob, itemId); err != nil {
+		t.Errorf("item of a transferred list is not visible to the new owner: %v", err)
+	}
+
+	aliceList := createList(t, repo, alice, "books")
+	if err := repo.Admin.DeleteUser(ctx, bob); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := repo.Admin.GetUserByUsername(ctx, "bob"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("deleted user is still found: %v", err)
+	}
+	if _, err := repo.Admin.GetUserById(ctx, bob); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("deleted user is still found by id: %v", err)
+	}
+	if stats, err := repo.Admin.GetStats(ctx, bob); err != nil || stats.Lists != 0 || stats.Items != 0 {
+		t.Errorf("GetStats of a deleted user: got %+v, %v", stats, err)
+	}
+	if _, err := repo.TodoList.GetById(ctx, alice, aliceList); err != nil {
+		t.Errorf("DeleteUser removed a list of another user: %v", err)
+	}
+	if err := repo.Admin.DeleteUser(ctx, bob); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteUser of a missing user: got %v, want sql.ErrNoRows", err)
+	}
+
+	users, err := repo.Admin.GetUsers(ctx)
+	if err != nil {
+		t.Fatalf("GetUsers: %v", err)
+	}
+	if len(users) != 1 || users[0].Username != "alice" {
+		t.Errorf("GetUsers: got %+v, want only alice", users)
+	}
+
+	createItem(t, repo, aliceList, "dune")
+	totals, err := repo.Admin.GetTotals(ctx)
+	if err != nil {
+		t.Fatalf("GetTotals: %v", err)
+	}
+	if want := (models.Totals{Users: 1, Lists: 1, Items: 1}); totals != want {
+		t.Errorf("GetTotals: got %+v, want %+v", totals, want)
+	}
+}
+
+func testImport(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "alice")
+
+	ids, err := repo.Backup.ImportLists(ctx, alice, []models.ListBackup{
+		{Title: "Groceries", Items: []models.ItemBackup{{Title: "Milk", Done: true}, {Title: "Bread", Description: "rye"}}},
+		{Title: "Empty", Description: "no items"},
+	})
+	if err != nil {
+		t.Fatalf("ImportLists: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("ImportLists: got ids %v, want 2", ids)
+	}
+
+	list, err := repo.TodoList.GetById(ctx, alice, ids[1])
+	if err != nil || list.Title != "Empty" || list.Description != "no items" {
+		t.Errorf("imported list: got %+v, %v", list, err)
+	}
+	items, err := repo.TodoItem.GetAll(ctx, alice, ids[0])
+	if err != nil || len(items) != 2 {
+		t.Fatalf("items of the imported list: got %v, %v", items, err)
+	}
+	for _, item := range items {
+		if (item.Title == "Milk") != item.Done {
+			t.Errorf("imported item %+v: done flag is not preserved", item)
+		}
+	}
+
+	// импорт для несуществующего пользователя падает целиком и ничего не оставляет
+	if _, err := repo.Backup.ImportLists(ctx, alice+1000, []models.ListBackup{{Title: "Orphan", Items: []models.ItemBackup{{Title: "x"}}}}); err == nil {
+		t.Error("ImportLists for a missing user: expected an error")
+	}
+	totals, err := repo.Admin.GetTotals(ctx)
+	if err != nil {
+		t.Fatalf("GetTotals: %v", err)
+	}
+	if want := (models.Totals{Users: 1, Lists: 2, Items: 2, DoneItems: 1}); totals != want {
+		t.Errorf("GetTotals after a failed import: got %+v, want %+v", totals, want)
+	}
+}
+
+func testDueDate(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "alice")
+	listId := createList(t, repo, alice, "groceries")
+
+	due := time.Date(2030, 5, 17, 9, 30, 0, 0, time.UTC)
+	itemId, err := repo.TodoItem.Create(ctx, listId, models.TodoItem{Title: "milk", DueDate: &due})
+	if err != nil {
+		t.Fatalf("Create with a due date: %v", err)
+	}
+	item, err := repo.TodoItem.GetById(ctx, alice, itemId)
+	if err != nil || item.DueDate == nil || !item.DueDate.Equal(due) {
+		t.Fatalf("GetById: got %+v, %v, want due date %v", item, err, due)
+	}
+
+	later := due.Add(48 * time.Hour)
+	if err := repo.TodoItem.Update(ctx, alice, itemId, models.UpdateItemInput{DueDate: &later}); err != nil {
+		t.Fatalf("Update due date: %v", err)
+	}
+	byList, err := repo.TodoItem.GetAllByLists(ctx, alice, []int{listId})
+	if err != nil || len(byList[listId]) != 1 || byList[listId][0].DueDate == nil || !byList[listId][0].DueDate.Equal(later) {
+		t.Fatalf("GetAllByLists after Update: got %v, %v, want due date %v", byList, err, later)
+	}
+
+	// PATCH с "due_date": null снимает срок
+	if err := repo.TodoItem.Patch(ctx, alice, itemId, map[string]interface{}{"due_date": (*time.Time)(nil)}); err != nil {
+		t.Fatalf("Patch due date to null: %v", err)
+	}
+	if item, err := repo.TodoItem.GetById(ctx, alice, itemId); err != nil || item.DueDate != nil {
+		t.Errorf("GetById after clearing the due date: got %+v, %v", item, err)
+	}
+}
+
+func testCalendarFeed(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+
+	if _, err := repo.CalendarFeed.GetFeedUser(ctx, "hash-a"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeedUser before SetFeedToken: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.CalendarFeed.SetFeedToken(ctx, alice, "hash-a"); err != nil {
+		t.Fatalf("SetFeedToken: %v", err)
+	}
+	if err := repo.CalendarFeed.SetFeedToken(ctx, bob, "hash-b"); err != nil {
+		t.Fatalf("SetFeedToken(bob): %v", err)
+	}
+	if userId, err := repo.CalendarFeed.GetFeedUser(ctx, "hash-a"); err != nil || userId != alice {
+		t.Errorf("GetFeedUser: got %d, %v, want %d", userId, err, alice)
+	}
+
+	// новый токен отзывает прежний
+	if err := repo.CalendarFeed.SetFeedToken(ctx, alice, "hash-a2"); err != nil {
+		t.Fatalf("SetFeedToken again: %v", err)
+	}
+	if _, err := repo.CalendarFeed.GetFeedUser(ctx, "hash-a"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeedUser with a replaced token: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.Admin.SetDisabled(ctx, alice, true); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if _, err := repo.CalendarFeed.GetFeedUser(ctx, "hash-a2"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeedUser of a disabled user: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.CalendarFeed.DeleteFeedToken(ctx, bob); err != nil {
+		t.Fatalf("DeleteFeedToken: %v", err)
+	}
+	if _, err := repo.CalendarFeed.GetFeedUser(ctx, "hash-b"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetFeedUser after DeleteFeedToken: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.CalendarFeed.DeleteFeedToken(ctx, bob); err != nil {
+		t.Errorf("DeleteFeedToken without a token: %v", err)
+	}
+}
+
+func testAppPasswords(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	created := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	phone, err := repo.AppPassword.CreateAppPassword(ctx, alice, models.AppPassword{Name: "phone", CreatedAt: created}, "hash-phone")
+	if err != nil {
+		t.Fatalf("CreateAppPassword: %v", err)
+	}
+	if _, err := repo.AppPassword.CreateAppPassword(ctx, alice, models.AppPassword{Name: "laptop", CreatedAt: created}, "hash-laptop"); err != nil {
+		t.Fatalf("CreateAppPassword(laptop): %v", err)
+	}
+
+	passwords, err := repo.AppPassword.GetAppPasswords(ctx, alice)
+	if err != nil || len(passwords) != 2 || passwords[0].Id != phone || passwords[0].Name != "phone" || !passwords[0].CreatedAt.Equal(created) {
+		t.Fatalf("GetAppPasswords: got %+v, %v", passwords, err)
+	}
+	if passwords, err := repo.AppPassword.GetAppPasswords(ctx, bob); err != nil || len(passwords) != 0 {
+		t.Errorf("GetAppPasswords(bob): got %+v, %v, want empty", passwords, err)
+	}
+
+	if userId, err := repo.AppPassword.GetAppPasswordUser(ctx, "alice", "hash-phone"); err != nil || userId != alice {
+		t.Errorf("GetAppPasswordUser: got %d, %v, want %d", userId, err, alice)
+	}
+	if _, err := repo.AppPassword.GetAppPasswordUser(ctx, "bob", "hash-phone"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAppPasswordUser with another username: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.AppPassword.DeleteAppPassword(ctx, bob, phone); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteAppPassword of another user: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.AppPassword.DeleteAppPassword(ctx, alice, phone); err != nil {
+		t.Fatalf("DeleteAppPassword: %v", err)
+	}
+	if _, err := repo.AppPassword.GetAppPasswordUser(ctx, "alice", "hash-phone"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAppPasswordUser after delete: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.Admin.SetDisabled(ctx, alice, true); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if _, err := repo.AppPassword.GetAppPasswordUser(ctx, "alice", "hash-laptop"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAppPasswordUser of a disabled user: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testAccessTokens(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	expires := created.Add(24 * time.Hour)
+
+	ci, err := repo.AccessToken.CreateAccessToken(ctx, alice, models.AccessToken{
+		Name: "ci", Scopes: []string{models.ScopeItemsWrite, models.ScopeRead}, ExpiresAt: &expires, CreatedAt: created,
+	}, "hash-ci")
+	if err != nil {
+		t.Fatalf("CreateAccessToken: %v", err)
+	}
+	if _, err := repo.AccessToken.CreateAccessToken(ctx, alice, models.AccessToken{Name: "backup", Scopes: []string{models.ScopeRead}, CreatedAt: created}, "hash-backup"); err != nil {
+		t.Fatalf("CreateAccessToken(backup): %v", err)
+	}
+
+	tokens, err := repo.AccessToken.GetAccessTokens(ctx, alice)
+	if err != nil || len(tokens) != 2 || tokens[0].Id != ci || tokens[1].ExpiresAt != nil {
+		t.Fatalf("GetAccessTokens: got %+v, %v", tokens, err)
+	}
+	if got := tokens[0]; got.Name != "ci" || len(got.Scopes) != 2 || got.Scopes[1] != models.ScopeRead ||
+		got.ExpiresAt == nil || !got.ExpiresAt.Equal(expires) || got.LastUsedAt != nil {
+		t.Errorf("GetAccessTokens: got %+v", got)
+	}
+	if tokens, err := repo.AccessToken.GetAccessTokens(ctx, bob); err != nil || len(tokens) != 0 {
+		t.Errorf("GetAccessTokens(bob): got %+v, %v, want empty", tokens, err)
+	}
+
+	token, err := repo.AccessToken.GetAccessTokenByHash(ctx, "hash-ci")
+	if err != nil || token.Id != ci || token.UserId != alice || len(token.Scopes) != 2 {
+		t.Errorf("GetAccessTokenByHash: got %+v, %v", token, err)
+	}
+	used := created.Add(time.Hour)
+	if err := repo.AccessToken.TouchAccessToken(ctx, ci, used); err != nil {
+		t.Fatalf("TouchAccessToken: %v", err)
+	}
+	if token, err := repo.AccessToken.GetAccessTokenByHash(ctx, "hash-ci"); err != nil || token.LastUsedAt == nil || !token.LastUsedAt.Equal(used) {
+		t.Errorf("GetAccessTokenByHash after touch: got %+v, %v", token, err)
+	}
+
+	if err := repo.AccessToken.DeleteAccessToken(ctx, bob, ci); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteAccessToken of another user: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.AccessToken.DeleteAccessToken(ctx, alice, ci); err != nil {
+		t.Fatalf("DeleteAccessToken: %v", err)
+	}
+	if _, err := repo.AccessToken.GetAccessTokenByHash(ctx, "hash-ci"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAccessTokenByHash after delete: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.Admin.SetDisabled(ctx, alice, true); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if _, err := repo.AccessToken.GetAccessTokenByHash(ctx, "hash-backup"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAccessTokenByHash of a disabled user: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testIdentities(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "alice")
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if _, err := repo.Identity.GetIdentityUser(ctx, "corp", "a-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetIdentityUser without identity: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.Identity.CreateIdentity(ctx, models.Identity{UserId: alice, Provider: "corp", Subject: "a-1", Email: "alice@example.com", CreatedAt: created}); err != nil {
+		t.Fatalf("CreateIdentity: %v", err)
+	}
+	if err := repo.Identity.CreateIdentity(ctx, models.Identity{UserId: alice, Provider: "corp", Subject: "a-1", CreatedAt: created}); err == nil {
+		t.Error("CreateIdentity with a duplicate subject: expected an error")
+	}
+	if user, err := repo.Identity.GetIdentityUser(ctx, "corp", "a-1"); err != nil || user.Id != alice || user.Username != "alice" {
+		t.Errorf("GetIdentityUser: got %+v, %v", user, err)
+	}
+	if _, err := repo.Identity.GetIdentityUser(ctx, "other", "a-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetIdentityUser of another provider: got %v, want sql.ErrNoRows", err)
+	}
+
+	bob, err := repo.Identity.CreateUserWithIdentity(ctx, models.User{Name: "Bob", Username: "bob", Password: "hash"},
+		models.Identity{Provider: "corp", Subject: "b-1", CreatedAt: created})
+	if err != nil {
+		t.Fatalf("CreateUserWithIdentity: %v", err)
+	}
+	if user, err := repo.Identity.GetIdentityUser(ctx, "corp", "b-1"); err != nil || user.Id != bob || user.Name != "Bob" {
+		t.Errorf("GetIdentityUser(bob): got %+v, %v", user, err)
+	}
+	identities, err := repo.Identity.GetIdentities(ctx, alice)
+	if err != nil || len(identities) != 1 || identities[0].Subject != "a-1" || identities[0].Email != "alice@example.com" ||
+		!identities[0].CreatedAt.Equal(created) {
+		t.Errorf("GetIdentities(alice): got %+v, %v", identities, err)
+	}
+	// занятое имя откатывает и пользователя, и связку
+	if _, err := repo.Identity.CreateUserWithIdentity(ctx, models.User{Name: "Bob", Username: "bob", Password: "hash"},
+		models.Identity{Provider: "corp", Subject: "b-2", CreatedAt: created}); err == nil {
+		t.Error("CreateUserWithIdentity with a taken username: expected an error")
+	}
+	if _, err := repo.Identity.GetIdentityUser(ctx, "corp", "b-2"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetIdentityUser after a failed create: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.Admin.SetDisabled(ctx, bob, true); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if user, err := repo.Identity.GetIdentityUser(ctx, "corp", "b-1"); err != nil || !user.Disabled {
+		t.Errorf("GetIdentityUser of a disabled user: got %+v, %v, want Disabled", user, err)
+	}
+	if err := repo.Admin.DeleteUser(ctx, bob); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := repo.Identity.GetIdentityUser(ctx, "corp", "b-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetIdentityUser of a deleted user: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testTwoFactor(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	if _, err := repo.TwoFactor.GetTOTP(ctx, alice); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetTOTP before enrollment: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.TwoFactor.ConfirmTOTP(ctx, alice, 10, created, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ConfirmTOTP before enrollment: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.TwoFactor.SetTOTP(ctx, models.TOTP{UserId: alice, Secret: "OLD", CreatedAt: created}); err != nil {
+		t.Fatalf("SetTOTP: %v", err)
+	}
+	if err := repo.TwoFactor.SetTOTP(ctx, models.TOTP{UserId: alice, Secret: "SECRET", CreatedAt: created}); err != nil {
+		t.Fatalf("SetTOTP again: %v", err)
+	}
+	totp, err := repo.TwoFactor.GetTOTP(ctx, alice)
+	if err != nil || totp.Secret != "SECRET" || totp.Enabled() || totp.LastStep != 0 {
+		t.Fatalf("GetTOTP after enrollment: got %+v, %v", totp, err)
+	}
+
+	if err := repo.TwoFactor.ConfirmTOTP(ctx, alice, 10, created, []string{"hash-1", "hash-2"}); err != nil {
+		t.Fatalf("ConfirmTOTP: %v", err)
+	}
+	if err := repo.TwoFactor.ConfirmTOTP(ctx, alice, 11, created, []string{"hash-3"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ConfirmTOTP again: got %v, want sql.ErrNoRows", err)
+	}
+	totp, err = repo.TwoFactor.GetTOTP(ctx, alice)
+	if err != nil || !totp.Enabled() || !totp.ConfirmedAt.Equal(created) || totp.LastStep != 10 {
+		t.Fatalf("GetTOTP after confirmation: got %+v, %v", totp, err)
+	}
+
+	// шаг принимается один раз и только если он позже последнего принятого
+	for _, tc := range []struct {
+		step int64
+		want bool
+	}{{10, false}, {9, false}, {11, true}, {11, false}} {
+		if used, err := repo.TwoFactor.UseTOTPStep(ctx, alice, tc.step); err != nil || used != tc.want {
+			t.Errorf("UseTOTPStep(%d): got %t, %v, want %t", tc.step, used, err, tc.want)
+		}
+	}
+
+	if err := repo.TwoFactor.UseRecoveryCode(ctx, bob, "hash-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UseRecoveryCode of another user: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.TwoFactor.UseRecoveryCode(ctx, alice, "hash-1"); err != nil {
+		t.Errorf("UseRecoveryCode: %v", err)
+	}
+	if err := repo.TwoFactor.UseRecoveryCode(ctx, alice, "hash-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UseRecoveryCode twice: got %v, want sql.ErrNoRows", err)
+	}
+	if count, err := repo.TwoFactor.CountRecoveryCodes(ctx, alice); err != nil || count != 1 {
+		t.Errorf("CountRecoveryCodes: got %d, %v, want 1", count, err)
+	}
+
+	if err := repo.TwoFactor.DeleteTOTP(ctx, alice); err != nil {
+		t.Fatalf("DeleteTOTP: %v", err)
+	}
+	if _, err := repo.TwoFactor.GetTOTP(ctx, alice); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetTOTP after DeleteTOTP: got %v, want sql.ErrNoRows", err)
+	}
+	if count, err := repo.TwoFactor.CountRecoveryCodes(ctx, alice); err != nil || count != 0 {
+		t.Errorf("CountRecoveryCodes after DeleteTOTP: got %d, %v, want 0", count, err)
+	}
+
+	if err := repo.TwoFactor.SetTOTP(ctx, models.TOTP{UserId: bob, Secret: "BOB", CreatedAt: created}); err != nil {
+		t.Fatalf("SetTOTP(bob): %v", err)
+	}
+	if err := repo.TwoFactor.ConfirmTOTP(ctx, bob, 1, created, []string{"hash-b"}); err != nil {
+		t.Fatalf("ConfirmTOTP(bob): %v", err)
+	}
+	if err := repo.Admin.DeleteUser(ctx, bob); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := repo.TwoFactor.GetTOTP(ctx, bob); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetTOTP of a deleted user: got %v, want sql.ErrNoRows", err)
+	}
+	if count, err := repo.TwoFactor.CountRecoveryCodes(ctx, bob); err != nil || count != 0 {
+		t.Errorf("CountRecoveryCodes of a deleted user: got %d, %v, want 0", count, err)
+	}
+}
+
+func testAccount(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+
+	if err := repo.Account.UpdateName(ctx, alice, "Alice Liddell"); err != nil {
+		t.Fatalf("UpdateName: %v", err)
+	}
+	if user, err := repo.Admin.GetUserById(ctx, alice); err != nil || user.Name != "Alice Liddell" {
+		t.Errorf("GetUserById after UpdateName: got %+v, %v", user, err)
+	}
+	if err := repo.Account.UpdateName(ctx, alice+100, "Nobody"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateName of a missing user: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.Account.CheckPassword(ctx, alice, "hash"); err != nil {
+		t.Errorf("CheckPassword: %v", err)
+	}
+	if err := repo.Account.CheckPassword(ctx, alice, "wrong"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("CheckPassword with a wrong hash: got %v, want sql.ErrNoRows", err)
+	}
+
+	if version, err := repo.Account.GetSessionVersion(ctx, alice); err != nil || version != 0 {
+		t.Errorf("GetSessionVersion before ChangePassword: got %d, %v, want 0", version, err)
+	}
+	if version, err := repo.Account.ChangePassword(ctx, alice, "new-hash"); err != nil || version != 1 {
+		t.Fatalf("ChangePassword: got %d, %v, want 1", version, err)
+	}
+	if version, err := repo.Account.ChangePassword(ctx, alice, "new-hash"); err != nil || version != 2 {
+		t.Fatalf("ChangePassword again: got %d, %v, want 2", version, err)
+	}
+	if version, err := repo.Account.GetSessionVersion(ctx, alice); err != nil || version != 2 {
+		t.Errorf("GetSessionVersion: got %d, %v, want 2", version, err)
+	}
+	if version, err := repo.Account.GetSessionVersion(ctx, bob); err != nil || version != 0 {
+		t.Errorf("GetSessionVersion of another user: got %d, %v, want 0", version, err)
+	}
+	if _, err := repo.Account.ChangePassword(ctx, alice+100, "hash"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ChangePassword of a missing user: got %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repo.Authorization.GetUser(ctx, "alice", "new-hash"); err != nil {
+		t.Errorf("GetUser with the new password: %v", err)
+	}
+	if err := repo.Account.CheckPassword(ctx, alice, "hash"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("CheckPassword with the old hash: got %v, want sql.ErrNoRows", err)
+	}
+
+	// сброс пароля и отключение оператором тоже отзывают сессии, отключенному сессия не выдается вовсе
+	if err := repo.Admin.SetPassword(ctx, alice, "reset-hash"); err != nil {
+		t.Fatalf("SetPassword: %v", err)
+	}
+	if version, err := repo.Account.GetSessionVersion(ctx, alice); err != nil || version != 3 {
+		t.Errorf("GetSessionVersion after SetPassword: got %d, %v, want 3", version, err)
+	}
+	if err := repo.Admin.SetDisabled(ctx, alice, true); err != nil {
+		t.Fatalf("SetDisabled: %v", err)
+	}
+	if _, err := repo.Account.GetSessionVersion(ctx, alice); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetSessionVersion of a disabled user: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.Admin.SetDisabled(ctx, alice, false); err != nil {
+		t.Fatalf("SetDisabled(false): %v", err)
+	}
+	if version, err := repo.Account.GetSessionVersion(ctx, alice); err != nil || version != 5 {
+		t.Errorf("GetSessionVersion after enabling: got %d, %v, want 5", version, err)
+	}
+
+	if err := repo.Admin.DeleteUser(ctx, alice); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := repo.Account.GetSessionVersion(ctx, alice); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetSessionVersion of a deleted user: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func testCalDAVObjects(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	work, home := createList(t, repo, alice, "work"), createList(t, repo, alice, "home")
+	report, plain, chores := createItem(t, repo, work, "report"), createItem(t, repo, work, "plain"), createItem(t, repo, home, "chores")
+
+	if err := repo.CalDAV.SetObject(ctx, report, models.CalendarObject{Name: "a.ics", UID: "uid-a"}); err != nil {
+		t.Fatalf("SetObject: %v", err)
+	}
+	if err := repo.CalDAV.SetObject(ctx, chores, models.CalendarObject{Name: "c.ics", UID: "uid-c"}); err != nil {
+		t.Fatalf("SetObject(chores): %v", err)
+	}
+	if err := repo.CalDAV.SetObject(ctx, report, models.CalendarObject{Name: "b.ics", UID: "uid-b"}); err != nil {
+		t.Fatalf("SetObject again: %v", err)
+	}
+
+	objects, err := repo.CalDAV.GetObjects(ctx, alice, []int{work})
+	if err != nil || len(objects) != 1 || objects[report].Name != "b.ics" || objects[report].UID != "uid-b" {
+		t.Fatalf("GetObjects: got %+v, %v", objects, err)
+	}
+	if _, ok := objects[plain]; ok {
+		t.Errorf("GetObjects returned an item without an object")
+	}
+	if objects, err := repo.CalDAV.GetObjects(ctx, alice, []int{work, home}); err != nil || len(objects) != 2 {
+		t.Errorf("GetObjects of two lists: got %+v, %v", objects, err)
+	}
+	if objects, err := repo.CalDAV.GetObjects(ctx, bob, []int{work}); err != nil || len(objects) != 0 {
+		t.Errorf("GetObjects of a foreign list: got %+v, %v, want empty", objects, err)
+	}
+
+	if err := repo.TodoItem.Delete(ctx, alice, report); err != nil {
+		t.Fatalf("TodoItem.Delete: %v", err)
+	}
+	if err := repo.TodoList.Delete(ctx, alice, home); err != nil {
+		t.Fatalf("TodoList.Delete: %v", err)
+	}
+	if objects, err := repo.CalDAV.GetObjects(ctx, alice, []int{work, home}); err != nil || len(objects) != 0 {
+		t.Errorf("GetObjects after deleting items: got %+v, %v, want empty", objects, err)
+	}
+}
+
+func testImportJobs(t *testing.T, repo *repository.Repository) {
+	ctx := context.Background()
+	alice, bob := createUser(t, repo, "alice"), createUser(t, repo, "bob")
+	now := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	lists := []models.ListBackup{
+		{Title: "board", Items: []models.ItemBackup{{Title: "card"}, {Title: "done card", Done: true}}},
+		{Title: "empty board", Items: []models.ItemBackup{}},
+	}
+
+	if _, _, err := repo.ImportJob.ClaimImportJob(ctx, now, now.Add(time.Minute)); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("ClaimImportJob without jobs: got %v, want sql.ErrNoRows", err)
+	}
+
+	id, err := repo.ImportJob.CreateImportJob(ctx, models.ImportJob{
+		UserId: alice, Source: "trello", Status: models.ImportPending, TotalLists: 2, TotalItems: 2, CreatedAt: now, UpdatedAt: now,
+	}, lists)
+	if err != nil {
+		t.Fatalf("CreateImportJob: %v", err)
+	}
+	if job, err := repo.ImportJob.GetImportJob(ctx, alice, id); err != nil || job.Source != "trello" || job.Status != models.ImportPending || job.TotalItems != 2 {
+		t.Errorf("GetImportJob: got %+v, %v", job, err)
+	}
+	if _, err := repo.ImportJob.GetImportJob(ctx, bob, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetImportJob of another user: got %v, want sql.ErrNoRows", err)
+	}
+
+	job, claimed, err := repo.ImportJob.ClaimImportJob(ctx, now, now.Add(time.Minute))
+	if err != nil || job.Id != id || job.Status != models.ImportRunning || job.Attempts != 1 || len(claimed) != 2 || len(claimed[0].Items) != 2 {
+		t.Fatalf("ClaimImportJob: got %+v, %+v, %v", job, claimed, err)
+	}
+	if _, _, err := repo.ImportJob.ClaimImportJob(ctx, now.Add(time.Second), now.Add(time.Minute)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ClaimImportJob of a locked job: got %v, want sql.ErrNoRows", err)
+	}
+
+	if err := repo.ImportJob.ImportJobList(ctx, job, claimed[0], now.Add(time.Minute)); err != nil {
+		t.Fatalf("ImportJobList: %v", err)
+	}
+	// тот же прогресс второй раз - как будто список уже создал другой обработчик
+	if err := repo.ImportJob.ImportJobList(ctx, job, claimed[0], now.Add(time.Minute)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ImportJobList with stale progress: got %v, want sql.ErrNoRows", err)
+	}
+	created, err := repo.TodoList.GetAll(ctx, alice)
+	if err != nil || len(created) != 1 || created[0].Title != "board" {
+		t.Fatalf("lists after ImportJobList: got %+v, %v", created, err)
+	}
+	if items, err := repo.TodoItem.GetAll(ctx, alice, created[0].Id); err != nil || len(items) != 2 {
+		t.Errorf("items after ImportJobList: got %+v, %v", items, err)
+	}
+
+	// аренда истекла - задание продолжается с того же места
+	later := now.Add(2 * time.Minute)
+	job, _, err = repo.ImportJob.ClaimImportJob(ctx, later, later.Add(time.Minute))
+	if err != nil || job.DoneLists != 1 || job.DoneItems != 2 || job.Attempts != 1 {
+		t.Fatalf("ClaimImportJob after lease: got %+v, %v", job, err)
+	}
+	if err := repo.ImportJob.ImportJobList(ctx, job, claimed[1], later.Add(time.Minute)); err != nil {
+		t.Fatalf("ImportJobList(empty board): %v", err)
+	}
+	job.Status, job.DoneLists, job.UpdatedAt = models.ImportCompleted, 2, later
+	if err := repo.ImportJob.FinishImportJob(ctx, job); err != nil {
+		t.Fatalf("FinishImportJob: %v", err)
+	}
+	if _, _, err := repo.ImportJob.ClaimImportJob(ctx, later.Add(time.Hour), later.Add(2*time.Hour)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("ClaimImportJob of a finished job: got %v, want sql.ErrNoRows", err)
+	}
+
+	jobs, err := repo.ImportJob.GetImportJobs(ctx, alice)
+	if err != nil || len(jobs) != 1 || jobs[0].Status != models.ImportCompleted || jobs[0].DoneLists != 2 || !jobs[0].UpdatedAt.Equal(later) {
+		t.Errorf("GetImportJobs: got %+v, %v", jobs, err)
+	}
+	if jobs, err := repo.ImportJob.GetImportJobs(ctx, bob); err != nil || len(jobs) != 0 {
+		t.Errorf("GetImportJobs(bob): got %+v, %v, want empty", jobs, err)
+	}
+
+	if err := repo.Admin.DeleteUser(ctx, alice); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err := repo.ImportJob.GetImportJob(ctx, alice, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetImportJob after DeleteUser: got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func createUser(t *testing.T, repo *repository.Repository, username string) int {
+	t.Helper()
+	id, err := repo.Authorization.CreateUser(context.Background(), models.User{Name: username, Username: username, Password: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", username, err)
+	}
+	return id
+}
+
+func createList(t *testing.T, repo *repository.Repository, userId int, title string) int {
+	t.Helper()
+	id, err := repo.TodoList.Create(context.Background(), userId, models.TodoList{Title: title})
+	if err != nil {
+		t.Fatalf("TodoList.Create(%s): %v", title, err)
+	}
+	return id
+}
+
+func createItem(t *testing.T, repo *repository.Repository, listId int, title string) int {
+	t.Helper()
+	id, err := repo.TodoItem.Create(context.Background(), listId, models.TodoItem{Title: title})
+	if err != nil {
+		t.Fatalf("TodoItem.Create(%s): %v", title, err)
+	}
+	return id
+}
+
+// postgresFactory возвращает фабрику для Postgres: перед каждой проверкой таблицы схемы очищаются,
+// поэтому базу для тестов нужно заводить отдельную
+func postgresFactory(db *sqlx.DB) repoFactory {
+	return func(t *testing.T) *repository.Repository {
+		t.Helper()
+		if _, err := db.Exec("TRUNCATE users, todo_lists, users_lists, todo_items, lists_items, calendar_feeds, app_passwords, caldav_objects, import_jobs, access_tokens, user_identities, user_totp, recovery_codes RESTART IDENTITY CASCADE"); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return repository.NewRepository(db)
+	}
+}
+
+// sqliteFactory возвращает фабрику для SQLite: каждая проверка получает свой файл базы во временном каталоге теста
+func sqliteFactory() repoFactory {
+	return func(t *testing.T) *repository.Repository {
+		t.Helper()
+		db, err := repository.NewSQLiteDB(repository.SQLiteConfig{Path: filepath.Join(t.TempDir(), "todo.db")})
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		migrator, err := repository.NewSQLiteMigrator(db)
+		if err != nil {
+			t.Fatalf("sqlite migrator: %v", err)
+		}
+		defer migrator.Close()
+		if err := migrator.Up(); err != nil {
+			t.Fatalf("migrate sqlite: %v", err)
+		}
+		return repository.NewSQLiteRepository(db)
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type TodoItemMemory struct {
+	store *memoryStore
+}
+
+func (r *TodoItemMemory) Create(ctx context.Context, listId int, item models.TodoItem) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.lists[listId]; !ok {
+		return 0, fmt.Errorf("list %d does not exist", listId) // в Postgres сработал бы внешний ключ lists_items
+	}
+
+	r.store.lastItemId++
+	item.Id = r.store.lastItemId
+	r.store.items[item.Id] = item
+	r.store.listsItems[item.Id] = listId
+
+	return item.Id, nil
+}
+
+func (r *TodoItemMemory) GetAll(ctx context.Context, userId, listId int) ([]models.TodoItem, error) {
+	items, err := r.GetAllByLists(ctx, userId, []int{listId})
+	if err != nil {
+		return nil, err
+	}
+	return items[listId], nil
+}
+
+func (r *TodoItemMemory) GetAllByLists(ctx context.Context, userId int, listIds []int) (map[int][]models.TodoItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	wanted := make(map[int]bool, len(listIds))
+	for _, listId := range listIds {
+		wanted[listId] = r.store.hasList(userId, listId)
+	}
+
+	items := make(map[int][]models.TodoItem, len(listIds))
+	for _, itemId := range r.sortedItemIds() {
+		listId := r.store.listsItems[itemId]
+		if wanted[listId] {
+			items[listId] = append(items[listId], r.store.items[itemId])
+		}
+	}
+	return items, nil
+}
+
+func (r *TodoItemMemory) Search(ctx context.Context, userId int, query string) ([]models.TodoItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var found []models.TodoItem
+	for _, itemId := range r.sortedItemIds() {
+		item := r.store.items[itemId]
+		if r.store.hasItem(userId, itemId) && (containsFold(item.Title, query) || containsFold(item.Description, query)) {
+			found = append(found, item)
+		}
+	}
+	return found, nil
+}
+
+func (r *TodoItemMemory) GetById(ctx context.Context, userId, itemId int) (models.TodoItem, error) {
+	if err := ctx.Err(); err != nil {
+		return models.TodoItem{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if !r.store.hasItem(userId, itemId) {
+		return models.TodoItem{}, sql.ErrNoRows
+	}
+	return r.store.items[itemId], nil
+}
+
+func (r *TodoItemMemory) GetListId(ctx context.Context, userId, itemId int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if !r.store.hasItem(userId, itemId) {
+		return 0, sql.ErrNoRows
+	}
+	return r.store.listsItems[itemId], nil
+}
+
+func (r *TodoItemMemory) Delete(ctx context.Context, userId, itemId int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if r.store.hasItem(userId, itemId) {
+		r.store.deleteItem(itemId)
+	}
+	return nil
+}
+
+func (r *TodoItemMemory) Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) error {
+	changes := make(map[string]interface{})
+	if input.Title != nil {
+		changes["title"] = *input.Title
+	}
+	if input.Description != nil {
+		changes["description"] = *input.Description
+	}
+	if input.Done != nil {
+		changes["done"] = *input.Done
+	}
+	if input.DueDate != nil {
+		changes["due_date"] = input.DueDate
+	}
+	return r.Patch(ctx, userId, itemId, changes)
+}
+
+func (r *TodoItemMemory) Patch(ctx context.Context, userId, itemId int, changes map[string]interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	item := r.store.items[itemId]
+	if err := applyChanges(&item, changes, todoItemColumns); err != nil {
+		return err
+	}
+	if r.store.hasItem(userId, itemId) {
+		r.store.items[itemId] = item
+	}
+	return nil
+}
+
+// sortedItemIds нужен для стабильного порядка выдачи: в Postgres он задается ORDER BY ti.id
+func (r *TodoItemMemory) sortedItemIds() []int {
+	ids := make([]int, 0, len(r.store.items))
+	for id := range r.store.items {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/ponomare0v/todo-go-app/pkg/models"
 )
 
@@ -16,16 +18,16 @@ func NewTodoItemPostgres(db *sqlx.DB) *TodoItemPostgres {
 	return &TodoItemPostgres{db: db}
 }
 
-func (r *TodoItemPostgres) Create(listId int, item models.TodoItem) (int, error) {
-	tx, err := r.db.Begin()
+func (r *TodoItemPostgres) Create(ctx context.Context, listId int, item models.TodoItem) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
 
 	var itemId int
-	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description) values ($1, $2) RETURNING id", todoItemsTable)
+	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description, due_date) values ($1, $2, $3) RETURNING id", todoItemsTable)
 
-	row := tx.QueryRow(createItemQuery, item.Title, item.Description)
+	row := tx.QueryRowContext(ctx, createItemQuery, item.Title, item.Description, item.DueDate)
 	err = row.Scan(&itemId)
 	if err != nil {
 		tx.Rollback()
@@ -33,7 +35,7 @@ func (r *TodoItemPostgres) Create(listId int, item models.TodoItem) (int, error)
 	}
 
 	createListItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) values ($1, $2)", listsItemsTable)
-	_, err = tx.Exec(createListItemsQuery, listId, itemId)
+	_, err = tx.ExecContext(ctx, createListItemsQuery, listId, itemId)
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -42,39 +44,50 @@ func (r *TodoItemPostgres) Create(listId int, item models.TodoItem) (int, error)
 	return itemId, tx.Commit()
 }
 
-func (r *TodoItemPostgres) GetAll(userId, listId int) ([]models.TodoItem, error) {
+func (r *TodoItemPostgres) GetAll(ctx context.Context, userId, listId int) ([]models.TodoItem, error) {
 	var items []models.TodoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
 							INNER JOIN %s ul on ul.list_id = li.list_id WHERE li.list_id = $1 AND ul.user_id = $2`,
 		todoItemsTable, listsItemsTable, usersListsTable)
-	if err := r.db.Select(&items, query, listId, userId); err != nil {
+	if err := r.db.SelectContext(ctx, &items, query, listId, userId); err != nil {
 		return nil, err
 	}
 
 	return items, nil
 }
 
-func (r *TodoItemPostgres) GetById(userId, itemId int) (models.TodoItem, error) {
+func (r *TodoItemPostgres) GetById(ctx context.Context, userId, itemId int) (models.TodoItem, error) {
 	var item models.TodoItem
-	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
 							INNER JOIN %s ul on ul.list_id = li.list_id WHERE ti.id = $1 AND ul.user_id = $2`,
 		todoItemsTable, listsItemsTable, usersListsTable)
-	if err := r.db.Get(&item, query, itemId, userId); err != nil {
+	if err := r.db.GetContext(ctx, &item, query, itemId, userId); err != nil {
 		return item, err
 	}
 
 	return item, nil
 }
 
-func (r *TodoItemPostgres) Delete(userId, itemId int) error {
+// GetListId возвращает id списка, в котором лежит доступная пользователю задача
+func (r *TodoItemPostgres) GetListId(ctx context.Context, userId, itemId int) (int, error) {
+	var listId int
+	query := fmt.Sprintf(`SELECT li.list_id FROM %s li INNER JOIN %s ul on ul.list_id = li.list_id
+							WHERE li.item_id = $1 AND ul.user_id = $2`,
+		listsItemsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &listId, query, itemId, userId)
+
+	return listId, err
+}
+
+func (r *TodoItemPostgres) Delete(ctx context.Context, userId, itemId int) error {
 	query := fmt.Sprintf(`DELETE FROM %s ti USING %s li, %s ul
 							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $1 AND ti.id = $2`,
 		todoItemsTable, listsItemsTable, usersListsTable)
-	_, err := r.db.Exec(query, userId, itemId)
+	_, err := r.db.ExecContext(ctx, query, userId, itemId)
 	return err
 }
 
-func (r *TodoItemPostgres) Update(userId, itemId int, input models.UpdateItemInput) error {
+func (r *TodoItemPostgres) Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) error {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
 	argId := 1
@@ -94,6 +107,11 @@ func (r *TodoItemPostgres) Update(userId, itemId int, input models.UpdateItemInp
 		args = append(args, *input.Done)
 		argId++
 	}
+	if input.DueDate != nil {
+		setValues = append(setValues, fmt.Sprintf("due_date=$%d", argId))
+		args = append(args, *input.DueDate)
+		argId++
+	}
 
 	setQuery := strings.Join(setValues, ", ")
 
@@ -103,7 +121,56 @@ func (r *TodoItemPostgres) Update(userId, itemId int, input models.UpdateItemInp
 
 	args = append(args, userId, itemId)
 
-	_, err := r.db.Exec(query, args...)
+	_, err := r.db.ExecContext(ctx, query, args...)
 	return err
 
 }
+
+func (r *TodoItemPostgres) Patch(ctx context.Context, userId, itemId int, changes map[string]interface{}) error {
+	setQuery, args, err := buildSetQuery(changes, todoItemColumns)
+	if err != nil {
+		return err
+	}
+	argId := len(args) + 1
+
+	query := fmt.Sprintf(`UPDATE %s ti SET %s FROM %s li, %s ul
+							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id = $%d AND ti.id = $%d`,
+		todoItemsTable, setQuery, listsItemsTable, usersListsTable, argId, argId+1)
+	args = append(args, userId, itemId)
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// GetAllByLists одним запросом возвращает задачи сразу нескольких списков, сгруппированные по id списка
+func (r *TodoItemPostgres) GetAllByLists(ctx context.Context, userId int, listIds []int) (map[int][]models.TodoItem, error) {
+	var rows []struct {
+		ListId int `db:"list_id"`
+		models.TodoItem
+	}
+	query := fmt.Sprintf(`SELECT li.list_id, ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+							INNER JOIN %s ul on ul.list_id = li.list_id WHERE li.list_id = ANY($1) AND ul.user_id = $2 ORDER BY ti.id`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(listIds), userId); err != nil {
+		return nil, err
+	}
+
+	items := make(map[int][]models.TodoItem, len(listIds))
+	for _, row := range rows {
+		items[row.ListId] = append(items[row.ListId], row.TodoItem)
+	}
+	return items, nil
+}
+
+func (r *TodoItemPostgres) Search(ctx context.Context, userId int, query string) ([]models.TodoItem, error) {
+	var items []models.TodoItem
+	searchQuery := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+							INNER JOIN %s ul on ul.list_id = li.list_id WHERE ul.user_id = $1 AND (ti.title ILIKE $2 OR ti.description ILIKE $2)
+							ORDER BY ti.id`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+	if err := r.db.SelectContext(ctx, &items, searchQuery, userId, likePattern(query)); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// TodoItemSQLite повторяет запросы TodoItemPostgres: вместо UPDATE ... FROM и DELETE ... USING
+// доступ к задаче проверяется подзапросом, а вместо ANY($1) используется IN, развернутый через sqlx.In
+type TodoItemSQLite struct {
+	db *sqlx.DB
+}
+
+func NewTodoItemSQLite(db *sqlx.DB) *TodoItemSQLite {
+	return &TodoItemSQLite{db: db}
+}
+
+// userItemsQuery - id задач из списков пользователя $N
+var userItemsQuery = fmt.Sprintf("SELECT li.item_id FROM %s li INNER JOIN %s ul on ul.list_id = li.list_id WHERE ul.user_id = $%%d",
+	listsItemsTable, usersListsTable)
+
+func (r *TodoItemSQLite) Create(ctx context.Context, listId int, item models.TodoItem) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var itemId int
+	createItemQuery := fmt.Sprintf("INSERT INTO %s (title, description, due_date) values ($1, $2, $3) RETURNING id", todoItemsTable)
+	row := tx.QueryRowContext(ctx, createItemQuery, item.Title, item.Description, item.DueDate)
+	if err := row.Scan(&itemId); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	createListItemsQuery := fmt.Sprintf("INSERT INTO %s (list_id, item_id) values ($1, $2)", listsItemsTable)
+	if _, err := tx.ExecContext(ctx, createListItemsQuery, listId, itemId); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return itemId, tx.Commit()
+}
+
+func (r *TodoItemSQLite) GetAll(ctx context.Context, userId, listId int) ([]models.TodoItem, error) {
+	var items []models.TodoItem
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+							INNER JOIN %s ul on ul.list_id = li.list_id WHERE li.list_id = $1 AND ul.user_id = $2`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+	if err := r.db.SelectContext(ctx, &items, query, listId, userId); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *TodoItemSQLite) GetById(ctx context.Context, userId, itemId int) (models.TodoItem, error) {
+	var item models.TodoItem
+	query := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+							INNER JOIN %s ul on ul.list_id = li.list_id WHERE ti.id = $1 AND ul.user_id = $2`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &item, query, itemId, userId)
+
+	return item, err
+}
+
+func (r *TodoItemSQLite) GetListId(ctx context.Context, userId, itemId int) (int, error) {
+	var listId int
+	query := fmt.Sprintf(`SELECT li.list_id FROM %s li INNER JOIN %s ul on ul.list_id = li.list_id
+							WHERE li.item_id = $1 AND ul.user_id = $2`,
+		listsItemsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &listId, query, itemId, userId)
+
+	return listId, err
+}
+
+func (r *TodoItemSQLite) Delete(ctx context.Context, userId, itemId int) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $2 AND id IN (%s)", todoItemsTable, fmt.Sprintf(userItemsQuery, 1))
+	_, err := r.db.ExecContext(ctx, query, userId, itemId)
+	return err
+}
+
+func (r *TodoItemSQLite) Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) error {
+	changes := make(map[string]interface{})
+	if input.Title != nil {
+		changes["title"] = *input.Title
+	}
+	if input.Description != nil {
+		changes["description"] = *input.Description
+	}
+	if input.Done != nil {
+		changes["done"] = *input.Done
+	}
+	if input.DueDate != nil {
+		changes["due_date"] = input.DueDate
+	}
+	return r.Patch(ctx, userId, itemId, changes)
+}
+
+func (r *TodoItemSQLite) Patch(ctx context.Context, userId, itemId int, changes map[string]interface{}) error {
+	setQuery, args, err := buildSetQuery(changes, todoItemColumns)
+	if err != nil {
+		return err
+	}
+	argId := len(args) + 1
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d AND id IN (%s)",
+		todoItemsTable, setQuery, argId, fmt.Sprintf(userItemsQuery, argId+1))
+	args = append(args, itemId, userId)
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *TodoItemSQLite) GetAllByLists(ctx context.Context, userId int, listIds []int) (map[int][]models.TodoItem, error) {
+	items := make(map[int][]models.TodoItem, len(listIds))
+	if len(listIds) == 0 {
+		return items, nil // sqlx.In не умеет разворачивать пустой слайс
+	}
+
+	var rows []struct {
+		ListId int `db:"list_id"`
+		models.TodoItem
+	}
+	query, args, err := sqlx.In(fmt.Sprintf(`SELECT li.list_id, ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+							INNER JOIN %s ul on ul.list_id = li.list_id WHERE li.list_id IN (?) AND ul.user_id = ? ORDER BY ti.id`,
+		todoItemsTable, listsItemsTable, usersListsTable), listIds, userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		items[row.ListId] = append(items[row.ListId], row.TodoItem)
+	}
+	return items, nil
+}
+
+func (r *TodoItemSQLite) Search(ctx context.Context, userId int, query string) ([]models.TodoItem, error) {
+	var items []models.TodoItem
+	searchQuery := fmt.Sprintf(`SELECT ti.id, ti.title, ti.description, ti.done, ti.due_date FROM %s ti INNER JOIN %s li on li.item_id = ti.id 
+							INNER JOIN %s ul on ul.list_id = li.list_id WHERE ul.user_id = $1
+							AND (ti.title LIKE $2 ESCAPE '\' OR ti.description LIKE $2 ESCAPE '\') ORDER BY ti.id`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+	if err := r.db.SelectContext(ctx, &items, searchQuery, userId, likePattern(query)); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
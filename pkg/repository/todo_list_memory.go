@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type TodoListMemory struct {
+	store *memoryStore
+}
+
+func (r *TodoListMemory) Create(ctx context.Context, userId int, list models.TodoList) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[userId]; !ok {
+		return 0, fmt.Errorf("user %d does not exist", userId) // в Postgres сработал бы внешний ключ users_lists
+	}
+
+	r.store.lastListId++
+	list.Id = r.store.lastListId
+	r.store.lists[list.Id] = list
+
+	if r.store.usersLists[userId] == nil {
+		r.store.usersLists[userId] = make(map[int]bool)
+	}
+	r.store.usersLists[userId][list.Id] = true
+
+	return list.Id, nil
+}
+
+func (r *TodoListMemory) GetAll(ctx context.Context, userId int) ([]models.TodoList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var lists []models.TodoList
+	for listId := range r.store.usersLists[userId] {
+		lists = append(lists, r.store.lists[listId])
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Id < lists[j].Id })
+
+	return lists, nil
+}
+
+func (r *TodoListMemory) GetById(ctx context.Context, userId, listId int) (models.TodoList, error) {
+	if err := ctx.Err(); err != nil {
+		return models.TodoList{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if !r.store.hasList(userId, listId) {
+		return models.TodoList{}, sql.ErrNoRows
+	}
+	return r.store.lists[listId], nil
+}
+
+func (r *TodoListMemory) Search(ctx context.Context, userId int, query string) ([]models.TodoList, error) {
+	lists, err := r.GetAll(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []models.TodoList
+	for _, list := range lists {
+		if containsFold(list.Title, query) || containsFold(list.Description, query) {
+			found = append(found, list)
+		}
+	}
+	return found, nil
+}
+
+func (r *TodoListMemory) Delete(ctx context.Context, userId, listId int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// как и DELETE ... USING, недоступный список молча пропускается
+	if r.store.hasList(userId, listId) {
+		r.store.deleteList(listId)
+	}
+	return nil
+}
+
+func (r *TodoListMemory) Update(ctx context.Context, userId, listId int, input models.UpdateListInput) error {
+	changes := make(map[string]interface{})
+	if input.Title != nil {
+		changes["title"] = *input.Title
+	}
+	if input.Description != nil {
+		changes["description"] = *input.Description
+	}
+	return r.Patch(ctx, userId, listId, changes)
+}
+
+func (r *TodoListMemory) Patch(ctx context.Context, userId, listId int, changes map[string]interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	list := r.store.lists[listId]
+	if err := applyChanges(&list, changes, todoListColumns); err != nil {
+		return err
+	}
+	if r.store.hasList(userId, listId) {
+		r.store.lists[listId] = list
+	}
+	return nil
+}
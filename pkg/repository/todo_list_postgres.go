@@ -1,12 +1,13 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/logging"
 	"github.com/ponomare0v/todo-go-app/pkg/models"
-	"github.com/sirupsen/logrus"
 )
 
 type TodoListPostgres struct {
@@ -22,8 +23,8 @@ func NewTodoListPostgres(db *sqlx.DB) *TodoListPostgres {
 //
 //
 
-func (r *TodoListPostgres) Create(userId int, list models.TodoList) (int, error) {
-	tx, err := r.db.Begin() //Для создании транзакции в объектах БД есть метод Begin
+func (r *TodoListPostgres) Create(ctx context.Context, userId int, list models.TodoList) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil) //Для создании транзакции в объектах БД есть метод Begin, BeginTxx еще и отменяет ее вместе с контекстом
 	if err != nil {
 		return 0, err
 	}
@@ -31,7 +32,7 @@ func (r *TodoListPostgres) Create(userId int, list models.TodoList) (int, error)
 	// Запрос для создании записи в таблице todo_lists, возвращая id нового списка.
 	var id int
 	createListQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id", todoListsTable)
-	row := tx.QueryRow(createListQuery, list.Title, list.Description)
+	row := tx.QueryRowContext(ctx, createListQuery, list.Title, list.Description)
 	if err := row.Scan(&id); err != nil {
 		tx.Rollback()
 		return 0, err
@@ -39,7 +40,7 @@ func (r *TodoListPostgres) Create(userId int, list models.TodoList) (int, error)
 
 	// Вставка в таблицу  users_lists, в которой свяжем id пользователя и id нового списка.
 	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2)", usersListsTable)
-	_, err = tx.Exec(createUsersListQuery, userId, id) //Для простого выполнения запроса, без чтения возвращаемой инфоормации - метод Exec.
+	_, err = tx.ExecContext(ctx, createUsersListQuery, userId, id) //Для простого выполнения запроса, без чтения возвращаемой инфоормации - метод Exec.
 	if err != nil {
 		tx.Rollback() //В случае ошибок - вызываем метод Rollback у транзакции, который откатывает все изменения базы данных до начала выполнения транзакции.
 		return 0, err
@@ -54,11 +55,11 @@ func (r *TodoListPostgres) Create(userId int, list models.TodoList) (int, error)
 //
 //
 
-func (r *TodoListPostgres) GetAll(userId int) ([]models.TodoList, error) {
+func (r *TodoListPostgres) GetAll(ctx context.Context, userId int) ([]models.TodoList, error) {
 	var lists []models.TodoList
 
 	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id WHERE ul.user_id = $1", todoListsTable, usersListsTable)
-	err := r.db.Select(&lists, query, userId) //db.Select() - работает аналогично с методом db.Get() только применяется при выборке больше одного элемента
+	err := r.db.SelectContext(ctx, &lists, query, userId) //db.Select() - работает аналогично с методом db.Get() только применяется при выборке больше одного элемента
 	//  и для записи в слайс. Нужно добавить теги db в наши модели, чтобы иметь возможность сделать выборки из базы
 
 	return lists, err
@@ -70,11 +71,11 @@ func (r *TodoListPostgres) GetAll(userId int) ([]models.TodoList, error) {
 //
 //
 
-func (r *TodoListPostgres) GetById(userId, listId int) (models.TodoList, error) {
+func (r *TodoListPostgres) GetById(ctx context.Context, userId, listId int) (models.TodoList, error) {
 	var list models.TodoList
 
 	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2", todoListsTable, usersListsTable) //добавили доп условие для проверки id листа
-	err := r.db.Get(&list, query, userId, listId)                                                                                                                                               // метод get
+	err := r.db.GetContext(ctx, &list, query, userId, listId)                                                                                                                                   // метод get
 
 	return list, err
 }
@@ -85,16 +86,32 @@ func (r *TodoListPostgres) GetById(userId, listId int) (models.TodoList, error)
 //
 //
 
-func (r *TodoListPostgres) Delete(userId, listId int) error {
+// Delete удаляет список вместе с задачами: ON DELETE CASCADE убирает только связку в lists_items, сами задачи остались бы в todo_items
+func (r *TodoListPostgres) Delete(ctx context.Context, userId, listId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
 
-	query := fmt.Sprintf("DELETE FROM %s tl USING %s ul WHERE tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2",
+	deleteItemsQuery := fmt.Sprintf(`DELETE FROM %s ti USING %s li, %s ul
+							WHERE ti.id = li.item_id AND li.list_id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+	if _, err := tx.ExecContext(ctx, deleteItemsQuery, userId, listId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	deleteListQuery := fmt.Sprintf("DELETE FROM %s tl USING %s ul WHERE tl.id = ul.list_id AND ul.user_id=$1 AND ul.list_id=$2",
 		todoListsTable, usersListsTable)
-	_, err := r.db.Exec(query, userId, listId)
+	if _, err := tx.ExecContext(ctx, deleteListQuery, userId, listId); err != nil {
+		tx.Rollback()
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
-func (r *TodoListPostgres) Update(userId, listId int, input models.UpdateListInput) error {
+func (r *TodoListPostgres) Update(ctx context.Context, userId, listId int, input models.UpdateListInput) error {
 	//Инициализируем три переменных - 1 слайс строк 2 слайс интерфейсов 3 id аргумента
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
@@ -131,9 +148,35 @@ func (r *TodoListPostgres) Update(userId, listId int, input models.UpdateListInp
 	// для наглядности.
 	args = append(args, listId, userId)
 
-	logrus.Debugf("updateQuery: %s", query)
-	logrus.Debugf("args: %s", args)
+	logging.FromContext(ctx).Debugf("updateQuery: %s", query)
+	logging.FromContext(ctx).Debugf("args: %s", args)
 
-	_, err := r.db.Exec(query, args...)
+	_, err := r.db.ExecContext(ctx, query, args...)
 	return err
 }
+
+func (r *TodoListPostgres) Patch(ctx context.Context, userId, listId int, changes map[string]interface{}) error {
+	setQuery, args, err := buildSetQuery(changes, todoListColumns)
+	if err != nil {
+		return err
+	}
+	argId := len(args) + 1
+
+	query := fmt.Sprintf("UPDATE %s tl SET %s FROM %s ul WHERE tl.id = ul.list_id AND ul.list_id=$%d AND ul.user_id=$%d",
+		todoListsTable, setQuery, usersListsTable, argId, argId+1)
+	args = append(args, listId, userId)
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (r *TodoListPostgres) Search(ctx context.Context, userId int, query string) ([]models.TodoList, error) {
+	var lists []models.TodoList
+
+	searchQuery := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id
+								WHERE ul.user_id = $1 AND (tl.title ILIKE $2 OR tl.description ILIKE $2) ORDER BY tl.id`,
+		todoListsTable, usersListsTable)
+	err := r.db.SelectContext(ctx, &lists, searchQuery, userId, likePattern(query))
+
+	return lists, err
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// TodoListSQLite повторяет запросы TodoListPostgres. SQLite (драйвер modernc) понимает плейсхолдеры $N,
+// но не поддерживает DELETE ... USING, поэтому права на список проверяются подзапросом к users_lists.
+type TodoListSQLite struct {
+	db *sqlx.DB
+}
+
+func NewTodoListSQLite(db *sqlx.DB) *TodoListSQLite {
+	return &TodoListSQLite{db: db}
+}
+
+func (r *TodoListSQLite) Create(ctx context.Context, userId int, list models.TodoList) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	createListQuery := fmt.Sprintf("INSERT INTO %s (title, description) VALUES ($1, $2) RETURNING id", todoListsTable)
+	row := tx.QueryRowContext(ctx, createListQuery, list.Title, list.Description)
+	if err := row.Scan(&id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	createUsersListQuery := fmt.Sprintf("INSERT INTO %s (user_id, list_id) VALUES ($1, $2)", usersListsTable)
+	if _, err := tx.ExecContext(ctx, createUsersListQuery, userId, id); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *TodoListSQLite) GetAll(ctx context.Context, userId int) ([]models.TodoList, error) {
+	var lists []models.TodoList
+
+	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id WHERE ul.user_id = $1", todoListsTable, usersListsTable)
+	err := r.db.SelectContext(ctx, &lists, query, userId)
+
+	return lists, err
+}
+
+func (r *TodoListSQLite) GetById(ctx context.Context, userId, listId int) (models.TodoList, error) {
+	var list models.TodoList
+
+	query := fmt.Sprintf("SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id WHERE ul.user_id = $1 AND ul.list_id = $2", todoListsTable, usersListsTable)
+	err := r.db.GetContext(ctx, &list, query, userId, listId)
+
+	return list, err
+}
+
+func (r *TodoListSQLite) Delete(ctx context.Context, userId, listId int) error {
+	// задачи списка удаляем явно, как и в Postgres: каскад по lists_items удалил бы только связку
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	deleteItemsQuery := fmt.Sprintf(`DELETE FROM %s WHERE id IN (SELECT li.item_id FROM %s li INNER JOIN %s ul on ul.list_id = li.list_id
+							WHERE ul.user_id = $1 AND li.list_id = $2)`,
+		todoItemsTable, listsItemsTable, usersListsTable)
+	if _, err := tx.ExecContext(ctx, deleteItemsQuery, userId, listId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	deleteListQuery := fmt.Sprintf("DELETE FROM %s WHERE id = $2 AND id IN (SELECT list_id FROM %s WHERE user_id = $1)",
+		todoListsTable, usersListsTable)
+	if _, err := tx.ExecContext(ctx, deleteListQuery, userId, listId); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TodoListSQLite) Update(ctx context.Context, userId, listId int, input models.UpdateListInput) error {
+	changes := make(map[string]interface{})
+	if input.Title != nil {
+		changes["title"] = *input.Title
+	}
+	if input.Description != nil {
+		changes["description"] = *input.Description
+	}
+	return r.Patch(ctx, userId, listId, changes)
+}
+
+func (r *TodoListSQLite) Patch(ctx context.Context, userId, listId int, changes map[string]interface{}) error {
+	setQuery, args, err := buildSetQuery(changes, todoListColumns)
+	if err != nil {
+		return err
+	}
+	argId := len(args) + 1
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d AND id IN (SELECT list_id FROM %s WHERE user_id = $%d)",
+		todoListsTable, setQuery, argId, usersListsTable, argId+1)
+	args = append(args, listId, userId)
+
+	_, err = r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Search - в SQLite нет ILIKE, а LIKE без учета регистра сравнивает только ASCII
+func (r *TodoListSQLite) Search(ctx context.Context, userId int, query string) ([]models.TodoList, error) {
+	var lists []models.TodoList
+
+	searchQuery := fmt.Sprintf(`SELECT tl.id, tl.title, tl.description FROM %s tl INNER JOIN %s ul on tl.id = ul.list_id
+								WHERE ul.user_id = $1 AND (tl.title LIKE $2 ESCAPE '\' OR tl.description LIKE $2 ESCAPE '\') ORDER BY tl.id`,
+		todoListsTable, usersListsTable)
+	err := r.db.SelectContext(ctx, &lists, searchQuery, userId, likePattern(query))
+
+	return lists, err
+}
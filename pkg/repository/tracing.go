@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberLiteral = regexp.MustCompile(`(^|[^$\w])\d+(?:\.\d+)?\b`) // $1 - плейсхолдер, его не трогаем
+	sqlWhitespace    = regexp.MustCompile(`\s+`)
+)
+
+// openDB открывает базу через драйвер, обернутый otelsql: каждый запрос становится спаном
+// внутри спана сервиса, который его вызвал (контекст приходит из *Context-методов sqlx)
+func openDB(driverName, dsn string) (*sqlx.DB, error) {
+	db, err := otelsql.Open(driverName, dsn,
+		otelsql.WithAttributes(dbSystem(driverName)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableQuery:         true, // текст пишем сами, уже без литералов
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			// запросы вне трейса (миграции, сбор метрик) отдельными корневыми спанами только засоряли бы хранилище трейсов
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+		otelsql.WithAttributesGetter(func(_ context.Context, _ otelsql.Method, query string, _ []driver.NamedValue) []attribute.KeyValue {
+			if query == "" {
+				return nil
+			}
+			return []attribute.KeyValue{semconv.DBQueryText(sanitizeQuery(query))}
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return sqlx.NewDb(db, driverName), nil
+}
+
+// sanitizeQuery заменяет строковые и числовые литералы на ? и схлопывает пробелы.
+// Значения запросов передаются плейсхолдерами и в спан не попадают, но литерал в тексте запроса
+// мог бы унести в трейс то, чего там быть не должно.
+func sanitizeQuery(query string) string {
+	query = sqlStringLiteral.ReplaceAllString(query, "?")
+	query = sqlNumberLiteral.ReplaceAllString(query, "${1}?")
+	return strings.TrimSpace(sqlWhitespace.ReplaceAllString(query, " "))
+}
+
+func dbSystem(driverName string) attribute.KeyValue {
+	if driverName == "postgres" {
+		return semconv.DBSystemPostgreSQL
+	}
+	return semconv.DBSystemSqlite
+}
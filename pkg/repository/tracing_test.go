@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestSanitizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id FROM users WHERE username = $1", "SELECT id FROM users WHERE username = $1"},
+		{"SELECT id FROM users WHERE username = 'alice' AND password_hash = 'it''s secret'", "SELECT id FROM users WHERE username = ? AND password_hash = ?"},
+		{"SELECT * FROM todo_items LIMIT 10 OFFSET 2.5", "SELECT * FROM todo_items LIMIT ? OFFSET ?"},
+		{"UPDATE t SET a=$1, b=42 WHERE id=$12", "UPDATE t SET a=$1, b=? WHERE id=$12"},
+		{"SELECT col1, t2.x FROM t2", "SELECT col1, t2.x FROM t2"},
+		{"  SELECT\n\t1\n  FROM   t  ", "SELECT ? FROM t"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeQuery(tt.query); got != tt.want {
+			t.Errorf("sanitizeQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestSQLSpans - запросы внутри трейса становятся дочерними спанами с текстом без значений, запросы вне трейса - нет
+func TestSQLSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prev := otel.GetTracerProvider()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	db, err := NewSQLiteDB(SQLiteConfig{Path: filepath.Join(t.TempDir(), "todo.db")})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := NewSQLiteMigrator(db)
+	if err != nil {
+		t.Fatalf("sqlite migrator: %v", err)
+	}
+	defer migrator.Close()
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("migrate sqlite: %v", err)
+	}
+	repo := NewSQLiteRepository(db)
+
+	userId, err := repo.Authorization.CreateUser(context.Background(), models.User{Name: "Alice", Username: "alice", Password: "secret-hash"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("queries outside a trace produced %d spans", len(spans))
+	}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "TodoListService.Create")
+	if _, err := repo.TodoList.Create(ctx, userId, models.TodoList{Title: "secret title"}); err != nil {
+		t.Fatalf("TodoList.Create: %v", err)
+	}
+	parent.End()
+
+	var queries []string
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "TodoListService.Create" {
+			continue
+		}
+		if span.Parent.TraceID() != parent.SpanContext().TraceID() {
+			t.Errorf("span %s is not in the request trace", span.Name)
+		}
+		for _, kv := range span.Attributes {
+			if strings.Contains(kv.Value.Emit(), "secret") {
+				t.Errorf("span %s leaks a query value: %s=%s", span.Name, kv.Key, kv.Value.Emit())
+			}
+			if kv.Key == semconv.DBQueryTextKey {
+				queries = append(queries, kv.Value.AsString())
+			}
+		}
+	}
+	if len(queries) == 0 || !strings.HasPrefix(queries[0], "INSERT INTO todo_lists (title, description) VALUES ($1, $2)") {
+		t.Errorf("db.query.text: got %q", queries)
+	}
+}
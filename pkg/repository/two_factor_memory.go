@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+type TwoFactorMemory struct {
+	store *memoryStore
+}
+
+func (r *TwoFactorMemory) GetTOTP(ctx context.Context, userId int) (models.TOTP, error) {
+	if err := ctx.Err(); err != nil {
+		return models.TOTP{}, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	totp, ok := r.store.totp[userId]
+	if !ok {
+		return models.TOTP{}, sql.ErrNoRows
+	}
+	return totp, nil
+}
+
+func (r *TwoFactorMemory) SetTOTP(ctx context.Context, totp models.TOTP) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[totp.UserId]; !ok {
+		return fmt.Errorf("user %d does not exist", totp.UserId) // в Postgres сработал бы внешний ключ user_totp
+	}
+	totp.LastStep = 0
+	totp.ConfirmedAt = nil
+	r.store.totp[totp.UserId] = totp
+	return nil
+}
+
+func (r *TwoFactorMemory) ConfirmTOTP(ctx context.Context, userId int, step int64, confirmedAt time.Time, recoveryHashes []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	totp, ok := r.store.totp[userId]
+	if !ok || totp.Enabled() {
+		return sql.ErrNoRows
+	}
+	totp.LastStep = step
+	totp.ConfirmedAt = &confirmedAt
+	r.store.totp[userId] = totp
+
+	codes := make(map[string]bool, len(recoveryHashes))
+	for _, hash := range recoveryHashes {
+		codes[hash] = true
+	}
+	r.store.recoveryCodes[userId] = codes
+	return nil
+}
+
+func (r *TwoFactorMemory) UseTOTPStep(ctx context.Context, userId int, step int64) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	totp, ok := r.store.totp[userId]
+	if !ok || totp.LastStep >= step {
+		return false, nil
+	}
+	totp.LastStep = step
+	r.store.totp[userId] = totp
+	return true, nil
+}
+
+func (r *TwoFactorMemory) UseRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if !r.store.recoveryCodes[userId][codeHash] {
+		return sql.ErrNoRows
+	}
+	delete(r.store.recoveryCodes[userId], codeHash)
+	return nil
+}
+
+func (r *TwoFactorMemory) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return len(r.store.recoveryCodes[userId]), nil
+}
+
+func (r *TwoFactorMemory) DeleteTOTP(ctx context.Context, userId int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.totp, userId)
+	delete(r.store.recoveryCodes, userId)
+	return nil
+}
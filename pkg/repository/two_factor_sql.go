@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+const (
+	userTOTPTable      = "user_totp"
+	recoveryCodesTable = "recovery_codes"
+)
+
+// TwoFactorSQL - запросы без диалектных особенностей, общие для Postgres и SQLite (ON CONFLICT есть в обоих)
+type TwoFactorSQL struct {
+	db *sqlx.DB
+}
+
+func NewTwoFactorSQL(db *sqlx.DB) *TwoFactorSQL {
+	return &TwoFactorSQL{db: db}
+}
+
+func (r *TwoFactorSQL) GetTOTP(ctx context.Context, userId int) (models.TOTP, error) {
+	var totp models.TOTP
+	query := fmt.Sprintf("SELECT user_id, secret, last_step, confirmed_at, created_at FROM %s WHERE user_id = $1", userTOTPTable)
+	err := r.db.GetContext(ctx, &totp, query, userId)
+
+	return totp, err
+}
+
+func (r *TwoFactorSQL) SetTOTP(ctx context.Context, totp models.TOTP) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, secret, last_step, confirmed_at, created_at) VALUES ($1, $2, 0, NULL, $3)
+							ON CONFLICT (user_id) DO UPDATE SET secret = excluded.secret, last_step = 0, confirmed_at = NULL,
+							created_at = excluded.created_at`, userTOTPTable)
+	_, err := r.db.ExecContext(ctx, query, totp.UserId, totp.Secret, totp.CreatedAt)
+
+	return err
+}
+
+func (r *TwoFactorSQL) ConfirmTOTP(ctx context.Context, userId int, step int64, confirmedAt time.Time, recoveryHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	confirmQuery := fmt.Sprintf("UPDATE %s SET confirmed_at = $1, last_step = $2 WHERE user_id = $3 AND confirmed_at IS NULL", userTOTPTable)
+	res, err := tx.ExecContext(ctx, confirmQuery, confirmedAt, step, userId)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if confirmed, err := res.RowsAffected(); err != nil || confirmed == 0 {
+		tx.Rollback()
+		if err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userId, recoveryHashes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *TwoFactorSQL) UseTOTPStep(ctx context.Context, userId int, step int64) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET last_step = $1 WHERE user_id = $2 AND last_step < $1", userTOTPTable)
+	res, err := r.db.ExecContext(ctx, query, step, userId)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := res.RowsAffected()
+	return updated > 0, err
+}
+
+func (r *TwoFactorSQL) UseRecoveryCode(ctx context.Context, userId int, codeHash string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND code_hash = $2", recoveryCodesTable)
+	res, err := r.db.ExecContext(ctx, query, userId, codeHash)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *TwoFactorSQL) CountRecoveryCodes(ctx context.Context, userId int) (int, error) {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE user_id = $1", recoveryCodesTable)
+	err := r.db.GetContext(ctx, &count, query, userId)
+
+	return count, err
+}
+
+func (r *TwoFactorSQL) DeleteTOTP(ctx context.Context, userId int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{recoveryCodesTable, userTOTPTable} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", table)
+		if _, err := tx.ExecContext(ctx, query, userId); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userId int, hashes []string) error {
+	deleteQuery := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", recoveryCodesTable)
+	if _, err := tx.ExecContext(ctx, deleteQuery, userId); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s (user_id, code_hash) VALUES ($1, $2)", recoveryCodesTable)
+	for _, hash := range hashes {
+		if _, err := tx.ExecContext(ctx, insertQuery, userId, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+type GRPCServer struct {
+	grpcServer *grpc.Server
+}
+
+// Run слушает порт и обслуживает на нем уже настроенный grpc.Server
+func (s *GRPCServer) Run(port string, grpcServer *grpc.Server) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+
+	s.grpcServer = grpcServer
+	return s.grpcServer.Serve(listener)
+}
+
+// Shutdown ждет завершения текущих вызовов, как и http.Server.Shutdown, но открытые стримы
+// могут жить бесконечно, поэтому по истечении ctx соединения закрываются принудительно
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+// AccessTokenPrefix отличает персональный токен от JWT в заголовке Authorization
+// и помогает сканерам секретов находить токены, случайно попавшие в репозиторий
+const AccessTokenPrefix = "todo_pat_"
+
+const (
+	accessTokenBytes = 20
+	// lastUsedPrecision - last_used_at обновляется не чаще раза в минуту, а не на каждый запрос скрипта
+	lastUsedPrecision = time.Minute
+)
+
+var (
+	ErrInvalidAccessToken  = errors.New("invalid or expired access token")
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrInsufficientScope   = errors.New("access token does not have the required scope")
+	// ErrSessionRequired - действие доступно только после входа по паролю: токеном нельзя выпустить новые учетные данные
+	ErrSessionRequired = errors.New("personal access tokens are not accepted here, sign in with a password")
+)
+
+type accessTokenKey struct{}
+
+// WithAccessToken отмечает, что запрос авторизован персональным токеном, а не JWT.
+// Запросы без отметки выполняются с полными правами пользователя.
+func WithAccessToken(ctx context.Context, token models.AccessToken) context.Context {
+	return context.WithValue(ctx, accessTokenKey{}, token)
+}
+
+// CheckScope возвращает ErrInsufficientScope, если запрос авторизован токеном без права scope
+func CheckScope(ctx context.Context, scope string) error {
+	token, ok := ctx.Value(accessTokenKey{}).(models.AccessToken)
+	if ok && !token.HasScope(scope) {
+		return fmt.Errorf("%w %s", ErrInsufficientScope, scope)
+	}
+	return nil
+}
+
+// CheckSession возвращает ErrSessionRequired, если запрос авторизован персональным токеном
+func CheckSession(ctx context.Context) error {
+	if _, ok := ctx.Value(accessTokenKey{}).(models.AccessToken); ok {
+		return ErrSessionRequired
+	}
+	return nil
+}
+
+type AccessTokenService struct {
+	repo repository.AccessToken
+}
+
+func NewAccessTokenService(repo repository.AccessToken) *AccessTokenService {
+	return &AccessTokenService{repo: repo}
+}
+
+// CreateAccessToken выпускает токен; сам токен возвращается только здесь
+func (s *AccessTokenService) CreateAccessToken(ctx context.Context, userId int, token models.AccessToken) (models.AccessToken, error) {
+	token.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	token.Normalize()
+	if err := token.Validate(); err != nil {
+		return models.AccessToken{}, err
+	}
+
+	buf := make([]byte, accessTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return models.AccessToken{}, err
+	}
+	secret := AccessTokenPrefix + strings.ToLower(appPasswordEncoding.EncodeToString(buf))
+
+	id, err := s.repo.CreateAccessToken(ctx, userId, token, hashSecret(secret))
+	if err != nil {
+		return models.AccessToken{}, err
+	}
+
+	token.Id = id
+	token.Token = secret
+	return token, nil
+}
+
+func (s *AccessTokenService) GetAccessTokens(ctx context.Context, userId int) ([]models.AccessToken, error) {
+	return s.repo.GetAccessTokens(ctx, userId)
+}
+
+func (s *AccessTokenService) DeleteAccessToken(ctx context.Context, userId, id int) error {
+	err := s.repo.DeleteAccessToken(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccessTokenNotFound
+	}
+	return err
+}
+
+// Authenticate проверяет токен из заголовка Authorization и отмечает время его использования
+func (s *AccessTokenService) Authenticate(ctx context.Context, secret string) (token models.AccessToken, err error) {
+	defer func() { metrics.ObserveAuth("access_token", err) }()
+
+	token, err = s.repo.GetAccessTokenByHash(ctx, hashSecret(secret))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AccessToken{}, ErrInvalidAccessToken
+	}
+	if err != nil {
+		return models.AccessToken{}, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if token.ExpiresAt != nil && !now.Before(*token.ExpiresAt) {
+		return models.AccessToken{}, ErrInvalidAccessToken
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedPrecision {
+		if err := s.repo.TouchAccessToken(ctx, token.Id, now); err != nil {
+			return models.AccessToken{}, err
+		}
+		token.LastUsedAt = &now
+	}
+	return token, nil
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+// ErrSessionRevoked - JWT выпущен до смены пароля, пользователь удален или отключен
+var ErrSessionRevoked = errors.New("session has been revoked, sign in again")
+
+// reauthTTL - сколько после входа через OIDC можно менять пароль и удалять аккаунт без текущего пароля
+const reauthTTL = 10 * time.Minute
+
+type sessionKey struct{}
+
+// WithSession отмечает, что запрос авторизован JWT: по способу и времени входа AccountService
+// узнает недавний вход через OIDC
+func WithSession(ctx context.Context, session models.Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// recentOIDCSignIn - запрос авторизован JWT, выпущенным входом через OIDC не раньше reauthTTL назад
+func recentOIDCSignIn(ctx context.Context) bool {
+	session, ok := ctx.Value(sessionKey{}).(models.Session)
+	return ok && session.AuthMethod == models.AuthMethodOIDC && time.Since(session.IssuedAt) < reauthTTL
+}
+
+// AccountService - самообслуживание пользователя: профиль, смена пароля, удаление и выгрузка данных
+type AccountService struct {
+	repo         repository.Account
+	users        repository.Admin
+	identities   repository.Identity
+	accessTokens repository.AccessToken
+	appPasswords repository.AppPassword
+	importJobs   repository.ImportJob
+	twoFactor    repository.TwoFactor
+	lockout      *ratelimit.Lockout
+}
+
+func NewAccountService(repo repository.Account, users repository.Admin, identities repository.Identity, accessTokens repository.AccessToken,
+	appPasswords repository.AppPassword, importJobs repository.ImportJob, twoFactor repository.TwoFactor, lockout *ratelimit.Lockout) *AccountService {
+	return &AccountService{
+		repo:         repo,
+		users:        users,
+		identities:   identities,
+		accessTokens: accessTokens,
+		appPasswords: appPasswords,
+		importJobs:   importJobs,
+		twoFactor:    twoFactor,
+		lockout:      lockout,
+	}
+}
+
+func (s *AccountService) GetProfile(ctx context.Context, userId int) (models.Profile, error) {
+	user, err := s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return models.Profile{}, err
+	}
+	return models.Profile{Id: user.Id, Name: user.Name, Username: user.Username}, nil
+}
+
+func (s *AccountService) UpdateProfile(ctx context.Context, userId int, input models.UpdateProfileInput) (models.Profile, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return models.Profile{}, err
+	}
+
+	if err := s.repo.UpdateName(ctx, userId, input.Name); err != nil {
+		return models.Profile{}, err
+	}
+	return s.GetProfile(ctx, userId)
+}
+
+// ChangePassword меняет пароль и отзывает все выпущенные JWT, кроме возвращаемого нового токена.
+// Персональные токены и пароли приложений продолжают действовать: их отзывают отдельно.
+// Без текущего пароля пароль можно сменить только сразу после входа через OIDC.
+func (s *AccountService) ChangePassword(ctx context.Context, userId int, input models.ChangePasswordInput) (token string, err error) {
+	defer func() { metrics.ObserveAuth("password_change", err) }()
+
+	if err := input.Validate(); err != nil {
+		return "", err
+	}
+	if err := s.confirmIdentity(ctx, userId, "current_password", input.CurrentPassword, ""); err != nil {
+		return "", err
+	}
+
+	version, err := s.repo.ChangePassword(ctx, userId, generatePasswordHash(input.NewPassword))
+	if err != nil {
+		return "", err
+	}
+	return newToken(userId, version, models.AuthMethodPassword)
+}
+
+// DeleteAccount удаляет пользователя со всеми его данными. Списки, которыми пользуется кто-то еще,
+// остаются у остальных участников. Пароль или код 2FA спрашивается, чтобы аккаунт нельзя было удалить
+// украденным токеном; пользователям, созданным через OIDC, вместо них достаточно заново войти через провайдера.
+func (s *AccountService) DeleteAccount(ctx context.Context, userId int, input models.DeleteAccountInput) error {
+	if err := s.confirmIdentity(ctx, userId, "password", input.Password, input.Code); err != nil {
+		return err
+	}
+	return s.users.DeleteUser(ctx, userId)
+}
+
+// ExportAccount собирает данные аккаунта для выгрузки; списки с задачами выгружает BackupService.Export
+func (s *AccountService) ExportAccount(ctx context.Context, userId int) (models.AccountExport, error) {
+	export := models.AccountExport{ExportedAt: time.Now().UTC().Truncate(time.Second)}
+
+	var err error
+	if export.Profile, err = s.GetProfile(ctx, userId); err != nil {
+		return models.AccountExport{}, err
+	}
+	if export.TwoFactor, err = twoFactorStatus(ctx, s.twoFactor, userId); err != nil {
+		return models.AccountExport{}, err
+	}
+	if export.Identities, err = s.identities.GetIdentities(ctx, userId); err != nil {
+		return models.AccountExport{}, err
+	}
+	if export.AccessTokens, err = s.accessTokens.GetAccessTokens(ctx, userId); err != nil {
+		return models.AccountExport{}, err
+	}
+	if export.AppPasswords, err = s.appPasswords.GetAppPasswords(ctx, userId); err != nil {
+		return models.AccountExport{}, err
+	}
+	if export.ImportJobs, err = s.importJobs.GetImportJobs(ctx, userId); err != nil {
+		return models.AccountExport{}, err
+	}
+	return export, nil
+}
+
+// confirmIdentity проверяет, что опасное действие совершает сам пользователь: по паролю, по коду 2FA
+// (если code передан) или по недавнему входу через OIDC, когда не передано ни то, ни другое.
+// Неверные пароль и код считаются неудачными попытками входа, иначе украденной сессией их можно было бы перебирать без блокировки.
+func (s *AccountService) confirmIdentity(ctx context.Context, userId int, field, password, code string) error {
+	if password == "" && code == "" {
+		if recentOIDCSignIn(ctx) {
+			return nil
+		}
+		return models.ValidationErrors{{Field: field, Code: models.CodeRequired, Message: "password is required, or sign in with OIDC again"}}
+	}
+
+	user, err := s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if err := s.lockout.Check(ctx, user.Username); err != nil {
+		return err
+	}
+
+	if password != "" {
+		err = s.checkPassword(ctx, userId, field, password)
+	} else {
+		err = s.checkCode(ctx, userId, code)
+	}
+	var validationErrs models.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		if lockErr := s.lockout.Fail(ctx, user.Username); lockErr != nil {
+			return lockErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	s.lockout.Succeed(ctx, user.Username)
+	return nil
+}
+
+// checkPassword сообщает о неверном пароле ошибкой валидации поля, а не 401: сессия при этом действительна
+func (s *AccountService) checkPassword(ctx context.Context, userId int, field, password string) error {
+	err := s.repo.CheckPassword(ctx, userId, generatePasswordHash(password))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ValidationErrors{{Field: field, Code: models.CodeInvalid, Message: "password is incorrect"}}
+	}
+	return err
+}
+
+// checkCode, как и checkPassword, сообщает о неверном коде ошибкой валидации
+func (s *AccountService) checkCode(ctx context.Context, userId int, code string) error {
+	err := checkTwoFactorCode(ctx, s.twoFactor, userId, code)
+	if errors.Is(err, ErrInvalidTwoFactorCode) || errors.Is(err, ErrTwoFactorNotEnabled) {
+		return models.ValidationErrors{{Field: "code", Code: models.CodeInvalid, Message: "two-factor code is incorrect"}}
+	}
+	return err
+}
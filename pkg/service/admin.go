@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+var ErrSameUser = errors.New("source and target users are the same")
+
+// AdminService - операции оператора над пользователями (todoctl). Пользователи адресуются по username,
+// проверок доступа к спискам здесь нет.
+type AdminService struct {
+	repo      repository.Admin
+	twoFactor repository.TwoFactor
+}
+
+func NewAdminService(repo repository.Admin, twoFactor repository.TwoFactor) *AdminService {
+	return &AdminService{repo: repo, twoFactor: twoFactor}
+}
+
+func (s *AdminService) GetUsers(ctx context.Context) ([]models.UserSummary, error) {
+	return s.repo.GetUsers(ctx)
+}
+
+func (s *AdminService) GetUser(ctx context.Context, username string) (models.UserSummary, error) {
+	user, err := s.repo.GetUserByUsername(ctx, strings.TrimSpace(username))
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, fmt.Errorf("user %q not found: %w", username, err)
+	}
+	return user, nil
+}
+
+// SetDisabled блокирует или разблокирует вход. Выданные JWT отзываются, персональные токены и пароли приложений
+// отключенного пользователя не принимаются.
+func (s *AdminService) SetDisabled(ctx context.Context, username string, disabled bool) error {
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetDisabled(ctx, user.Id, disabled)
+}
+
+// ResetPassword задает пароль и, как смена пароля самим пользователем, отзывает выданные JWT
+func (s *AdminService) ResetPassword(ctx context.Context, username, password string) error {
+	if err := models.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetPassword(ctx, user.Id, generatePasswordHash(password))
+}
+
+// ResetTwoFactor выключает 2FA пользователю, потерявшему и телефон, и коды восстановления
+func (s *AdminService) ResetTwoFactor(ctx context.Context, username string) error {
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.twoFactor.DeleteTOTP(ctx, user.Id)
+}
+
+func (s *AdminService) TransferList(ctx context.Context, listId int, fromUsername, toUsername string) error {
+	from, err := s.GetUser(ctx, fromUsername)
+	if err != nil {
+		return err
+	}
+	to, err := s.GetUser(ctx, toUsername)
+	if err != nil {
+		return err
+	}
+	if from.Id == to.Id {
+		return ErrSameUser
+	}
+
+	err = s.repo.TransferList(ctx, listId, from.Id, to.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %q has no list %d: %w", fromUsername, listId, err)
+	}
+	return err
+}
+
+func (s *AdminService) GetStats(ctx context.Context, username string) (models.UserStats, error) {
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return models.UserStats{}, err
+	}
+	return s.repo.GetStats(ctx, user.Id)
+}
+
+// PurgeUser удаляет пользователя и все его данные, кроме списков, которыми пользуется кто-то еще
+func (s *AdminService) PurgeUser(ctx context.Context, username string) error {
+	user, err := s.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteUser(ctx, user.Id)
+}
+
+// GetTotals - объем данных во всем хранилище, источник бизнес-метрик
+func (s *AdminService) GetTotals(ctx context.Context) (models.Totals, error) {
+	return s.repo.GetTotals(ctx)
+}
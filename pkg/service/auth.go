@@ -1,13 +1,18 @@
 package service
 
 import (
+	"context"
 	"crypto/sha1"
+	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
 	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
 	"github.com/ponomare0v/todo-go-app/pkg/repository"
 )
 
@@ -19,46 +24,133 @@ const (
 
 type tokenClaims struct {
 	jwt.StandardClaims
-	UserId int `json:"user_id"`
+	UserId         int    `json:"user_id"`
+	SessionVersion int    `json:"session_version,omitempty"` // токены прежних версий отозваны сменой пароля
+	AuthMethod     string `json:"auth_method,omitempty"`     // models.AuthMethodPassword или models.AuthMethodOIDC
 }
 
 //
 //
 
 type AuthService struct {
-	repo repository.Authorization
+	repo      repository.Authorization
+	accounts  repository.Account
+	twoFactor repository.TwoFactor
+	lockout   *ratelimit.Lockout
 }
 
-func NewAuthService(repo repository.Authorization) *AuthService {
-	return &AuthService{repo: repo}
+func NewAuthService(repo repository.Authorization, accounts repository.Account, twoFactor repository.TwoFactor, lockout *ratelimit.Lockout) *AuthService {
+	return &AuthService{repo: repo, accounts: accounts, twoFactor: twoFactor, lockout: lockout}
 }
 
 //
 //
 
-func (s *AuthService) CreateUser(user models.User) (int, error) {
+func (s *AuthService) CreateUser(ctx context.Context, user models.User) (id int, err error) {
+	defer func() { metrics.ObserveAuth("sign_up", err) }()
+
+	user.Normalize()
+	if err := user.Validate(); err != nil {
+		return 0, err
+	}
+
 	user.Password = generatePasswordHash(user.Password)
-	return s.repo.CreateUser(user)
+	return s.repo.CreateUser(ctx, user)
 }
 
 //
 //
 
-func (s *AuthService) GenerateToken(username, password string) (string, error) {
-	user, err := s.repo.GetUser(username, generatePasswordHash(password))
+// GenerateToken проверяет пароль и выпускает JWT. Если у пользователя включена 2FA, вместо токена
+// возвращается *TwoFactorRequiredError с challenge для GenerateTokenWithCode.
+func (s *AuthService) GenerateToken(ctx context.Context, username, password string) (token string, err error) {
+	defer func() {
+		// пароль верный - попытка успешна, второй шаг считается отдельно как sign_in_2fa
+		if errors.Is(err, ErrTwoFactorRequired) {
+			metrics.ObserveAuth("sign_in", nil)
+			return
+		}
+		metrics.ObserveAuth("sign_in", err)
+	}()
+
+	// заблокированный логин не проверяем вовсе, иначе перебор продолжался бы и во время блокировки
+	username = strings.TrimSpace(username)
+	if err := s.lockout.Check(ctx, username); err != nil {
+		return "", err
+	}
+
+	user, err := s.repo.GetUser(ctx, username, generatePasswordHash(password))
+	if errors.Is(err, sql.ErrNoRows) {
+		// неверный пароль и несуществующий логин считаются одинаково, чтобы по блокировке нельзя было подбирать логины
+		if lockErr := s.lockout.Fail(ctx, username); lockErr != nil {
+			return "", lockErr
+		}
+		return "", err
+	}
 	if err != nil {
 		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
+	// неудачи не сбрасываются до второго шага, иначе, зная пароль, можно было бы перебирать коды без блокировки
+	if err := twoFactorChallenge(ctx, s.twoFactor, user.Id, username, models.AuthMethodPassword); err != nil {
+		return "", err
+	}
+	s.lockout.Succeed(ctx, username)
+
+	return newSession(ctx, s.accounts, user.Id, models.AuthMethodPassword)
+}
+
+// GenerateTokenWithCode завершает вход с 2FA: проверяет challenge из GenerateToken и код из приложения
+// или код восстановления. Неверный код считается неудачной попыткой входа для блокировки.
+func (s *AuthService) GenerateTokenWithCode(ctx context.Context, challengeToken, code string) (token string, err error) {
+	defer func() { metrics.ObserveAuth("sign_in_2fa", err) }()
+
+	claims, err := parseChallenge(challengeToken)
+	if err != nil {
+		return "", ErrInvalidChallenge
+	}
+	if err := s.lockout.Check(ctx, claims.Username); err != nil {
+		return "", err
+	}
+
+	err = checkTwoFactorCode(ctx, s.twoFactor, claims.UserId, code)
+	switch {
+	case errors.Is(err, ErrInvalidTwoFactorCode):
+		if lockErr := s.lockout.Fail(ctx, claims.Username); lockErr != nil {
+			return "", lockErr
+		}
+		return "", err
+	case errors.Is(err, ErrTwoFactorNotEnabled):
+		return "", ErrInvalidChallenge // 2FA выключили после ввода пароля
+	case err != nil:
+		return "", err
+	}
+	s.lockout.Succeed(ctx, claims.Username)
+
+	return newSession(ctx, s.accounts, claims.UserId, claims.AuthMethod)
+}
+
+// newSession выпускает JWT с текущей версией сессий пользователя; им заканчивается и вход по паролю, и вход через OIDC
+func newSession(ctx context.Context, accounts repository.Account, userId int, authMethod string) (string, error) {
+	version, err := accounts.GetSessionVersion(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	return newToken(userId, version, authMethod)
+}
+
+func newToken(userId, sessionVersion int, authMethod string) (string, error) {
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
 		jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(tokenTTL).Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
-		user.Id,
+		userId,
+		sessionVersion,
+		authMethod,
 	})
 
-	return token.SignedString([]byte(signingKey))
+	return jwtToken.SignedString([]byte(signingKey))
 }
 
 //
@@ -66,7 +158,9 @@ func (s *AuthService) GenerateToken(username, password string) (string, error) {
 
 // метод структуры AuthService, где вызовем функцию из библиотеки jwt ParseWithClaims,
 // которая принимает сам токен, структуру Claims и функцию, которая возвращает ключ подпись или ошибку.
-func (s *AuthService) ParseToken(accessToken string) (int, error) {
+func (s *AuthService) ParseToken(ctx context.Context, accessToken string) (session models.Session, err error) {
+	defer func() { metrics.ObserveAuth("token", err) }()
+
 	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		//В этой функции нужно проверить метод подписи токена, если это не HMAC, то возвращем ошибку,
 		//  а если все окей то возвращаем ключ-подпись (signingKey).
@@ -78,18 +172,27 @@ func (s *AuthService) ParseToken(accessToken string) (int, error) {
 	})
 
 	if err != nil {
-		return 0, err
+		return models.Session{}, err
 	}
 
 	//Функция parseWithClaims возвращет объект токена, в котором есть поле Claims типа интерфейс,
 	//  приведим его к собственной структуре и проверяем всё ли хорошо.
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return 0, errors.New("token claims are not of type *tokenClaims")
+		return models.Session{}, errors.New("token claims are not of type *tokenClaims")
+	}
+
+	// токены, выпущенные до смены пароля, и токены удаленного или отключенного пользователя больше не принимаются
+	version, err := s.accounts.GetSessionVersion(ctx, claims.UserId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && version != claims.SessionVersion) {
+		return models.Session{}, ErrSessionRevoked
+	}
+	if err != nil {
+		return models.Session{}, err
 	}
 
-	//Если все же успешно распарсили токен - вернем значение id пользователя.
-	return claims.UserId, nil
+	//Если все же успешно распарсили токен - вернем значение id пользователя и то, как он вошел.
+	return models.Session{UserId: claims.UserId, AuthMethod: claims.AuthMethod, IssuedAt: time.Unix(claims.IssuedAt, 0)}, nil
 }
 
 //
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+// задачи при экспорте выбираются пачками по столько списков, чтобы не держать в памяти все сразу
+const exportBatchSize = 100
+
+type BackupService struct {
+	repo      repository.Backup
+	listRepo  repository.TodoList
+	itemsRepo repository.TodoItem
+}
+
+func NewBackupService(repo repository.Backup, listRepo repository.TodoList, itemsRepo repository.TodoItem) *BackupService {
+	return &BackupService{repo: repo, listRepo: listRepo, itemsRepo: itemsRepo}
+}
+
+// Export передает в emit по одному все списки пользователя вместе с задачами
+func (s *BackupService) Export(ctx context.Context, userId int, emit func(models.ListBackup) error) error {
+	return eachList(ctx, s.listRepo, s.itemsRepo, userId, func(list models.TodoList, items []models.TodoItem) error {
+		backup := models.ListBackup{Title: list.Title, Description: list.Description, Items: []models.ItemBackup{}}
+		for _, item := range items {
+			backup.Items = append(backup.Items, models.ItemBackup{Title: item.Title, Description: item.Description, Done: item.Done, DueDate: item.DueDate})
+		}
+		return emit(backup)
+	})
+}
+
+// eachList передает в fn по одному все списки пользователя с их задачами
+func eachList(ctx context.Context, listRepo repository.TodoList, itemsRepo repository.TodoItem, userId int,
+	fn func(list models.TodoList, items []models.TodoItem) error) error {
+	lists, err := listRepo.GetAll(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(lists); start += exportBatchSize {
+		batch := lists[start:min(start+exportBatchSize, len(lists))]
+		listIds := make([]int, len(batch))
+		for i, list := range batch {
+			listIds[i] = list.Id
+		}
+
+		itemsByList, err := itemsRepo.GetAllByLists(ctx, userId, listIds)
+		if err != nil {
+			return err
+		}
+
+		for _, list := range batch {
+			if err := fn(list, itemsByList[list.Id]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Import проверяет все списки и задачи и, если ошибок нет и это не dry run, создает их одной транзакцией.
+// Ошибки отдельных записей возвращаются в отчете, а не ошибкой метода.
+func (s *BackupService) Import(ctx context.Context, userId int, lists []models.ListBackup, dryRun bool) (models.ImportReport, error) {
+	report := checkImport(lists)
+	report.DryRun = dryRun
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	ids, err := s.repo.ImportLists(ctx, userId, lists)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	report.ListIds = ids
+	return report, nil
+}
+
+// checkImport нормализует списки на месте, считает их и задачи и собирает ошибки валидации по каждой записи
+func checkImport(lists []models.ListBackup) models.ImportReport {
+	var report models.ImportReport
+	for i := range lists {
+		list := &lists[i]
+		list.Normalize()
+		report.Lists++
+		report.Items += len(list.Items)
+
+		path := fmt.Sprintf("lists[%d]", i)
+		report.Errors = appendImportErrors(report.Errors, list.Row, path, list.TodoList().Validate())
+		for j, item := range list.Items {
+			row := item.Row
+			if row == 0 {
+				row = list.Row
+			}
+			report.Errors = appendImportErrors(report.Errors, row, fmt.Sprintf("%s.items[%d]", path, j), item.TodoItem().Validate())
+		}
+	}
+	return report
+}
+
+// appendImportErrors переносит ошибки валидации полей в отчет, добавляя к полю путь до записи
+func appendImportErrors(errs []models.ImportError, row int, path string, err error) []models.ImportError {
+	var validationErrs models.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return errs
+	}
+	for _, fieldErr := range validationErrs {
+		fieldErr.Field = path + "." + fieldErr.Field
+		errs = append(errs, models.ImportError{Row: row, FieldError: fieldErr})
+	}
+	return errs
+}
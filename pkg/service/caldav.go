@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+var (
+	ErrInvalidAppPassword  = errors.New("invalid username or app password")
+	ErrAppPasswordNotFound = errors.New("app password not found")
+	ErrObjectNotFound      = errors.New("calendar object not found")
+	// ErrObjectConflict - имя item-<id>.ics занято под задачи без записи в caldav_objects или UID уже есть в списке
+	ErrObjectConflict     = errors.New("calendar object conflicts with an existing one")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// 20 случайных байт дают 32 символа base32, пароль выдается группами по 4 символа для ввода вручную
+const appPasswordBytes = 20
+
+var (
+	appPasswordEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+	itemObjectName      = regexp.MustCompile(`^item-(\d+)\.ics$`)
+)
+
+// CalDAVService отображает списки в CalDAV-коллекции, а задачи - в ресурсы с одним VTODO.
+// Чтение и запись идут через сервисы списков и задач, поэтому работают те же проверки доступа,
+// валидация и события, что и в REST. Задачи, созданные не через CalDAV, называются item-<id>.ics,
+// а имя и UID задач от CalDAV-клиентов сохраняются, чтобы клиент узнавал свои ресурсы при синхронизации.
+type CalDAVService struct {
+	passwords repository.AppPassword
+	objects   repository.CalDAV
+	lists     TodoList
+	items     TodoItem
+}
+
+func NewCalDAVService(passwords repository.AppPassword, objects repository.CalDAV, lists TodoList, items TodoItem) *CalDAVService {
+	return &CalDAVService{passwords: passwords, objects: objects, lists: lists, items: items}
+}
+
+// CreateAppPassword создает пароль приложения; сам пароль возвращается только здесь
+func (s *CalDAVService) CreateAppPassword(ctx context.Context, userId int, password models.AppPassword) (models.AppPassword, error) {
+	password.Normalize()
+	if err := password.Validate(); err != nil {
+		return models.AppPassword{}, err
+	}
+
+	buf := make([]byte, appPasswordBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return models.AppPassword{}, err
+	}
+	secret := strings.ToLower(appPasswordEncoding.EncodeToString(buf))
+
+	password.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	id, err := s.passwords.CreateAppPassword(ctx, userId, password, hashSecret(secret))
+	if err != nil {
+		return models.AppPassword{}, err
+	}
+
+	password.Id = id
+	password.Password = groupAppPassword(secret)
+	return password, nil
+}
+
+func (s *CalDAVService) GetAppPasswords(ctx context.Context, userId int) ([]models.AppPassword, error) {
+	return s.passwords.GetAppPasswords(ctx, userId)
+}
+
+func (s *CalDAVService) DeleteAppPassword(ctx context.Context, userId, id int) error {
+	err := s.passwords.DeleteAppPassword(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAppPasswordNotFound
+	}
+	return err
+}
+
+// Authenticate проверяет логин и пароль приложения из HTTP Basic. Пароль пользователя здесь не подходит.
+func (s *CalDAVService) Authenticate(ctx context.Context, username, password string) (userId int, err error) {
+	defer func() { metrics.ObserveAuth("app_password", err) }()
+
+	// пароль можно ввести без дефисов и в любом регистре
+	secret := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(password))
+	if secret == "" {
+		return 0, ErrInvalidAppPassword
+	}
+
+	userId, err = s.passwords.GetAppPasswordUser(ctx, strings.TrimSpace(username), hashSecret(secret))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidAppPassword
+	}
+	return userId, err
+}
+
+// GetCalendars возвращает все списки пользователя как коллекции
+func (s *CalDAVService) GetCalendars(ctx context.Context, userId int) ([]models.Calendar, error) {
+	lists, err := s.lists.GetAll(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := s.getObjects(ctx, userId, lists)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := make([]models.Calendar, 0, len(lists))
+	for _, list := range lists {
+		calendars = append(calendars, models.Calendar{List: list, CTag: calendarCTag(list, objects[list.Id])})
+	}
+	return calendars, nil
+}
+
+// GetCalendar возвращает коллекцию списка вместе с ее ресурсами, отсортированными по имени
+func (s *CalDAVService) GetCalendar(ctx context.Context, userId, listId int) (models.Calendar, []models.CalendarObject, error) {
+	list, err := s.lists.GetById(ctx, userId, listId)
+	if err != nil {
+		return models.Calendar{}, nil, err
+	}
+
+	objects, err := s.getObjects(ctx, userId, []models.TodoList{list})
+	if err != nil {
+		return models.Calendar{}, nil, err
+	}
+	return models.Calendar{List: list, CTag: calendarCTag(list, objects[list.Id])}, objects[list.Id], nil
+}
+
+// GetObject возвращает ресурс вместе со списком: название списка попадает в CATEGORIES задачи
+func (s *CalDAVService) GetObject(ctx context.Context, userId, listId int, name string) (models.TodoList, models.CalendarObject, error) {
+	calendar, objects, err := s.GetCalendar(ctx, userId, listId)
+	if err != nil {
+		return models.TodoList{}, models.CalendarObject{}, err
+	}
+	object, err := findObject(objects, name)
+	return calendar.List, object, err
+}
+
+// PutObject создает задачу из ресурса или полностью заменяет существующую. ifMatch и ifNoneMatch -
+// значения одноименных заголовков, по ним клиент не дает перезаписать чужие изменения (RFC 7232).
+func (s *CalDAVService) PutObject(ctx context.Context, userId, listId int, name string, object models.CalendarObject, ifMatch, ifNoneMatch string) (created bool, err error) {
+	_, objects, err := s.GetCalendar(ctx, userId, listId)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := findObject(objects, name)
+	exists := err == nil
+	if err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return false, err
+	}
+	if !preconditionsMet(ifMatch, ifNoneMatch, current.ETag, exists) {
+		return false, ErrPreconditionFailed
+	}
+
+	if exists {
+		// PATCH, а не Update: полная замена должна уметь и снять срок выполнения
+		body, err := json.Marshal(map[string]interface{}{
+			"title":       object.Item.Title,
+			"description": object.Item.Description,
+			"done":        object.Item.Done,
+			"due_date":    object.Item.DueDate,
+		})
+		if err != nil {
+			return false, err
+		}
+		return false, s.items.Patch(ctx, userId, current.Item.Id, models.Patch{Type: models.MergePatchType, Body: body})
+	}
+
+	if itemObjectName.MatchString(name) {
+		return false, ErrObjectConflict
+	}
+	object.Name = name
+	if object.UID == "" {
+		object.UID = strings.TrimSuffix(name, ".ics")
+	}
+	if err := object.Validate(); err != nil {
+		return false, err
+	}
+	for _, other := range objects {
+		if other.UID == object.UID {
+			return false, ErrObjectConflict // RFC 4791, раздел 5.3.2.1: no-uid-conflict
+		}
+	}
+
+	itemId, err := s.items.Create(ctx, userId, listId, object.Item)
+	if err != nil {
+		return false, err
+	}
+	if err := s.objects.SetObject(ctx, itemId, object); err != nil {
+		// без записи задача видна клиенту под другим именем, и при повторе он создал бы дубль
+		_ = s.items.Delete(ctx, userId, itemId)
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *CalDAVService) DeleteObject(ctx context.Context, userId, listId int, name, ifMatch string) error {
+	_, current, err := s.GetObject(ctx, userId, listId, name)
+	if err != nil {
+		return err
+	}
+	if !preconditionsMet(ifMatch, "", current.ETag, true) {
+		return ErrPreconditionFailed
+	}
+	return s.items.Delete(ctx, userId, current.Item.Id)
+}
+
+// getObjects собирает ресурсы всех переданных списков по id списка
+func (s *CalDAVService) getObjects(ctx context.Context, userId int, lists []models.TodoList) (map[int][]models.CalendarObject, error) {
+	listIds := make([]int, len(lists))
+	for i, list := range lists {
+		listIds[i] = list.Id
+	}
+
+	itemsByList, err := s.items.GetAllByLists(ctx, userId, listIds)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.objects.GetObjects(ctx, userId, listIds)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make(map[int][]models.CalendarObject, len(lists))
+	for _, list := range lists {
+		for _, item := range itemsByList[list.Id] {
+			object := models.ItemObject(item)
+			if custom, ok := stored[item.Id]; ok {
+				object.Name, object.UID = custom.Name, custom.UID
+			}
+			object.ETag = objectETag(list, object)
+			objects[list.Id] = append(objects[list.Id], object)
+		}
+		sort.Slice(objects[list.Id], func(i, j int) bool { return objects[list.Id][i].Name < objects[list.Id][j].Name })
+	}
+	return objects, nil
+}
+
+func findObject(objects []models.CalendarObject, name string) (models.CalendarObject, error) {
+	for _, object := range objects {
+		if object.Name == name {
+			return object, nil
+		}
+	}
+	return models.CalendarObject{}, ErrObjectNotFound
+}
+
+// objectETag - хэш всего, что попадает в VTODO, кроме DTSTAMP. Отдельной колонки с версией нет,
+// поэтому ETag меняется ровно тогда, когда меняется содержимое ресурса.
+func objectETag(list models.TodoList, object models.CalendarObject) string {
+	h := sha256.New()
+	item := object.Item
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%t\x00", object.UID, list.Title, item.Title, item.Description, item.Done)
+	if item.DueDate != nil {
+		io.WriteString(h, item.DueDate.UTC().Format(time.RFC3339))
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// calendarCTag меняется при изменении списка, любой его задачи, а также при добавлении и удалении задач
+func calendarCTag(list models.TodoList, objects []models.CalendarObject) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", list.Title, list.Description)
+	for _, object := range objects {
+		fmt.Fprintf(h, "%s\x00%s\x00", object.Name, object.ETag)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// preconditionsMet проверяет If-Match и If-None-Match; etag - текущий ETag ресурса без кавычек
+func preconditionsMet(ifMatch, ifNoneMatch, etag string, exists bool) bool {
+	if ifMatch != "" && !(exists && matchETag(ifMatch, etag)) {
+		return false
+	}
+	if ifNoneMatch != "" && exists && matchETag(ifNoneMatch, etag) {
+		return false
+	}
+	return true
+}
+
+func matchETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || strings.Trim(candidate, `"`) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// groupAppPassword разбивает пароль на группы по 4 символа: abcd-efgh-...
+func groupAppPassword(secret string) string {
+	groups := make([]string, 0, len(secret)/4+1)
+	for len(secret) > 4 {
+		groups = append(groups, secret[:4])
+		secret = secret[4:]
+	}
+	return strings.Join(append(groups, secret), "-")
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+// ErrFeedNotFound - токен ленты неизвестен, отозван или его владелец заблокирован
+var ErrFeedNotFound = errors.New("calendar feed not found")
+
+// 32 случайных байта - токен нельзя подобрать, он заменяет календарным клиентам заголовок Authorization
+const feedTokenBytes = 32
+
+// CalendarService выдает секретные ссылки на ICS-ленту. В базе хранится только SHA-256 токена,
+// поэтому показать выданную ссылку повторно нельзя - только выпустить новую.
+type CalendarService struct {
+	repo      repository.CalendarFeed
+	listRepo  repository.TodoList
+	itemsRepo repository.TodoItem
+}
+
+func NewCalendarService(repo repository.CalendarFeed, listRepo repository.TodoList, itemsRepo repository.TodoItem) *CalendarService {
+	return &CalendarService{repo: repo, listRepo: listRepo, itemsRepo: itemsRepo}
+}
+
+// CreateFeedToken выпускает новый токен ленты; прежняя ссылка перестает работать
+func (s *CalendarService) CreateFeedToken(ctx context.Context, userId int) (string, error) {
+	buf := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := s.repo.SetFeedToken(ctx, userId, hashSecret(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *CalendarService) DeleteFeedToken(ctx context.Context, userId int) error {
+	return s.repo.DeleteFeedToken(ctx, userId)
+}
+
+func (s *CalendarService) GetFeedUser(ctx context.Context, token string) (int, error) {
+	if token == "" {
+		return 0, ErrFeedNotFound
+	}
+	userId, err := s.repo.GetFeedUser(ctx, hashSecret(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrFeedNotFound
+	}
+	return userId, err
+}
+
+// GetFeed передает в emit по одному все списки пользователя с задачами
+func (s *CalendarService) GetFeed(ctx context.Context, userId int, emit func(list models.TodoList, items []models.TodoItem) error) error {
+	return eachList(ctx, s.listRepo, s.itemsRepo, userId, emit)
+}
+
+// hashSecret хэширует сгенерированные сервером секреты - токены лент и пароли приложений.
+// Они случайные и длинные, поэтому соль и медленный хэш не нужны, а по хэшу их можно искать в базе.
+func hashSecret(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
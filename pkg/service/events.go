@@ -0,0 +1,55 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+// размер буфера подписчика: если клиент не успевает читать, лишние события отбрасываются,
+// чтобы медленный стрим не тормозил запись задач
+const eventBufferSize = 64
+
+// itemEvents - простой in-process брокер событий изменения задач
+type itemEvents struct {
+	mu          sync.RWMutex
+	subscribers map[int]chan models.ItemEvent
+	nextId      int
+}
+
+func newItemEvents() *itemEvents {
+	return &itemEvents{subscribers: make(map[int]chan models.ItemEvent)}
+}
+
+func (b *itemEvents) subscribe() (<-chan models.ItemEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextId
+	b.nextId++
+	ch := make(chan models.ItemEvent, eventBufferSize)
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			close(ch)
+			b.mu.Unlock()
+		})
+	}
+	return ch, unsubscribe
+}
+
+func (b *itemEvents) publish(event models.ItemEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+)
+
+const (
+	// importJobLease - сколько задание принадлежит обработчику без прогресса; после этого его берет другая реплика
+	importJobLease = time.Minute
+	// maxImportAttempts - сколько раз подряд задание можно взять в работу, так и не создав ни одного списка
+	maxImportAttempts = 5
+)
+
+var ErrImportJobNotFound = errors.New("import job not found")
+
+type ImportJobService struct {
+	repo repository.ImportJob
+}
+
+func NewImportJobService(repo repository.ImportJob) *ImportJobService {
+	return &ImportJobService{repo: repo}
+}
+
+// CreateImportJob проверяет списки так же, как /api/import, и ставит задание в очередь.
+// Если какая-то запись не прошла проверку, задание не создается, а ошибки возвращаются в отчете.
+func (s *ImportJobService) CreateImportJob(ctx context.Context, userId int, source string, lists []models.ListBackup) (models.ImportJob, models.ImportReport, error) {
+	report := checkImport(lists)
+	if len(report.Errors) > 0 {
+		return models.ImportJob{}, report, nil
+	}
+
+	now := time.Now().UTC()
+	job := models.ImportJob{
+		UserId:     userId,
+		Source:     source,
+		Status:     models.ImportPending,
+		TotalLists: report.Lists,
+		TotalItems: report.Items,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	id, err := s.repo.CreateImportJob(ctx, job, lists)
+	if err != nil {
+		return models.ImportJob{}, models.ImportReport{}, err
+	}
+	job.Id = id
+	return job, report, nil
+}
+
+func (s *ImportJobService) GetImportJobs(ctx context.Context, userId int) ([]models.ImportJob, error) {
+	return s.repo.GetImportJobs(ctx, userId)
+}
+
+func (s *ImportJobService) GetImportJob(ctx context.Context, userId, id int) (models.ImportJob, error) {
+	job, err := s.repo.GetImportJob(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ImportJob{}, ErrImportJobNotFound
+	}
+	return job, err
+}
+
+// ProcessImportJob берет одно задание из очереди и создает его оставшиеся списки; false - очередь пуста.
+// Если обработка прервалась (остановка, ошибка БД), задание продолжится после истечения аренды
+// с первого несозданного списка.
+func (s *ImportJobService) ProcessImportJob(ctx context.Context) (bool, error) {
+	now := time.Now().UTC()
+	job, lists, err := s.repo.ClaimImportJob(ctx, now, now.Add(importJobLease))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if job.Attempts > maxImportAttempts {
+		return true, s.finish(ctx, job, models.ImportFailed, fmt.Sprintf("import stopped after %d attempts without progress", maxImportAttempts))
+	}
+
+	for _, list := range lists[min(job.DoneLists, len(lists)):] {
+		now := time.Now().UTC()
+		job.UpdatedAt = now
+		err := s.repo.ImportJobList(ctx, job, list, now.Add(importJobLease))
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil // задание удалено вместе с пользователем или его продолжил другой обработчик
+		}
+		if err != nil {
+			return true, fmt.Errorf("import job %d: %w", job.Id, err)
+		}
+		job.DoneLists++
+		job.DoneItems += len(list.Items)
+	}
+
+	return true, s.finish(ctx, job, models.ImportCompleted, "")
+}
+
+func (s *ImportJobService) finish(ctx context.Context, job models.ImportJob, status, message string) error {
+	job.Status, job.Error, job.UpdatedAt = status, message, time.Now().UTC()
+	return s.repo.FinishImportJob(ctx, job)
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ponomare0v/todo-go-app/pkg/metrics"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/oidc"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"golang.org/x/oauth2"
+)
+
+// OIDCLoginTTL - сколько живет начатый вход: за это время пользователь должен пройти страницу провайдера
+const OIDCLoginTTL = 10 * time.Minute
+
+var (
+	ErrOIDCLoginExpired = errors.New("oidc login session is missing or expired, start the login again")
+	ErrOIDCLoginFailed  = errors.New("oidc login failed")
+	ErrOIDCUnavailable  = errors.New("oidc provider is unavailable")
+	ErrOIDCNoAccount    = errors.New("no account is linked to this identity")
+	ErrOIDCLinkRequired = errors.New("password is required to link this identity to the existing account")
+	ErrOIDCLinkFailed   = errors.New("password is incorrect, the identity is not linked")
+	ErrUserDisabled     = errors.New("user is disabled")
+)
+
+// OIDCLinkRequiredError - аккаунт провайдера совпал с существующим пользователем Username. Связать их можно
+// только паролем этого пользователя: LinkOIDCIdentity с LinkToken, пока не истек OIDCLoginTTL.
+type OIDCLinkRequiredError struct {
+	LinkToken string
+	Username  string
+}
+
+func (e *OIDCLinkRequiredError) Error() string {
+	return ErrOIDCLinkRequired.Error()
+}
+
+func (e *OIDCLinkRequiredError) Unwrap() error {
+	return ErrOIDCLinkRequired
+}
+
+// oidcLinkClaims - проверенный аккаунт провайдера и пользователь, с которым его предлагается связать.
+// Подписываются своим ключом, поэтому не принимаются ни вместо JWT, ни вместо challenge 2FA.
+type oidcLinkClaims struct {
+	jwt.StandardClaims
+	UserId   int    `json:"user_id"`
+	Username string `json:"username"` // ключ блокировки входа, как в GenerateToken
+	Provider string `json:"provider"`
+	Identity string `json:"identity"` // sub провайдера
+	Email    string `json:"email"`
+}
+
+var usernameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// oidcLogin - начатый вход, хранится у браузера в подписанной cookie до возврата с кодом.
+// Так callback может прийти на любую реплику, а сервер ничего не хранит.
+type oidcLogin struct {
+	Provider  string `json:"p"`
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"` // PKCE code verifier
+	ExpiresAt int64  `json:"e"`
+}
+
+// OIDCService - вход через провайдеров OpenID Connect. Аккаунт провайдера связывается с пользователем
+// при первом входе: по настройке link_by с уже существующим или с новым, если включен auto_provision.
+type OIDCService struct {
+	providers  *oidc.Providers
+	identities repository.Identity
+	users      repository.Admin
+	accounts   repository.Account
+	twoFactor  repository.TwoFactor
+	lockout    *ratelimit.Lockout
+}
+
+func NewOIDCService(providers *oidc.Providers, identities repository.Identity, users repository.Admin, accounts repository.Account,
+	twoFactor repository.TwoFactor, lockout *ratelimit.Lockout) *OIDCService {
+	return &OIDCService{providers: providers, identities: identities, users: users, accounts: accounts, twoFactor: twoFactor, lockout: lockout}
+}
+
+func (s *OIDCService) GetOIDCProviders(ctx context.Context) ([]string, error) {
+	names := s.providers.Names()
+	if names == nil {
+		names = []string{}
+	}
+	return names, nil
+}
+
+// StartOIDCLogin возвращает адрес страницы входа провайдера и сессию входа, которую нужно вернуть в FinishOIDCLogin
+func (s *OIDCService) StartOIDCLogin(ctx context.Context, provider, redirectURL string) (authURL, session string, err error) {
+	login := oidcLogin{
+		Provider:  provider,
+		State:     randomToken(),
+		Nonce:     randomToken(),
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiresAt: time.Now().Add(OIDCLoginTTL).Unix(),
+	}
+
+	authURL, err = s.providers.AuthCodeURL(ctx, provider, redirectURL, login.State, login.Nonce, login.Verifier)
+	if errors.Is(err, oidc.ErrUnknownProvider) {
+		return "", "", err
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrOIDCUnavailable, err)
+	}
+
+	session, err = sealOIDCLogin(login)
+	return authURL, session, err
+}
+
+// FinishOIDCLogin проверяет возврат от провайдера, находит или создает пользователя и выпускает JWT.
+// Если аккаунт провайдера совпал с существующим пользователем, возвращается *OIDCLinkRequiredError,
+// если у пользователя включена 2FA - *TwoFactorRequiredError, как в AuthService.GenerateToken.
+func (s *OIDCService) FinishOIDCLogin(ctx context.Context, session, state, code, redirectURL string) (token string, err error) {
+	defer func() {
+		// провайдер вход подтвердил, связывание и код считаются отдельно как oidc_link и sign_in_2fa
+		if errors.Is(err, ErrOIDCLinkRequired) || errors.Is(err, ErrTwoFactorRequired) {
+			metrics.ObserveAuth("oidc", nil)
+			return
+		}
+		metrics.ObserveAuth("oidc", err)
+	}()
+
+	login, err := openOIDCLogin(session)
+	if err != nil || !hmac.Equal([]byte(login.State), []byte(state)) {
+		return "", ErrOIDCLoginExpired
+	}
+
+	claims, err := s.providers.Exchange(ctx, login.Provider, redirectURL, code, login.Verifier, login.Nonce)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	user, err := s.identityUser(ctx, login.Provider, claims)
+	if err != nil {
+		return "", err
+	}
+	if user.Disabled {
+		return "", ErrUserDisabled
+	}
+	// второй фактор провайдера приложение не видит, поэтому свой код спрашивается и здесь
+	if err := twoFactorChallenge(ctx, s.twoFactor, user.Id, user.Username, models.AuthMethodOIDC); err != nil {
+		return "", err
+	}
+	return newSession(ctx, s.accounts, user.Id, models.AuthMethodOIDC)
+}
+
+// LinkOIDCIdentity завершает первый вход через OIDC в существующий аккаунт: связывание подтверждается паролем
+// этого аккаунта, неверный пароль учитывается в блокировке входа, как в GenerateToken. С включенной 2FA
+// аккаунт связывается, но вместо токена возвращается *TwoFactorRequiredError.
+func (s *OIDCService) LinkOIDCIdentity(ctx context.Context, linkToken, password string) (token string, err error) {
+	defer func() {
+		if errors.Is(err, ErrTwoFactorRequired) {
+			metrics.ObserveAuth("oidc_link", nil)
+			return
+		}
+		metrics.ObserveAuth("oidc_link", err)
+	}()
+
+	claims, err := parseLinkToken(linkToken)
+	if err != nil {
+		return "", ErrOIDCLoginExpired
+	}
+	if err := s.lockout.Check(ctx, claims.Username); err != nil {
+		return "", err
+	}
+
+	err = s.accounts.CheckPassword(ctx, claims.UserId, generatePasswordHash(password))
+	if errors.Is(err, sql.ErrNoRows) {
+		if lockErr := s.lockout.Fail(ctx, claims.Username); lockErr != nil {
+			return "", lockErr
+		}
+		return "", ErrOIDCLinkFailed
+	}
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.users.GetUserById(ctx, claims.UserId)
+	if err != nil {
+		return "", err
+	}
+	if user.Disabled {
+		return "", ErrUserDisabled
+	}
+
+	linked, err := s.identities.GetIdentityUser(ctx, claims.Provider, claims.Identity)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = s.identities.CreateIdentity(ctx, models.Identity{
+			UserId:    user.Id,
+			Provider:  claims.Provider,
+			Subject:   claims.Identity,
+			Email:     claims.Email,
+			CreatedAt: time.Now().UTC().Truncate(time.Second),
+		})
+	case err == nil && linked.Id != user.Id:
+		return "", ErrOIDCLoginExpired // аккаунт провайдера уже связали с другим пользователем
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// неудачи, как и в GenerateToken, сбрасываются только после второго шага
+	if err := twoFactorChallenge(ctx, s.twoFactor, user.Id, claims.Username, models.AuthMethodOIDC); err != nil {
+		return "", err
+	}
+	s.lockout.Succeed(ctx, claims.Username)
+	return newSession(ctx, s.accounts, user.Id, models.AuthMethodOIDC)
+}
+
+// identityUser находит пользователя аккаунта провайдера, при первом входе связывает или создает его
+func (s *OIDCService) identityUser(ctx context.Context, provider string, claims oidc.Claims) (models.UserSummary, error) {
+	user, err := s.identities.GetIdentityUser(ctx, provider, claims.Subject)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return user, err
+	}
+
+	cfg, err := s.providers.Config(provider)
+	if err != nil {
+		return models.UserSummary{}, err
+	}
+	identity := models.Identity{
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	if username := linkUsername(cfg, claims); username != "" {
+		user, err := s.users.GetUserByUsername(ctx, username)
+		if err == nil {
+			// молча не связываем: совпадение логина еще не значит, что это тот же человек
+			linkToken, err := newLinkToken(identity, user)
+			if err != nil {
+				return models.UserSummary{}, err
+			}
+			return models.UserSummary{}, &OIDCLinkRequiredError{LinkToken: linkToken, Username: user.Username}
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return models.UserSummary{}, err
+		}
+	}
+
+	if !cfg.AutoProvision {
+		return models.UserSummary{}, ErrOIDCNoAccount
+	}
+	return s.provision(ctx, claims, identity)
+}
+
+// provision создает пользователя со случайным паролем: войти он может только через провайдера
+func (s *OIDCService) provision(ctx context.Context, claims oidc.Claims, identity models.Identity) (models.UserSummary, error) {
+	username, err := s.freeUsername(ctx, oidcUsername(claims))
+	if err != nil {
+		return models.UserSummary{}, err
+	}
+	user := models.User{Name: claims.Name, Username: username, Password: randomToken()}
+	if user.Name == "" {
+		user.Name = username
+	}
+	user.Normalize()
+	if err := user.Validate(); err != nil {
+		return models.UserSummary{}, err
+	}
+	user.Password = generatePasswordHash(user.Password)
+
+	id, err := s.identities.CreateUserWithIdentity(ctx, user, identity)
+	if err != nil {
+		return models.UserSummary{}, err
+	}
+	return models.UserSummary{Id: id, Name: user.Name, Username: user.Username}, nil
+}
+
+// freeUsername добавляет к логину -2, -3 и т.д., пока не найдет свободный
+func (s *OIDCService) freeUsername(ctx context.Context, base string) (string, error) {
+	for i := 1; i <= 100; i++ {
+		username := base
+		if i > 1 {
+			username = base + "-" + strconv.Itoa(i)
+		}
+		_, err := s.users.GetUserByUsername(ctx, username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("no free username for %q", base)
+}
+
+// linkUsername - логин существующего пользователя для связывания. Email подходит только подтвержденный
+// и только в доменах из link_domains: логин - часть до @, так что домен сравнивается здесь
+func linkUsername(cfg oidc.ProviderConfig, claims oidc.Claims) string {
+	switch cfg.LinkBy {
+	case oidc.LinkByUsername:
+		return claims.PreferredUsername
+	case oidc.LinkByEmail:
+		local, domain, ok := strings.Cut(claims.Email, "@")
+		if claims.EmailVerified && ok && slices.Contains(cfg.LinkDomains, strings.ToLower(domain)) {
+			return local
+		}
+	}
+	return ""
+}
+
+// oidcUsername строит логин нового пользователя из preferred_username или части email до @
+// по правилам models.User: только латиница, цифры, '_', '.' и '-', от 3 до 32 символов с запасом на суффикс
+func oidcUsername(claims oidc.Claims) string {
+	username := claims.PreferredUsername
+	if username == "" {
+		username = emailLocalPart(claims.Email)
+	}
+	username = strings.Trim(usernameUnsafe.ReplaceAllString(username, "_"), "_")
+	if len(username) > 28 {
+		username = username[:28]
+	}
+	if len(username) < 3 {
+		username = "user" + username
+	}
+	return username
+}
+
+func emailLocalPart(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	return local
+}
+
+// sealOIDCLogin сериализует вход и подписывает его HMAC, чтобы браузер не мог подменить провайдера, nonce или verifier
+func sealOIDCLogin(login oidcLogin) (string, error) {
+	payload, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + oidcSignature(encoded), nil
+}
+
+func openOIDCLogin(session string) (oidcLogin, error) {
+	encoded, signature, ok := strings.Cut(session, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(oidcSignature(encoded))) {
+		return oidcLogin{}, ErrOIDCLoginExpired
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return oidcLogin{}, ErrOIDCLoginExpired
+	}
+
+	var login oidcLogin
+	if err := json.Unmarshal(payload, &login); err != nil || time.Now().Unix() > login.ExpiresAt {
+		return oidcLogin{}, ErrOIDCLoginExpired
+	}
+	return login, nil
+}
+
+func newLinkToken(identity models.Identity, user models.UserSummary) (string, error) {
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, &oidcLinkClaims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(OIDCLoginTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		UserId:   user.Id,
+		Username: user.Username,
+		Provider: identity.Provider,
+		Identity: identity.Subject,
+		Email:    identity.Email,
+	})
+
+	return jwtToken.SignedString(oidcLinkKey())
+}
+
+func parseLinkToken(linkToken string) (oidcLinkClaims, error) {
+	var claims oidcLinkClaims
+	_, err := jwt.ParseWithClaims(linkToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return oidcLinkKey(), nil
+	})
+	return claims, err
+}
+
+func oidcLinkKey() []byte {
+	return []byte("oidc-link:" + signingKey)
+}
+
+func oidcSignature(encoded string) string {
+	mac := hmac.New(sha256.New, []byte("oidc-login:"+signingKey))
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func randomToken() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err) // crypto/rand не возвращает ошибок на поддерживаемых платформах
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
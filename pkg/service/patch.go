@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+)
+
+var (
+	ErrUnsupportedPatch = errors.New("unsupported patch media type")
+	ErrMalformedPatch   = errors.New("malformed patch document")
+	ErrInvalidPatch     = errors.New("patch produces an invalid resource")
+	ErrPatchConflict    = errors.New("patch test operation failed")
+)
+
+// applyPatch применяет patch к текущему состоянию сущности current и записывает результат в out.
+// out должен быть указателем на нулевое значение того же типа - поля, удаленные патчем, останутся пустыми.
+func applyPatch(current interface{}, patch models.Patch, out interface{}) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	switch patch.Type {
+	case models.MergePatchType:
+		patched, err = jsonpatch.MergePatch(doc, patch.Body)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrMalformedPatch, err.Error())
+		}
+	case models.JSONPatchType:
+		ops, err := jsonpatch.DecodePatch(patch.Body)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrMalformedPatch, err.Error())
+		}
+		patched, err = ops.Apply(doc)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return fmt.Errorf("%w: %s", ErrPatchConflict, err.Error())
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedPatch, patch.Type)
+	}
+
+	// неизвестные поля и неверные типы значений - это ошибка патча, а не сервера
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+	}
+	return nil
+}
+
+// changedColumns сравнивает две структуры одного типа и возвращает измененные поля,
+// ключами выступают значения тегов db. Поле id изменять нельзя.
+func changedColumns(before, after interface{}) (map[string]interface{}, error) {
+	b := reflect.ValueOf(before)
+	a := reflect.ValueOf(after)
+	if b.Type() != a.Type() || b.Kind() != reflect.Struct {
+		return nil, errors.New("changedColumns: arguments must be structs of the same type")
+	}
+
+	changes := make(map[string]interface{})
+	for i := 0; i < b.NumField(); i++ {
+		column := b.Type().Field(i).Tag.Get("db")
+		if column == "" || column == "-" {
+			continue
+		}
+
+		oldValue, newValue := b.Field(i).Interface(), a.Field(i).Interface()
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if column == "id" {
+			return nil, fmt.Errorf("%w: id is read-only", ErrInvalidPatch)
+		}
+		changes[column] = newValue
+	}
+	return changes, nil
+}
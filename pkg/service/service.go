@@ -1,43 +1,165 @@
 package service
 
 import (
+	"context"
+
 	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/oidc"
+	"github.com/ponomare0v/todo-go-app/pkg/ratelimit"
 	"github.com/ponomare0v/todo-go-app/pkg/repository"
 )
 
 type Authorization interface {
-	CreateUser(user models.User) (int, error)
-	GenerateToken(username, password string) (string, error)
-	ParseToken(token string) (int, error)
+	CreateUser(ctx context.Context, user models.User) (int, error)
+	GenerateToken(ctx context.Context, username, password string) (string, error)
+	GenerateTokenWithCode(ctx context.Context, challengeToken, code string) (string, error)
+	ParseToken(ctx context.Context, token string) (models.Session, error)
+}
+
+// Account - самообслуживание пользователя через /api/me
+type Account interface {
+	GetProfile(ctx context.Context, userId int) (models.Profile, error)
+	UpdateProfile(ctx context.Context, userId int, input models.UpdateProfileInput) (models.Profile, error)
+	ChangePassword(ctx context.Context, userId int, input models.ChangePasswordInput) (string, error)
+	DeleteAccount(ctx context.Context, userId int, input models.DeleteAccountInput) error
+	ExportAccount(ctx context.Context, userId int) (models.AccountExport, error)
+}
+
+type Admin interface {
+	GetUsers(ctx context.Context) ([]models.UserSummary, error)
+	GetUser(ctx context.Context, username string) (models.UserSummary, error)
+	SetDisabled(ctx context.Context, username string, disabled bool) error
+	ResetPassword(ctx context.Context, username, password string) error
+	ResetTwoFactor(ctx context.Context, username string) error
+	TransferList(ctx context.Context, listId int, fromUsername, toUsername string) error
+	GetStats(ctx context.Context, username string) (models.UserStats, error)
+	PurgeUser(ctx context.Context, username string) error
+	GetTotals(ctx context.Context) (models.Totals, error)
 }
 
 type TodoList interface {
-	Create(userId int, list models.TodoList) (int, error)
-	GetAll(userId int) ([]models.TodoList, error)
-	GetById(userId, listId int) (models.TodoList, error)
-	Delete(userId, listId int) error
-	Update(userId, listId int, input models.UpdateListInput) error
+	Create(ctx context.Context, userId int, list models.TodoList) (int, error)
+	GetAll(ctx context.Context, userId int) ([]models.TodoList, error)
+	GetById(ctx context.Context, userId, listId int) (models.TodoList, error)
+	Search(ctx context.Context, userId int, query string) ([]models.TodoList, error)
+	Delete(ctx context.Context, userId, listId int) error
+	Update(ctx context.Context, userId, listId int, input models.UpdateListInput) error
+	Patch(ctx context.Context, userId, listId int, patch models.Patch) error
 }
 
 type TodoItem interface {
-	Create(userId, listId int, item models.TodoItem) (int, error)
-	GetAll(userId, listId int) ([]models.TodoItem, error)
-	GetById(userId, itemId int) (models.TodoItem, error)
-	Delete(userId, itemId int) error
-	Update(userId, itemId int, input models.UpdateItemInput) error
+	Create(ctx context.Context, userId, listId int, item models.TodoItem) (int, error)
+	GetAll(ctx context.Context, userId, listId int) ([]models.TodoItem, error)
+	GetAllByLists(ctx context.Context, userId int, listIds []int) (map[int][]models.TodoItem, error)
+	Search(ctx context.Context, userId int, query string) ([]models.TodoItem, error)
+	GetById(ctx context.Context, userId, itemId int) (models.TodoItem, error)
+	Delete(ctx context.Context, userId, itemId int) error
+	Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) error
+	Patch(ctx context.Context, userId, itemId int, patch models.Patch) error
+	Subscribe(ctx context.Context, userId, listId int) (<-chan models.ItemEvent, error)
+}
+
+// Backup - перенос данных пользователя в файлы и обратно (/api/export, /api/import)
+type Backup interface {
+	Export(ctx context.Context, userId int, emit func(models.ListBackup) error) error
+	Import(ctx context.Context, userId int, lists []models.ListBackup, dryRun bool) (models.ImportReport, error)
+}
+
+// Calendar - ICS-лента пользователя по секретной ссылке, без JWT
+type Calendar interface {
+	CreateFeedToken(ctx context.Context, userId int) (string, error)
+	DeleteFeedToken(ctx context.Context, userId int) error
+	GetFeedUser(ctx context.Context, token string) (int, error)
+	GetFeed(ctx context.Context, userId int, emit func(list models.TodoList, items []models.TodoItem) error) error
+}
+
+// CalDAV - синхронизация списков с клиентами задач по CalDAV и пароли приложений для входа по HTTP Basic
+type CalDAV interface {
+	CreateAppPassword(ctx context.Context, userId int, password models.AppPassword) (models.AppPassword, error)
+	GetAppPasswords(ctx context.Context, userId int) ([]models.AppPassword, error)
+	DeleteAppPassword(ctx context.Context, userId, id int) error
+	Authenticate(ctx context.Context, username, password string) (int, error)
+
+	GetCalendars(ctx context.Context, userId int) ([]models.Calendar, error)
+	GetCalendar(ctx context.Context, userId, listId int) (models.Calendar, []models.CalendarObject, error)
+	GetObject(ctx context.Context, userId, listId int, name string) (models.TodoList, models.CalendarObject, error)
+	PutObject(ctx context.Context, userId, listId int, name string, object models.CalendarObject, ifMatch, ifNoneMatch string) (bool, error)
+	DeleteObject(ctx context.Context, userId, listId int, name, ifMatch string) error
+}
+
+// ImportJob - фоновый импорт из Trello и Todoist (/api/imports). ProcessImportJob вызывает фоновый обработчик.
+type ImportJob interface {
+	CreateImportJob(ctx context.Context, userId int, source string, lists []models.ListBackup) (models.ImportJob, models.ImportReport, error)
+	GetImportJobs(ctx context.Context, userId int) ([]models.ImportJob, error)
+	GetImportJob(ctx context.Context, userId, id int) (models.ImportJob, error)
+	ProcessImportJob(ctx context.Context) (bool, error)
+}
+
+// AccessToken - персональные токены доступа для скриптов (/api/me/tokens)
+type AccessToken interface {
+	CreateAccessToken(ctx context.Context, userId int, token models.AccessToken) (models.AccessToken, error)
+	GetAccessTokens(ctx context.Context, userId int) ([]models.AccessToken, error)
+	DeleteAccessToken(ctx context.Context, userId, id int) error
+	Authenticate(ctx context.Context, token string) (models.AccessToken, error)
+}
+
+// OIDC - вход через провайдеров OpenID Connect (/auth/oidc), заканчивается выпуском того же JWT, что и /auth/sign-in
+type OIDC interface {
+	GetOIDCProviders(ctx context.Context) ([]string, error)
+	StartOIDCLogin(ctx context.Context, provider, redirectURL string) (authURL, session string, err error)
+	FinishOIDCLogin(ctx context.Context, session, state, code, redirectURL string) (string, error)
+	LinkOIDCIdentity(ctx context.Context, linkToken, password string) (string, error)
+}
+
+// TwoFactor - подключение и отключение TOTP (/api/me/2fa); код при входе проверяет Authorization.GenerateTokenWithCode
+type TwoFactor interface {
+	GetTwoFactor(ctx context.Context, userId int) (models.TwoFactorStatus, error)
+	EnrollTwoFactor(ctx context.Context, userId int) (models.TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, userId int, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userId int, code string) error
+}
+
+// Config - зависимости сервисов, которые не относятся к хранилищу
+type Config struct {
+	Lockout *ratelimit.Lockout // блокировка входа после неудачных попыток, nil - без блокировки
+	OIDC    *oidc.Providers    // провайдеры OpenID Connect, nil - вход через OIDC выключен
 }
 
 // структура сервис собирает все сервисы в одном месте
 type Service struct {
 	Authorization
+	Admin
 	TodoList
 	TodoItem
+	Backup
+	Calendar
+	CalDAV
+	ImportJob
+	AccessToken
+	OIDC
+	TwoFactor
+	Account
 }
 
-func NewService(repos *repository.Repository) *Service {
+func NewService(repos *repository.Repository, cfg Config) *Service {
+	// CalDAV работает поверх тех же сервисов списков и задач, чтобы подписчики получали события и о его изменениях
+	events := newItemEvents()
+	lists := NewTodoListService(repos.TodoList, events)
+	items := NewTodoItemService(repos.TodoItem, repos.TodoList, events)
+
 	return &Service{
-		Authorization: NewAuthService(repos.Authorization),
-		TodoList:      NewTodoListService(repos.TodoList),
-		TodoItem:      NewTodoItemService(repos.TodoItem, repos.TodoList),
+		Authorization: NewAuthService(repos.Authorization, repos.Account, repos.TwoFactor, cfg.Lockout),
+		Admin:         NewAdminService(repos.Admin, repos.TwoFactor),
+		TodoList:      lists,
+		TodoItem:      items,
+		Backup:        NewBackupService(repos.Backup, repos.TodoList, repos.TodoItem),
+		Calendar:      NewCalendarService(repos.CalendarFeed, repos.TodoList, repos.TodoItem),
+		CalDAV:        NewCalDAVService(repos.AppPassword, repos.CalDAV, lists, items),
+		ImportJob:     NewImportJobService(repos.ImportJob),
+		AccessToken:   NewAccessTokenService(repos.AccessToken),
+		OIDC:          NewOIDCService(cfg.OIDC, repos.Identity, repos.Admin, repos.Account, repos.TwoFactor, cfg.Lockout),
+		TwoFactor:     NewTwoFactorService(repos.TwoFactor, repos.Admin),
+		Account: NewAccountService(repos.Account, repos.Admin, repos.Identity, repos.AccessToken,
+			repos.AppPassword, repos.ImportJob, repos.TwoFactor, cfg.Lockout),
 	}
 }
@@ -1,40 +1,190 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
 	"github.com/ponomare0v/todo-go-app/pkg/models"
 	"github.com/ponomare0v/todo-go-app/pkg/repository"
 )
 
+// ErrItemNotFound - задача не существует или недоступна пользователю
+var ErrItemNotFound = errors.New("item not found")
+
 type TodoItemService struct {
 	repo     repository.TodoItem
 	listRepo repository.TodoList
+	events   *itemEvents
 }
 
-func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList) *TodoItemService {
-	return &TodoItemService{repo: repo, listRepo: listRepo}
+func NewTodoItemService(repo repository.TodoItem, listRepo repository.TodoList, events *itemEvents) *TodoItemService {
+	return &TodoItemService{repo: repo, listRepo: listRepo, events: events}
 }
 
-func (s *TodoItemService) Create(userId, listId int, item models.TodoItem) (int, error) {
-	_, err := s.listRepo.GetById(userId, listId) //проверка на сущ списка и принадлежности пользователю
+func (s *TodoItemService) Create(ctx context.Context, userId, listId int, item models.TodoItem) (int, error) {
+	item.Normalize()
+	if err := item.Validate(); err != nil {
+		return 0, err
+	}
+
+	_, err := s.listRepo.GetById(ctx, userId, listId) //проверка на сущ списка и принадлежности пользователю
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.Create(ctx, listId, item)
 	if err != nil {
 		return 0, err
 	}
 
-	return s.repo.Create(listId, item)
+	item.Id = id
+	s.events.publish(models.ItemEvent{Type: models.ItemCreated, ListId: listId, Item: item})
+	return id, nil
 }
 
-func (s *TodoItemService) GetAll(userId, listId int) ([]models.TodoItem, error) {
-	return s.repo.GetAll(userId, listId)
+func (s *TodoItemService) GetAll(ctx context.Context, userId, listId int) ([]models.TodoItem, error) {
+	return s.repo.GetAll(ctx, userId, listId)
 }
 
-func (s *TodoItemService) GetById(userId, itemId int) (models.TodoItem, error) {
-	return s.repo.GetById(userId, itemId)
+func (s *TodoItemService) GetById(ctx context.Context, userId, itemId int) (models.TodoItem, error) {
+	return s.repo.GetById(ctx, userId, itemId)
 }
 
-func (s *TodoItemService) Delete(userId, itemId int) error {
-	return s.repo.Delete(userId, itemId)
+func (s *TodoItemService) Delete(ctx context.Context, userId, itemId int) error {
+	// id списка нужно узнать до удаления, потом связь в lists_items уже пропадет.
+	// Если задача недоступна, удалять и оповещать нечего, но поведение Delete сохраняем прежним.
+	listId, lookupErr := s.repo.GetListId(ctx, userId, itemId)
+
+	if err := s.repo.Delete(ctx, userId, itemId); err != nil {
+		return err
+	}
+	if lookupErr != nil {
+		return nil
+	}
+
+	s.events.publish(models.ItemEvent{Type: models.ItemDeleted, ListId: listId, Item: models.TodoItem{Id: itemId}})
+	return nil
 }
 
-func (s *TodoItemService) Update(userId, itemId int, input models.UpdateItemInput) error {
-	return s.repo.Update(userId, itemId, input)
+func (s *TodoItemService) Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) error {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, userId, itemId, input); err != nil {
+		return err
+	}
+
+	s.publishUpdated(ctx, userId, itemId)
+	return nil
+}
+
+func (s *TodoItemService) Patch(ctx context.Context, userId, itemId int, patch models.Patch) error {
+	current, err := s.repo.GetById(ctx, userId, itemId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrItemNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var patched models.TodoItem
+	if err := applyPatch(current, patch, &patched); err != nil {
+		return err
+	}
+	patched.Normalize()
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+
+	changes, err := changedColumns(current, patched)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := s.repo.Patch(ctx, userId, itemId, changes); err != nil {
+		return err
+	}
+
+	s.publishUpdated(ctx, userId, itemId)
+	return nil
+}
+
+func (s *TodoItemService) GetAllByLists(ctx context.Context, userId int, listIds []int) (map[int][]models.TodoItem, error) {
+	if len(listIds) == 0 {
+		return map[int][]models.TodoItem{}, nil
+	}
+	return s.repo.GetAllByLists(ctx, userId, listIds)
+}
+
+func (s *TodoItemService) Search(ctx context.Context, userId int, query string) ([]models.TodoItem, error) {
+	return s.repo.Search(ctx, userId, strings.TrimSpace(query))
+}
+
+// Subscribe подписывает пользователя на изменения задач в списке listId (0 - во всех доступных ему списках).
+// Канал закрывается, когда отменяется ctx, а для одного списка - еще и когда пользователь теряет к нему доступ
+// или список удаляют.
+func (s *TodoItemService) Subscribe(ctx context.Context, userId, listId int) (<-chan models.ItemEvent, error) {
+	if listId != 0 {
+		if _, err := s.listRepo.GetById(ctx, userId, listId); err != nil {
+			return nil, err
+		}
+	}
+
+	events, unsubscribe := s.events.subscribe()
+	out := make(chan models.ItemEvent, eventBufferSize)
+
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+
+		for {
+			var event models.ItemEvent
+			select {
+			case <-ctx.Done():
+				return
+			case event = <-events:
+			}
+
+			if listId != 0 && event.ListId != listId {
+				continue
+			}
+			// доступ к списку могут отозвать или удалить список уже после подписки, поэтому он проверяется на каждое событие
+			_, err := s.listRepo.GetById(ctx, userId, event.ListId)
+			if listId != 0 && errors.Is(err, sql.ErrNoRows) {
+				return
+			}
+			if err != nil || event.Type == models.ListDeleted {
+				continue
+			}
+
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// publishUpdated рассылает актуальное состояние задачи после изменения
+func (s *TodoItemService) publishUpdated(ctx context.Context, userId, itemId int) {
+	listId, err := s.repo.GetListId(ctx, userId, itemId)
+	if err != nil {
+		return
+	}
+	item, err := s.repo.GetById(ctx, userId, itemId)
+	if err != nil {
+		return
+	}
+
+	s.events.publish(models.ItemEvent{Type: models.ItemUpdated, ListId: listId, Item: item})
 }
@@ -1,37 +1,88 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
 	"github.com/ponomare0v/todo-go-app/pkg/models"
 	"github.com/ponomare0v/todo-go-app/pkg/repository"
 )
 
+// ErrListNotFound - список не существует или недоступен пользователю
+var ErrListNotFound = errors.New("list not found")
+
 type TodoListService struct {
-	repo repository.TodoList
+	repo   repository.TodoList
+	events *itemEvents
 }
 
-func NewTodoListService(repo repository.TodoList) *TodoListService {
-	return &TodoListService{repo: repo}
+func NewTodoListService(repo repository.TodoList, events *itemEvents) *TodoListService {
+	return &TodoListService{repo: repo, events: events}
 }
 
-func (s *TodoListService) Create(userId int, list models.TodoList) (int, error) {
-	return s.repo.Create(userId, list)
+func (s *TodoListService) Create(ctx context.Context, userId int, list models.TodoList) (int, error) {
+	list.Normalize()
+	if err := list.Validate(); err != nil {
+		return 0, err
+	}
+	return s.repo.Create(ctx, userId, list)
 }
 
-func (s *TodoListService) GetAll(userId int) ([]models.TodoList, error) {
-	return s.repo.GetAll(userId)
+func (s *TodoListService) GetAll(ctx context.Context, userId int) ([]models.TodoList, error) {
+	return s.repo.GetAll(ctx, userId)
 }
 
-func (s *TodoListService) GetById(userId, listId int) (models.TodoList, error) {
-	return s.repo.GetById(userId, listId)
+func (s *TodoListService) GetById(ctx context.Context, userId, listId int) (models.TodoList, error) {
+	return s.repo.GetById(ctx, userId, listId)
 }
 
-func (s *TodoListService) Delete(userId, listId int) error {
-	return s.repo.Delete(userId, listId)
+// Delete удаляет список; подписки на его задачи после этого закрываются
+func (s *TodoListService) Delete(ctx context.Context, userId, listId int) error {
+	if err := s.repo.Delete(ctx, userId, listId); err != nil {
+		return err
+	}
+	s.events.publish(models.ItemEvent{Type: models.ListDeleted, ListId: listId})
+	return nil
 }
 
-func (s *TodoListService) Update(userId, listId int, input models.UpdateListInput) error {
+func (s *TodoListService) Update(ctx context.Context, userId, listId int, input models.UpdateListInput) error {
+	input.Normalize()
 	if err := input.Validate(); err != nil {
 		return err
 	}
-	return s.repo.Update(userId, listId, input)
+	return s.repo.Update(ctx, userId, listId, input)
+}
+
+func (s *TodoListService) Patch(ctx context.Context, userId, listId int, patch models.Patch) error {
+	current, err := s.repo.GetById(ctx, userId, listId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrListNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var patched models.TodoList
+	if err := applyPatch(current, patch, &patched); err != nil {
+		return err
+	}
+	patched.Normalize()
+	if err := patched.Validate(); err != nil {
+		return err
+	}
+
+	changes, err := changedColumns(current, patched)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.repo.Patch(ctx, userId, listId, changes)
+}
+
+func (s *TodoListService) Search(ctx context.Context, userId int, query string) ([]models.TodoList, error) {
+	return s.repo.Search(ctx, userId, strings.TrimSpace(query))
 }
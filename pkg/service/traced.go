@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ponomare0v/todo-go-app/pkg/service")
+
+// Trace оборачивает сервисы так, что каждый вызов метода становится спаном AuthService.CreateUser,
+// TodoItemService.Create и т.д. Запросы в БД, сделанные внутри метода, попадают в трейс дочерними спанами.
+func Trace(services *Service) *Service {
+	return &Service{
+		Authorization: &authTraced{next: services.Authorization},
+		Admin:         &adminTraced{next: services.Admin},
+		TodoList:      &todoListTraced{next: services.TodoList},
+		TodoItem:      &todoItemTraced{next: services.TodoItem},
+		Backup:        &backupTraced{next: services.Backup},
+		Calendar:      &calendarTraced{next: services.Calendar},
+		CalDAV:        &caldavTraced{next: services.CalDAV},
+		ImportJob:     &importJobTraced{next: services.ImportJob},
+		AccessToken:   &accessTokenTraced{next: services.AccessToken},
+		OIDC:          &oidcTraced{next: services.OIDC},
+		TwoFactor:     &twoFactorTraced{next: services.TwoFactor},
+		Account:       &accountTraced{next: services.Account},
+	}
+}
+
+// endSpan вызывается через defer и получает ошибку по указателю - к моменту вызова она уже записана
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+type authTraced struct {
+	next Authorization
+}
+
+func (s *authTraced) CreateUser(ctx context.Context, user models.User) (res int, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.CreateUser")
+	defer endSpan(span, &err)
+	return s.next.CreateUser(ctx, user)
+}
+
+func (s *authTraced) GenerateToken(ctx context.Context, username, password string) (res string, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.GenerateToken")
+	defer endSpan(span, &err)
+	return s.next.GenerateToken(ctx, username, password)
+}
+
+func (s *authTraced) GenerateTokenWithCode(ctx context.Context, challengeToken, code string) (res string, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.GenerateTokenWithCode")
+	defer endSpan(span, &err)
+	return s.next.GenerateTokenWithCode(ctx, challengeToken, code)
+}
+
+func (s *authTraced) ParseToken(ctx context.Context, token string) (res models.Session, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.ParseToken")
+	defer endSpan(span, &err)
+	return s.next.ParseToken(ctx, token)
+}
+
+type adminTraced struct {
+	next Admin
+}
+
+func (s *adminTraced) GetUsers(ctx context.Context) (res []models.UserSummary, err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.GetUsers")
+	defer endSpan(span, &err)
+	return s.next.GetUsers(ctx)
+}
+
+func (s *adminTraced) GetUser(ctx context.Context, username string) (res models.UserSummary, err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.GetUser")
+	defer endSpan(span, &err)
+	return s.next.GetUser(ctx, username)
+}
+
+func (s *adminTraced) SetDisabled(ctx context.Context, username string, disabled bool) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.SetDisabled")
+	defer endSpan(span, &err)
+	return s.next.SetDisabled(ctx, username, disabled)
+}
+
+func (s *adminTraced) ResetPassword(ctx context.Context, username, password string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.ResetPassword")
+	defer endSpan(span, &err)
+	return s.next.ResetPassword(ctx, username, password)
+}
+
+func (s *adminTraced) ResetTwoFactor(ctx context.Context, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.ResetTwoFactor")
+	defer endSpan(span, &err)
+	return s.next.ResetTwoFactor(ctx, username)
+}
+
+func (s *adminTraced) TransferList(ctx context.Context, listId int, fromUsername, toUsername string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.TransferList")
+	defer endSpan(span, &err)
+	return s.next.TransferList(ctx, listId, fromUsername, toUsername)
+}
+
+func (s *adminTraced) GetStats(ctx context.Context, username string) (res models.UserStats, err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.GetStats")
+	defer endSpan(span, &err)
+	return s.next.GetStats(ctx, username)
+}
+
+func (s *adminTraced) PurgeUser(ctx context.Context, username string) (err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.PurgeUser")
+	defer endSpan(span, &err)
+	return s.next.PurgeUser(ctx, username)
+}
+
+func (s *adminTraced) GetTotals(ctx context.Context) (res models.Totals, err error) {
+	ctx, span := tracer.Start(ctx, "AdminService.GetTotals")
+	defer endSpan(span, &err)
+	return s.next.GetTotals(ctx)
+}
+
+type todoListTraced struct {
+	next TodoList
+}
+
+func (s *todoListTraced) Create(ctx context.Context, userId int, list models.TodoList) (res int, err error) {
+	ctx, span := tracer.Start(ctx, "TodoListService.Create")
+	defer endSpan(span, &err)
+	return s.next.Create(ctx, userId, list)
+}
+
+func (s *todoListTraced) GetAll(ctx context.Context, userId int) (res []models.TodoList, err error) {
+	ctx, span := tracer.Start(ctx, "TodoListService.GetAll")
+	defer endSpan(span, &err)
+	return s.next.GetAll(ctx, userId)
+}
+
+func (s *todoListTraced) GetById(ctx context.Context, userId, listId int) (res models.TodoList, err error) {
+	ctx, span := tracer.Start(ctx, "TodoListService.GetById")
+	defer endSpan(span, &err)
+	return s.next.GetById(ctx, userId, listId)
+}
+
+func (s *todoListTraced) Search(ctx context.Context, userId int, query string) (res []models.TodoList, err error) {
+	ctx, span := tracer.Start(ctx, "TodoListService.Search")
+	defer endSpan(span, &err)
+	return s.next.Search(ctx, userId, query)
+}
+
+func (s *todoListTraced) Delete(ctx context.Context, userId, listId int) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoListService.Delete")
+	defer endSpan(span, &err)
+	return s.next.Delete(ctx, userId, listId)
+}
+
+func (s *todoListTraced) Update(ctx context.Context, userId, listId int, input models.UpdateListInput) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoListService.Update")
+	defer endSpan(span, &err)
+	return s.next.Update(ctx, userId, listId, input)
+}
+
+func (s *todoListTraced) Patch(ctx context.Context, userId, listId int, patch models.Patch) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoListService.Patch")
+	defer endSpan(span, &err)
+	return s.next.Patch(ctx, userId, listId, patch)
+}
+
+type todoItemTraced struct {
+	next TodoItem
+}
+
+func (s *todoItemTraced) Create(ctx context.Context, userId, listId int, item models.TodoItem) (res int, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.Create")
+	defer endSpan(span, &err)
+	return s.next.Create(ctx, userId, listId, item)
+}
+
+func (s *todoItemTraced) GetAll(ctx context.Context, userId, listId int) (res []models.TodoItem, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.GetAll")
+	defer endSpan(span, &err)
+	return s.next.GetAll(ctx, userId, listId)
+}
+
+func (s *todoItemTraced) GetAllByLists(ctx context.Context, userId int, listIds []int) (res map[int][]models.TodoItem, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.GetAllByLists")
+	defer endSpan(span, &err)
+	return s.next.GetAllByLists(ctx, userId, listIds)
+}
+
+func (s *todoItemTraced) Search(ctx context.Context, userId int, query string) (res []models.TodoItem, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.Search")
+	defer endSpan(span, &err)
+	return s.next.Search(ctx, userId, query)
+}
+
+func (s *todoItemTraced) GetById(ctx context.Context, userId, itemId int) (res models.TodoItem, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.GetById")
+	defer endSpan(span, &err)
+	return s.next.GetById(ctx, userId, itemId)
+}
+
+func (s *todoItemTraced) Delete(ctx context.Context, userId, itemId int) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.Delete")
+	defer endSpan(span, &err)
+	return s.next.Delete(ctx, userId, itemId)
+}
+
+func (s *todoItemTraced) Update(ctx context.Context, userId, itemId int, input models.UpdateItemInput) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.Update")
+	defer endSpan(span, &err)
+	return s.next.Update(ctx, userId, itemId, input)
+}
+
+func (s *todoItemTraced) Patch(ctx context.Context, userId, itemId int, patch models.Patch) (err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.Patch")
+	defer endSpan(span, &err)
+	return s.next.Patch(ctx, userId, itemId, patch)
+}
+
+func (s *todoItemTraced) Subscribe(ctx context.Context, userId, listId int) (res <-chan models.ItemEvent, err error) {
+	ctx, span := tracer.Start(ctx, "TodoItemService.Subscribe")
+	defer endSpan(span, &err)
+	return s.next.Subscribe(ctx, userId, listId)
+}
+
+type backupTraced struct {
+	next Backup
+}
+
+func (s *backupTraced) Export(ctx context.Context, userId int, emit func(models.ListBackup) error) (err error) {
+	ctx, span := tracer.Start(ctx, "BackupService.Export")
+	defer endSpan(span, &err)
+	return s.next.Export(ctx, userId, emit)
+}
+
+func (s *backupTraced) Import(ctx context.Context, userId int, lists []models.ListBackup, dryRun bool) (res models.ImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "BackupService.Import")
+	defer endSpan(span, &err)
+	return s.next.Import(ctx, userId, lists, dryRun)
+}
+
+type calendarTraced struct {
+	next Calendar
+}
+
+func (s *calendarTraced) CreateFeedToken(ctx context.Context, userId int) (res string, err error) {
+	ctx, span := tracer.Start(ctx, "CalendarService.CreateFeedToken")
+	defer endSpan(span, &err)
+	return s.next.CreateFeedToken(ctx, userId)
+}
+
+func (s *calendarTraced) DeleteFeedToken(ctx context.Context, userId int) (err error) {
+	ctx, span := tracer.Start(ctx, "CalendarService.DeleteFeedToken")
+	defer endSpan(span, &err)
+	return s.next.DeleteFeedToken(ctx, userId)
+}
+
+func (s *calendarTraced) GetFeedUser(ctx context.Context, token string) (res int, err error) {
+	ctx, span := tracer.Start(ctx, "CalendarService.GetFeedUser")
+	defer endSpan(span, &err)
+	return s.next.GetFeedUser(ctx, token)
+}
+
+func (s *calendarTraced) GetFeed(ctx context.Context, userId int, emit func(list models.TodoList, items []models.TodoItem) error) (err error) {
+	ctx, span := tracer.Start(ctx, "CalendarService.GetFeed")
+	defer endSpan(span, &err)
+	return s.next.GetFeed(ctx, userId, emit)
+}
+
+type caldavTraced struct {
+	next CalDAV
+}
+
+func (s *caldavTraced) CreateAppPassword(ctx context.Context, userId int, password models.AppPassword) (res models.AppPassword, err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.CreateAppPassword")
+	defer endSpan(span, &err)
+	return s.next.CreateAppPassword(ctx, userId, password)
+}
+
+func (s *caldavTraced) GetAppPasswords(ctx context.Context, userId int) (res []models.AppPassword, err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.GetAppPasswords")
+	defer endSpan(span, &err)
+	return s.next.GetAppPasswords(ctx, userId)
+}
+
+func (s *caldavTraced) DeleteAppPassword(ctx context.Context, userId, id int) (err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.DeleteAppPassword")
+	defer endSpan(span, &err)
+	return s.next.DeleteAppPassword(ctx, userId, id)
+}
+
+func (s *caldavTraced) Authenticate(ctx context.Context, username, password string) (res int, err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.Authenticate")
+	defer endSpan(span, &err)
+	return s.next.Authenticate(ctx, username, password)
+}
+
+func (s *caldavTraced) GetCalendars(ctx context.Context, userId int) (res []models.Calendar, err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.GetCalendars")
+	defer endSpan(span, &err)
+	return s.next.GetCalendars(ctx, userId)
+}
+
+func (s *caldavTraced) GetCalendar(ctx context.Context, userId, listId int) (res models.Calendar, objects []models.CalendarObject, err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.GetCalendar")
+	defer endSpan(span, &err)
+	return s.next.GetCalendar(ctx, userId, listId)
+}
+
+func (s *caldavTraced) GetObject(ctx context.Context, userId, listId int, name string) (list models.TodoList, res models.CalendarObject, err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.GetObject")
+	defer endSpan(span, &err)
+	return s.next.GetObject(ctx, userId, listId, name)
+}
+
+func (s *caldavTraced) PutObject(ctx context.Context, userId, listId int, name string, object models.CalendarObject, ifMatch, ifNoneMatch string) (res bool, err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.PutObject")
+	defer endSpan(span, &err)
+	return s.next.PutObject(ctx, userId, listId, name, object, ifMatch, ifNoneMatch)
+}
+
+func (s *caldavTraced) DeleteObject(ctx context.Context, userId, listId int, name, ifMatch string) (err error) {
+	ctx, span := tracer.Start(ctx, "CalDAVService.DeleteObject")
+	defer endSpan(span, &err)
+	return s.next.DeleteObject(ctx, userId, listId, name, ifMatch)
+}
+
+type importJobTraced struct {
+	next ImportJob
+}
+
+func (s *importJobTraced) CreateImportJob(ctx context.Context, userId int, source string, lists []models.ListBackup) (res models.ImportJob, report models.ImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "ImportJobService.CreateImportJob")
+	defer endSpan(span, &err)
+	return s.next.CreateImportJob(ctx, userId, source, lists)
+}
+
+func (s *importJobTraced) GetImportJobs(ctx context.Context, userId int) (res []models.ImportJob, err error) {
+	ctx, span := tracer.Start(ctx, "ImportJobService.GetImportJobs")
+	defer endSpan(span, &err)
+	return s.next.GetImportJobs(ctx, userId)
+}
+
+func (s *importJobTraced) GetImportJob(ctx context.Context, userId, id int) (res models.ImportJob, err error) {
+	ctx, span := tracer.Start(ctx, "ImportJobService.GetImportJob")
+	defer endSpan(span, &err)
+	return s.next.GetImportJob(ctx, userId, id)
+}
+
+func (s *importJobTraced) ProcessImportJob(ctx context.Context) (res bool, err error) {
+	ctx, span := tracer.Start(ctx, "ImportJobService.ProcessImportJob")
+	defer endSpan(span, &err)
+	return s.next.ProcessImportJob(ctx)
+}
+
+type accessTokenTraced struct {
+	next AccessToken
+}
+
+func (s *accessTokenTraced) CreateAccessToken(ctx context.Context, userId int, token models.AccessToken) (res models.AccessToken, err error) {
+	ctx, span := tracer.Start(ctx, "AccessTokenService.CreateAccessToken")
+	defer endSpan(span, &err)
+	return s.next.CreateAccessToken(ctx, userId, token)
+}
+
+func (s *accessTokenTraced) GetAccessTokens(ctx context.Context, userId int) (res []models.AccessToken, err error) {
+	ctx, span := tracer.Start(ctx, "AccessTokenService.GetAccessTokens")
+	defer endSpan(span, &err)
+	return s.next.GetAccessTokens(ctx, userId)
+}
+
+func (s *accessTokenTraced) DeleteAccessToken(ctx context.Context, userId, id int) (err error) {
+	ctx, span := tracer.Start(ctx, "AccessTokenService.DeleteAccessToken")
+	defer endSpan(span, &err)
+	return s.next.DeleteAccessToken(ctx, userId, id)
+}
+
+func (s *accessTokenTraced) Authenticate(ctx context.Context, token string) (res models.AccessToken, err error) {
+	ctx, span := tracer.Start(ctx, "AccessTokenService.Authenticate")
+	defer endSpan(span, &err)
+	return s.next.Authenticate(ctx, token)
+}
+
+type oidcTraced struct {
+	next OIDC
+}
+
+func (s *oidcTraced) GetOIDCProviders(ctx context.Context) (res []string, err error) {
+	ctx, span := tracer.Start(ctx, "OIDCService.GetOIDCProviders")
+	defer endSpan(span, &err)
+	return s.next.GetOIDCProviders(ctx)
+}
+
+func (s *oidcTraced) StartOIDCLogin(ctx context.Context, provider, redirectURL string) (authURL, session string, err error) {
+	ctx, span := tracer.Start(ctx, "OIDCService.StartOIDCLogin")
+	defer endSpan(span, &err)
+	return s.next.StartOIDCLogin(ctx, provider, redirectURL)
+}
+
+func (s *oidcTraced) FinishOIDCLogin(ctx context.Context, session, state, code, redirectURL string) (res string, err error) {
+	ctx, span := tracer.Start(ctx, "OIDCService.FinishOIDCLogin")
+	defer endSpan(span, &err)
+	return s.next.FinishOIDCLogin(ctx, session, state, code, redirectURL)
+}
+
+func (s *oidcTraced) LinkOIDCIdentity(ctx context.Context, linkToken, password string) (res string, err error) {
+	ctx, span := tracer.Start(ctx, "OIDCService.LinkOIDCIdentity")
+	defer endSpan(span, &err)
+	return s.next.LinkOIDCIdentity(ctx, linkToken, password)
+}
+
+type twoFactorTraced struct {
+	next TwoFactor
+}
+
+func (s *twoFactorTraced) GetTwoFactor(ctx context.Context, userId int) (res models.TwoFactorStatus, err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.GetTwoFactor")
+	defer endSpan(span, &err)
+	return s.next.GetTwoFactor(ctx, userId)
+}
+
+func (s *twoFactorTraced) EnrollTwoFactor(ctx context.Context, userId int) (res models.TwoFactorEnrollment, err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.EnrollTwoFactor")
+	defer endSpan(span, &err)
+	return s.next.EnrollTwoFactor(ctx, userId)
+}
+
+func (s *twoFactorTraced) ConfirmTwoFactor(ctx context.Context, userId int, code string) (res []string, err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.ConfirmTwoFactor")
+	defer endSpan(span, &err)
+	return s.next.ConfirmTwoFactor(ctx, userId, code)
+}
+
+func (s *twoFactorTraced) DisableTwoFactor(ctx context.Context, userId int, code string) (err error) {
+	ctx, span := tracer.Start(ctx, "TwoFactorService.DisableTwoFactor")
+	defer endSpan(span, &err)
+	return s.next.DisableTwoFactor(ctx, userId, code)
+}
+
+type accountTraced struct {
+	next Account
+}
+
+func (s *accountTraced) GetProfile(ctx context.Context, userId int) (res models.Profile, err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.GetProfile")
+	defer endSpan(span, &err)
+	return s.next.GetProfile(ctx, userId)
+}
+
+func (s *accountTraced) UpdateProfile(ctx context.Context, userId int, input models.UpdateProfileInput) (res models.Profile, err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.UpdateProfile")
+	defer endSpan(span, &err)
+	return s.next.UpdateProfile(ctx, userId, input)
+}
+
+func (s *accountTraced) ChangePassword(ctx context.Context, userId int, input models.ChangePasswordInput) (res string, err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.ChangePassword")
+	defer endSpan(span, &err)
+	return s.next.ChangePassword(ctx, userId, input)
+}
+
+func (s *accountTraced) DeleteAccount(ctx context.Context, userId int, input models.DeleteAccountInput) (err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.DeleteAccount")
+	defer endSpan(span, &err)
+	return s.next.DeleteAccount(ctx, userId, input)
+}
+
+func (s *accountTraced) ExportAccount(ctx context.Context, userId int) (res models.AccountExport, err error) {
+	ctx, span := tracer.Start(ctx, "AccountService.ExportAccount")
+	defer endSpan(span, &err)
+	return s.next.ExportAccount(ctx, userId)
+}
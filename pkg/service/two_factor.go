@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ponomare0v/todo-go-app/pkg/models"
+	"github.com/ponomare0v/todo-go-app/pkg/repository"
+	"github.com/ponomare0v/todo-go-app/pkg/totp"
+)
+
+const (
+	totpIssuer = "todo-go-app" // подпись аккаунта в приложении-аутентификаторе
+
+	// challengeTTL - сколько после верного пароля можно ввести код
+	challengeTTL      = 5 * time.Minute
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 5 // байт, 8 символов base32
+)
+
+var (
+	ErrTwoFactorRequired    = errors.New("two-factor code required")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment is not started")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("sign-in challenge is invalid or expired, sign in again")
+)
+
+// TwoFactorRequiredError - пароль верный, но у пользователя включена 2FA: вход завершается
+// в GenerateTokenWithCode с ChallengeToken и кодом из приложения или кодом восстановления
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorRequiredError) Unwrap() error {
+	return ErrTwoFactorRequired
+}
+
+// challengeClaims подписываются другим ключом, чем tokenClaims, поэтому ParseToken не примет challenge вместо JWT
+type challengeClaims struct {
+	jwt.StandardClaims
+	UserId     int    `json:"user_id"`
+	Username   string `json:"username"`    // ключ блокировки входа, как в GenerateToken
+	AuthMethod string `json:"auth_method"` // переходит в JWT после ввода кода
+}
+
+// TwoFactorService - подключение и отключение TOTP. Код при входе проверяет AuthService.GenerateTokenWithCode.
+type TwoFactorService struct {
+	repo  repository.TwoFactor
+	users repository.Admin
+}
+
+func NewTwoFactorService(repo repository.TwoFactor, users repository.Admin) *TwoFactorService {
+	return &TwoFactorService{repo: repo, users: users}
+}
+
+func (s *TwoFactorService) GetTwoFactor(ctx context.Context, userId int) (models.TwoFactorStatus, error) {
+	return twoFactorStatus(ctx, s.repo, userId)
+}
+
+func twoFactorStatus(ctx context.Context, repo repository.TwoFactor, userId int) (models.TwoFactorStatus, error) {
+	enrolled, err := repo.GetTOTP(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !enrolled.Enabled()) {
+		return models.TwoFactorStatus{}, nil
+	}
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+
+	left, err := repo.CountRecoveryCodes(ctx, userId)
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+	return models.TwoFactorStatus{Enabled: true, RecoveryCodesLeft: left}, nil
+}
+
+// EnrollTwoFactor создает новый секрет. 2FA включится только после ConfirmTwoFactor, до этого
+// повторный вызов просто заменяет секрет - например, если QR-код не удалось отсканировать.
+func (s *TwoFactorService) EnrollTwoFactor(ctx context.Context, userId int) (models.TwoFactorEnrollment, error) {
+	existing, err := s.repo.GetTOTP(ctx, userId)
+	if err == nil && existing.Enabled() {
+		return models.TwoFactorEnrollment{}, ErrTwoFactorEnabled
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.TwoFactorEnrollment{}, err
+	}
+
+	user, err := s.users.GetUserById(ctx, userId)
+	if err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+
+	enrolled := models.TOTP{UserId: userId, Secret: secret, CreatedAt: time.Now().UTC().Truncate(time.Second)}
+	if err := s.repo.SetTOTP(ctx, enrolled); err != nil {
+		return models.TwoFactorEnrollment{}, err
+	}
+	return models.TwoFactorEnrollment{Secret: secret, URI: totp.URI(totpIssuer, user.Username, secret)}, nil
+}
+
+// ConfirmTwoFactor включает 2FA по первому коду из приложения и возвращает коды восстановления.
+// Коды показываются один раз: в базе остаются только их хэши.
+func (s *TwoFactorService) ConfirmTwoFactor(ctx context.Context, userId int, code string) ([]string, error) {
+	enrolled, err := s.repo.GetTOTP(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enrolled.Enabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(enrolled.Secret, normalizeTwoFactorCode(code), now)
+	if !ok {
+		return nil, invalidCodeError()
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.ConfirmTOTP(ctx, userId, step, now.UTC().Truncate(time.Second), hashes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTwoFactorNotEnrolled // подключение подтвердили или отменили параллельно
+	}
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor выключает 2FA; нужен действующий код, чтобы это не мог сделать кто-то с украденной сессией
+func (s *TwoFactorService) DisableTwoFactor(ctx context.Context, userId int, code string) error {
+	err := checkTwoFactorCode(ctx, s.repo, userId, code)
+	if errors.Is(err, ErrInvalidTwoFactorCode) {
+		return invalidCodeError()
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteTOTP(ctx, userId)
+}
+
+// checkTwoFactorCode принимает код из приложения или код восстановления; оба используются один раз
+func checkTwoFactorCode(ctx context.Context, repo repository.TwoFactor, userId int, code string) error {
+	enrolled, err := repo.GetTOTP(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !enrolled.Enabled()) {
+		return ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+
+	code = normalizeTwoFactorCode(code)
+	if step, ok := totp.Validate(enrolled.Secret, code, time.Now()); ok {
+		used, err := repo.UseTOTPStep(ctx, userId, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode // этот код уже вводили
+		}
+		return nil
+	}
+
+	err = repo.UseRecoveryCode(ctx, userId, hashSecret(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidTwoFactorCode
+	}
+	return err
+}
+
+// normalizeTwoFactorCode убирает пробелы и дефисы: приложения показывают "123 456", коды восстановления - "abcd-efgh"
+func normalizeTwoFactorCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
+
+func invalidCodeError() error {
+	return models.ValidationErrors{{Field: "code", Code: models.CodeInvalid, Message: ErrInvalidTwoFactorCode.Error()}}
+}
+
+// newRecoveryCodes возвращает коды для показа пользователю (xxxx-xxxx) и их хэши для хранения
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	buf := make([]byte, recoveryCodeSize)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(appPasswordEncoding.EncodeToString(buf))
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, hashSecret(code))
+	}
+	return codes, hashes, nil
+}
+
+// twoFactorChallenge возвращает *TwoFactorRequiredError, если у пользователя включена 2FA. Через него проходит
+// любой вход, который выпускает JWT: по паролю, через OIDC и связывание OIDC с существующим аккаунтом.
+// username - ключ блокировки входа, по нему GenerateTokenWithCode считает неверные коды.
+func twoFactorChallenge(ctx context.Context, repo repository.TwoFactor, userId int, username, authMethod string) error {
+	enrolled, err := repo.GetTOTP(ctx, userId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !enrolled.Enabled()) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	challenge, err := newChallenge(userId, username, authMethod)
+	if err != nil {
+		return err
+	}
+	return &TwoFactorRequiredError{ChallengeToken: challenge}
+}
+
+func newChallenge(userId int, username, authMethod string) (string, error) {
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, &challengeClaims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(challengeTTL).Unix(),
+			IssuedAt:  time.Now().Unix(),
+		},
+		UserId:     userId,
+		Username:   username,
+		AuthMethod: authMethod,
+	})
+
+	return jwtToken.SignedString(challengeKey())
+}
+
+func parseChallenge(challengeToken string) (challengeClaims, error) {
+	var claims challengeClaims
+	_, err := jwt.ParseWithClaims(challengeToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return challengeKey(), nil
+	})
+	return claims, err
+}
+
+func challengeKey() []byte {
+	return []byte("2fa-challenge:" + signingKey)
+}
@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret - ключ "12345678901234567890" из приложения B RFC 6238 для HMAC-SHA1 в base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// в RFC коды из 8 цифр, у нас 6: это последние 6 цифр того же значения
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d: got %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	if got, err := Code(strings.ToLower(rfcSecret), Step(time.Unix(59, 0))); err != nil || got != "287082" {
+		t.Errorf("Code with a lowercase secret: got %s, %v", got, err)
+	}
+	if _, err := Code("not base32!", 1); err == nil {
+		t.Error("Code with an invalid secret: got no error")
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name string
+		step int64
+		ok   bool
+	}{
+		{"current step", current, true},
+		{"previous step", current - 1, true},
+		{"next step", current + 1, true},
+		{"two steps back", current - 2, false},
+		{"two steps ahead", current + 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, tt.step)
+			if err != nil {
+				t.Fatalf("Code: %v", err)
+			}
+			step, ok := Validate(rfcSecret, code, now)
+			if ok != tt.ok || (ok && step != tt.step) {
+				t.Errorf("Validate: got step %d, %t; want step %d, %t", step, ok, tt.step, tt.ok)
+			}
+		})
+	}
+
+	// границы окна считаются по шагам, а не по секундам: последняя секунда шага current+1 еще принимается
+	code, _ := Code(rfcSecret, current)
+	if _, ok := Validate(rfcSecret, code, time.Unix((current+2)*30-1, 0)); !ok {
+		t.Error("Validate at the last second of the next step: rejected")
+	}
+	if _, ok := Validate(rfcSecret, code, time.Unix((current+2)*30, 0)); ok {
+		t.Error("Validate at the first second two steps later: accepted")
+	}
+}
+
+func TestValidateMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870821", "abcdef", "94287082"} {
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("Validate(%q): accepted", code)
+		}
+	}
+}
+
+// TestValidateReplay - Validate сам не помнит принятые коды: защиту от повтора дает шаг, который он возвращает
+// и который вызывающий сравнивает с последним принятым (см. models.TOTP.LastStep)
+func TestValidateReplay(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := Code(rfcSecret, Step(now))
+
+	var lastStep int64
+	accept := func(at time.Time) bool {
+		step, ok := Validate(rfcSecret, code, at)
+		if !ok || step <= lastStep {
+			return false
+		}
+		lastStep = step
+		return true
+	}
+
+	if !accept(now) {
+		t.Fatal("first use: rejected")
+	}
+	if accept(now) {
+		t.Error("same code in the same step: accepted")
+	}
+	if accept(now.Add(Period)) {
+		t.Error("same code in the next step, still within skew: accepted")
+	}
+}
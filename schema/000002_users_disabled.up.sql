@@ -0,0 +1,2 @@
+-- Заблокированные пользователи не могут войти
+ALTER TABLE users ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+DROP TABLE caldav_objects;
+
+DROP TABLE app_passwords;
@@ -0,0 +1,17 @@
+-- Пароли приложений для входа по HTTP Basic (CalDAV); хранится только SHA-256 пароля
+CREATE TABLE app_passwords (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Имя ресурса и UID, с которыми задачу создал CalDAV-клиент; задачам без записи соответствует item-<id>.ics
+CREATE TABLE caldav_objects (
+    item_id INT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    uid VARCHAR(255) NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES todo_items(id) ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+-- Персональные токены доступа для скриптов; хранится только SHA-256 токена.
+-- scopes - права через пробел, как scope в OAuth 2.0.
+CREATE TABLE access_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX access_tokens_user_id_idx ON access_tokens (user_id);
@@ -0,0 +1,2 @@
+-- Версия сессий пользователя попадает в JWT; смена пароля увеличивает ее и тем отзывает все выпущенные токены
+ALTER TABLE users ADD COLUMN session_version INT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+-- Удаленные задачи без списка не восстанавливаются
@@ -0,0 +1,2 @@
+-- Раньше удаление списка оставляло его задачи в todo_items без связки в lists_items
+DELETE FROM todo_items WHERE id NOT IN (SELECT item_id FROM lists_items);
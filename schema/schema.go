@@ -0,0 +1,14 @@
+// Package schema встраивает SQL-миграции в бинарник, чтобы приложению не нужны были файлы рядом с ним
+package schema
+
+import "embed"
+
+// Postgres - миграции для основного хранилища, файлы лежат в корне каталога
+//
+//go:embed *.sql
+var Postgres embed.FS
+
+// SQLite - миграции для хранилища SQLite, файлы лежат в каталоге sqlite
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS users_lists;
+DROP TABLE IF EXISTS lists_items;
+DROP TABLE IF EXISTS todo_items;
+DROP TABLE IF EXISTS todo_lists;
+DROP TABLE IF EXISTS users;
\ No newline at end of file
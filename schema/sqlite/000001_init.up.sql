@@ -0,0 +1,45 @@
+-- Та же схема, что и для Postgres: SERIAL заменен на INTEGER PRIMARY KEY AUTOINCREMENT,
+-- а внешние ключи работают только при включенной PRAGMA foreign_keys (ее выставляет NewSQLiteDB).
+
+-- Создание таблицы пользователей
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL
+);
+
+-- Создание таблицы списков задач
+CREATE TABLE todo_lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(255)
+);
+
+-- Промежуточная таблица "связка пользователей и списков"
+CREATE TABLE users_lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL,
+    list_id INT NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (list_id) REFERENCES todo_lists(id) ON DELETE CASCADE,
+    UNIQUE (user_id, list_id)
+);
+
+-- Создание таблицы задач
+CREATE TABLE todo_items(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(255),
+    done BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- Промежуточная таблица "связка списков и задач"
+CREATE TABLE lists_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    list_id INT NOT NULL,
+    item_id INT NOT NULL,
+    FOREIGN KEY (list_id) REFERENCES todo_lists(id) ON DELETE CASCADE,
+    FOREIGN KEY (item_id) REFERENCES todo_items(id) ON DELETE CASCADE,
+    UNIQUE (list_id, item_id)
+);
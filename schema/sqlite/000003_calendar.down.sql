@@ -0,0 +1,3 @@
+DROP TABLE calendar_feeds;
+
+ALTER TABLE todo_items DROP COLUMN due_date;
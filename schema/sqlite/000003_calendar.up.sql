@@ -0,0 +1,9 @@
+-- Срок выполнения задачи, по нему задачи попадают в календарь (VTODO DUE)
+ALTER TABLE todo_items ADD COLUMN due_date DATETIME;
+
+-- Секретные ссылки на ICS-ленту пользователя; хранится только SHA-256 токена
+CREATE TABLE calendar_feeds (
+    user_id INTEGER PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
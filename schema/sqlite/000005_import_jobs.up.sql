@@ -0,0 +1,22 @@
+-- Фоновые импорты из Trello и Todoist. data - списки задания в JSON, после завершения очищается.
+-- locked_until - unix-время, до которого задание держит обработчик; после него задание подхватывает любая реплика.
+CREATE TABLE import_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    data TEXT NOT NULL DEFAULT '',
+    total_lists INT NOT NULL DEFAULT 0,
+    total_items INT NOT NULL DEFAULT 0,
+    done_lists INT NOT NULL DEFAULT 0,
+    done_items INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    attempts INT NOT NULL DEFAULT 0,
+    locked_until INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX import_jobs_user_id_idx ON import_jobs (user_id);
+CREATE INDEX import_jobs_status_idx ON import_jobs (status);
@@ -0,0 +1,19 @@
+-- Двухфакторная аутентификация по TOTP. Пока confirmed_at пуст, подключение начато, но не подтверждено кодом.
+-- last_step - последний принятый шаг времени, коды этого и более ранних шагов повторно не принимаются.
+CREATE TABLE user_totp (
+    user_id INT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at DATETIME,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Одноразовые коды восстановления; хранится только SHA-256 кода, использованный код удаляется.
+CREATE TABLE recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (user_id, code_hash)
+);